@@ -16,8 +16,7 @@ import (
 	"gonum.org/v1/gonum/graph/community"
 	"gonum.org/v1/gonum/graph/encoding"
 	"gonum.org/v1/gonum/graph/network"
-	"gonum.org/v1/gonum/graph/path"
-	"gonum.org/v1/gonum/graph/topo"
+	"gonum.org/v1/gonum/graph/simple"
 )
 
 // PageRank performs a PageRank analysis on g using the provided damping
@@ -25,11 +24,20 @@ import (
 //
 // The PageRank value is written into the "rank" attribute of each node.
 func PageRank(g *Graph, damp, tol float64) {
+	done, err := trace(g, "PageRank", []string{"rank"}, "damp", damp, "tol", tol)
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
+	if g.Nodes().Len() == 0 {
+		return
+	}
 	rank := network.PageRank(directed{g}, damp, tol)
 
 	nodes := g.NodeMap()
 	for id, w := range rank {
-		nodes[id].SetAttribute(encoding.Attribute{"rank", fmt.Sprint(w)})
+		nodes[id].SetAttribute(encoding.Attribute{Key: "rank", Value: fmt.Sprint(w)})
 	}
 }
 
@@ -44,12 +52,17 @@ func (g directed) To(id int64) graph.Nodes           { return g.From(id) }
 //
 // The closeness centrality value is written into the "closeness" attribute of each node.
 func Closeness(g *Graph) {
-	p := path.DijkstraAllPaths(g)
-	rank := network.Closeness(g, p)
+	done, err := trace(g, "Closeness", []string{"closeness"})
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
+	rank := allShortestPaths(g).closeness()
 
 	nodes := g.NodeMap()
 	for id, w := range rank {
-		nodes[id].SetAttribute(encoding.Attribute{"closeness", fmt.Sprint(w)})
+		nodes[id].SetAttribute(encoding.Attribute{Key: "closeness", Value: fmt.Sprint(w)})
 	}
 }
 
@@ -57,12 +70,54 @@ func Closeness(g *Graph) {
 //
 // The farness centrality value is written into the "farness" attribute of each node.
 func Farness(g *Graph) {
-	p := path.DijkstraAllPaths(g)
-	rank := network.Farness(g, p)
+	done, err := trace(g, "Farness", []string{"farness"})
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
+	rank := allShortestPaths(g).farness()
+
+	nodes := g.NodeMap()
+	for id, w := range rank {
+		nodes[id].SetAttribute(encoding.Attribute{Key: "farness", Value: fmt.Sprint(w)})
+	}
+}
+
+// Harmonic performs a harmonic centrality analysis on g.
+//
+// The harmonic centrality value is written into the "harmonic" attribute of each node.
+func Harmonic(g *Graph) {
+	done, err := trace(g, "Harmonic", []string{"harmonic"})
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
+	rank := allShortestPaths(g).harmonic()
+
+	nodes := g.NodeMap()
+	for id, w := range rank {
+		nodes[id].SetAttribute(encoding.Attribute{Key: "harmonic", Value: fmt.Sprint(w)})
+	}
+}
+
+// Eccentricity performs an eccentricity analysis on g. Unreachable nodes are
+// not considered.
+//
+// The eccentricity value is written into the "eccentricity" attribute of each node.
+func Eccentricity(g *Graph) {
+	done, err := trace(g, "Eccentricity", []string{"eccentricity"})
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
+	rank := allShortestPaths(g).eccentricity()
 
 	nodes := g.NodeMap()
 	for id, w := range rank {
-		nodes[id].SetAttribute(encoding.Attribute{"farness", fmt.Sprint(w)})
+		nodes[id].SetAttribute(encoding.Attribute{Key: "eccentricity", Value: fmt.Sprint(w)})
 	}
 }
 
@@ -70,6 +125,12 @@ func Farness(g *Graph) {
 //
 // The betweenness centrality value is written into the "betweenness" attribute of each node.
 func Betweenness(g *Graph) {
+	done, err := trace(g, "Betweenness", []string{"betweenness"})
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
 	rank := network.Betweenness(g)
 	nodes := g.NodeMap()
 	// network.Betweenness does not retain zero
@@ -81,7 +142,7 @@ func Betweenness(g *Graph) {
 	}
 
 	for id, w := range rank {
-		nodes[id].SetAttribute(encoding.Attribute{"betweenness", fmt.Sprint(w)})
+		nodes[id].SetAttribute(encoding.Attribute{Key: "betweenness", Value: fmt.Sprint(w)})
 	}
 }
 
@@ -89,27 +150,120 @@ func Betweenness(g *Graph) {
 //
 // The edge betweenness centrality value is written into the "edge_betweenness" attribute of each edge.
 func EdgeBetweenness(g *Graph) {
+	done, err := trace(g, "EdgeBetweenness", []string{"edge_betweenness"})
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
 	rank := network.EdgeBetweenness(g)
 
 	for ids, w := range rank {
 		e := g.EdgeBetween(ids[0], ids[1])
-		e.(*Edge).SetAttribute(encoding.Attribute{"edge_betweenness", fmt.Sprint(w)})
+		e.(*Edge).SetAttribute(encoding.Attribute{Key: "edge_betweenness", Value: fmt.Sprint(w)})
 	}
 }
 
 // Communities performs a community modularisation of the graph g at the
-// specified resolution.
+// specified resolution. Edges are weighted by their "weight" attribute,
+// or 1 if it is not set. Since "weight" is also used as a layout hint in
+// DOT files, edges with a weight that is not a non-negative number are
+// given unit weight and a warning is logged with the logger set by
+// SetLogger; CommunitiesWithOptions returns an error instead.
 //
 // The community identity value is written into the "community" attribute of each node.
 func Communities(g *Graph, resolution float64) {
-	r := community.Modularize(g, resolution, nil)
+	// The only error returned when lenient is a
+	// refusal to overwrite, which has been logged.
+	communities(g, CommunityOptions{Resolution: resolution}, true)
+}
+
+// CommunityOptions controls community modularisation.
+type CommunityOptions struct {
+	// Resolution is the modularisation resolution.
+	Resolution float64
+
+	// Weight is the edge attribute holding edge
+	// weights. The default is "weight". Edges
+	// without the attribute have unit weight.
+	Weight string
+}
+
+// CommunitiesWithOptions performs a community modularisation of the graph
+// g using the provided options. It returns an error if an edge weight is
+// not a non-negative number.
+//
+// The community identity value is written into the "community" attribute of each node.
+func CommunitiesWithOptions(g *Graph, opts CommunityOptions) error {
+	return communities(g, opts, false)
+}
+
+// communities performs the community modularisation described by
+// CommunitiesWithOptions, with invalid weights handled as described by
+// weighted.
+func communities(g *Graph, opts CommunityOptions, lenient bool) (err error) {
+	if opts.Weight == "" {
+		opts.Weight = "weight"
+	}
+	done, err := trace(g, "Communities", []string{"community"}, "resolution", opts.Resolution, "weight", opts.Weight)
+	if err != nil {
+		return err
+	}
+	defer func() { done(err) }()
+
+	w, err := weighted(g, opts.Weight, lenient)
+	if err != nil {
+		return err
+	}
+	r := community.Modularize(w, opts.Resolution, nil)
 
 	nodes := g.NodeMap()
 	for i, c := range r.Communities() {
 		for _, n := range c {
-			nodes[n.ID()].SetAttribute(encoding.Attribute{"community", fmt.Sprint(i)})
+			nodes[n.ID()].SetAttribute(encoding.Attribute{Key: "community", Value: fmt.Sprint(i)})
 		}
 	}
+	return nil
+}
+
+// weighted returns a weighted copy of g with the same node IDs. Edge
+// weights are given by the attribute key of each edge, or 1 if it is
+// not set. Edges with zero weight are omitted. If a weight is not a
+// non-negative number, weighted returns an error, or, if lenient is true,
+// gives the edge unit weight and logs a warning.
+func weighted(g *Graph, key string, lenient bool) (*simple.WeightedUndirectedGraph, error) {
+	w := simple.NewWeightedUndirectedGraph(0, 0)
+	for _, n := range graph.NodesOf(g.Nodes()) {
+		w.AddNode(simple.Node(n.ID()))
+	}
+	var invalid int
+	for _, e := range graph.EdgesOf(g.Edges()) {
+		e := e.(*Edge)
+		v, err := edgeWeight(e, key)
+		if err == nil && v < 0 {
+			err = fmt.Errorf("negative %s for edge %q--%q: %v", key, e.F.Name, e.T.Name, v)
+		}
+		if err != nil {
+			if !lenient {
+				return nil, err
+			}
+			invalid++
+			v = 1
+		}
+		if v == 0 {
+			continue
+		}
+		w.SetWeightedEdge(w.NewWeightedEdge(simple.Node(e.F.ID()), simple.Node(e.T.ID()), v))
+	}
+	if invalid != 0 {
+		loggerMu.RLock()
+		l := logger
+		loggerMu.RUnlock()
+		if l != nil {
+			l.Warn("invalid edge weights given unit weight", "attribute", key, "edges", invalid)
+		}
+	}
+	return w, nil
 }
 
 // Clique performs a maximal clique analysis on g where cliques must be
@@ -119,7 +273,13 @@ func Communities(g *Graph, resolution float64) {
 // "clique" attribute of each node and the number of cliques a node is a member of
 // is written into "clique_count".
 func Clique(g *Graph, k int) {
-	mc := topo.BronKerbosch(g)
+	done, err := trace(g, "Clique", []string{"clique_count", "clique"}, "k", k)
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
+	mc := maximalCliques(g)
 	var ck int
 	for _, c := range mc {
 		if len(c) >= k {
@@ -134,19 +294,19 @@ func Clique(g *Graph, k int) {
 		}
 		for _, n := range c {
 			found := false
-			attrs := nodes[n.ID()].Attributes
-			if attrs.Get("clique_count") == "" {
-				for j, a := range attrs {
+			node := nodes[n.ID()]
+			if node.Get("clique_count") == "" {
+				for _, a := range node.Attributes {
 					if a.Key == "clique" {
-						attrs[j] = encoding.Attribute{"clique", fmt.Sprintf("%s,%d", a.Value, i)}
+						node.SetAttribute(encoding.Attribute{Key: "clique", Value: fmt.Sprintf("%s,%d", a.Value, i)})
 						found = true
 						break
 					}
 				}
 			}
 			if !found {
-				nodes[n.ID()].SetAttribute(encoding.Attribute{"clique", fmt.Sprint(i)})
-				nodes[n.ID()].SetAttribute(encoding.Attribute{"clique_count", ""})
+				nodes[n.ID()].SetAttribute(encoding.Attribute{Key: "clique", Value: fmt.Sprint(i)})
+				nodes[n.ID()].SetAttribute(encoding.Attribute{Key: "clique_count", Value: ""})
 			}
 		}
 		i++
@@ -154,7 +314,7 @@ func Clique(g *Graph, k int) {
 	for _, n := range nodes {
 		for _, a := range n.Attributes {
 			if a.Key == "clique" {
-				n.SetAttribute(encoding.Attribute{"clique_count", fmt.Sprint(len(strings.Split(a.Value, ",")))})
+				n.SetAttribute(encoding.Attribute{Key: "clique_count", Value: fmt.Sprint(len(strings.Split(a.Value, ",")))})
 				break
 			}
 		}
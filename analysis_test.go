@@ -0,0 +1,81 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"testing"
+)
+
+// weightedRing is a 6-cycle with alternating heavy and light edges
+// under the "weight" attribute, and the opposite alternation under "w".
+const weightedRing = `graph {
+	a -- b [weight=10, w=0.01];
+	b -- c [weight=0.01, w=10];
+	c -- d [weight=10, w=0.01];
+	d -- e [weight=0.01, w=10];
+	e -- f [weight=10, w=0.01];
+	f -- a [weight=0.01, w=10];
+}`
+
+var communityWeightTests = []struct {
+	opts CommunityOptions
+	want [][]string
+}{
+	{
+		opts: CommunityOptions{Resolution: 1},
+		want: [][]string{{"a", "b"}, {"c", "d"}, {"e", "f"}},
+	},
+	{
+		opts: CommunityOptions{Resolution: 1, Weight: "weight"},
+		want: [][]string{{"a", "b"}, {"c", "d"}, {"e", "f"}},
+	},
+	{
+		opts: CommunityOptions{Resolution: 1, Weight: "w"},
+		want: [][]string{{"b", "c"}, {"d", "e"}, {"f", "a"}},
+	},
+}
+
+func TestCommunitiesWithOptions(t *testing.T) {
+	for i, test := range communityWeightTests {
+		g := graphFromDOT(t, weightedRing)
+		if err := CommunitiesWithOptions(g, test.opts); err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		checkPartition(t, g, "community", test.want)
+	}
+}
+
+func TestCommunitiesInvalidWeight(t *testing.T) {
+	const dot = `graph { a -- b [weight=heavy]; b -- c; c -- a; c -- d [weight=-1] }`
+
+	g := graphFromDOT(t, dot)
+	if err := CommunitiesWithOptions(g, CommunityOptions{Resolution: 1}); err == nil {
+		t.Error("expected error for invalid weight")
+	}
+
+	l := &testLogger{}
+	SetLogger(l)
+	defer SetLogger(nil)
+	g = graphFromDOT(t, dot)
+	Communities(g, 1)
+	for _, n := range NodesOf(g) {
+		if n.Get("community") == "" {
+			t.Errorf("missing community for %s", n.Name)
+		}
+	}
+	var warned bool
+	for _, ev := range l.events {
+		if ev.level == "warn" {
+			warned = true
+			if got := ev.arg("edges"); got != 2 {
+				t.Errorf("unexpected number of invalid edges: got:%v want:2", got)
+			}
+		}
+	}
+	if !warned {
+		t.Error("expected warning for invalid weights")
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"math/bits"
+)
+
+// hllLog2m is the base 2 logarithm of the number of registers in each
+// HyperLogLog counter. The relative standard error of a counter is about
+// 1.04/sqrt(2^hllLog2m), 6.5% for 256 registers, but errors largely
+// cancel when the counters of many nodes are summed.
+const hllLog2m = 8
+
+// hllCounters is a set of HyperLogLog counters, one for each node of a
+// graph, estimating the sizes of sets of node indices.
+type hllCounters []uint8
+
+// newHLLCounters returns n counters, with counter i holding the set {i}.
+func newHLLCounters(n int) hllCounters {
+	const m = 1 << hllLog2m
+	c := make(hllCounters, n*m)
+	for i := 0; i < n; i++ {
+		h := splitmix64(uint64(i))
+		j := h >> (64 - hllLog2m)
+		rank := uint8(bits.LeadingZeros64(h<<hllLog2m|1<<(hllLog2m-1)) + 1)
+		c[i*m+int(j)] = rank
+	}
+	return c
+}
+
+// counter returns the registers of counter i.
+func (c hllCounters) counter(i int) []uint8 {
+	const m = 1 << hllLog2m
+	return c[i*m : (i+1)*m]
+}
+
+// union sets counter dst of c to the union of dst and counter src of s,
+// returning whether dst was changed.
+func (c hllCounters) union(dst int, s hllCounters, src int) bool {
+	a, b := c.counter(dst), s.counter(src)
+	var changed bool
+	for k, r := range b {
+		if r > a[k] {
+			a[k] = r
+			changed = true
+		}
+	}
+	return changed
+}
+
+// size returns the estimated size of the set held by counter i.
+func (c hllCounters) size(i int) float64 {
+	const m = 1 << hllLog2m
+	alpha := 0.7213 / (1 + 1.079/m)
+	var sum float64
+	var zeros int
+	for _, r := range c.counter(i) {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	e := alpha * m * m / sum
+	if e <= 2.5*m && zeros != 0 {
+		// Use linear counting for small sets.
+		e = m * math.Log(float64(m)/float64(zeros))
+	}
+	return e
+}
+
+// splitmix64 returns a well mixed hash of x.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// NeighborhoodFunction returns the approximate neighbourhood function of
+// g, N(t), the number of ordered pairs of nodes (u, v), including u = v,
+// with v within t hops of u, for t from 0 to maxHops. If maxHops is
+// negative, N is computed until it stops increasing, so the last element
+// is the number of pairs of nodes that are connected.
+//
+// The function is estimated with the HyperANF algorithm (Boldi, Rosa and
+// Vigna, doi:10.1145/1963405.1963493), which keeps a HyperLogLog counter
+// of the ball of nodes within t hops of each node and grows each ball by
+// taking the union with the balls of the node's neighbours. Each step
+// takes time proportional to the number of edges and memory proportional
+// to the number of nodes, so the function can be estimated for graphs
+// that are too large for exact all-pairs shortest paths. Each value has a
+// relative standard error of a few percent.
+func NeighborhoodFunction(g *Graph, maxHops int) []float64 {
+	x := g.Index()
+	adj := x.adjacency(g)
+	n := len(adj)
+
+	cur := newHLLCounters(n)
+	next := make(hllCounters, len(cur))
+	nf := []float64{sumSizes(cur, n)}
+	for t := 1; maxHops < 0 || t <= maxHops; t++ {
+		copy(next, cur)
+		var changed bool
+		for i, to := range adj {
+			for _, j := range to {
+				if next.union(i, cur, j) {
+					changed = true
+				}
+			}
+		}
+		cur, next = next, cur
+		if !changed {
+			if maxHops < 0 {
+				break
+			}
+			// The balls have stopped growing, so the
+			// remaining values are all the same.
+			for ; t <= maxHops; t++ {
+				nf = append(nf, nf[len(nf)-1])
+			}
+			break
+		}
+		nf = append(nf, sumSizes(cur, n))
+	}
+	return nf
+}
+
+// sumSizes returns the sum of the estimated sizes of the first n counters
+// in c.
+func sumSizes(c hllCounters, n int) float64 {
+	var sum float64
+	for i := 0; i < n; i++ {
+		sum += c.size(i)
+	}
+	return sum
+}
+
+// EffectiveDiameter returns the effective diameter of g, the number of
+// hops within which the fraction q of connected pairs of distinct nodes
+// lie, interpolated between whole numbers of hops. The common choice of q
+// is 0.9. The distribution of path lengths is estimated with
+// NeighborhoodFunction so that the measure can be computed for graphs that
+// are too large for exact all-pairs shortest paths.
+// EffectiveDiameter panics if q is not in (0, 1].
+func EffectiveDiameter(g *Graph, q float64) float64 {
+	if q <= 0 || q > 1 {
+		panic("graphprac: invalid effective diameter quantile")
+	}
+	nf := NeighborhoodFunction(g, -1)
+
+	// Exclude the pairs of each node with itself.
+	self := nf[0]
+	total := nf[len(nf)-1] - self
+	if total <= 0 {
+		return 0
+	}
+	target := q * total
+	for h := 1; h < len(nf); h++ {
+		pairs := nf[h] - self
+		if pairs < target {
+			continue
+		}
+		prev := nf[h-1] - self
+		if pairs == prev {
+			return float64(h)
+		}
+		return float64(h-1) + (target-prev)/(pairs-prev)
+	}
+	return float64(len(nf) - 1)
+}
@@ -0,0 +1,98 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+var effectiveDiameterTests = []struct {
+	dot  string
+	q    float64
+	want float64
+}{
+	// The ordered pairs of distinct nodes of a 4-path are
+	// 6 at 1 hop, 4 at 2 hops and 2 at 3 hops.
+	{dot: `graph { a -- b -- c -- d }`, q: 0.5, want: 1},
+	{dot: `graph { a -- b -- c -- d }`, q: 0.9, want: 2.4},
+	{dot: `graph { a -- b -- c -- d }`, q: 1, want: 3},
+	{dot: `graph { a -- b -- c -- a }`, q: 0.9, want: 0.9},
+	{dot: `graph { a; b }`, q: 0.9, want: 0},
+}
+
+func TestEffectiveDiameter(t *testing.T) {
+	for i, test := range effectiveDiameterTests {
+		g := graphFromDOT(t, test.dot)
+		if got := EffectiveDiameter(g, test.q); math.Abs(got-test.want) > 0.05 {
+			t.Errorf("unexpected effective diameter for test %d: got:%v want:%v", i, got, test.want)
+		}
+	}
+
+	for _, q := range []float64{0, -1, 1.5} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for q=%v", q)
+				}
+			}()
+			EffectiveDiameter(graphFromDOT(t, `graph { a -- b }`), q)
+		}()
+	}
+}
+
+func TestNeighborhoodFunction(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b -- c -- d; e }`)
+	want := []float64{5, 11, 15, 17}
+	for _, test := range []struct {
+		maxHops int
+		want    []float64
+	}{
+		{maxHops: -1, want: want},
+		{maxHops: 0, want: want[:1]},
+		{maxHops: 2, want: want[:3]},
+		{maxHops: 5, want: append(want, 17, 17)},
+	} {
+		got := NeighborhoodFunction(g, test.maxHops)
+		if len(got) != len(test.want) {
+			t.Errorf("unexpected length for maxHops=%d: got:%d want:%d", test.maxHops, len(got), len(test.want))
+			continue
+		}
+		for h, v := range got {
+			if math.Abs(v-test.want[h]) > 0.02*test.want[h] {
+				t.Errorf("unexpected N(%d) for maxHops=%d: got:%v want:%v", h, test.maxHops, v, test.want[h])
+			}
+		}
+	}
+}
+
+func TestNeighborhoodFunctionRing(t *testing.T) {
+	// Within t hops of each node of an n-ring there are
+	// 2t+1 nodes until the ball covers the ring.
+	const n = 400
+	var buf strings.Builder
+	buf.WriteString("graph {\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, "\tn%d -- n%d;\n", i, (i+1)%n)
+	}
+	buf.WriteString("}")
+	g := graphFromDOT(t, buf.String())
+
+	got := NeighborhoodFunction(g, -1)
+	if len(got) != n/2+1 {
+		t.Fatalf("unexpected length: got:%d want:%d", len(got), n/2+1)
+	}
+	for h, v := range got {
+		want := float64(n * (2*h + 1))
+		if want > n*n {
+			want = n * n
+		}
+		if math.Abs(v-want) > 0.1*want {
+			t.Errorf("unexpected N(%d): got:%v want:%v", h, v, want)
+		}
+	}
+}
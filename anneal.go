@@ -0,0 +1,328 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// AnnealSchedule is the cooling schedule of a simulated annealing
+// optimisation. Zero fields take their default values.
+//
+// Temperatures are measured in units of 1/m, where m is the total edge
+// weight of the graph, so that at a temperature of 1 a move that reduces
+// the modularity by 1/m is accepted with probability 1/e.
+type AnnealSchedule struct {
+	// Start and Stop are the initial and
+	// final temperatures. The defaults are
+	// 1 and 0.01.
+	Start, Stop float64
+
+	// Cooling is the factor by which the
+	// temperature is multiplied after each
+	// round of moves. It must be in (0, 1).
+	// The default is 0.95.
+	Cooling float64
+
+	// Moves is the number of moves attempted
+	// for each node at each temperature. The
+	// default is 50.
+	Moves int
+}
+
+// AnnealCommunities performs a community modularisation of g by simulated
+// annealing (Guimerà and Amaral, doi:10.1038/nature03288) following the
+// provided cooling schedule, and returns the modularity of the final
+// partition. Starting from each node in its own community, randomly
+// chosen nodes are moved to the community of a random neighbour or to a
+// new community, with moves that reduce the modularity accepted with a
+// probability that falls with the temperature. After the schedule
+// completes, nodes are greedily moved until no move increases the
+// modularity. This is much slower than Communities, but usually finds
+// partitions with higher modularity. The moves are determined by seed.
+// Edges are weighted by their "weight" attribute, or 1 if it is not set.
+// AnnealCommunities returns an error if a weight is negative.
+//
+// AnnealCommunities panics if the schedule is invalid.
+//
+// The community identity value is written into the "community_sa" attribute of each node.
+func AnnealCommunities(g *Graph, schedule AnnealSchedule, seed int64) (modularity float64, err error) {
+	if schedule.Start == 0 {
+		schedule.Start = 1
+	}
+	if schedule.Stop == 0 {
+		schedule.Stop = 0.01
+	}
+	if schedule.Cooling == 0 {
+		schedule.Cooling = 0.95
+	}
+	if schedule.Moves == 0 {
+		schedule.Moves = 50
+	}
+	if schedule.Stop < 0 || schedule.Start < schedule.Stop || schedule.Cooling < 0 || schedule.Cooling >= 1 || schedule.Moves < 0 {
+		panic("graphprac: invalid anneal schedule")
+	}
+	done, err := trace(g, "AnnealCommunities", []string{"community_sa"},
+		"start", schedule.Start, "stop", schedule.Stop, "cooling", schedule.Cooling, "moves", schedule.Moves, "seed", seed)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { done(err) }()
+
+	x := g.Index()
+	adj := x.adjacency(g)
+	w, err := edgeWeights(g, x, adj)
+	if err != nil {
+		return 0, err
+	}
+	p := newPartition(adj, w)
+	n := len(adj)
+
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	if p.m2 != 0 {
+		m := p.m2 / 2
+		for t := schedule.Start; t >= schedule.Stop; t *= schedule.Cooling {
+			for k := 0; k < schedule.Moves*n; k++ {
+				i := rnd.Intn(n)
+				if len(adj[i]) == 0 {
+					continue
+				}
+				var to int
+				if r := rnd.Intn(len(adj[i]) + 1); r < len(adj[i]) {
+					to = p.comm[adj[i][r]]
+				} else {
+					if p.size[p.comm[i]] == 1 {
+						continue
+					}
+					to = p.empty()
+				}
+				if to == p.comm[i] {
+					continue
+				}
+				p.weightsTo(i)
+				if dq := p.gain(i, to); dq >= 0 || rnd.Float64() < math.Exp(dq*m/t) {
+					p.move(i, to)
+				}
+			}
+		}
+		p.quench()
+	}
+
+	for i, c := range p.labels() {
+		x.Node(i).SetAttribute(encoding.Attribute{Key: "community_sa", Value: fmt.Sprint(c)})
+	}
+	return p.modularity(), nil
+}
+
+// partition is a partition of the nodes of a weighted graph into
+// communities for modularity optimisation. Nodes and communities are
+// indexed from 0 to n-1.
+type partition struct {
+	adj [][]int
+	w   [][]float64
+
+	// k is the strength of each node and
+	// m2 is the sum of strengths, twice
+	// the total edge weight.
+	k  []float64
+	m2 float64
+
+	// res is the modularity resolution.
+	res float64
+
+	// comm is the community of each node,
+	// and tot and size are the total
+	// strength and number of nodes of each
+	// community.
+	comm []int
+	tot  []float64
+	size []int
+
+	// free holds the empty communities.
+	free []int
+
+	// wTo holds the weight of edges from the
+	// current node to each community in
+	// touched.
+	wTo     []float64
+	touched []int
+}
+
+// newPartition returns a partition of the graph with the adjacency lists
+// adj and edge weights w with each node in its own community.
+func newPartition(adj [][]int, w [][]float64) *partition {
+	n := len(adj)
+	k := make([]float64, n)
+	var m2 float64
+	for i := range adj {
+		k[i] = sum(w[i])
+		m2 += k[i]
+	}
+	p := newPartitionOf(adj, w, k, m2, 1)
+	comm := make([]int, n)
+	for i := range comm {
+		comm[i] = i
+	}
+	p.assign(comm)
+	return p
+}
+
+// newPartitionOf returns an unassigned partition of the graph with the
+// adjacency lists adj, edge weights w, node strengths k including the
+// weight of any self loops, total strength m2 and modularity resolution
+// res.
+func newPartitionOf(adj [][]int, w [][]float64, k []float64, m2, res float64) *partition {
+	n := len(adj)
+	return &partition{
+		adj:  adj,
+		w:    w,
+		k:    k,
+		m2:   m2,
+		res:  res,
+		tot:  make([]float64, n),
+		size: make([]int, n),
+		wTo:  make([]float64, n),
+	}
+}
+
+// assign sets the community of each node to the values in comm, which
+// must be in [0, n).
+func (p *partition) assign(comm []int) {
+	p.comm = comm
+	for c := range p.tot {
+		p.tot[c] = 0
+		p.size[c] = 0
+	}
+	for i, c := range comm {
+		p.tot[c] += p.k[i]
+		p.size[c]++
+	}
+	p.free = p.free[:0]
+	for c, n := range p.size {
+		if n == 0 {
+			p.free = append(p.free, c)
+		}
+	}
+}
+
+// empty returns an empty community.
+func (p *partition) empty() int {
+	return p.free[len(p.free)-1]
+}
+
+// weightsTo sets wTo to the weights of edges from node i to each community.
+func (p *partition) weightsTo(i int) {
+	for _, c := range p.touched {
+		p.wTo[c] = 0
+	}
+	p.touched = p.touched[:0]
+	for k, j := range p.adj[i] {
+		c := p.comm[j]
+		if p.wTo[c] == 0 {
+			p.touched = append(p.touched, c)
+		}
+		p.wTo[c] += p.w[i][k]
+	}
+}
+
+// gain returns the change in modularity of moving node i to community c.
+// wTo must hold the weights from i.
+func (p *partition) gain(i, c int) float64 {
+	a := p.comm[i]
+	if c == a {
+		return 0
+	}
+	return 2*(p.wTo[c]-p.wTo[a])/p.m2 - 2*p.res*p.k[i]*(p.tot[c]-p.tot[a]+p.k[i])/(p.m2*p.m2)
+}
+
+// move moves node i to community c.
+func (p *partition) move(i, c int) {
+	a := p.comm[i]
+	if c == a {
+		return
+	}
+	if p.size[c] == 0 {
+		p.free = p.free[:len(p.free)-1]
+	}
+	p.tot[a] -= p.k[i]
+	p.size[a]--
+	if p.size[a] == 0 {
+		p.free = append(p.free, a)
+	}
+	p.tot[c] += p.k[i]
+	p.size[c]++
+	p.comm[i] = c
+}
+
+// quench moves each node to the community of its neighbours, or a new
+// community, that most increases the modularity until no move increases
+// it.
+func (p *partition) quench() {
+	const tol = 1e-12
+	for moved := true; moved; {
+		moved = false
+		for i := range p.adj {
+			p.weightsTo(i)
+			best, bestGain := p.comm[i], tol
+			for _, c := range p.touched {
+				if dq := p.gain(i, c); dq > bestGain {
+					best, bestGain = c, dq
+				}
+			}
+			if p.size[p.comm[i]] > 1 {
+				if c := p.empty(); p.gain(i, c) > bestGain {
+					best = c
+				}
+			}
+			if best != p.comm[i] {
+				p.move(i, best)
+				moved = true
+			}
+		}
+	}
+}
+
+// labels returns the community of each node renumbered from zero in
+// order of the first node in each community.
+func (p *partition) labels() []int {
+	label := make(map[int]int)
+	l := make([]int, len(p.comm))
+	for i, c := range p.comm {
+		id, ok := label[c]
+		if !ok {
+			id = len(label)
+			label[c] = id
+		}
+		l[i] = id
+	}
+	return l
+}
+
+// modularity returns the modularity of the partition. The graph must not
+// have self loops.
+func (p *partition) modularity() float64 {
+	if p.m2 == 0 {
+		return 0
+	}
+	var q float64
+	for i, to := range p.adj {
+		for k, j := range to {
+			if p.comm[i] == p.comm[j] {
+				q += p.w[i][k]
+			}
+		}
+	}
+	q /= p.m2
+	for c, t := range p.tot {
+		if p.size[c] != 0 {
+			q -= p.res * (t / p.m2) * (t / p.m2)
+		}
+	}
+	return q
+}
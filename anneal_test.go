@@ -0,0 +1,69 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"testing"
+)
+
+var annealTests = []struct {
+	dot    string
+	q      float64
+	groups [][]string
+}{
+	{
+		dot:    twoTriangles,
+		q:      6.0/7 - 2*(7.0/14)*(7.0/14),
+		groups: [][]string{{"a", "b", "c"}, {"d", "e", "f"}},
+	},
+	{
+		dot:    ringOfCliques(4, 5),
+		q:      40.0/44 - 4*(22.0/88)*(22.0/88),
+		groups: cliqueGroups(4, 5),
+	},
+	{
+		dot:    `graph { a; b }`,
+		q:      0,
+		groups: [][]string{{"a"}, {"b"}},
+	},
+}
+
+func TestAnnealCommunities(t *testing.T) {
+	for i, test := range annealTests {
+		g := graphFromDOT(t, test.dot)
+		q, err := AnnealCommunities(g, AnnealSchedule{Moves: 10}, 1)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		if math.Abs(q-test.q) > 1e-12 {
+			t.Errorf("unexpected modularity for test %d: got:%v want:%v", i, q, test.q)
+		}
+		checkPartition(t, g, "community_sa", test.groups)
+	}
+
+	g := graphFromDOT(t, `graph { a -- b [weight=-1] }`)
+	if _, err := AnnealCommunities(g, AnnealSchedule{}, 1); err == nil {
+		t.Error("expected error for negative weight")
+	}
+
+	for _, s := range []AnnealSchedule{
+		{Start: 0.1, Stop: 1},
+		{Cooling: 1},
+		{Cooling: -0.5},
+		{Moves: -1},
+		{Stop: -1},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for schedule %+v", s)
+				}
+			}()
+			AnnealCommunities(graphFromDOT(t, twoTriangles), s, 1)
+		}()
+	}
+}
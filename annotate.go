@@ -0,0 +1,294 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// AnnotationFormat is the format of a protein annotation file read by
+// AnnotateProteins.
+type AnnotationFormat int
+
+// Protein annotation formats.
+const (
+	// GAFAnnotation is the Gene Ontology
+	// Consortium's GO annotation file format.
+	GAFAnnotation AnnotationFormat = iota
+
+	// UniProtAnnotation is the UniProtKB
+	// flat file format.
+	UniProtAnnotation
+)
+
+func (f AnnotationFormat) String() string {
+	switch f {
+	case GAFAnnotation:
+		return "GAF"
+	case UniProtAnnotation:
+		return "UniProt"
+	default:
+		return fmt.Sprintf("AnnotationFormat(%d)", int(f))
+	}
+}
+
+// proteinRecord is the annotation of a single protein.
+type proteinRecord struct {
+	// ids holds the identifiers used to
+	// match the protein to nodes.
+	ids []string
+
+	accession   string
+	description string
+	organism    string
+	terms       []string
+}
+
+// AnnotateProteins reads protein annotations in the given format from r
+// and writes them into the attributes of the nodes of g they describe.
+// Nodes are matched to proteins, ignoring case, by DOT ID against the
+// accessions, entry names, gene names, synonyms and ordered locus and ORF
+// names of the proteins, so the systematic and standard names used in
+// protein interaction networks are both recognised.
+//
+// The primary accession of a protein is written into the "accession"
+// attribute, its name into "description", its organism into "organism",
+// and its Gene Ontology term IDs, sorted and separated by commas, into
+// "go". GAF annotations with a NOT qualifier are ignored, and terms from
+// all the records matching a node are merged. The organism of GAF records
+// is their taxon, for example "taxon:559292".
+//
+// AnnotateProteins returns an error if no node of g matches a protein.
+// Syntax errors in the data are returned as a *ParseError.
+func AnnotateProteins(g *Graph, r io.Reader, format AnnotationFormat) error {
+	var (
+		records []*proteinRecord
+		err     error
+	)
+	switch format {
+	case GAFAnnotation:
+		records, err = readGAF(r)
+	case UniProtAnnotation:
+		records, err = readUniProt(r)
+	default:
+		return fmt.Errorf("invalid annotation format: %v", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string][]*proteinRecord)
+	for _, p := range records {
+		seen := make(map[string]bool)
+		for _, id := range p.ids {
+			id = strings.ToLower(id)
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			byID[id] = append(byID[id], p)
+		}
+	}
+
+	var annotated int
+	for _, n := range NodesOf(g) {
+		matches := byID[strings.ToLower(n.Name)]
+		if len(matches) == 0 {
+			continue
+		}
+		annotated++
+		terms := make(map[string]bool)
+		for _, p := range matches {
+			for _, t := range p.terms {
+				terms[t] = true
+			}
+		}
+		p := matches[0]
+		for _, kv := range [...]struct{ key, val string }{
+			{key: "accession", val: p.accession},
+			{key: "description", val: p.description},
+			{key: "organism", val: p.organism},
+			{key: "go", val: strings.Join(sortedKeys(terms), ",")},
+		} {
+			if kv.val != "" {
+				n.SetQuoted(kv.key, kv.val)
+			}
+		}
+	}
+	if annotated == 0 {
+		return fmt.Errorf("no node matches an annotated protein")
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of set in lexical order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// readGAF reads GO annotation file records from r, merging the lines
+// describing the same database object.
+func readGAF(r io.Reader) ([]*proteinRecord, error) {
+	var records []*proteinRecord
+	objects := make(map[string]*proteinRecord)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if text == "" || text[0] == '!' {
+			continue
+		}
+		f := strings.Split(text, "\t")
+		if len(f) < 15 {
+			return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("expected at least 15 fields, got %d", len(f))}
+		}
+		key := f[0] + ":" + f[1]
+		p, ok := objects[key]
+		if !ok {
+			p = &proteinRecord{
+				ids:         append([]string{f[1], f[2]}, strings.Split(f[10], "|")...),
+				accession:   f[1],
+				description: f[9],
+				organism:    strings.Split(f[12], "|")[0],
+			}
+			objects[key] = p
+			records = append(records, p)
+		}
+		if !strings.HasPrefix(f[4], "GO:") {
+			return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("invalid GO ID %q", f[4])}
+		}
+		if !hasQualifier(f[3], "NOT") {
+			p.terms = append(p.terms, f[4])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// hasQualifier returns whether the '|' separated GAF qualifiers in q
+// include want.
+func hasQualifier(q, want string) bool {
+	for _, s := range strings.Split(q, "|") {
+		if strings.EqualFold(s, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// readUniProt reads UniProtKB flat file entries from r.
+func readUniProt(r io.Reader) ([]*proteinRecord, error) {
+	var (
+		records  []*proteinRecord
+		p        *proteinRecord
+		organism []string
+	)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if text == "//" {
+			if p == nil {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("entry terminator without entry")}
+			}
+			p.organism = strings.TrimSuffix(strings.Join(organism, " "), ".")
+			records = append(records, p)
+			p, organism = nil, nil
+			continue
+		}
+		if len(text) < 2 {
+			return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("missing line code")}
+		}
+		code, data := text[:2], ""
+		if len(text) > 5 {
+			data = strings.TrimSpace(text[5:])
+		}
+		if p == nil {
+			if code != "ID" {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("expected ID line at start of entry")}
+			}
+			p = &proteinRecord{}
+		}
+		switch code {
+		case "ID":
+			f := strings.Fields(data)
+			if len(f) == 0 {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("missing entry name")}
+			}
+			p.ids = append(p.ids, f[0])
+		case "AC":
+			for _, ac := range strings.Split(data, ";") {
+				ac = strings.TrimSpace(ac)
+				if ac == "" {
+					continue
+				}
+				if p.accession == "" {
+					p.accession = ac
+				}
+				p.ids = append(p.ids, ac)
+			}
+		case "DE":
+			if p.description == "" {
+				if i := strings.Index(data, "Full="); i >= 0 {
+					p.description = strings.TrimSuffix(withoutEvidence(data[i+len("Full="):]), ";")
+				}
+			}
+		case "GN":
+			for _, item := range strings.Split(data, ";") {
+				i := strings.Index(item, "=")
+				if i < 0 {
+					continue
+				}
+				for _, name := range strings.Split(withoutEvidence(item[i+1:]), ",") {
+					p.ids = append(p.ids, strings.TrimSpace(name))
+				}
+			}
+		case "OS":
+			organism = append(organism, data)
+		case "DR":
+			f := strings.Split(data, ";")
+			if len(f) > 1 && strings.TrimSpace(f[0]) == "GO" {
+				p.terms = append(p.terms, strings.TrimSpace(f[1]))
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if p != nil {
+		return nil, fmt.Errorf("unterminated entry")
+	}
+	return records, nil
+}
+
+// withoutEvidence returns s with any UniProt evidence tags, such as
+// "{ECO:0000269|PubMed:10089879}", removed.
+func withoutEvidence(s string) string {
+	for {
+		i := strings.Index(s, "{")
+		if i < 0 {
+			return strings.TrimSpace(s)
+		}
+		j := strings.Index(s[i:], "}")
+		if j < 0 {
+			return strings.TrimSpace(s[:i])
+		}
+		s = strings.TrimRight(s[:i], " ") + s[i+j+1:]
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gafLine returns a GAF 2.2 line for the given object and GO term.
+func gafLine(id, symbol, qualifier, term, name, synonyms, taxon string) string {
+	return strings.Join([]string{
+		"SGD", id, symbol, qualifier, term, "PMID:1", "IDA", "", "P",
+		name, synonyms, "protein", taxon, "20170101", "SGD",
+	}, "\t")
+}
+
+var gafData = strings.Join([]string{
+	"!gaf-version: 2.2",
+	gafLine("S000000253", "CDC28", "enables", "GO:0004693", "Cyclin-dependent kinase", "YBR160W|CDK1", "taxon:559292"),
+	gafLine("S000000253", "CDC28", "involved_in", "GO:0000082", "Cyclin-dependent kinase", "YBR160W|CDK1", "taxon:559292"),
+	gafLine("S000000253", "CDC28", "NOT|involved_in", "GO:0006260", "Cyclin-dependent kinase", "YBR160W|CDK1", "taxon:559292"),
+	gafLine("S000002937", "CLN3", "enables", "GO:0016538", "G1/S-specific cyclin", "YAL040C", "taxon:559292|taxon:4932"),
+	"",
+}, "\n")
+
+const uniProtData = `ID   CDK1_YEAST              Reviewed;         298 AA.
+AC   P00546; D6VQG1;
+DE   RecName: Full=Cyclin-dependent kinase 1 {ECO:0000305};
+DE            Short=CDK1;
+GN   Name=CDC28 {ECO:0000303|PubMed:1}; Synonyms=CDK1, HSL5;
+GN   OrderedLocusNames=YBR160W; ORFNames=YBR1211;
+OS   Saccharomyces cerevisiae (strain ATCC 204508 / S288c)
+OS   (Baker's yeast).
+DR   GO; GO:0004693; F:cyclin-dependent protein serine/threonine kinase activity; IDA:SGD.
+DR   GO; GO:0000082; P:G1/S transition of mitotic cell cycle; IMP:SGD.
+DR   PDB; 1ABC; X-ray; A=1-298.
+//
+ID   CG13_YEAST              Reviewed;         580 AA.
+AC   P20438;
+DE   RecName: Full=G1/S-specific cyclin CLN3;
+GN   Name=CLN3; OrderedLocusNames=YAL040C;
+OS   Saccharomyces cerevisiae.
+//
+`
+
+var annotateProteinsTests = []struct {
+	name   string
+	data   string
+	format AnnotationFormat
+	want   map[string]Attributes
+}{
+	{
+		name:   "gaf",
+		data:   gafData,
+		format: GAFAnnotation,
+		want: map[string]Attributes{
+			"cdc28": {
+				{Key: "accession", Value: `"S000000253"`},
+				{Key: "description", Value: `"Cyclin-dependent kinase"`},
+				{Key: "organism", Value: `"taxon:559292"`},
+				{Key: "go", Value: `"GO:0000082,GO:0004693"`},
+			},
+			"YBR160W": {
+				{Key: "accession", Value: `"S000000253"`},
+				{Key: "description", Value: `"Cyclin-dependent kinase"`},
+				{Key: "organism", Value: `"taxon:559292"`},
+				{Key: "go", Value: `"GO:0000082,GO:0004693"`},
+			},
+			"YAL040C": {
+				{Key: "accession", Value: `"S000002937"`},
+				{Key: "description", Value: `"G1/S-specific cyclin"`},
+				{Key: "organism", Value: `"taxon:559292"`},
+				{Key: "go", Value: `"GO:0016538"`},
+			},
+			"other": nil,
+		},
+	},
+	{
+		name:   "uniprot",
+		data:   uniProtData,
+		format: UniProtAnnotation,
+		want: map[string]Attributes{
+			"cdc28": {
+				{Key: "accession", Value: `"P00546"`},
+				{Key: "description", Value: `"Cyclin-dependent kinase 1"`},
+				{Key: "organism", Value: `"Saccharomyces cerevisiae (strain ATCC 204508 / S288c) (Baker's yeast)"`},
+				{Key: "go", Value: `"GO:0000082,GO:0004693"`},
+			},
+			"YBR160W": {
+				{Key: "accession", Value: `"P00546"`},
+				{Key: "description", Value: `"Cyclin-dependent kinase 1"`},
+				{Key: "organism", Value: `"Saccharomyces cerevisiae (strain ATCC 204508 / S288c) (Baker's yeast)"`},
+				{Key: "go", Value: `"GO:0000082,GO:0004693"`},
+			},
+			"YAL040C": {
+				{Key: "accession", Value: `"P20438"`},
+				{Key: "description", Value: `"G1/S-specific cyclin CLN3"`},
+				{Key: "organism", Value: `"Saccharomyces cerevisiae"`},
+			},
+			"other": nil,
+		},
+	},
+}
+
+func TestAnnotateProteins(t *testing.T) {
+	for _, test := range annotateProteinsTests {
+		g := graphFromDOT(t, `graph { cdc28 -- YAL040C; YBR160W -- other; }`)
+		err := AnnotateProteins(g, strings.NewReader(test.data), test.format)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", test.name, err)
+			continue
+		}
+		for name, want := range test.want {
+			got := g.NodeNamed(name).Attributes
+			if len(got) == 0 && len(want) == 0 {
+				continue
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("unexpected attributes for %s node %s:\ngot: %v\nwant:%v", test.name, name, got, want)
+			}
+		}
+	}
+}
+
+var annotateProteinsErrorTests = []struct {
+	name    string
+	data    string
+	format  AnnotationFormat
+	isParse bool
+}{
+	{name: "format", data: gafData, format: AnnotationFormat(-1)},
+	{name: "no match", data: gafLine("S1", "ABC1", "", "GO:0000001", "", "", "taxon:1"), format: GAFAnnotation},
+	{name: "short gaf", data: "SGD\tS1\tCDC28\n", format: GAFAnnotation, isParse: true},
+	{name: "gaf term", data: gafLine("S1", "CDC28", "", "0000001", "", "", "taxon:1"), format: GAFAnnotation, isParse: true},
+	{name: "no ID", data: "AC   P00546;\n//\n", format: UniProtAnnotation, isParse: true},
+	{name: "bare terminator", data: "//\n", format: UniProtAnnotation, isParse: true},
+	{name: "unterminated", data: "ID   CDC28\n", format: UniProtAnnotation},
+}
+
+func TestAnnotateProteinsErrors(t *testing.T) {
+	for _, test := range annotateProteinsErrorTests {
+		g := graphFromDOT(t, `graph { cdc28 -- other; }`)
+		err := AnnotateProteins(g, strings.NewReader(test.data), test.format)
+		if err == nil {
+			t.Errorf("expected error for %s", test.name)
+			continue
+		}
+		if _, ok := err.(*ParseError); ok != test.isParse {
+			t.Errorf("unexpected error type for %s: %T", test.name, err)
+		}
+	}
+}
+
+var withoutEvidenceTests = []struct {
+	in, want string
+}{
+	{in: "CDC28", want: "CDC28"},
+	{in: "CDC28 {ECO:0000303|PubMed:1}", want: "CDC28"},
+	{in: "A {ECO:1}, B {ECO:2}", want: "A, B"},
+	{in: "A {ECO:1", want: "A"},
+}
+
+func TestWithoutEvidence(t *testing.T) {
+	for i, test := range withoutEvidenceTests {
+		got := withoutEvidence(test.in)
+		if got != test.want {
+			t.Errorf("unexpected result for test %d: got:%q want:%q", i, got, test.want)
+		}
+	}
+}
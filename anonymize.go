@@ -0,0 +1,84 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"sort"
+	"strconv"
+
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Anonymize returns a copy of g with the DOT ID of each node replaced by a
+// hash of the DOT ID salted with salt, so that graphs holding personal
+// data can be shared. The structure of g is retained, but node IDs are
+// reassigned in the order of the hashed names so that they do not reveal
+// the order of the original data. Only the node and edge attributes named
+// in keep are retained, and only where their values are finite numbers;
+// the name and global attributes of g are not copied.
+//
+// The same salt and DOT ID always give the same hash, so graphs
+// anonymised with the same salt can be compared, and the salt must be
+// kept private to prevent names being recovered by hashing guesses.
+func Anonymize(g *Graph, salt string, keep ...string) *Graph {
+	retain := make(map[string]bool, len(keep))
+	for _, k := range keep {
+		retain[k] = true
+	}
+
+	nodes := NodesOf(g)
+	hashes := make(map[int64]string, len(nodes))
+	for _, n := range nodes {
+		hashes[n.ID()] = anonymousName(salt, n.Name)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return hashes[nodes[i].ID()] < hashes[nodes[j].ID()] })
+
+	dst := &Graph{UndirectedGraph: simple.NewUndirectedGraph()}
+	copies := make(map[int64]*Node, len(nodes))
+	for i, n := range nodes {
+		c := &Node{NodeID: int64(i), Name: hashes[n.ID()], Attributes: numericAttributes(n.Attributes, retain)}
+		dst.AddNode(c)
+		copies[n.ID()] = c
+	}
+	edges := g.Edges()
+	for edges.Next() {
+		e := edges.Edge().(*Edge)
+		dst.SetEdge(&Edge{
+			F:          copies[e.F.ID()],
+			T:          copies[e.T.ID()],
+			Attributes: numericAttributes(e.Attributes, retain),
+		})
+	}
+	return dst
+}
+
+// anonymousName returns the salted hash of name.
+func anonymousName(salt, name string) string {
+	h := sha256.New()
+	h.Write([]byte(salt))
+	h.Write([]byte{0})
+	h.Write([]byte(name))
+	return "n" + hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// numericAttributes returns the attributes of a with keys in retain and
+// finite numerical values.
+func numericAttributes(a Attributes, retain map[string]bool) Attributes {
+	var dst Attributes
+	for _, attr := range a {
+		if !retain[attr.Key] {
+			continue
+		}
+		v, err := strconv.ParseFloat(a.GetUnquoted(attr.Key), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		dst = append(dst, attr)
+	}
+	return dst
+}
@@ -0,0 +1,107 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const anonymizeDOT = `graph {
+	alice [age=30, city=Paris, score=inf];
+	bob [age=x];
+	carol;
+	alice -- bob [weight=2, note=hi];
+	bob -- carol [weight=nan];
+}`
+
+// Hashes of salt\x00name for the nodes of anonymizeDOT.
+const (
+	anonAlice = "n3bf7ce5bd09d139b"
+	anonBob   = "n8550e580006ac02b"
+	anonCarol = "n6325d87f5bb5aa5a"
+)
+
+var anonymousNameTests = []struct {
+	salt, name string
+	want       string
+}{
+	{salt: "salt", name: "alice", want: anonAlice},
+	{salt: "salt", name: "bob", want: anonBob},
+	{salt: "salt", name: "carol", want: anonCarol},
+}
+
+func TestAnonymousName(t *testing.T) {
+	for i, test := range anonymousNameTests {
+		got := anonymousName(test.salt, test.name)
+		if got != test.want {
+			t.Errorf("unexpected name for test %d: got:%s want:%s", i, got, test.want)
+		}
+	}
+	if anonymousName("sal", "tbob") == anonymousName("salt", "bob") {
+		t.Error("expected salt and name boundary to be distinguished")
+	}
+	if anonymousName("pepper", "alice") == anonAlice {
+		t.Error("expected different salts to give different names")
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	g := graphFromDOT(t, anonymizeDOT)
+	a := Anonymize(g, "salt", "age", "weight", "score")
+
+	sorted := NodesOf(a)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID() < sorted[j].ID() })
+	var nodes []string
+	for _, n := range sorted {
+		nodes = append(nodes, n.Name)
+	}
+	wantNodes := []string{anonAlice, anonCarol, anonBob}
+	if !reflect.DeepEqual(nodes, wantNodes) {
+		t.Errorf("unexpected nodes: got:%v want:%v", nodes, wantNodes)
+	}
+
+	var edges []string
+	it := a.Edges()
+	for it.Next() {
+		e := it.Edge().(*Edge)
+		u, v := e.F.Name, e.T.Name
+		if v < u {
+			u, v = v, u
+		}
+		edges = append(edges, u+"--"+v+"["+e.Attributes.Get("weight")+"]")
+	}
+	sort.Strings(edges)
+	wantEdges := []string{
+		anonAlice + "--" + anonBob + "[2]",
+		anonCarol + "--" + anonBob + "[]",
+	}
+	if !reflect.DeepEqual(edges, wantEdges) {
+		t.Errorf("unexpected edges:\ngot: %v\nwant:%v", edges, wantEdges)
+	}
+
+	alice := a.NodeNamed(anonAlice)
+	if alice == nil {
+		t.Fatalf("missing anonymised node %s", anonAlice)
+	}
+	wantAttrs := Attributes{{Key: "age", Value: "30"}}
+	if !reflect.DeepEqual(alice.Attributes, wantAttrs) {
+		t.Errorf("unexpected attributes: got:%v want:%v", alice.Attributes, wantAttrs)
+	}
+	if attrs := a.NodeNamed(anonBob).Attributes; len(attrs) != 0 {
+		t.Errorf("unexpected attributes for non-numeric value: %v", attrs)
+	}
+
+	again := Anonymize(g, "salt")
+	for _, n := range sorted {
+		if m := again.Node(n.ID()); m == nil || m.(*Node).Name != n.Name {
+			t.Errorf("anonymisation not deterministic for node %d", n.ID())
+		}
+	}
+	if g.NodeNamed("alice") == nil {
+		t.Errorf("source graph modified")
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Norm is an attribute normalisation method.
+type Norm int
+
+const (
+	// MinMaxNorm scales values linearly onto the interval [0, 1].
+	MinMaxNorm Norm = iota
+	// ZScoreNorm centres values on their mean in units of
+	// their standard deviation.
+	ZScoreNorm
+	// RankNorm replaces values with their fractional rank
+	// scaled onto the interval [0, 1]. Tied values are given
+	// their mean rank.
+	RankNorm
+)
+
+// NormalizeAttribute normalises the float64 values of the given node
+// attribute in place using the specified method. Nodes without the
+// attribute are left unaltered.
+func NormalizeAttribute(g *Graph, attr string, method Norm) error {
+	return NormalizeAttributeTo(g, attr, attr, method)
+}
+
+// NormalizeAttributeTo normalises the float64 values of the src node
+// attribute using the specified method and writes the result into the dst
+// attribute of each node. Nodes without the src attribute are left
+// unaltered. Writing a dst attribute other than src is subject to the
+// overwrite checks described by SetAnalysisOptions.
+func NormalizeAttributeTo(g *Graph, src, dst string, method Norm) error {
+	if src != dst {
+		err := checkOverwrite(g, []string{dst}, "NormalizeAttributeTo", "src", src, "dst", dst, "method", method)
+		if err != nil {
+			return err
+		}
+	}
+	nodes, vals, err := nodeValues(g, src)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	switch method {
+	case MinMaxNorm:
+		min, max := math.Inf(1), math.Inf(-1)
+		for _, v := range vals {
+			min = math.Min(min, v)
+			max = math.Max(max, v)
+		}
+		for i, v := range vals {
+			if max == min {
+				vals[i] = 0
+				continue
+			}
+			vals[i] = (v - min) / (max - min)
+		}
+	case ZScoreNorm:
+		mean, std := stat.MeanStdDev(vals, nil)
+		for i, v := range vals {
+			if std == 0 || math.IsNaN(std) {
+				vals[i] = 0
+				continue
+			}
+			vals[i] = (v - mean) / std
+		}
+	case RankNorm:
+		ranks := fractionalRanks(vals)
+		for i, r := range ranks {
+			if len(ranks) == 1 {
+				vals[i] = 0
+				continue
+			}
+			vals[i] = r / float64(len(ranks)-1)
+		}
+	default:
+		return fmt.Errorf("invalid normalisation method: %d", method)
+	}
+
+	for i, n := range nodes {
+		n.SetAttribute(encoding.Attribute{Key: dst, Value: fmt.Sprint(vals[i])})
+	}
+	setProvenance(g, []string{dst}, "NormalizeAttributeTo", "src", src, "dst", dst, "method", method)
+	return nil
+}
+
+// fractionalRanks returns the zero-based ranks of vals with tied values
+// given their mean rank.
+func fractionalRanks(vals []float64) []float64 {
+	idx := make([]int, len(vals))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool { return vals[idx[i]] < vals[idx[j]] })
+
+	ranks := make([]float64, len(vals))
+	for i := 0; i < len(idx); {
+		j := i + 1
+		for j < len(idx) && vals[idx[j]] == vals[idx[i]] {
+			j++
+		}
+		r := float64(i+j-1) / 2
+		for _, k := range idx[i:j] {
+			ranks[k] = r
+		}
+		i = j
+	}
+	return ranks
+}
+
+// nodeValues returns the nodes of g that have the given attribute and
+// the attribute values parsed as float64.
+func nodeValues(g *Graph, attr string) ([]*Node, []float64, error) {
+	var nodes []*Node
+	var vals []float64
+	for _, n := range graph.NodesOf(g.Nodes()) {
+		n := n.(*Node)
+		for _, a := range n.Attributes {
+			if a.Key == attr {
+				v, err := strconv.ParseFloat(a.Value, 64)
+				if err != nil {
+					return nil, nil, err
+				}
+				nodes = append(nodes, n)
+				vals = append(vals, v)
+				break
+			}
+		}
+	}
+	return nodes, vals, nil
+}
+
+// BinScheme is an attribute discretisation scheme.
+type BinScheme int
+
+const (
+	// EqualWidthBins divides the range of values into bins
+	// of equal width.
+	EqualWidthBins BinScheme = iota
+	// QuantileBins divides values into bins holding
+	// approximately equal numbers of nodes.
+	QuantileBins
+	// JenksBins divides values into bins using Jenks natural
+	// breaks, minimising the within-bin sum of squared
+	// deviations.
+	JenksBins
+)
+
+// BinAttribute discretises the float64 values of the given node attribute
+// into the specified number of bins using the provided scheme.
+//
+// The zero-based bin index is written into the "<attr>_bin" attribute of
+// each node holding attr.
+func BinAttribute(g *Graph, attr string, bins int, scheme BinScheme) error {
+	if bins < 1 {
+		return fmt.Errorf("invalid number of bins: %d", bins)
+	}
+	err := checkOverwrite(g, []string{attr + "_bin"}, "BinAttribute", "attr", attr, "bins", bins, "scheme", scheme)
+	if err != nil {
+		return err
+	}
+	nodes, vals, err := nodeValues(g, attr)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	var bin []int
+	switch scheme {
+	case EqualWidthBins:
+		bin = equalWidthBins(vals, bins)
+	case QuantileBins:
+		bin = quantileBins(vals, bins)
+	case JenksBins:
+		bin = jenksBins(vals, bins)
+	default:
+		return fmt.Errorf("invalid binning scheme: %d", scheme)
+	}
+
+	key := attr + "_bin"
+	for i, n := range nodes {
+		n.SetAttribute(encoding.Attribute{Key: key, Value: fmt.Sprint(bin[i])})
+	}
+	setProvenance(g, []string{key}, "BinAttribute", "attr", attr, "bins", bins, "scheme", scheme)
+	return nil
+}
+
+func equalWidthBins(vals []float64, bins int) []int {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range vals {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	bin := make([]int, len(vals))
+	if max == min {
+		return bin
+	}
+	width := (max - min) / float64(bins)
+	for i, v := range vals {
+		b := int((v - min) / width)
+		if b >= bins {
+			b = bins - 1
+		}
+		bin[i] = b
+	}
+	return bin
+}
+
+func quantileBins(vals []float64, bins int) []int {
+	ranks := fractionalRanks(vals)
+	bin := make([]int, len(vals))
+	for i, r := range ranks {
+		b := int(r * float64(bins) / float64(len(vals)))
+		if b >= bins {
+			b = bins - 1
+		}
+		bin[i] = b
+	}
+	return bin
+}
+
+// jenksBins returns the Jenks natural breaks bin assignments for vals
+// using the Fisher dynamic programming formulation.
+func jenksBins(vals []float64, bins int) []int {
+	sorted := make([]float64, len(vals))
+	copy(sorted, vals)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if bins > n {
+		bins = n
+	}
+
+	// Prefix sums allow constant time calculation of the
+	// sum of squared deviations of any run of values.
+	sum := make([]float64, n+1)
+	sumSq := make([]float64, n+1)
+	for i, v := range sorted {
+		sum[i+1] = sum[i] + v
+		sumSq[i+1] = sumSq[i] + v*v
+	}
+	ssd := func(i, j int) float64 {
+		s := sum[j] - sum[i]
+		return sumSq[j] - sumSq[i] - s*s/float64(j-i)
+	}
+
+	// cost[k][j] is the minimum cost of placing the first j
+	// values into k+1 bins, and lower[k][j] is the start of
+	// the last of those bins.
+	cost := make([][]float64, bins)
+	lower := make([][]int, bins)
+	for k := range cost {
+		cost[k] = make([]float64, n+1)
+		lower[k] = make([]int, n+1)
+	}
+	for j := 1; j <= n; j++ {
+		cost[0][j] = ssd(0, j)
+	}
+	for k := 1; k < bins; k++ {
+		for j := k + 1; j <= n; j++ {
+			cost[k][j] = math.Inf(1)
+			for i := k; i < j; i++ {
+				c := cost[k-1][i] + ssd(i, j)
+				if c < cost[k][j] {
+					cost[k][j] = c
+					lower[k][j] = i
+				}
+			}
+		}
+	}
+
+	// Walk back through the table to find the lower
+	// bound of each bin.
+	breaks := make([]float64, bins)
+	j := n
+	for k := bins - 1; k > 0; k-- {
+		i := lower[k][j]
+		breaks[k] = sorted[i]
+		j = i
+	}
+	breaks[0] = math.Inf(-1)
+
+	bin := make([]int, len(vals))
+	for i, v := range vals {
+		bin[i] = sort.Search(len(breaks), func(k int) bool { return breaks[k] > v }) - 1
+	}
+	return bin
+}
@@ -0,0 +1,101 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"testing"
+)
+
+var normalizeTests = []struct {
+	vals   []string
+	method Norm
+	want   []float64
+}{
+	{
+		vals:   []string{"1", "2", "3", "5"},
+		method: MinMaxNorm,
+		want:   []float64{0, 0.25, 0.5, 1},
+	},
+	{
+		vals:   []string{"1", "2", "3", "5"},
+		method: ZScoreNorm,
+		want:   []float64{-1.02469507659596, -0.43915503282684, 0.14638501094228, 1.31746509848052},
+	},
+	{
+		vals:   []string{"1", "2", "2", "5"},
+		method: RankNorm,
+		want:   []float64{0, 0.5, 0.5, 1},
+	},
+	{
+		vals:   []string{"4", "4", "4", "4"},
+		method: MinMaxNorm,
+		want:   []float64{0, 0, 0, 0},
+	},
+	{
+		vals:   []string{"4", "4", "4", "4"},
+		method: ZScoreNorm,
+		want:   []float64{0, 0, 0, 0},
+	},
+}
+
+func TestNormalizeAttribute(t *testing.T) {
+	for i, test := range normalizeTests {
+		g := graphFromDOT(t, `graph { a [x=`+test.vals[0]+`]; b [x=`+test.vals[1]+`]; c [x=`+test.vals[2]+`]; d [x=`+test.vals[3]+`]; e }`)
+		err := NormalizeAttributeTo(g, "x", "y", test.method)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		for j, name := range []string{"a", "b", "c", "d"} {
+			got := floatAttr(t, g, name, "y")
+			if math.Abs(got-test.want[j]) > 1e-12 {
+				t.Errorf("unexpected result for test %d node %s: got:%v want:%v", i, name, got, test.want[j])
+			}
+		}
+		if g.NodeNamed("e").Get("y") != "" {
+			t.Errorf("unexpected value for node without attribute in test %d", i)
+		}
+	}
+}
+
+var binTests = []struct {
+	bins   int
+	scheme BinScheme
+	want   []string
+}{
+	{bins: 3, scheme: EqualWidthBins, want: []string{"0", "0", "0", "2"}},
+	{bins: 3, scheme: QuantileBins, want: []string{"0", "0", "1", "2"}},
+	{bins: 2, scheme: JenksBins, want: []string{"0", "0", "0", "1"}},
+	{bins: 4, scheme: JenksBins, want: []string{"0", "1", "2", "3"}},
+	{bins: 1, scheme: EqualWidthBins, want: []string{"0", "0", "0", "0"}},
+}
+
+func TestBinAttribute(t *testing.T) {
+	for i, test := range binTests {
+		g := graphFromDOT(t, `graph { a [x=1]; b [x=2]; c [x=3]; d [x=10] }`)
+		err := BinAttribute(g, "x", test.bins, test.scheme)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		for j, name := range []string{"a", "b", "c", "d"} {
+			got := g.NodeNamed(name).Get("x_bin")
+			if got != test.want[j] {
+				t.Errorf("unexpected bin for test %d node %s: got:%s want:%s", i, name, got, test.want[j])
+			}
+		}
+	}
+}
+
+func TestBinAttributeInvalid(t *testing.T) {
+	g := graphFromDOT(t, `graph { a [x=1]; b [x=text] }`)
+	if err := BinAttribute(g, "x", 0, EqualWidthBins); err == nil {
+		t.Error("expected error for zero bins")
+	}
+	if err := BinAttribute(g, "x", 2, EqualWidthBins); err == nil {
+		t.Error("expected error for non-numeric value")
+	}
+}
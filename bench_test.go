@@ -0,0 +1,150 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+	"text/tabwriter"
+
+	"golang.org/x/exp/rand"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/graphs/gen"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+var report = flag.Bool("report", false, "print a table of benchmark results")
+
+// benchSizes are the orders of the generated benchmark graphs.
+var benchSizes = []int{250, 1000, 2500}
+
+// benchCase is an operation to be benchmarked on a graph.
+type benchCase struct {
+	name string
+	fn   func(b *testing.B, g *Graph)
+}
+
+var benchCases = []benchCase{
+	{name: "Load", fn: func(b *testing.B, g *Graph) {
+		data := []byte(DOT(g))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, err := readDOT(data, LoadOptions{})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}},
+	{name: "PageRank", fn: func(b *testing.B, g *Graph) {
+		for i := 0; i < b.N; i++ {
+			PageRank(g, 0.85, 1e-6)
+		}
+	}},
+	{name: "Betweenness", fn: func(b *testing.B, g *Graph) {
+		for i := 0; i < b.N; i++ {
+			Betweenness(g)
+		}
+	}},
+	{name: "BronKerbosch", fn: func(b *testing.B, g *Graph) {
+		for i := 0; i < b.N; i++ {
+			maximalCliques(g)
+		}
+	}},
+	{name: "Communities", fn: func(b *testing.B, g *Graph) {
+		for i := 0; i < b.N; i++ {
+			Communities(g, 1)
+		}
+	}},
+	{name: "DOT", fn: func(b *testing.B, g *Graph) {
+		for i := 0; i < b.N; i++ {
+			DOT(g)
+		}
+	}},
+}
+
+// benchGraph returns a preferential attachment graph of order n
+// generated from a fixed seed.
+func benchGraph(n int) *Graph {
+	src := simple.NewUndirectedGraph()
+	err := gen.PreferentialAttachment(src, n, 3, rand.NewSource(1))
+	if err != nil {
+		panic(err)
+	}
+	return graphFrom(src)
+}
+
+// graphFrom returns a *Graph with the structure of src. Nodes are named
+// with their DOT ID if available, or their ID otherwise.
+func graphFrom(src graph.Graph) *Graph {
+	dst := &Graph{UndirectedGraph: simple.NewUndirectedGraph()}
+	for _, n := range graph.NodesOf(src.Nodes()) {
+		name := strconv.FormatInt(n.ID(), 10)
+		if n, ok := n.(interface{ DOTID() string }); ok && n.DOTID() != "" {
+			name = n.DOTID()
+		}
+		dst.AddNode(&Node{NodeID: n.ID(), Name: name})
+	}
+	for _, u := range graph.NodesOf(src.Nodes()) {
+		for _, v := range graph.NodesOf(src.From(u.ID())) {
+			dst.NewEdge(dst.Node(u.ID()), dst.Node(v.ID()))
+		}
+	}
+	return dst
+}
+
+func BenchmarkAnalyses(b *testing.B) {
+	yeast, err := NewGraph("YeastL.dot")
+	if err != nil {
+		b.Fatalf("failed to load yeast graph: %v", err)
+	}
+	graphs := []struct {
+		name string
+		g    *Graph
+	}{{name: "yeast", g: yeast}}
+	for _, n := range benchSizes {
+		graphs = append(graphs, struct {
+			name string
+			g    *Graph
+		}{name: fmt.Sprintf("pa%d", n), g: benchGraph(n)})
+	}
+
+	for _, c := range benchCases {
+		for _, g := range graphs {
+			b.Run(c.name+"/"+g.name, func(b *testing.B) {
+				b.ReportAllocs()
+				c.fn(b, g.g)
+			})
+		}
+	}
+}
+
+// TestBenchmarkReport runs the package benchmarks on generated graphs of
+// several sizes and prints a table of the results when the -report flag
+// is set, for example with
+//
+//	go test -run BenchmarkReport -report
+func TestBenchmarkReport(t *testing.T) {
+	if !*report {
+		t.Skip("benchmark report not requested")
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "benchmark\tnodes\tedges\tns/op\tB/op\tallocs/op\t")
+	for _, n := range benchSizes {
+		g := benchGraph(n)
+		for _, c := range benchCases {
+			r := testing.Benchmark(func(b *testing.B) {
+				b.ReportAllocs()
+				c.fn(b, g)
+			})
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t\n",
+				c.name, n, g.Edges().Len(), r.NsPerOp(), r.AllocedBytesPerOp(), r.AllocsPerOp())
+		}
+	}
+	w.Flush()
+}
@@ -0,0 +1,74 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graphprac-binary")
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "g.bin")
+
+	g := graphFromDOT(t, `strict graph G {
+	a [label="A \"quoted\" node", email="a@example.com"];
+	a -- b [weight=2];
+	b -- c;
+	d;
+	subgraph cluster_x { label="X"; a; subgraph cluster_y { b; c } }
+}`)
+	Eccentricity(g)
+
+	// Saving a session is not an export.
+	err = SetExportPolicy(ExportPolicy{Rules: []RedactRule{{Pattern: "email"}}})
+	if err != nil {
+		t.Fatalf("unexpected error setting policy: %v", err)
+	}
+	defer SetExportPolicy(ExportPolicy{})
+	err = SaveBinary(g, path)
+	if err != nil {
+		t.Fatalf("unexpected error saving graph: %v", err)
+	}
+	SetExportPolicy(ExportPolicy{})
+
+	got, err := LoadBinary(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading graph: %v", err)
+	}
+	if got, want := DOT(got), DOT(g); got != want {
+		t.Errorf("unexpected round trip graph:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if got.Name != "G" || !got.Strict {
+		t.Errorf("unexpected graph header: got:name=%q strict=%t want:name=\"G\" strict=true", got.Name, got.Strict)
+	}
+	if !reflect.DeepEqual(got.clusters, g.clusters) {
+		t.Errorf("unexpected clusters:\ngot: %+v\nwant:%+v", got.clusters, g.clusters)
+	}
+	for _, n := range NodesOf(g) {
+		if got.Node(n.ID()).(*Node).Name != n.Name {
+			t.Errorf("node ID %d not retained for %s", n.ID(), n.Name)
+		}
+	}
+	p, ok := Provenance(got, "eccentricity")
+	if want, _ := Provenance(g, "eccentricity"); !ok || p.call() != want.call() || !p.Time.Equal(want.Time) {
+		t.Errorf("unexpected provenance: got:%+v want:%+v", p, want)
+	}
+
+	err = ioutil.WriteFile(path, []byte("not a graph"), 0644)
+	if err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := LoadBinary(path); err == nil {
+		t.Error("expected error for invalid data")
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Bipartite is a graph whose nodes are divided into two sets, the rows
+// and columns of an incidence matrix, with edges only between nodes in
+// different sets. An affiliation network, with people in one set and the
+// groups they belong to in the other, is a bipartite graph. The set of
+// each node is held in its "bipartite" attribute, 0 for rows and 1 for
+// columns.
+type Bipartite struct {
+	// Graph holds the nodes of both sets
+	// and the edges between them.
+	*Graph
+
+	// rows and cols hold the nodes of each
+	// set in the order of the matrix.
+	rows, cols []*Node
+}
+
+// Rows returns the nodes corresponding to the rows of the incidence matrix
+// of b, in order.
+func (b *Bipartite) Rows() []*Node {
+	return append([]*Node(nil), b.rows...)
+}
+
+// Columns returns the nodes corresponding to the columns of the incidence
+// matrix of b, in order.
+func (b *Bipartite) Columns() []*Node {
+	return append([]*Node(nil), b.cols...)
+}
+
+// ReadIncidence reads a rectangular incidence matrix from r and returns the
+// corresponding bipartite graph, with an edge between row i and column j
+// for each non-zero element. The matrix may be in Matrix Market format,
+// either as coordinate entries or as a dense array, with real, integer or
+// pattern values:
+//
+//	%%MatrixMarket matrix coordinate real general
+//	% rows columns entries
+//	3 2 4
+//	1 1 1.5
+//	2 1 1
+//	2 2 3
+//	3 2 1
+//
+// or may be plain text with a line of white space separated values for
+// each row. Lines starting with '%' or '#' in plain text are comments.
+//
+// The row nodes have DOT IDs "r1" to "rn" and node IDs 0 to n-1, and the
+// column nodes have DOT IDs "c1" to "cm" and node IDs n to n+m-1. The value
+// of each element is written into the "weight" attribute of its edge,
+// except for pattern matrices, which have no values. Repeated coordinate
+// entries are summed. ReadIncidence returns an error if the matrix is not
+// rectangular or an entry is outside the matrix.
+func ReadIncidence(r io.Reader) (*Bipartite, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(len("%%MatrixMarket"))
+	if err == nil && strings.EqualFold(string(head), "%%MatrixMarket") {
+		return readMatrixMarket(br)
+	}
+	return readDenseIncidence(br)
+}
+
+// newBipartite returns a bipartite graph with the given numbers of rows
+// and columns and no edges.
+func newBipartite(rows, cols int) *Bipartite {
+	b := &Bipartite{
+		Graph: &Graph{UndirectedGraph: simple.NewUndirectedGraph()},
+		rows:  make([]*Node, rows),
+		cols:  make([]*Node, cols),
+	}
+	for i := range b.rows {
+		n := &Node{NodeID: int64(i), Name: fmt.Sprintf("r%d", i+1), Attributes: Attributes{{Key: "bipartite", Value: "0"}}}
+		b.AddNode(n)
+		b.rows[i] = n
+	}
+	for j := range b.cols {
+		n := &Node{NodeID: int64(rows + j), Name: fmt.Sprintf("c%d", j+1), Attributes: Attributes{{Key: "bipartite", Value: "1"}}}
+		b.AddNode(n)
+		b.cols[j] = n
+	}
+	return b
+}
+
+// addIncidence adds the weight v to the edge between row i and column j
+// of b, creating the edge if it does not exist. If weighted is false, no
+// weight is recorded.
+func (b *Bipartite) addIncidence(i, j int, v float64, weighted bool) {
+	e := b.NewEdge(b.rows[i], b.cols[j]).(*Edge)
+	if !weighted {
+		return
+	}
+	if s := e.Get("weight"); s != "" {
+		prev, _ := strconv.ParseFloat(s, 64)
+		v += prev
+	}
+	e.SetAttribute(encoding.Attribute{Key: "weight", Value: fmt.Sprint(v)})
+}
+
+// readMatrixMarket reads a Matrix Market incidence matrix from r.
+func readMatrixMarket(r io.Reader) (*Bipartite, error) {
+	sc := bufio.NewScanner(r)
+	sc.Scan()
+	line := 1
+	banner := sc.Text()
+	f := strings.Fields(strings.ToLower(banner))
+	if len(f) != 5 || f[1] != "matrix" {
+		return nil, &ParseError{Line: line, Text: banner, Err: fmt.Errorf("invalid Matrix Market banner")}
+	}
+	format, field, symmetry := f[2], f[3], f[4]
+	if format != "coordinate" && format != "array" {
+		return nil, &ParseError{Line: line, Text: banner, Err: fmt.Errorf("unsupported format %q", format)}
+	}
+	if field != "real" && field != "integer" && field != "pattern" || field == "pattern" && format == "array" {
+		return nil, &ParseError{Line: line, Text: banner, Err: fmt.Errorf("unsupported field %q", field)}
+	}
+	if symmetry != "general" {
+		return nil, &ParseError{Line: line, Text: banner, Err: fmt.Errorf("unsupported symmetry %q for incidence matrix", symmetry)}
+	}
+	weighted := field != "pattern"
+
+	var (
+		b          *Bipartite
+		rows, cols int
+		entries    int
+		seen       int
+		err        error
+	)
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || text[0] == '%' {
+			continue
+		}
+		f := strings.Fields(text)
+		if b == nil {
+			want := 3
+			if format == "array" {
+				want = 2
+			}
+			if len(f) != want {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("invalid size line")}
+			}
+			size := make([]int, len(f))
+			for i, s := range f {
+				size[i], err = strconv.Atoi(s)
+				if err != nil || size[i] < 0 {
+					return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("invalid size %q", s)}
+				}
+			}
+			rows, cols = size[0], size[1]
+			entries = rows * cols
+			if format == "coordinate" {
+				entries = size[2]
+			}
+			b = newBipartite(rows, cols)
+			continue
+		}
+		if seen == entries {
+			return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("too many entries")}
+		}
+
+		var i, j int
+		var val string
+		switch format {
+		case "coordinate":
+			want := 3
+			if !weighted {
+				want = 2
+			}
+			if len(f) != want {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("invalid entry")}
+			}
+			i, err = strconv.Atoi(f[0])
+			if err == nil {
+				j, err = strconv.Atoi(f[1])
+			}
+			if err != nil {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("invalid index")}
+			}
+			i--
+			j--
+			if i < 0 || i >= rows || j < 0 || j >= cols {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("entry outside %d×%d matrix", rows, cols)}
+			}
+			if weighted {
+				val = f[2]
+			}
+		case "array":
+			// Array entries are in column-major order.
+			if len(f) != 1 {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("invalid entry")}
+			}
+			i, j = seen%rows, seen/rows
+			val = f[0]
+		}
+		seen++
+
+		v := 1.0
+		if weighted {
+			v, err = strconv.ParseFloat(val, 64)
+			if err != nil {
+				return nil, &ParseError{Line: line, Text: text, Err: err}
+			}
+			if v == 0 {
+				continue
+			}
+		}
+		b.addIncidence(i, j, v, weighted)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, fmt.Errorf("missing size line")
+	}
+	if seen != entries {
+		return nil, fmt.Errorf("unexpected number of entries: got=%d want=%d", seen, entries)
+	}
+	return b, nil
+}
+
+// readDenseIncidence reads a plain text incidence matrix from r.
+func readDenseIncidence(r io.Reader) (*Bipartite, error) {
+	var (
+		vals [][]float64
+		cols = -1
+	)
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || text[0] == '%' || text[0] == '#' {
+			continue
+		}
+		f := strings.Fields(text)
+		if cols < 0 {
+			cols = len(f)
+		}
+		if len(f) != cols {
+			return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("matrix is not rectangular: %d values, want %d", len(f), cols)}
+		}
+		row := make([]float64, cols)
+		for j, s := range f {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, &ParseError{Line: line, Text: text, Err: err}
+			}
+			row[j] = v
+		}
+		vals = append(vals, row)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if cols < 0 {
+		cols = 0
+	}
+	b := newBipartite(len(vals), cols)
+	for i, row := range vals {
+		for j, v := range row {
+			if v != 0 {
+				b.addIncidence(i, j, v, true)
+			}
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,125 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// incidenceEdges returns the edges of b as "row--column=weight" strings
+// in sorted order.
+func incidenceEdges(b *Bipartite) []string {
+	var edges []string
+	for _, e := range graph.EdgesOf(b.Edges()) {
+		e := e.(*Edge)
+		u, v := e.F, e.T
+		if u.Get("bipartite") == "1" {
+			u, v = v, u
+		}
+		edges = append(edges, u.Name+"--"+v.Name+"="+e.Get("weight"))
+	}
+	sort.Strings(edges)
+	return edges
+}
+
+var incidenceTests = []struct {
+	src        string
+	rows, cols int
+	want       []string
+}{
+	{
+		src: `%%MatrixMarket matrix coordinate real general
+% rows columns entries
+3 2 4
+1 1 1.5
+2 1 1
+2 2 3
+3 2 1
+`,
+		rows: 3, cols: 2,
+		want: []string{"r1--c1=1.5", "r2--c1=1", "r2--c2=3", "r3--c2=1"},
+	},
+	{
+		src:  "%%MatrixMarket matrix coordinate integer general\n2 3 3\n1 3 2\n1 3 5\n2 1 0\n",
+		rows: 2, cols: 3,
+		want: []string{"r1--c3=7"},
+	},
+	{
+		src:  "%%matrixmarket MATRIX Coordinate Pattern General\n2 2 2\n1 2\n2 1\n",
+		rows: 2, cols: 2,
+		want: []string{"r1--c2=", "r2--c1="},
+	},
+	{
+		// Array entries are in column-major order.
+		src:  "%%MatrixMarket matrix array real general\n2 3\n1\n0\n0\n2\n4\n0\n",
+		rows: 2, cols: 3,
+		want: []string{"r1--c1=1", "r1--c3=4", "r2--c2=2"},
+	},
+	{
+		src:  "# Plain text.\n1 0 1\n\n0 0.5 0\n% A comment.\n0 0 0\n",
+		rows: 3, cols: 3,
+		want: []string{"r1--c1=1", "r1--c3=1", "r2--c2=0.5"},
+	},
+	{
+		src:  "",
+		rows: 0, cols: 0,
+	},
+}
+
+func TestReadIncidence(t *testing.T) {
+	for i, test := range incidenceTests {
+		b, err := ReadIncidence(strings.NewReader(test.src))
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		rows, cols := b.Rows(), b.Columns()
+		if len(rows) != test.rows || len(cols) != test.cols {
+			t.Errorf("unexpected size for test %d: got:%d×%d want:%d×%d", i, len(rows), len(cols), test.rows, test.cols)
+			continue
+		}
+		for j, n := range rows {
+			if n.ID() != int64(j) || n.Get("bipartite") != "0" {
+				t.Errorf("unexpected row node %d for test %d: ID=%d set=%s", j, i, n.ID(), n.Get("bipartite"))
+			}
+		}
+		for j, n := range cols {
+			if n.ID() != int64(test.rows+j) || n.Get("bipartite") != "1" {
+				t.Errorf("unexpected column node %d for test %d: ID=%d set=%s", j, i, n.ID(), n.Get("bipartite"))
+			}
+		}
+		if got := incidenceEdges(b); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected edges for test %d: got:%q want:%q", i, got, test.want)
+		}
+	}
+}
+
+var incidenceErrorTests = []string{
+	"1 0\n0\n",
+	"1 x\n",
+	"%%MatrixMarket matrix\n",
+	"%%MatrixMarket matrix coordinate complex general\n1 1 1\n1 1 1 0\n",
+	"%%MatrixMarket matrix coordinate real symmetric\n2 2 1\n1 1 1\n",
+	"%%MatrixMarket matrix coordinate real general\n2 2\n",
+	"%%MatrixMarket matrix coordinate real general\n2 2 1\n3 1 1\n",
+	"%%MatrixMarket matrix coordinate real general\n2 2 1\n1 1 1\n2 2 1\n",
+	"%%MatrixMarket matrix coordinate real general\n2 2 2\n1 1 1\n",
+	"%%MatrixMarket matrix coordinate pattern general\n2 2 1\n1 1 1\n",
+	"%%MatrixMarket matrix array real general\n1 2\n1\n",
+	"%%MatrixMarket matrix coordinate real general\n",
+}
+
+func TestReadIncidenceErrors(t *testing.T) {
+	for i, src := range incidenceErrorTests {
+		if _, err := ReadIncidence(strings.NewReader(src)); err == nil {
+			t.Errorf("expected error for test %d", i)
+		}
+	}
+}
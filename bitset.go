@@ -0,0 +1,210 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math/bits"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/topo"
+)
+
+// bitset is a fixed size set of small non-negative integers.
+type bitset []uint64
+
+func newBitset(n int) bitset { return make(bitset, (n+63)/64) }
+
+func (b bitset) has(i int) bool { return b[i/64]&(1<<uint(i%64)) != 0 }
+func (b bitset) set(i int)      { b[i/64] |= 1 << uint(i%64) }
+func (b bitset) unset(i int)    { b[i/64] &^= 1 << uint(i%64) }
+
+// intersectionLen returns the number of elements in both b and o.
+func (b bitset) intersectionLen(o bitset) int {
+	var n int
+	for i, w := range b {
+		n += bits.OnesCount64(w & o[i])
+	}
+	return n
+}
+
+// intersect returns a new set holding the elements in both b and o.
+func (b bitset) intersect(o bitset) bitset {
+	r := make(bitset, len(b))
+	for i, w := range b {
+		r[i] = w & o[i]
+	}
+	return r
+}
+
+// isEmpty returns whether b has no elements.
+func (b bitset) isEmpty() bool {
+	for _, w := range b {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// do calls fn for each element of b in ascending order.
+func (b bitset) do(fn func(i int)) {
+	for i, w := range b {
+		for w != 0 {
+			j := bits.TrailingZeros64(w)
+			fn(i*64 + j)
+			w &^= 1 << uint(j)
+		}
+	}
+}
+
+// bitAdjacency is a bit-packed adjacency matrix of a set of nodes of an
+// undirected graph, such as the neighbourhood of a node. It requires k²/8
+// bytes for a set of k nodes, so it is only built for small or dense sets.
+type bitAdjacency struct {
+	nodes []graph.Node
+	rows  []bitset
+}
+
+// newBitAdjacency returns the bit-packed adjacency matrix of the subgraph
+// induced by set, a set of indices into x with adjacency lists adj. The
+// index of each node in the matrix is its position in set. local must
+// have the length of adj and hold -1 at each index, and it is restored
+// before newBitAdjacency returns.
+func newBitAdjacency(x NodeIndex, adj [][]int, set []int, local []int) *bitAdjacency {
+	for k, i := range set {
+		local[i] = k
+	}
+	a := &bitAdjacency{nodes: make([]graph.Node, len(set)), rows: make([]bitset, len(set))}
+	for k, i := range set {
+		a.nodes[k] = x.nodes[i]
+		a.rows[k] = newBitset(len(set))
+		for _, j := range adj[i] {
+			if l := local[j]; l >= 0 && l != k {
+				a.rows[k].set(l)
+			}
+		}
+	}
+	for _, i := range set {
+		local[i] = -1
+	}
+	return a
+}
+
+// triangles returns the number of triangles each node in g is part of.
+// Each triangle is found once, from its lowest indexed node, by marking
+// the neighbours of the node in a bitset.
+func triangles(g graph.Undirected) map[int64]int {
+	x := newNodeIndex(g)
+	adj := x.adjacency(g)
+	t := make([]int, len(adj))
+	mark := newBitset(len(adj))
+	for u, nu := range adj {
+		for _, v := range nu {
+			mark.set(v)
+		}
+		for _, v := range nu {
+			if v <= u {
+				continue
+			}
+			for _, w := range adj[v] {
+				if w > v && mark.has(w) {
+					t[u]++
+					t[v]++
+					t[w]++
+				}
+			}
+		}
+		for _, v := range nu {
+			mark.unset(v)
+		}
+	}
+	tri := make(map[int64]int, len(t))
+	for i, n := range t {
+		tri[x.ID(i)] = n
+	}
+	return tri
+}
+
+// maximalCliques returns the maximal cliques of g. Each node is visited
+// in degeneracy order, and the cliques holding it and none of the nodes
+// already visited are found with the Bron–Kerbosch algorithm with Tomita
+// pivoting over a bit-packed adjacency matrix of its neighbourhood, as
+// described by Eppstein, Löffler and Strash.
+func maximalCliques(g graph.Undirected) [][]graph.Node {
+	x := newNodeIndex(g)
+	adj := x.adjacency(g)
+	order, _ := topo.DegeneracyOrdering(g)
+	pos := make([]int, len(order))
+	for k, n := range order {
+		// The visiting order is the reverse of the
+		// returned ordering, so that each node has
+		// few neighbours visited after it.
+		pos[x.Index(n.ID())] = len(order) - 1 - k
+	}
+	local := make([]int, len(adj))
+	for i := range local {
+		local[i] = -1
+	}
+
+	var cliques [][]graph.Node
+	for k := len(order) - 1; k >= 0; k-- {
+		v := x.Index(order[k].ID())
+		var nbrs []int
+		for _, u := range adj[v] {
+			if u != v {
+				nbrs = append(nbrs, u)
+			}
+		}
+		a := newBitAdjacency(x, adj, nbrs, local)
+		p, q := newBitset(len(nbrs)), newBitset(len(nbrs))
+		for l, u := range nbrs {
+			if pos[u] > pos[v] {
+				p.set(l)
+			} else {
+				q.set(l)
+			}
+		}
+		a.bronKerbosch(x.nodes[v], nil, p, q, &cliques)
+	}
+	return cliques
+}
+
+// bronKerbosch adds the maximal cliques holding root, the nodes of a
+// indexed by r and some of p, and none of x, to cliques.
+func (a *bitAdjacency) bronKerbosch(root graph.Node, r []int, p, x bitset, cliques *[][]graph.Node) {
+	if p.isEmpty() {
+		if x.isEmpty() {
+			c := make([]graph.Node, len(r)+1)
+			c[0] = root
+			for i, j := range r {
+				c[i+1] = a.nodes[j]
+			}
+			*cliques = append(*cliques, c)
+		}
+		return
+	}
+
+	// Choose the pivot with the most neighbours in p.
+	pivot, most := -1, -1
+	choose := func(u int) {
+		if n := p.intersectionLen(a.rows[u]); n > most {
+			pivot, most = u, n
+		}
+	}
+	p.do(choose)
+	x.do(choose)
+
+	var cand []int
+	p.do(func(v int) {
+		if !a.rows[pivot].has(v) {
+			cand = append(cand, v)
+		}
+	})
+	for _, v := range cand {
+		a.bronKerbosch(root, append(r[:len(r):len(r)], v), p.intersect(a.rows[v]), x.intersect(a.rows[v]), cliques)
+		p.unset(v)
+		x.set(v)
+	}
+}
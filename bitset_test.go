@@ -0,0 +1,75 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestTrianglesAndClustering(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b -- c -- a; c -- d; b -- d; e }`)
+	Triangles(g)
+	Clustering(g)
+	want := map[string]struct {
+		triangles  string
+		clustering float64
+	}{
+		"a": {triangles: "1", clustering: 1},
+		"b": {triangles: "2", clustering: 2.0 / 3},
+		"c": {triangles: "2", clustering: 2.0 / 3},
+		"d": {triangles: "1", clustering: 1},
+		"e": {triangles: "0", clustering: 0},
+	}
+	for name, w := range want {
+		n := g.NodeNamed(name)
+		if got := n.Get("triangles"); got != w.triangles {
+			t.Errorf("unexpected triangle count for %s: got:%s want:%s", name, got, w.triangles)
+		}
+		if got := floatAttr(t, g, name, "clustering"); math.Abs(got-w.clustering) > 1e-12 {
+			t.Errorf("unexpected clustering for %s: got:%v want:%v", name, got, w.clustering)
+		}
+	}
+}
+
+var cliqueTests = []struct {
+	dot  string
+	want []string
+}{
+	{
+		dot:  `graph { a -- b -- c -- a }`,
+		want: []string{"a,b,c"},
+	},
+	{
+		dot:  `graph { a -- b -- c -- d -- a; a -- c; b -- d; d -- e -- f -- d; g -- h; i }`,
+		want: []string{"a,b,c,d", "d,e,f", "g,h", "i"},
+	},
+	{
+		dot:  `graph { a -- b -- c -- d -- a }`,
+		want: []string{"a,b", "a,d", "b,c", "c,d"},
+	},
+}
+
+func TestMaximalCliques(t *testing.T) {
+	for i, test := range cliqueTests {
+		g := graphFromDOT(t, test.dot)
+		var got []string
+		for _, c := range maximalCliques(g) {
+			names := make([]string, len(c))
+			for j, n := range c {
+				names[j] = n.(*Node).Name
+			}
+			sort.Strings(names)
+			got = append(got, strings.Join(names, ","))
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected cliques for test %d: got:%v want:%v", i, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"sort"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/stat"
+)
+
+// Interval is a confidence interval.
+type Interval struct {
+	Lower, Upper float64
+}
+
+// BootstrapMetric returns 95% bootstrap confidence intervals for the node
+// metric calculated by the analysis metric, keyed by node ID. Each of
+// resamples graphs is constructed with the nodes of g and as many edges
+// as g, drawn with replacement from the edges of g, the analysis is run on
+// each resampled graph and the interval for each node is given by the
+// 2.5th and 97.5th percentiles of its values. An edge drawn more than once
+// is included once with its "weight" attribute, or 1 if it is not set,
+// multiplied by the number of times it was drawn, so weighted analyses
+// see the resampled edge multiplicities. About a third of the edges of g
+// are absent from each resampled graph, so the intervals of metrics that
+// depend on the number of edges, such as unweighted degree, are shifted
+// from the values for g. The resampled graphs are determined by seed, and
+// g is not modified. Nodes that are not given a value by the analysis are
+// omitted.
+//
+// BootstrapMetric returns an error if the analysis fails on a resampled
+// graph. It panics if resamples is less than one.
+func BootstrapMetric(g *Graph, metric Analysis, resamples int, seed int64) (map[int64]Interval, error) {
+	if resamples < 1 {
+		panic("graphprac: invalid number of resamples")
+	}
+	edges := sortedEdges(g)
+	keep := make(map[int64]int)
+	for i, n := range graph.NodesOf(g.Nodes()) {
+		keep[n.ID()] = i
+	}
+
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	samples := make(map[int64][]float64)
+	count := make([]int, len(edges))
+	for r := 0; r < resamples; r++ {
+		for i := range count {
+			count[i] = 0
+		}
+		for range edges {
+			count[rnd.Intn(len(edges))]++
+		}
+		b, err := resampled(g, keep, metric.Attr, edges, count)
+		if err != nil {
+			return nil, err
+		}
+		vals, err := metric.values(b)
+		if err != nil {
+			return nil, fmt.Errorf("resample %d: %v", r, err)
+		}
+		for id, v := range vals {
+			samples[id] = append(samples[id], v)
+		}
+	}
+
+	ci := make(map[int64]Interval, len(samples))
+	for id, s := range samples {
+		sort.Float64s(s)
+		ci[id] = Interval{
+			Lower: stat.Quantile(0.025, stat.Empirical, s, nil),
+			Upper: stat.Quantile(0.975, stat.Empirical, s, nil),
+		}
+	}
+	return ci, nil
+}
+
+// resampled returns a copy of the nodes of g with IDs in keep holding each
+// of edges with a non-zero count, with the edge weight multiplied by the
+// count. The attribute attr is removed from the copied nodes and the copy
+// has no recorded provenance so that attr can be written by any analysis.
+func resampled(g *Graph, keep map[int64]int, attr string, edges []graph.Edge, count []int) (*Graph, error) {
+	dst := subgraphNodes(g, keep)
+	dst.provenance = nil
+	for _, n := range NodesOf(dst) {
+		n.SetAttribute(encoding.Attribute{Key: attr})
+	}
+	for i, e := range edges {
+		if count[i] == 0 {
+			continue
+		}
+		e := e.(*Edge)
+		r := &Edge{
+			F:          dst.Node(e.F.ID()).(*Node),
+			T:          dst.Node(e.T.ID()).(*Node),
+			Attributes: append(Attributes(nil), e.Attributes...),
+		}
+		if count[i] > 1 {
+			w, err := edgeWeight(e, "weight")
+			if err != nil {
+				return nil, err
+			}
+			r.SetAttribute(encoding.Attribute{Key: "weight", Value: fmt.Sprint(w * float64(count[i]))})
+		}
+		dst.SetEdge(r)
+	}
+	return dst, nil
+}
+
+// sortedEdges returns the edges of g in order of the IDs of their ends so
+// that random choices of edges are determined by the seed alone.
+func sortedEdges(g *Graph) []graph.Edge {
+	edges := graph.EdgesOf(g.Edges())
+	ends := func(e graph.Edge) (lo, hi int64) {
+		lo, hi = e.From().ID(), e.To().ID()
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		return lo, hi
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		ui, vi := ends(edges[i])
+		uj, vj := ends(edges[j])
+		return ui < uj || (ui == uj && vi < vj)
+	})
+	return edges
+}
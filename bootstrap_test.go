@@ -0,0 +1,85 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBootstrapMetric(t *testing.T) {
+	const star = `graph { c -- {a b d e f} }`
+	g := graphFromDOT(t, star)
+	want := DOT(g)
+	strength := Analysis{Name: "Strength", Attr: "strength"}
+
+	ci, err := BootstrapMetric(g, strength, 200, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := DOT(g); got != want {
+		t.Errorf("unexpected change to graph:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if len(ci) != 6 {
+		t.Errorf("unexpected number of intervals: got:%d want:6", len(ci))
+	}
+
+	// Every resampled edge is incident to the centre, so its
+	// strength is the number of edges in every resample.
+	if got, want := ci[g.NodeNamed("c").ID()], (Interval{Lower: 5, Upper: 5}); got != want {
+		t.Errorf("unexpected interval for centre: got:%+v want:%+v", got, want)
+	}
+	for _, name := range []string{"a", "b", "d", "e", "f"} {
+		got := ci[g.NodeNamed(name).ID()]
+		if got.Lower != 0 || got.Upper < 2 || got.Upper > 5 {
+			t.Errorf("unexpected interval for leaf %s: got:%+v", name, got)
+		}
+	}
+
+	if got, _ := BootstrapMetric(g, strength, 200, 1); !reflect.DeepEqual(got, ci) {
+		t.Errorf("unexpected intervals for repeated seed: got:%v want:%v", got, ci)
+	}
+}
+
+func TestBootstrapMetricWeighted(t *testing.T) {
+	// Resampled edge weights are multiplied by the number
+	// of times they are drawn, so the total strength is
+	// twice the total weight of drawn edges.
+	g := graphFromDOT(t, `graph { a -- b [weight=2]; b -- c [weight=2] }`)
+	ci, err := BootstrapMetric(g, Analysis{Name: "Strength", Attr: "strength"}, 100, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := ci[g.NodeNamed("b").ID()], (Interval{Lower: 4, Upper: 4}); got != want {
+		t.Errorf("unexpected interval for b: got:%+v want:%+v", got, want)
+	}
+	if got := ci[g.NodeNamed("a").ID()]; got.Lower != 0 || got.Upper != 4 {
+		t.Errorf("unexpected interval for a: got:%+v want:{Lower:0 Upper:4}", got)
+	}
+}
+
+func TestBootstrapMetricErrors(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b [weight=heavy]; b -- c }`)
+	for _, test := range []struct {
+		name   string
+		metric Analysis
+	}{
+		{name: "unknown analysis", metric: Analysis{Name: "NoSuchAnalysis", Attr: "x"}},
+		{name: "invalid parameter", metric: Analysis{Name: "PageRank", Params: map[string]string{"damp": "2"}, Attr: "rank"}},
+		{name: "failed analysis", metric: Analysis{Name: "Strength", Attr: "strength"}},
+	} {
+		ci, err := BootstrapMetric(g, test.metric, 10, 1)
+		if err == nil {
+			t.Errorf("expected error for %s: got:%v", test.name, ci)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for no resamples")
+		}
+	}()
+	BootstrapMetric(g, Analysis{Name: "Strength", Attr: "strength"}, 0, 1)
+}
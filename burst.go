@@ -0,0 +1,129 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"sort"
+
+	"gonum.org/v1/gonum/mathext"
+)
+
+// Burst is an interval of anomalously high contact activity in a
+// temporal graph.
+type Burst struct {
+	// Node is the node whose contacts are
+	// bursty, or nil for a burst in the
+	// activity of the whole graph.
+	Node *Node
+
+	// Start and End are the bounds of the
+	// interval, [Start, End).
+	Start, End float64
+
+	// Contacts is the number of contacts in
+	// the interval and Expected is the number
+	// expected from the mean rate of contacts.
+	Contacts int
+	Expected float64
+}
+
+// burstAlpha is the significance level for a window to be in a burst,
+// before correction for the number of windows.
+const burstAlpha = 0.01
+
+// DetectBursts returns the bursts of contact activity in g, for the graph
+// as a whole and for the contacts of each node. Time is divided into
+// windows of the given width starting at the time of the first contact,
+// and the number of contacts in each window is compared with a Poisson
+// distribution with the mean number of contacts per window over all the
+// windows. A window is part of a burst if the probability of at least as
+// many contacts is less than 0.01 divided by the number of windows, a
+// Bonferroni correction for testing every window, and adjacent windows
+// are merged into a single burst. The bursts are ordered by start time,
+// with bursts of the whole graph before those of nodes and bursts of
+// nodes ordered by DOT ID.
+//
+// DetectBursts panics if window is not positive.
+func DetectBursts(g *TemporalGraph, window float64) []Burst {
+	if !(window > 0) {
+		panic("graphprac: invalid burst window")
+	}
+	if len(g.contacts) == 0 {
+		return nil
+	}
+	first := g.times[0]
+	n := int((g.times[len(g.times)-1]-first)/window) + 1
+
+	total := make([]int, n)
+	perNode := make(map[*Node][]int)
+	count := func(u *Node, w int) {
+		c, ok := perNode[u]
+		if !ok {
+			c = make([]int, n)
+			perNode[u] = c
+		}
+		c[w]++
+	}
+	for i, c := range g.contacts {
+		w := int((g.times[i] - first) / window)
+		total[w]++
+		count(c.F, w)
+		count(c.T, w)
+	}
+
+	bursts := bursty(nil, total, first, window)
+	for u, c := range perNode {
+		i := len(bursts)
+		bursts = bursty(bursts, c, first, window)
+		for ; i < len(bursts); i++ {
+			bursts[i].Node = u
+		}
+	}
+	sort.SliceStable(bursts, func(i, j int) bool {
+		a, b := bursts[i], bursts[j]
+		switch {
+		case a.Start != b.Start:
+			return a.Start < b.Start
+		case a.Node == nil || b.Node == nil:
+			return a.Node == nil && b.Node != nil
+		default:
+			return a.Node.Name < b.Node.Name
+		}
+	})
+	return bursts
+}
+
+// bursty appends to dst the bursts in the counts of contacts in windows of
+// the given width starting at first. The Node field of the appended
+// bursts is nil.
+func bursty(dst []Burst, counts []int, first, window float64) []Burst {
+	var sum int
+	for _, c := range counts {
+		sum += c
+	}
+	mean := float64(sum) / float64(len(counts))
+	threshold := burstAlpha / float64(len(counts))
+
+	inBurst := false
+	for w, c := range counts {
+		// The probability of at least c contacts from
+		// a Poisson distribution is P(c, mean), the
+		// regularized lower incomplete gamma function.
+		if c == 0 || mathext.GammaIncReg(float64(c), mean) >= threshold {
+			inBurst = false
+			continue
+		}
+		start := first + float64(w)*window
+		if !inBurst {
+			dst = append(dst, Burst{Start: start})
+			inBurst = true
+		}
+		b := &dst[len(dst)-1]
+		b.End = start + window
+		b.Contacts += c
+		b.Expected += mean
+	}
+	return dst
+}
@@ -0,0 +1,72 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDetectBursts(t *testing.T) {
+	// a and b have one contact in each of 30 unit windows
+	// and c and d have 15 contacts in each of windows 10
+	// and 11.
+	var steady, burst []string
+	for i := 0; i < 30; i++ {
+		steady = append(steady, fmt.Sprint(i))
+	}
+	for i := 0; i < 15; i++ {
+		burst = append(burst, fmt.Sprintf("%v", 10.05+float64(i)*0.06), fmt.Sprintf("%v", 11.05+float64(i)*0.06))
+	}
+	dot := fmt.Sprintf(`graph { a -- b [time=%q]; c -- d [time=%q] }`,
+		strings.Join(steady, ","), strings.Join(burst, ","))
+	g, err := NewTemporalGraph(graphFromDOT(t, dot))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := DetectBursts(g, 1)
+	want := []struct {
+		node       string
+		start, end float64
+		contacts   int
+		expected   float64
+	}{
+		{node: "", start: 10, end: 12, contacts: 32, expected: 4},
+		{node: "c", start: 10, end: 12, contacts: 30, expected: 2},
+		{node: "d", start: 10, end: 12, contacts: 30, expected: 2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected number of bursts: got:%d want:%d\n%+v", len(got), len(want), got)
+	}
+	for i, b := range got {
+		var node string
+		if b.Node != nil {
+			node = b.Node.Name
+		}
+		w := want[i]
+		if node != w.node || b.Start != w.start || b.End != w.end || b.Contacts != w.contacts || b.Expected != w.expected {
+			t.Errorf("unexpected burst %d: got:{%s %v %v %d %v} want:%+v",
+				i, node, b.Start, b.End, b.Contacts, b.Expected, w)
+		}
+	}
+
+	// Steady activity has no bursts.
+	steadyOnly, err := NewTemporalGraph(graphFromDOT(t, fmt.Sprintf(`graph { a -- b [time=%q] }`, strings.Join(steady, ","))))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b := DetectBursts(steadyOnly, 1); len(b) != 0 {
+		t.Errorf("unexpected bursts for steady activity: %+v", b)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for zero window")
+		}
+	}()
+	DetectBursts(g, 0)
+}
@@ -0,0 +1,207 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// WLHash returns the Weisfeiler-Lehman hash of the structure of g after
+// the given number of refinement iterations. Isomorphic graphs have the
+// same hash. Node names and attributes are not considered.
+func WLHash(g *Graph, iterations int) string {
+	return wlHash(g, iterations, func(n *Node) string {
+		return fmt.Sprint(g.From(n.ID()).Len())
+	}, nil)
+}
+
+// wlHash returns the Weisfeiler-Lehman hash of g after the given number of
+// refinement iterations, with nodes initially labelled by label. If
+// edgeLabel is not nil, the label of each neighbour is combined with the
+// label of the edge to it during refinement.
+func wlHash(g *Graph, iterations int, label func(*Node) string, edgeLabel func(*Edge) string) string {
+	nodes := NodesOf(g)
+	labels := make(map[int64]string, len(nodes))
+	for _, n := range nodes {
+		labels[n.ID()] = label(n)
+	}
+	for i := 0; i < iterations; i++ {
+		next := make(map[int64]string, len(nodes))
+		for _, n := range nodes {
+			var adj []string
+			for _, v := range graph.NodesOf(g.From(n.ID())) {
+				l := labels[v.ID()]
+				if edgeLabel != nil {
+					l = hashStrings([]string{l, edgeLabel(g.EdgeBetween(n.ID(), v.ID()).(*Edge))})
+				}
+				adj = append(adj, l)
+			}
+			sort.Strings(adj)
+			next[n.ID()] = hashStrings(append([]string{labels[n.ID()]}, adj...))
+		}
+		labels = next
+	}
+	all := make([]string, 0, len(labels))
+	for _, l := range labels {
+		all = append(all, l)
+	}
+	sort.Strings(all)
+	return hashStrings(all)
+}
+
+func hashStrings(s []string) string {
+	h := sha256.New()
+	for _, e := range s {
+		fmt.Fprintf(h, "%q", e)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Cache is an on-disk store of analysis results. Results are keyed on
+// the structure, node names and attributes of the analysed graph and a
+// caller provided key describing the analysis and its parameters.
+type Cache struct {
+	dir string
+}
+
+// NewCache returns a Cache storing results in dir, creating the directory
+// if necessary.
+func NewCache(dir string) (*Cache, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// cacheEntry is the stored form of a set of analysis results.
+type cacheEntry struct {
+	Nodes map[string]map[string]string `json:"nodes"`
+	Edges map[string]map[string]string `json:"edges,omitempty"`
+}
+
+// Do calls fn on g unless the cache holds results for the same graph and
+// key, in which case the node and edge attributes listed in attrs are
+// restored from the cache instead. After fn is called, the attributes in
+// attrs are stored in the cache. The key should describe the analysis and
+// its parameters, for example "pagerank 0.85 1e-6".
+//
+// Graphs are the same if they have the same structure, node names and
+// node and edge attributes, other than the attributes in attrs, so that
+// results are recomputed when an input to the analysis, such as an edge
+// weight, has changed.
+func (c *Cache) Do(g *Graph, key string, attrs []string, fn func(*Graph)) error {
+	path := c.path(g, key, attrs)
+	b, err := ioutil.ReadFile(path)
+	if err == nil {
+		var e cacheEntry
+		err = json.Unmarshal(b, &e)
+		if err != nil {
+			return fmt.Errorf("corrupt cache entry %s: %v", path, err)
+		}
+		for _, n := range NodesOf(g) {
+			for k, v := range e.Nodes[n.Name] {
+				n.SetAttribute(encoding.Attribute{Key: k, Value: v})
+			}
+		}
+		for _, ge := range graph.EdgesOf(g.Edges()) {
+			ge := ge.(*Edge)
+			for k, v := range e.Edges[edgeName(ge)] {
+				ge.SetAttribute(encoding.Attribute{Key: k, Value: v})
+			}
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	fn(g)
+
+	e := cacheEntry{
+		Nodes: make(map[string]map[string]string),
+		Edges: make(map[string]map[string]string),
+	}
+	for _, n := range NodesOf(g) {
+		if v := pick(n.Attributes, attrs); v != nil {
+			e.Nodes[n.Name] = v
+		}
+	}
+	for _, ge := range graph.EdgesOf(g.Edges()) {
+		ge := ge.(*Edge)
+		if v := pick(ge.Attributes, attrs); v != nil {
+			e.Edges[edgeName(ge)] = v
+		}
+	}
+	b, err = json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// path returns the cache file path for the analysis of g described by key
+// and writing the attributes in attrs.
+func (c *Cache) path(g *Graph, key string, attrs []string) string {
+	out := make(map[string]bool, len(attrs))
+	for _, k := range attrs {
+		out[k] = true
+	}
+	h := wlHash(g, 1,
+		func(n *Node) string { return n.Name + "\t" + inputLabel(n.Attributes, out) },
+		func(e *Edge) string { return inputLabel(e.Attributes, out) },
+	)
+	return filepath.Join(c.dir, h[:32]+"-"+hashStrings([]string{key})[:16]+".json")
+}
+
+// inputLabel returns a label for the attributes in a that are not in out.
+func inputLabel(a Attributes, out map[string]bool) string {
+	kv := make([]string, 0, len(a))
+	for _, attr := range a {
+		if !out[attr.Key] && attr.Value != "" {
+			kv = append(kv, attr.Key+"="+attr.Value)
+		}
+	}
+	sort.Strings(kv)
+	return hashStrings(kv)
+}
+
+// pick returns the values of the attributes in a with keys in keys, or
+// nil if none are present.
+func pick(a Attributes, keys []string) map[string]string {
+	var m map[string]string
+	for _, k := range keys {
+		for _, kv := range a {
+			if kv.Key == k {
+				if m == nil {
+					m = make(map[string]string)
+				}
+				m[k] = kv.Value
+				break
+			}
+		}
+	}
+	return m
+}
+
+// edgeName returns an order independent name for the edge e.
+func edgeName(e *Edge) string {
+	u, v := e.F.Name, e.T.Name
+	if u > v {
+		u, v = v, u
+	}
+	return strings.Join([]string{u, v}, "\t")
+}
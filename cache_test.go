@@ -0,0 +1,90 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+var wlHashTests = []struct {
+	a, b string
+	same bool
+}{
+	{
+		a:    `graph { a -- b -- c -- a; c -- d }`,
+		b:    `graph { w -- x; x -- y -- z -- x }`,
+		same: true,
+	},
+	{
+		a:    `graph { a -- b -- c -- d }`,
+		b:    `graph { a -- b; a -- c; a -- d }`,
+		same: false,
+	},
+	{
+		a:    `graph { a -- b [weight=2] }`,
+		b:    `graph { a -- b }`,
+		same: true,
+	},
+}
+
+func TestWLHash(t *testing.T) {
+	for i, test := range wlHashTests {
+		a := WLHash(graphFromDOT(t, test.a), 3)
+		b := WLHash(graphFromDOT(t, test.b), 3)
+		if (a == b) != test.same {
+			t.Errorf("unexpected hash equality for test %d: got:%t want:%t", i, a == b, test.same)
+		}
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graphprac-cache")
+	if err != nil {
+		t.Fatalf("failed to create cache directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	c, err := NewCache(dir)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+
+	var calls int
+	sum := func(g *Graph) {
+		calls++
+		for _, n := range NodesOf(g) {
+			n.SetAttribute(encoding.Attribute{Key: "result", Value: n.Name + "!"})
+		}
+	}
+
+	steps := []struct {
+		dot       string
+		wantCalls int
+	}{
+		{dot: `graph { a -- b [weight=1] }`, wantCalls: 1},
+		{dot: `graph { a -- b [weight=1] }`, wantCalls: 1},
+		{dot: `graph { a -- b [weight=2] }`, wantCalls: 2},
+		{dot: `graph { a -- c [weight=2] }`, wantCalls: 3},
+		{dot: `graph { a -- b [weight=1] }`, wantCalls: 3},
+	}
+	for i, step := range steps {
+		g := graphFromDOT(t, step.dot)
+		err := c.Do(g, "test", []string{"result"}, sum)
+		if err != nil {
+			t.Fatalf("unexpected error for step %d: %v", i, err)
+		}
+		if calls != step.wantCalls {
+			t.Errorf("unexpected number of calls after step %d: got:%d want:%d", i, calls, step.wantCalls)
+		}
+		for _, n := range NodesOf(g) {
+			if got, want := n.Get("result"), n.Name+"!"; got != want {
+				t.Errorf("unexpected result for %s in step %d: got:%s want:%s", n.Name, i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Checkpoint is a snapshot of the structure and attributes of a Graph
+// that can be restored with the Graph's Restore method.
+type Checkpoint struct {
+	nodes []nodeState
+	edges []edgeState
+
+	graphAttrs, nodeAttrs, edgeAttrs Attributes
+
+	provenance map[string]AttributeProvenance
+}
+
+type nodeState struct {
+	node  *Node
+	name  string
+	attrs Attributes
+}
+
+type edgeState struct {
+	edge  *Edge
+	f, t  *Node
+	attrs Attributes
+}
+
+// Checkpoint returns a snapshot of the nodes, edges and attributes of g.
+// Attribute values are not copied when the checkpoint is taken; instead
+// the attributes of g are copied when they are next modified with
+// SetAttribute, so checkpoints are cheap to take. Modifying attribute
+// values by indexing into Attributes directly will also modify the
+// checkpoint.
+func (g *Graph) Checkpoint() Checkpoint {
+	cp := Checkpoint{
+		graphAttrs: share(&g.GraphAttrs),
+		nodeAttrs:  share(&g.NodeAttrs),
+		edgeAttrs:  share(&g.EdgeAttrs),
+	}
+	for _, n := range NodesOf(g) {
+		cp.nodes = append(cp.nodes, nodeState{node: n, name: n.Name, attrs: share(&n.Attributes)})
+	}
+	for _, e := range graph.EdgesOf(g.Edges()) {
+		e := e.(*Edge)
+		cp.edges = append(cp.edges, edgeState{edge: e, f: e.F, t: e.T, attrs: share(&e.Attributes)})
+	}
+	if g.provenance != nil {
+		cp.provenance = make(map[string]AttributeProvenance, len(g.provenance))
+		for k, v := range g.provenance {
+			cp.provenance[k] = v
+		}
+	}
+	return cp
+}
+
+// Restore returns g to the state recorded in cp, which must have been
+// returned by a call to g's Checkpoint method. Nodes and edges removed
+// since the checkpoint was taken are restored and those added are removed.
+// A checkpoint may be restored more than once. Subscribers to g are sent a
+// single Restored event.
+func (g *Graph) Restore(cp Checkpoint) {
+	g.UndirectedGraph = simple.NewUndirectedGraph()
+	g.GraphAttrs = cp.graphAttrs
+	g.NodeAttrs = cp.nodeAttrs
+	g.EdgeAttrs = cp.edgeAttrs
+	for _, s := range cp.nodes {
+		s.node.Name = s.name
+		s.node.Attributes = s.attrs
+		g.UndirectedGraph.AddNode(s.node)
+		s.node.graph = g
+	}
+	for _, s := range cp.edges {
+		s.edge.F, s.edge.T = s.f, s.t
+		s.edge.Attributes = s.attrs
+		g.UndirectedGraph.SetEdge(s.edge)
+	}
+	g.provenance = nil
+	for k, v := range cp.provenance {
+		g.setProvenanceRecord(k, v)
+	}
+	g.notify(Event{Op: Restored})
+}
+
+// share returns a without spare capacity and sets a to the same slice
+// so that subsequent modifications through SetAttribute copy the
+// attributes rather than altering the returned slice.
+func share(a *Attributes) Attributes {
+	*a = (*a)[:len(*a):len(*a)]
+	return *a
+}
@@ -0,0 +1,43 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+func TestCheckpoint(t *testing.T) {
+	g := graphFromDOT(t, `graph G { a [x=1]; a -- b [w=2]; b -- c }`)
+	want := DOT(g)
+	cp := g.Checkpoint()
+
+	a, b, c := g.NodeNamed("a"), g.NodeNamed("b"), g.NodeNamed("c")
+	a.SetAttribute(encoding.Attribute{Key: "x", Value: "5"})
+	g.EdgeBetween(a.ID(), b.ID()).(*Edge).SetAttribute(encoding.Attribute{Key: "w", Value: ""})
+	g.RemoveEdge(b.ID(), c.ID())
+	g.RemoveNode(c.ID())
+	d := g.NewNode().(*Node)
+	d.Name = "d"
+	g.AddNode(d)
+	g.SetEdge(g.NewEdge(a, d))
+	g.GraphAttrs.SetAttribute(encoding.Attribute{Key: "label", Value: "changed"})
+	Eccentricity(g)
+	if DOT(g) == want {
+		t.Fatal("graph not changed")
+	}
+
+	for i := 0; i < 2; i++ {
+		g.Restore(cp)
+		if got := DOT(g); got != want {
+			t.Errorf("unexpected graph after restore %d:\ngot:\n%s\nwant:\n%s", i, got, want)
+		}
+		if _, ok := Provenance(g, "eccentricity"); ok {
+			t.Errorf("unexpected provenance after restore %d", i)
+		}
+		g.NodeNamed("a").SetAttribute(encoding.Attribute{Key: "x", Value: "6"})
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// PlotChord renders a chord diagram of the flows between the communities
+// of g as an SVG written to w. Community membership is given by the value
+// of the attr attribute of each node, for example the "community"
+// attribute written by Communities. Each community is drawn as a segment
+// of a circle with a length proportional to the total weight of the edges
+// leaving it, and the edges between each pair of communities are drawn as
+// a ribbon with a width proportional to their total weight. Edge weights
+// are given by the "weight" attribute of each edge, or 1 if it is not set.
+// Edges within a community and nodes without the attribute are ignored.
+func PlotChord(g *Graph, attr string, w io.Writer) error {
+	var names []string
+	index := make(map[string]int)
+	member := make(map[int64]int)
+	for _, n := range NodesOf(g) {
+		c := n.GetUnquoted(attr)
+		if c == "" {
+			continue
+		}
+		if _, ok := index[c]; !ok {
+			index[c] = 0
+			names = append(names, c)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no node has attribute %q", attr)
+	}
+	sortNatural(names)
+	for i, c := range names {
+		index[c] = i
+	}
+	for _, n := range NodesOf(g) {
+		if i, ok := index[n.GetUnquoted(attr)]; ok {
+			member[n.ID()] = i
+		}
+	}
+
+	flow := make([][]float64, len(names))
+	for i := range flow {
+		flow[i] = make([]float64, len(names))
+	}
+	for uid, i := range member {
+		for _, v := range graph.NodesOf(g.From(uid)) {
+			j, ok := member[v.ID()]
+			if !ok || i == j || v.ID() < uid {
+				continue
+			}
+			w, err := edgeWeight(g.EdgeBetween(uid, v.ID()).(*Edge), "weight")
+			if err != nil {
+				return err
+			}
+			flow[i][j] += w
+			flow[j][i] += w
+		}
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		return err
+	}
+	p.HideAxes()
+	p.Add(&chordPlot{names: names, flow: flow})
+	return writePlot(p, w)
+}
+
+// edgeWeight returns the value of the numerical attribute key of e, or 1
+// if the attribute is not set.
+func edgeWeight(e *Edge, key string) (float64, error) {
+	v := e.GetUnquoted(key)
+	if v == "" {
+		return 1, nil
+	}
+	w, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s for edge %q--%q: %v", key, e.F.Name, e.T.Name, err)
+	}
+	return w, nil
+}
+
+// chordPlot is a plot.Plotter that draws a chord diagram.
+type chordPlot struct {
+	names []string
+	flow  [][]float64
+}
+
+func (p *chordPlot) Plot(c draw.Canvas, _ *plot.Plot) {
+	const (
+		gap   = 0.02 // Gap between segments in radians.
+		width = 0.06 // Segment width as a fraction of radius.
+	)
+	centre := c.Center()
+	radius := vg.Length(0.8 * math.Min(float64(c.Max.X-c.Min.X), float64(c.Max.Y-c.Min.Y)) / 2)
+	point := func(theta float64, r vg.Length) vg.Point {
+		return vg.Point{
+			X: centre.X + r*vg.Length(math.Cos(theta)),
+			Y: centre.Y + r*vg.Length(math.Sin(theta)),
+		}
+	}
+
+	var total float64
+	for _, row := range p.flow {
+		for _, f := range row {
+			total += f
+		}
+	}
+	if total == 0 {
+		return
+	}
+	scale := (2*math.Pi - gap*float64(len(p.names))) / total
+
+	// start[i][j] is the angle at which the ribbon
+	// between i and j leaves segment i.
+	start := make([][]float64, len(p.names))
+	segStart := make([]float64, len(p.names))
+	theta := math.Pi / 2
+	for i, row := range p.flow {
+		segStart[i] = theta
+		start[i] = make([]float64, len(row))
+		for j, f := range row {
+			start[i][j] = theta
+			theta += f * scale
+		}
+		theta += gap
+	}
+
+	for i, row := range p.flow {
+		for j := i + 1; j < len(row); j++ {
+			f := row[j]
+			if f == 0 {
+				continue
+			}
+			a0, a := start[i][j], f*scale
+			b0, b := start[j][i], p.flow[j][i]*scale
+			var path vg.Path
+			path.Move(point(a0, radius))
+			path.Arc(centre, radius, a0, a)
+			path.QuadTo(centre, point(b0, radius))
+			path.Arc(centre, radius, b0, b)
+			path.QuadTo(centre, point(a0, radius))
+			path.Close()
+			c.SetColor(translucent(plotutil.Color(i), 0x80))
+			c.Fill(path)
+		}
+	}
+
+	font, err := vg.MakeFont(plot.DefaultFont, vg.Points(10))
+	if err != nil {
+		panic(err)
+	}
+	outer := radius * (1 + width)
+	for i, row := range p.flow {
+		var a float64
+		for _, f := range row {
+			a += f * scale
+		}
+		if a == 0 {
+			continue
+		}
+		s := segStart[i]
+		var path vg.Path
+		path.Move(point(s, radius))
+		path.Arc(centre, radius, s, a)
+		path.Arc(centre, outer, s+a, -a)
+		path.Close()
+		c.SetColor(plotutil.Color(i))
+		c.Fill(path)
+
+		mid := s + a/2
+		c.FillText(draw.TextStyle{Color: color.Black, Font: font, XAlign: draw.XCenter, YAlign: draw.YCenter},
+			point(mid, outer+vg.Points(12)), p.names[i])
+	}
+}
+
+// translucent returns c with the given alpha.
+func translucent(c color.Color, alpha uint8) color.Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	n.A = alpha
+	return n
+}
@@ -0,0 +1,55 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"strings"
+	"testing"
+)
+
+var edgeWeightTests = []struct {
+	attrs   Attributes
+	want    float64
+	wantErr bool
+}{
+	{attrs: nil, want: 1},
+	{attrs: Attributes{{Key: "weight", Value: "2.5"}}, want: 2.5},
+	{attrs: Attributes{{Key: "weight", Value: `"3"`}}, want: 3},
+	{attrs: Attributes{{Key: "weight", Value: "heavy"}}, wantErr: true},
+}
+
+func TestEdgeWeight(t *testing.T) {
+	for i, test := range edgeWeightTests {
+		e := &Edge{F: &Node{Name: "a"}, T: &Node{NodeID: 1, Name: "b"}, Attributes: test.attrs}
+		got, err := edgeWeight(e, "weight")
+		if (err != nil) != test.wantErr {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("unexpected weight for test %d: got:%v want:%v", i, got, test.want)
+		}
+	}
+}
+
+func TestPlotChord(t *testing.T) {
+	g := graphFromDOT(t, plotDOT)
+	var buf strings.Builder
+	err := PlotChord(g, "group", &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkSVG(t, buf.String())
+
+	err = PlotChord(g, "missing", &strings.Builder{})
+	if err == nil {
+		t.Error("expected error for missing attribute")
+	}
+	g = graphFromDOT(t, `graph { a [c=1]; b [c=2]; a -- b [weight=heavy]; }`)
+	err = PlotChord(g, "c", &strings.Builder{})
+	if err == nil {
+		t.Error("expected error for invalid weight")
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/encoding/dot"
+	dotfmt "gonum.org/v1/gonum/graph/formats/dot"
+	"gonum.org/v1/gonum/graph/formats/dot/ast"
+	"gonum.org/v1/gonum/graph/iterator"
+)
+
+// Cluster is a DOT cluster subgraph, a named group of nodes that GraphViz
+// draws together within a box. Clusters may be nested.
+type Cluster struct {
+	// Name is the DOT ID of the cluster,
+	// which starts with "cluster".
+	Name string
+
+	// Attributes holds the graph attributes
+	// of the cluster, for example its label.
+	Attributes
+
+	// Nodes holds the nodes of the cluster
+	// that are not in a nested cluster, and
+	// Clusters holds the nested clusters.
+	Nodes    []*Node
+	Clusters []*Cluster
+}
+
+// clusterSpec is the form of a Cluster held by a Graph. Nodes are held
+// by DOT ID so that removed nodes are not retained.
+type clusterSpec struct {
+	name     string
+	attrs    Attributes
+	nodes    []string
+	clusters []*clusterSpec
+}
+
+// Clusters returns the hierarchy of DOT cluster subgraphs read with g. The
+// nodes of each cluster are those nodes of the cluster that are still in
+// g, and clusters are in the order they were read. Clusters are written
+// back out when g is rendered as DOT.
+func (g *Graph) Clusters() []*Cluster {
+	if len(g.clusters) == 0 {
+		return nil
+	}
+	byName := make(map[string]*Node)
+	for _, n := range NodesOf(g) {
+		byName[n.Name] = n
+	}
+	return clustersFrom(g.clusters, byName)
+}
+
+func clustersFrom(specs []*clusterSpec, byName map[string]*Node) []*Cluster {
+	var clusters []*Cluster
+	for _, s := range specs {
+		c := &Cluster{
+			Name:       s.name,
+			Attributes: append(Attributes(nil), s.attrs...),
+			Clusters:   clustersFrom(s.clusters, byName),
+		}
+		for _, name := range s.nodes {
+			if n, ok := byName[name]; ok {
+				c.Nodes = append(c.Nodes, n)
+			}
+		}
+		clusters = append(clusters, c)
+	}
+	return clusters
+}
+
+// readClusters returns the cluster hierarchy of the first graph in the DOT
+// data in b. The gonum DOT decoder flattens subgraphs, so the data is
+// parsed separately to find the clusters.
+func readClusters(b []byte) ([]*clusterSpec, error) {
+	if !bytes.Contains(b, []byte("cluster")) {
+		return nil, nil
+	}
+	f, err := dotfmt.ParseBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Graphs) == 0 {
+		return nil, nil
+	}
+	return clustersIn(f.Graphs[0].Stmts), nil
+}
+
+// clustersIn returns the clusters within stmts.
+func clustersIn(stmts []ast.Stmt) []*clusterSpec {
+	var clusters []*clusterSpec
+	for _, s := range subgraphsIn(stmts) {
+		if !strings.HasPrefix(unquoteDOTID(s.ID), "cluster") {
+			clusters = append(clusters, clustersIn(s.Stmts)...)
+			continue
+		}
+		c := &clusterSpec{
+			name:     unquoteDOTID(s.ID),
+			clusters: clustersIn(s.Stmts),
+		}
+		for _, stmt := range s.Stmts {
+			switch stmt := stmt.(type) {
+			case *ast.AttrStmt:
+				if stmt.Kind == ast.GraphKind {
+					for _, a := range stmt.Attrs {
+						c.attrs.SetAttribute(encoding.Attribute{Key: unquoteDOTID(a.Key), Value: unquoteDOTID(a.Val)})
+					}
+				}
+			case *ast.Attr:
+				c.attrs.SetAttribute(encoding.Attribute{Key: unquoteDOTID(stmt.Key), Value: unquoteDOTID(stmt.Val)})
+			}
+		}
+
+		nested := make(map[string]bool)
+		for _, n := range allClusterNodes(c.clusters) {
+			nested[n] = true
+		}
+		for _, n := range nodesIn(s.Stmts) {
+			if !nested[n] {
+				nested[n] = true
+				c.nodes = append(c.nodes, n)
+			}
+		}
+		clusters = append(clusters, c)
+	}
+	return clusters
+}
+
+// subgraphsIn returns the subgraphs directly within stmts, including those
+// used as edge end points.
+func subgraphsIn(stmts []ast.Stmt) []*ast.Subgraph {
+	var subgraphs []*ast.Subgraph
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.Subgraph:
+			subgraphs = append(subgraphs, stmt)
+		case *ast.EdgeStmt:
+			if s, ok := stmt.From.(*ast.Subgraph); ok {
+				subgraphs = append(subgraphs, s)
+			}
+			for e := stmt.To; e != nil; e = e.To {
+				if s, ok := e.Vertex.(*ast.Subgraph); ok {
+					subgraphs = append(subgraphs, s)
+				}
+			}
+		}
+	}
+	return subgraphs
+}
+
+// nodesIn returns the DOT IDs of the nodes within stmts, including those
+// within subgraphs, in the order they first appear.
+func nodesIn(stmts []ast.Stmt) []string {
+	var nodes []string
+	seen := make(map[string]bool)
+	add := func(v ast.Vertex) {
+		switch v := v.(type) {
+		case *ast.Node:
+			id := unquoteDOTID(v.ID)
+			if !seen[id] {
+				seen[id] = true
+				nodes = append(nodes, id)
+			}
+		case *ast.Subgraph:
+			for _, id := range nodesIn(v.Stmts) {
+				if !seen[id] {
+					seen[id] = true
+					nodes = append(nodes, id)
+				}
+			}
+		}
+	}
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.NodeStmt:
+			add(stmt.Node)
+		case *ast.EdgeStmt:
+			add(stmt.From)
+			for e := stmt.To; e != nil; e = e.To {
+				add(e.Vertex)
+			}
+		case *ast.Subgraph:
+			add(stmt)
+		}
+	}
+	return nodes
+}
+
+// allClusterNodes returns the DOT IDs of the nodes in clusters and their
+// nested clusters.
+func allClusterNodes(clusters []*clusterSpec) []string {
+	var nodes []string
+	for _, c := range clusters {
+		nodes = append(nodes, c.nodes...)
+		nodes = append(nodes, allClusterNodes(c.clusters)...)
+	}
+	return nodes
+}
+
+// unquoteDOTID unquotes the DOT ID s in the same way as the gonum DOT
+// decoder, leaving quoted HTML-like strings quoted.
+func unquoteDOTID(s string) string {
+	if len(s) >= 4 && strings.HasPrefix(s, `"<`) && strings.HasSuffix(s, `>"`) {
+		return s
+	}
+	if t, err := strconv.Unquote(s); err == nil {
+		return t
+	}
+	return s
+}
+
+// dotCluster is a cluster prepared for DOT marshaling as a subgraph of
+// a dotGraph.
+type dotCluster struct {
+	name     string
+	attrs    dotAttributes
+	nodes    []graph.Node
+	clusters []dot.Graph
+}
+
+// newDOTClusters returns the clusters of specs holding the nodes in
+// byName, omitting clusters with no nodes.
+func newDOTClusters(specs []*clusterSpec, byName map[string]*dotNode, opts DOTOptions) []dot.Graph {
+	var clusters []dot.Graph
+	for _, s := range specs {
+		c := &dotCluster{
+			name:     s.name,
+			attrs:    filterAttributes(s.attrs, opts),
+			clusters: newDOTClusters(s.clusters, byName, opts),
+		}
+		for _, name := range s.nodes {
+			if n, ok := byName[name]; ok {
+				c.nodes = append(c.nodes, clusterNode{n})
+			}
+		}
+		if len(c.nodes) == 0 && len(c.clusters) == 0 {
+			continue
+		}
+		clusters = append(clusters, c)
+	}
+	return clusters
+}
+
+func (c *dotCluster) DOTID() string                  { return c.name }
+func (c *dotCluster) Structure() []dot.Graph         { return c.clusters }
+func (c *dotCluster) Nodes() graph.Nodes             { return iterator.NewOrderedNodes(c.nodes) }
+func (c *dotCluster) From(int64) graph.Nodes         { return graph.Empty }
+func (c *dotCluster) HasEdgeBetween(_, _ int64) bool { return false }
+func (c *dotCluster) Edge(_, _ int64) graph.Edge     { return nil }
+func (c *dotCluster) DOTAttributers() (graph, node, edge encoding.Attributer) {
+	return c.attrs, dotAttributes(nil), dotAttributes(nil)
+}
+
+func (c *dotCluster) Node(id int64) graph.Node {
+	for _, n := range c.nodes {
+		if n.ID() == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// clusterNode is a node written within a cluster. It has no attributes
+// so that the node's attributes are written only once.
+type clusterNode struct {
+	n *dotNode
+}
+
+func (n clusterNode) ID() int64     { return n.n.id }
+func (n clusterNode) DOTID() string { return n.n.dotID }
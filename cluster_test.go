@@ -0,0 +1,90 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// clusterString returns a compact description of clusters with their
+// label attribute, member node names and nested clusters.
+func clusterString(clusters []*Cluster) string {
+	var parts []string
+	for _, c := range clusters {
+		var names []string
+		for _, n := range c.Nodes {
+			names = append(names, n.Name)
+		}
+		s := c.Name
+		if l := c.GetUnquoted("label"); l != "" {
+			s += "[" + l + "]"
+		}
+		s += "(" + strings.Join(names, " ")
+		if len(c.Clusters) != 0 {
+			s += " " + clusterString(c.Clusters)
+		}
+		parts = append(parts, s+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+var clusterTests = []struct {
+	dot    string
+	remove string
+	want   string
+}{
+	{
+		dot:  `graph { a -- b }`,
+		want: ``,
+	},
+	{
+		dot:  `graph { subgraph cluster_a { a; b } subgraph other { c } c -- a }`,
+		want: `cluster_a(a b)`,
+	},
+	{
+		dot:  `graph G { subgraph cluster_a { label=A; a; b; subgraph cluster_inner { c } } subgraph cluster_b { d } a -- b; c -- d; e -- a }`,
+		want: `cluster_a[A](a b cluster_inner(c)) cluster_b(d)`,
+	},
+	{
+		dot:    `graph G { subgraph cluster_a { label=A; a; b; subgraph cluster_inner { c } } subgraph cluster_b { d } a -- b; c -- d; e -- a }`,
+		remove: "b",
+		want:   `cluster_a[A](a cluster_inner(c)) cluster_b(d)`,
+	},
+}
+
+func TestClusters(t *testing.T) {
+	for i, test := range clusterTests {
+		g := graphFromDOT(t, test.dot)
+		if test.remove != "" {
+			g.RemoveNode(g.NodeNamed(test.remove).ID())
+		}
+		if got := clusterString(g.Clusters()); got != test.want {
+			t.Errorf("unexpected clusters for test %d: got:%s want:%s", i, got, test.want)
+		}
+
+		// Clusters must survive a round trip through DOT.
+		want := DOT(g)
+		r := graphFromDOT(t, want)
+		if got := clusterString(r.Clusters()); got != test.want {
+			t.Errorf("unexpected round trip clusters for test %d: got:%s want:%s", i, got, test.want)
+		}
+		if got := DOT(r); got != want {
+			t.Errorf("unexpected round trip DOT for test %d:\ngot:\n%s\nwant:\n%s", i, got, want)
+		}
+	}
+}
+
+func TestClustersCopy(t *testing.T) {
+	g := graphFromDOT(t, `graph { subgraph cluster_a { label=A; a } }`)
+	c := g.Clusters()
+	c[0].SetAttribute(encoding.Attribute{Key: "label", Value: "B"})
+	c[0].Nodes = nil
+	if got, want := clusterString(g.Clusters()), "cluster_a[A](a)"; got != want {
+		t.Errorf("unexpected clusters after modifying returned value: got:%s want:%s", got, want)
+	}
+}
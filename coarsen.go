@@ -0,0 +1,134 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Coarsen returns up to levels progressively smaller summaries of g for
+// multilevel drawing. Each level is made from the one before, starting
+// from g, by heavy-edge matching: nodes are visited in node ID order and
+// each node not yet matched is merged with the unmatched neighbour joined
+// to it by the heaviest edge. Edge weights are given by the "weight"
+// attribute of each edge, or 1 if it is not set, and the edges of a
+// coarse graph have the sum of the weights of the edges they replace as
+// their "weight" attribute.
+//
+// Each coarse node takes the DOT ID of the lowest ID node merged into it,
+// and holds the DOT IDs of the nodes of g it summarises in its "members"
+// attribute, separated by commas. Coarsening stops early when no more
+// nodes can be merged, so fewer than levels graphs may be returned.
+//
+// Coarsen returns an error if an edge weight is invalid or negative, and
+// panics if levels is negative.
+func Coarsen(g *Graph, levels int) ([]*Graph, error) {
+	if levels < 0 {
+		panic("graphprac: invalid number of levels")
+	}
+	var coarse []*Graph
+	members := make(map[int64][]string)
+	x := g.Index()
+	for i := 0; i < x.Len(); i++ {
+		members[x.ID(i)] = []string{x.Node(i).Name}
+	}
+	for l := 0; l < levels; l++ {
+		c, m, err := coarsenOnce(g, members)
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			break
+		}
+		coarse = append(coarse, c)
+		g, members = c, m
+	}
+	return coarse, nil
+}
+
+// coarsenOnce returns the heavy-edge matching coarsening of g and the
+// members of its nodes, given the members of the nodes of g. It returns
+// a nil graph if no nodes of g can be merged.
+func coarsenOnce(g *Graph, members map[int64][]string) (*Graph, map[int64][]string, error) {
+	x := g.Index()
+	adj := x.adjacency(g)
+	w, err := edgeWeights(g, x, adj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	n := x.Len()
+	coarseOf := make([]int, n)
+	for i := range coarseOf {
+		coarseOf[i] = -1
+	}
+	var nc int
+	merged := false
+	for i, nbrs := range adj {
+		if coarseOf[i] >= 0 {
+			continue
+		}
+		coarseOf[i] = nc
+		best := -1
+		for k, j := range nbrs {
+			if coarseOf[j] < 0 && (best < 0 || w[i][k] > w[i][best]) {
+				best = k
+			}
+		}
+		if best >= 0 {
+			coarseOf[adj[i][best]] = nc
+			merged = true
+		}
+		nc++
+	}
+	if !merged {
+		return nil, nil, nil
+	}
+
+	c := &Graph{
+		UndirectedGraph: simple.NewUndirectedGraph(),
+		GraphAttrs:      append(Attributes(nil), g.GraphAttrs...),
+		NodeAttrs:       append(Attributes(nil), g.NodeAttrs...),
+		EdgeAttrs:       append(Attributes(nil), g.EdgeAttrs...),
+		Name:            g.Name,
+		Strict:          g.Strict,
+	}
+	nodes := make([]*Node, nc)
+	cm := make(map[int64][]string, nc)
+	for i, ci := range coarseOf {
+		if nodes[ci] == nil {
+			nodes[ci] = &Node{NodeID: int64(ci), Name: x.Node(i).Name}
+			c.AddNode(nodes[ci])
+		}
+		cm[int64(ci)] = append(cm[int64(ci)], members[x.ID(i)]...)
+	}
+	for id, names := range cm {
+		nodes[id].SetQuoted("members", strings.Join(names, ","))
+	}
+
+	weight := make(map[[2]int]float64)
+	for i, nbrs := range adj {
+		for k, j := range nbrs {
+			u, v := coarseOf[i], coarseOf[j]
+			if j < i || u == v {
+				continue
+			}
+			if u > v {
+				u, v = v, u
+			}
+			weight[[2]int{u, v}] += w[i][k]
+		}
+	}
+	for uv, wt := range weight {
+		e := &Edge{F: nodes[uv[0]], T: nodes[uv[1]]}
+		e.SetAttribute(encoding.Attribute{Key: "weight", Value: fmt.Sprint(wt)})
+		c.SetEdge(e)
+	}
+	return c, cm, nil
+}
@@ -0,0 +1,121 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// coarseString returns the nodes of g with their members and the edges
+// of g with their weights as sorted strings.
+func coarseString(g *Graph) (nodes, edges []string) {
+	for _, n := range NodesOf(g) {
+		nodes = append(nodes, n.Name+"["+n.GetUnquoted("members")+"]")
+	}
+	sort.Strings(nodes)
+	for _, e := range graph.EdgesOf(g.Edges()) {
+		e := e.(*Edge)
+		u, v := e.F.Name, e.T.Name
+		if v < u {
+			u, v = v, u
+		}
+		edges = append(edges, u+"--"+v+"="+e.Get("weight"))
+	}
+	sort.Strings(edges)
+	return nodes, edges
+}
+
+var coarsenTests = []struct {
+	dot    string
+	levels int
+	nodes  [][]string
+	edges  [][]string
+}{
+	{
+		dot:    `graph { a -- b [weight=1]; b -- c [weight=5]; c -- d [weight=1]; d -- e [weight=2] }`,
+		levels: 10,
+		nodes: [][]string{
+			{"a[a,b]", "c[c,d]", "e[e]"},
+			{"a[a,b,c,d]", "e[e]"},
+			{"a[a,b,c,d,e]"},
+		},
+		edges: [][]string{
+			{"a--c=5", "c--e=2"},
+			{"a--e=2"},
+			nil,
+		},
+	},
+	{
+		dot:    `graph { a -- b [weight=1]; b -- c [weight=5]; c -- d [weight=1]; d -- e [weight=2] }`,
+		levels: 1,
+		nodes:  [][]string{{"a[a,b]", "c[c,d]", "e[e]"}},
+		edges:  [][]string{{"a--c=5", "c--e=2"}},
+	},
+	{
+		// The heaviest edge of the first node is used.
+		dot:    `graph { a -- b; a -- c [weight=3]; b -- c; b -- d }`,
+		levels: 1,
+		nodes:  [][]string{{"a[a,c]", "b[b,d]"}},
+		edges:  [][]string{{"a--b=2"}},
+	},
+	{
+		dot:    `graph { a -- b }`,
+		levels: 0,
+	},
+	{
+		dot:    `graph { a; b }`,
+		levels: 3,
+	},
+}
+
+func TestCoarsen(t *testing.T) {
+	for i, test := range coarsenTests {
+		g := graphFromDOT(t, test.dot)
+		want := DOT(g)
+		coarse, err := Coarsen(g, test.levels)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		if DOT(g) != want {
+			t.Errorf("coarsening modified the graph for test %d", i)
+		}
+		if len(coarse) != len(test.nodes) {
+			t.Errorf("unexpected number of levels for test %d: got:%d want:%d", i, len(coarse), len(test.nodes))
+			continue
+		}
+		for l, c := range coarse {
+			nodes, edges := coarseString(c)
+			if !reflect.DeepEqual(nodes, test.nodes[l]) {
+				t.Errorf("unexpected nodes for test %d level %d: got:%q want:%q", i, l, nodes, test.nodes[l])
+			}
+			if !reflect.DeepEqual(edges, test.edges[l]) {
+				t.Errorf("unexpected edges for test %d level %d: got:%q want:%q", i, l, edges, test.edges[l])
+			}
+		}
+	}
+}
+
+func TestCoarsenErrors(t *testing.T) {
+	for _, dot := range []string{
+		`graph { a -- b [weight=-1] }`,
+		`graph { a -- b [weight=heavy] }`,
+	} {
+		if _, err := Coarsen(graphFromDOT(t, dot), 1); err == nil {
+			t.Errorf("expected error for %s", dot)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for negative levels")
+		}
+	}()
+	Coarsen(graphFromDOT(t, `graph { a -- b }`), -1)
+}
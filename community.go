@@ -0,0 +1,417 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"golang.org/x/exp/rand"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/community"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// topCentral is the number of most central members reported
+// in a CommunitySummary.
+const topCentral = 5
+
+// CommunitySummary holds summary statistics for a community. It is
+// intended to be serialised with encoding/json.
+type CommunitySummary struct {
+	Community string `json:"community"`
+	Size      int    `json:"size"`
+
+	// InternalEdges is the number of edges with
+	// both ends in the community.
+	InternalEdges int `json:"internal_edges"`
+	// Density is the internal edge density.
+	Density float64 `json:"density"`
+	// Conductance is the fraction of edge ends in
+	// the community that leave it, relative to the
+	// smaller of the community and its complement.
+	Conductance float64 `json:"conductance"`
+
+	// AverageDegree is the mean degree of the
+	// community's members in the whole graph.
+	AverageDegree float64 `json:"average_degree"`
+
+	// Central holds the DOT IDs of the members
+	// with the highest degree, in descending order.
+	Central []string `json:"central"`
+}
+
+// CommunityStats returns summary statistics for each community of g. The
+// community membership of each node is given by the value of its attr
+// attribute, for example the "community" attribute written by Communities.
+// Nodes without the attribute are not included in any community. The
+// summaries are ordered by community.
+func CommunityStats(g *Graph, attr string) []CommunitySummary {
+	names, members := communitiesOf(g, attr)
+	total := 2 * g.Edges().Len()
+	stats := make([]CommunitySummary, len(names))
+	for i, c := range names {
+		set := members[c]
+		in := distinctIDs(set)
+
+		var internal, cut, vol int
+		deg := make(map[int64]int, len(set))
+		for _, n := range set {
+			for _, v := range graph.NodesOf(g.From(n.ID())) {
+				deg[n.ID()]++
+				if in[v.ID()] {
+					internal++
+				} else {
+					cut++
+				}
+			}
+			vol += deg[n.ID()]
+		}
+		internal /= 2
+
+		central := append([]*Node(nil), set...)
+		sort.SliceStable(central, func(i, j int) bool {
+			return deg[central[i].ID()] > deg[central[j].ID()]
+		})
+		if len(central) > topCentral {
+			central = central[:topCentral]
+		}
+		ids := make([]string, len(central))
+		for i, n := range central {
+			ids[i] = dotIDOf(n)
+		}
+
+		s := CommunitySummary{
+			Community:     c,
+			Size:          len(set),
+			InternalEdges: internal,
+			Conductance:   conductance(cut, vol, total),
+			AverageDegree: float64(vol) / float64(len(set)),
+			Central:       ids,
+		}
+		if len(set) > 1 {
+			s.Density = 2 * float64(internal) / float64(len(set)*(len(set)-1))
+		}
+		stats[i] = s
+	}
+	return stats
+}
+
+// conductance returns the conductance of a node set with the given cut
+// size and volume in a graph with the given total volume. The conductance
+// of a set with no edges or including all edges is zero.
+func conductance(cut, vol, total int) float64 {
+	d := vol
+	if total-vol < d {
+		d = total - vol
+	}
+	if d == 0 {
+		return 0
+	}
+	return float64(cut) / float64(d)
+}
+
+// communitiesOf returns the communities of g given by the attr attribute
+// of each node and their members. The community names are returned in
+// numerical order if they are all numbers, and lexical order otherwise.
+// Members are ordered by DOT ID.
+func communitiesOf(g *Graph, attr string) (names []string, members map[string][]*Node) {
+	members = make(map[string][]*Node)
+	for _, n := range NodesOf(g) {
+		c := n.GetUnquoted(attr)
+		if c == "" {
+			continue
+		}
+		if _, ok := members[c]; !ok {
+			names = append(names, c)
+		}
+		members[c] = append(members[c], n)
+	}
+	sortNatural(names)
+	for _, set := range members {
+		sort.Slice(set, func(i, j int) bool { return dotIDOf(set[i]) < dotIDOf(set[j]) })
+	}
+	return names, members
+}
+
+// Conductance returns the conductance of the node set in g, the number of
+// edges leaving the set divided by the smaller of the volumes of the set
+// and its complement, where the volume of a set is the sum of the degrees
+// of its nodes. Lower values indicate a better separated set.
+func Conductance(g *Graph, set []*Node) float64 {
+	cut, vol, total := cutVolume(g, set)
+	return conductance(cut, vol, total)
+}
+
+// NormalizedCut returns the normalised cut of g between the node set and
+// its complement, the number of edges leaving the set divided by the volume
+// of the set plus the same divided by the volume of the complement. A
+// set or complement with zero volume does not contribute.
+func NormalizedCut(g *Graph, set []*Node) float64 {
+	cut, vol, total := cutVolume(g, set)
+	var ncut float64
+	if vol != 0 {
+		ncut += float64(cut) / float64(vol)
+	}
+	if total-vol != 0 {
+		ncut += float64(cut) / float64(total-vol)
+	}
+	return ncut
+}
+
+// ExpansionRatio returns the edge expansion of the node set in g, the
+// number of edges leaving the set divided by the number of nodes in the
+// smaller of the set and its complement.
+func ExpansionRatio(g *Graph, set []*Node) float64 {
+	cut, _, _ := cutVolume(g, set)
+	n := len(distinctIDs(set))
+	if m := g.Nodes().Len() - n; m < n {
+		n = m
+	}
+	if n == 0 {
+		return 0
+	}
+	return float64(cut) / float64(n)
+}
+
+// cutVolume returns the number of edges leaving the node set in g, the
+// volume of the set and the total volume of g.
+func cutVolume(g *Graph, set []*Node) (cut, vol, total int) {
+	in := distinctIDs(set)
+	for id := range in {
+		for _, v := range graph.NodesOf(g.From(id)) {
+			vol++
+			if !in[v.ID()] {
+				cut++
+			}
+		}
+	}
+	return cut, vol, 2 * g.Edges().Len()
+}
+
+// distinctIDs returns the set of IDs of the nodes in set.
+func distinctIDs(set []*Node) map[int64]bool {
+	in := make(map[int64]bool, len(set))
+	for _, n := range set {
+		in[n.ID()] = true
+	}
+	return in
+}
+
+// ConsensusCommunities performs repeated community modularisations of g at
+// the specified resolution and combines them into a consensus partition.
+// The modularisations use a random source seeded with seed so results are
+// reproducible.
+//
+// The consensus is found using the method of Lancichinetti and Fortunato,
+// with co-assignment only counted between adjacent nodes so that the cost
+// grows with the number of edges rather than the square of the number of
+// nodes: edges of g joining nodes assigned to the same community in more
+// than half of the runs are weighted by that fraction, and the resulting
+// graph is repeatedly modularised in the same way until all runs agree.
+// Nodes joined by a path of such edges form a consensus community.
+//
+// The consensus community identity is written into the "community_consensus"
+// attribute of each node, and the mean fraction of the original runs in
+// which the node shared a community with its neighbours in its consensus
+// community is written into the "stability" attribute. Nodes without
+// neighbours in their consensus community have a stability of 1.
+func ConsensusCommunities(g *Graph, runs int, resolution float64, seed int64) {
+	if runs < 1 {
+		panic("graphprac: invalid number of runs")
+	}
+	done, err := trace(g, "ConsensusCommunities", []string{"community_consensus", "stability"}, "runs", runs, "resolution", resolution, "seed", seed)
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
+	// maxConsensusIter bounds the number of rounds of
+	// reclustering of the consensus graph.
+	const maxConsensusIter = 20
+
+	x := g.Index()
+	n := x.Len()
+	var pairs [][2]int
+	for i, to := range x.adjacency(g) {
+		for _, j := range to {
+			if j > i {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+	}
+	src := rand.NewSource(uint64(seed))
+	orig := coassignment(g, x, pairs, runs, resolution, src)
+	together := orig
+	for iter := 0; iter < maxConsensusIter && !unanimous(together, runs); iter++ {
+		c := simple.NewWeightedUndirectedGraph(0, 0)
+		for i := 0; i < n; i++ {
+			c.AddNode(simple.Node(x.ID(i)))
+		}
+		for p, t := range together {
+			if 2*int(t) > runs {
+				i, j := pairs[p][0], pairs[p][1]
+				c.SetWeightedEdge(c.NewWeightedEdge(simple.Node(x.ID(i)), simple.Node(x.ID(j)), float64(t)/float64(runs)))
+			}
+		}
+		together = coassignment(c, x, pairs, runs, resolution, src)
+	}
+
+	uf := newUnionFind(n)
+	for p, t := range together {
+		if 2*int(t) > runs {
+			uf.union(pairs[p][0], pairs[p][1])
+		}
+	}
+
+	sum := make([]int, n)
+	count := make([]int, n)
+	for p, t := range orig {
+		i, j := pairs[p][0], pairs[p][1]
+		if uf.find(i) != uf.find(j) {
+			continue
+		}
+		sum[i] += int(t)
+		sum[j] += int(t)
+		count[i]++
+		count[j]++
+	}
+	label := make(map[int]int)
+	for i := 0; i < n; i++ {
+		r := uf.find(i)
+		if _, ok := label[r]; !ok {
+			label[r] = len(label)
+		}
+		stability := 1.0
+		if count[i] != 0 {
+			stability = float64(sum[i]) / float64(runs*count[i])
+		}
+		node := x.Node(i)
+		node.SetAttribute(encoding.Attribute{Key: "community_consensus", Value: fmt.Sprint(label[r])})
+		node.SetAttribute(encoding.Attribute{Key: "stability", Value: fmt.Sprint(stability)})
+	}
+}
+
+// coassignment returns the number of runs out of the given number of
+// modularisations of g in which the nodes of each pair in pairs were
+// assigned to the same community. Pairs hold indices in x.
+func coassignment(g graph.Undirected, x NodeIndex, pairs [][2]int, runs int, resolution float64, src rand.Source) []int32 {
+	together := make([]int32, len(pairs))
+	label := make([]int, x.Len())
+	for r := 0; r < runs; r++ {
+		for k, c := range community.Modularize(g, resolution, src).Communities() {
+			for _, v := range c {
+				label[x.Index(v.ID())] = k
+			}
+		}
+		for p, ij := range pairs {
+			if label[ij[0]] == label[ij[1]] {
+				together[p]++
+			}
+		}
+	}
+	return together
+}
+
+// unanimous returns whether every pair of nodes was assigned either to
+// the same community in all runs or in none.
+func unanimous(together []int32, runs int) bool {
+	for _, t := range together {
+		if t != 0 && int(t) != runs {
+			return false
+		}
+	}
+	return true
+}
+
+// unionFind is a disjoint set forest over the integers 0 to n-1.
+type unionFind struct {
+	parent []int
+	rank   []int
+}
+
+func newUnionFind(n int) *unionFind {
+	u := &unionFind{parent: make([]int, n), rank: make([]int, n)}
+	for i := range u.parent {
+		u.parent[i] = i
+	}
+	return u
+}
+
+func (u *unionFind) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+func (u *unionFind) union(i, j int) {
+	i, j = u.find(i), u.find(j)
+	switch {
+	case i == j:
+		return
+	case u.rank[i] < u.rank[j]:
+		i, j = j, i
+	case u.rank[i] == u.rank[j]:
+		u.rank[i]++
+	}
+	u.parent[j] = i
+}
+
+// SweepPoint holds the result of a community modularisation at a single
+// resolution. It is intended to be serialised with encoding/json.
+type SweepPoint struct {
+	Resolution  float64 `json:"resolution"`
+	Communities int     `json:"communities"`
+
+	// Modularity is the modularity of the partition
+	// evaluated at a resolution of 1, so that values
+	// are comparable between resolutions.
+	Modularity float64 `json:"modularity"`
+	// ScaledModularity is the modularity of the
+	// partition evaluated at the sweep resolution.
+	ScaledModularity float64 `json:"scaled_modularity"`
+}
+
+// ResolutionSweep performs a community modularisation of g at each of the
+// specified resolutions and returns the number of communities found and
+// their modularity. Edges are weighted by their "weight" attribute, or 1
+// if it is not set, as for CommunitiesWithOptions. The modularisations use
+// a random source seeded with seed at each resolution so results are
+// reproducible. ResolutionSweep returns an error if a weight is not a
+// non-negative number.
+//
+// The community identity value for each resolution is written into the
+// "community_<resolution>" attribute of each node, for example
+// "community_0.5".
+func ResolutionSweep(g *Graph, resolutions []float64, seed int64) ([]SweepPoint, error) {
+	w, err := weighted(g, "weight", false)
+	if err != nil {
+		return nil, err
+	}
+	nodes := g.NodeMap()
+	points := make([]SweepPoint, len(resolutions))
+	for i, res := range resolutions {
+		c := community.Modularize(w, res, rand.NewSource(uint64(seed))).Communities()
+		points[i] = SweepPoint{
+			Resolution:       res,
+			Communities:      len(c),
+			Modularity:       community.Q(w, c, 1),
+			ScaledModularity: community.Q(w, c, res),
+		}
+		key := "community_" + strconv.FormatFloat(res, 'g', -1, 64)
+		for j, members := range c {
+			for _, n := range members {
+				nodes[n.ID()].SetAttribute(encoding.Attribute{Key: key, Value: fmt.Sprint(j)})
+			}
+		}
+		setProvenance(g, []string{key}, "ResolutionSweep", "resolution", res, "seed", seed)
+	}
+	return points, nil
+}
@@ -0,0 +1,228 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// twoTriangles is a pair of triangles joined by a bridge
+// between c and d.
+const twoTriangles = `graph {
+	a -- b -- c -- a;
+	d -- e -- f -- d;
+	c -- d;
+}`
+
+func TestCommunityStats(t *testing.T) {
+	g := graphFromDOT(t, `graph {
+	a [c=1]; b [c=1]; c [c=1]; d [c=2]; e [c=2]; f [c=2]; g;
+	a -- b -- c -- a;
+	d -- e -- f -- d;
+	c -- d;
+}`)
+	got := CommunityStats(g, "c")
+	want := []CommunitySummary{
+		{
+			Community:     "1",
+			Size:          3,
+			InternalEdges: 3,
+			Density:       1,
+			Conductance:   1.0 / 7,
+			AverageDegree: 7.0 / 3,
+			Central:       []string{"c", "a", "b"},
+		},
+		{
+			Community:     "2",
+			Size:          3,
+			InternalEdges: 3,
+			Density:       1,
+			Conductance:   1.0 / 7,
+			AverageDegree: 7.0 / 3,
+			Central:       []string{"d", "e", "f"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected community stats:\ngot: %+v\nwant:%+v", got, want)
+	}
+}
+
+var cutTests = []struct {
+	set         []string
+	conductance float64
+	ncut        float64
+	expansion   float64
+}{
+	{set: []string{"a", "b", "c"}, conductance: 1.0 / 7, ncut: 2.0 / 7, expansion: 1.0 / 3},
+	{set: []string{"a"}, conductance: 1, ncut: 1 + 2.0/12, expansion: 2},
+	{set: []string{"c", "d"}, conductance: 4.0 / 6, ncut: 4.0/6 + 4.0/8, expansion: 2},
+	{set: []string{"a", "a"}, conductance: 1, ncut: 1 + 2.0/12, expansion: 2},
+	{set: []string{"a", "b", "c", "d", "e", "f"}, conductance: 0, ncut: 0, expansion: 0},
+}
+
+func TestCutMeasures(t *testing.T) {
+	g := graphFromDOT(t, twoTriangles)
+	for i, test := range cutTests {
+		set := make([]*Node, len(test.set))
+		for j, name := range test.set {
+			set[j] = g.NodeNamed(name)
+		}
+		for _, m := range []struct {
+			name string
+			fn   func(*Graph, []*Node) float64
+			want float64
+		}{
+			{name: "conductance", fn: Conductance, want: test.conductance},
+			{name: "normalized cut", fn: NormalizedCut, want: test.ncut},
+			{name: "expansion ratio", fn: ExpansionRatio, want: test.expansion},
+		} {
+			if got := m.fn(g, set); math.Abs(got-m.want) > 1e-12 {
+				t.Errorf("unexpected %s for test %d: got:%v want:%v", m.name, i, got, m.want)
+			}
+		}
+	}
+}
+
+var localCommunityTests = []struct {
+	seed   string
+	method LocalMethod
+	want   []string
+}{
+	{seed: "a", method: PPRSweep, want: []string{"a", "b", "c"}},
+	{seed: "f", method: PPRSweep, want: []string{"d", "e", "f"}},
+	{seed: "a", method: GreedyConductance, want: []string{"a", "b", "c"}},
+	{seed: "e", method: GreedyConductance, want: []string{"d", "e", "f"}},
+	{seed: "x", method: PPRSweep, want: nil},
+}
+
+func TestLocalCommunity(t *testing.T) {
+	g := graphFromDOT(t, twoTriangles)
+	for i, test := range localCommunityTests {
+		var got []string
+		for _, n := range LocalCommunity(g, test.seed, test.method) {
+			got = append(got, n.Name)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected community for test %d: got:%v want:%v", i, got, test.want)
+		}
+	}
+}
+
+// ringOfCliques returns the DOT for a ring of k cliques of the given size
+// with adjacent cliques joined by a single edge. The nodes of clique i are
+// named "c<i>_0" to "c<i>_<size-1>".
+func ringOfCliques(k, size int) string {
+	var b strings.Builder
+	b.WriteString("graph {\n")
+	for c := 0; c < k; c++ {
+		for i := 0; i < size; i++ {
+			for j := i + 1; j < size; j++ {
+				fmt.Fprintf(&b, "\tc%d_%d -- c%d_%d;\n", c, i, c, j)
+			}
+		}
+		fmt.Fprintf(&b, "\tc%d_0 -- c%d_1;\n", c, (c+1)%k)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// cliqueGroups returns the node names of each clique of the graph
+// returned by ringOfCliques.
+func cliqueGroups(k, size int) [][]string {
+	groups := make([][]string, k)
+	for c := range groups {
+		for i := 0; i < size; i++ {
+			groups[c] = append(groups[c], fmt.Sprintf("c%d_%d", c, i))
+		}
+	}
+	return groups
+}
+
+// checkPartition checks that the nodes in each of groups share a value
+// of the attr attribute that is distinct from the other groups.
+func checkPartition(t *testing.T, g *Graph, attr string, groups [][]string) {
+	t.Helper()
+	seen := make(map[string]bool)
+	for _, group := range groups {
+		label := g.NodeNamed(group[0]).Get(attr)
+		if label == "" {
+			t.Errorf("missing %s for %s", attr, group[0])
+			continue
+		}
+		if seen[label] {
+			t.Errorf("%s %s shared between groups", attr, label)
+		}
+		seen[label] = true
+		for _, name := range group[1:] {
+			if got := g.NodeNamed(name).Get(attr); got != label {
+				t.Errorf("unexpected %s for %s: got:%s want:%s", attr, name, got, label)
+			}
+		}
+	}
+}
+
+func TestConsensusCommunities(t *testing.T) {
+	g := graphFromDOT(t, ringOfCliques(4, 5))
+	ConsensusCommunities(g, 10, 1, 1)
+	checkPartition(t, g, "community_consensus", cliqueGroups(4, 5))
+	for _, n := range NodesOf(g) {
+		if got := floatAttr(t, g, n.Name, "stability"); got != 1 {
+			t.Errorf("unexpected stability for %s: got:%v want:1", n.Name, got)
+		}
+	}
+}
+
+func TestResolutionSweep(t *testing.T) {
+	g := graphFromDOT(t, ringOfCliques(4, 5))
+	resolutions := []float64{0.01, 1, 100}
+	got, err := ResolutionSweep(g, resolutions, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != len(resolutions) {
+		t.Fatalf("unexpected number of sweep points: got:%d want:%d", len(got), len(resolutions))
+	}
+	for i, p := range got {
+		if p.Resolution != resolutions[i] {
+			t.Errorf("unexpected resolution for point %d: got:%v want:%v", i, p.Resolution, resolutions[i])
+		}
+		if i > 0 && p.Communities < got[i-1].Communities {
+			t.Errorf("number of communities decreased with resolution at point %d: %d < %d", i, p.Communities, got[i-1].Communities)
+		}
+	}
+
+	// The optimal partition at a resolution of 1 is the
+	// cliques, with 10 internal edges in each of the 4
+	// cliques and 4 edges joining them.
+	if got[1].Communities != 4 {
+		t.Errorf("unexpected number of communities at resolution 1: got:%d want:4", got[1].Communities)
+	}
+	const q = 40.0/44 - 4*(22.0/88)*(22.0/88)
+	if math.Abs(got[1].Modularity-q) > 1e-12 || math.Abs(got[1].ScaledModularity-q) > 1e-12 {
+		t.Errorf("unexpected modularity at resolution 1: got:%v and %v want:%v", got[1].Modularity, got[1].ScaledModularity, q)
+	}
+	checkPartition(t, g, "community_1", cliqueGroups(4, 5))
+
+	again, err := ResolutionSweep(g, resolutions, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(again, got) {
+		t.Errorf("sweep not reproducible with the same seed:\ngot: %+v\nwant:%+v", again, got)
+	}
+
+	g.EdgeBetween(g.NodeNamed("c0_0").ID(), g.NodeNamed("c0_1").ID()).(*Edge).SetAttribute(encoding.Attribute{Key: "weight", Value: "-1"})
+	if _, err := ResolutionSweep(g, resolutions, 1); err == nil {
+		t.Error("expected error for negative weight")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Digraph is a general directed graph with node and edge attributes. The
+// edges of a Digraph are held from their tail to their head. A Digraph
+// can be obtained from DOT data with ReadMixedDOT and Mixed.Directed.
+type Digraph struct {
+	*simple.DirectedGraph
+	GraphAttrs, NodeAttrs, EdgeAttrs Attributes
+
+	// Name is the DOT ID of the graph and
+	// Strict is whether it is a strict graph.
+	Name   string
+	Strict bool
+
+	// analysisState holds the provenance
+	// of attributes written by analyses
+	// and the last refusal to overwrite
+	// an attribute.
+	analysisState
+}
+
+// NewNode adds a new node with a unique node ID to the graph.
+func (g *Digraph) NewNode() graph.Node {
+	return &Node{NodeID: g.DirectedGraph.NewNode().ID()}
+}
+
+// NewEdge adds a new edge from the source to the destination node to the graph,
+// or returns the existing edge if already present.
+func (g *Digraph) NewEdge(from, to graph.Node) graph.Edge {
+	if e := g.Edge(from.ID(), to.ID()); e != nil {
+		return e
+	}
+	e := &Edge{F: from.(*Node), T: to.(*Node)}
+	g.SetEdge(e)
+	return e
+}
+
+// SetDOTID sets the graph's DOT ID.
+func (g *Digraph) SetDOTID(id string) {
+	g.Name = id
+}
+
+// DOTAttributers returns the global DOT attributes for the graph.
+func (g *Digraph) DOTAttributers() (graph, node, edge encoding.Attributer) {
+	return g.GraphAttrs, g.NodeAttrs, g.EdgeAttrs
+}
+
+// NodeMap returns a mapping of ID integers to nodes in the graph.
+func (g *Digraph) NodeMap() map[int64]*Node {
+	nodes := make(map[int64]*Node)
+	for _, n := range graph.NodesOf(g.Nodes()) {
+		nodes[n.ID()] = n.(*Node)
+	}
+	return nodes
+}
+
+// NodeNamed returns the node in g with the given DOT ID, or nil if there
+// is no such node.
+func (g *Digraph) NodeNamed(name string) *Node {
+	for _, n := range graph.NodesOf(g.Nodes()) {
+		if n := n.(*Node); n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// graphAttributes returns the graph attributes of g.
+func (g *Digraph) graphAttributes() *Attributes {
+	return &g.GraphAttrs
+}
+
+// Index returns a dense node index for the nodes of g.
+func (g *Digraph) Index() NodeIndex {
+	return newNodeIndex(g)
+}
+
+// dotHeader returns the DOT ID of g and whether it is strict.
+func (g *Digraph) dotHeader() (name string, strict bool) {
+	return g.Name, g.Strict
+}
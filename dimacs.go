@@ -0,0 +1,139 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// ReadDIMACS reads a graph in the DIMACS edge format from r. The format
+// holds comment lines starting with "c", a problem line "p edge n m", and
+// a line "e u v" for each edge between the vertices numbered u and v,
+// counting from 1. Lines "n v w" giving vertex weights, as used in the
+// DIMACS clique and colouring benchmarks, are also accepted. The nodes of
+// the returned graph have DOT IDs and node IDs given by their vertex
+// number and vertex number less one, and vertex weights are written into
+// the "weight" attribute of the nodes. Self edges are discarded.
+func ReadDIMACS(r io.Reader) (*Graph, error) {
+	var g *Graph
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || text[0] == 'c' {
+			continue
+		}
+		f := strings.Fields(text)
+		switch f[0] {
+		case "p":
+			if g != nil {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("repeated problem line")}
+			}
+			if len(f) != 4 {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("invalid problem line")}
+			}
+			n, err := strconv.Atoi(f[2])
+			if err != nil || n < 0 {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("invalid number of vertices: %q", f[2])}
+			}
+			g = numberedGraph(n)
+		case "e", "n":
+			if g == nil {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("missing problem line")}
+			}
+			if len(f) != 3 {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("expected two fields after %q", f[0])}
+			}
+			u, err := numberedNode(g, f[1])
+			if err != nil {
+				return nil, &ParseError{Line: line, Text: text, Err: err}
+			}
+			if f[0] == "n" {
+				w, err := strconv.ParseFloat(f[2], 64)
+				if err != nil {
+					return nil, &ParseError{Line: line, Text: text, Err: err}
+				}
+				u.SetAttribute(encoding.Attribute{Key: "weight", Value: fmt.Sprint(w)})
+				continue
+			}
+			v, err := numberedNode(g, f[2])
+			if err != nil {
+				return nil, &ParseError{Line: line, Text: text, Err: err}
+			}
+			if u != v {
+				g.NewEdge(u, v)
+			}
+		default:
+			return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("unknown line type %q", f[0])}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, fmt.Errorf("missing problem line")
+	}
+	return g, nil
+}
+
+// WriteDIMACS writes g to w in the DIMACS edge format, with vertices
+// numbered from 1 in the order of g.Index(). Numerical "weight" attributes
+// of nodes are written as vertex weights. WriteDIMACS returns an error if a
+// node "weight" attribute is not a number.
+func WriteDIMACS(g *Graph, w io.Writer) error {
+	x := g.Index()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p edge %d %d\n", x.Len(), g.Edges().Len())
+	for i := 0; i < x.Len(); i++ {
+		n := x.Node(i)
+		s := exportAttributes(n.Attributes).GetUnquoted("weight")
+		if s == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid weight for node %q: %v", n.Name, err)
+		}
+		fmt.Fprintf(bw, "n %d %v\n", i+1, v)
+	}
+	for i, to := range x.adjacency(g) {
+		for _, j := range to {
+			if j > i {
+				fmt.Fprintf(bw, "e %d %d\n", i+1, j+1)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// numberedGraph returns a graph with n nodes with node IDs 0 to n-1 and
+// DOT IDs 1 to n.
+func numberedGraph(n int) *Graph {
+	g := &Graph{UndirectedGraph: simple.NewUndirectedGraph()}
+	for i := 0; i < n; i++ {
+		g.AddNode(&Node{NodeID: int64(i), Name: strconv.Itoa(i + 1)})
+	}
+	return g
+}
+
+// numberedNode returns the node of a graph returned by numberedGraph with
+// the vertex number in s.
+func numberedNode(g *Graph, s string) (*Node, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vertex %q", s)
+	}
+	n, ok := g.Node(int64(v - 1)).(*Node)
+	if !ok {
+		return nil, fmt.Errorf("vertex %d out of range [1,%d]", v, g.Nodes().Len())
+	}
+	return n, nil
+}
@@ -0,0 +1,87 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"strings"
+	"testing"
+)
+
+var dimacsTests = []struct {
+	src  string
+	want string
+}{
+	{
+		src:  "p edge 0 0\n",
+		want: "p edge 0 0\n",
+	},
+	{
+		src:  "c A path.\np edge 3 2\ne 1 2\ne 3 2\n",
+		want: "p edge 3 2\ne 1 2\ne 2 3\n",
+	},
+	{
+		src:  "p edge 4 4\nn 1 1.5\nn 4 2\ne 1 2\ne 1 3\ne 3 4\ne 4 4\n",
+		want: "p edge 4 3\nn 1 1.5\nn 4 2\ne 1 2\ne 1 3\ne 3 4\n",
+	},
+	{
+		src:  "p edge 3 2\n\ne 2 3\ne 3 2\n",
+		want: "p edge 3 1\ne 2 3\n",
+	},
+}
+
+func TestDIMACSRoundTrip(t *testing.T) {
+	for i, test := range dimacsTests {
+		g, err := ReadDIMACS(strings.NewReader(test.src))
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		var buf strings.Builder
+		if err := WriteDIMACS(g, &buf); err != nil {
+			t.Errorf("unexpected error writing test %d: %v", i, err)
+			continue
+		}
+		if got := buf.String(); got != test.want {
+			t.Errorf("unexpected DIMACS for test %d:\ngot:\n%s\nwant:\n%s", i, got, test.want)
+		}
+
+		// Written files must be read back unchanged.
+		r, err := ReadDIMACS(strings.NewReader(test.want))
+		if err != nil {
+			t.Errorf("unexpected error reading written test %d: %v", i, err)
+			continue
+		}
+		if got, want := DOT(r), DOT(g); got != want {
+			t.Errorf("unexpected round trip graph for test %d:\ngot:\n%s\nwant:\n%s", i, got, want)
+		}
+	}
+}
+
+var dimacsErrorTests = []string{
+	"",
+	"e 1 2\n",
+	"p edge 2 1\np edge 2 1\n",
+	"p edge two 1\n",
+	"p edge 2\n",
+	"p edge 2 1\ne 1 3\n",
+	"p edge 2 1\ne 1\n",
+	"p edge 2 1\nn 1 heavy\n",
+	"p edge 2 1\nx 1 2\n",
+}
+
+func TestReadDIMACSErrors(t *testing.T) {
+	for i, src := range dimacsErrorTests {
+		if _, err := ReadDIMACS(strings.NewReader(src)); err == nil {
+			t.Errorf("expected error for test %d", i)
+		}
+	}
+}
+
+func TestWriteDIMACSInvalidWeight(t *testing.T) {
+	g := graphFromDOT(t, `graph { a [weight=heavy]; a -- b }`)
+	if err := WriteDIMACS(g, &strings.Builder{}); err == nil {
+		t.Error("expected error for non-numeric weight")
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bytes"
+	"html"
+)
+
+// DisplayObject is a rendered graph for display in a Jupyter notebook.
+// It satisfies the SVGer and HTMLer conventions used by gophernotes,
+// and its HTML can be passed to gonbui.DisplayHTML when using gonb.
+type DisplayObject struct {
+	svg string
+	err error
+}
+
+// Display renders g as an SVG using GraphViz with the provided options
+// and returns it as a DisplayObject. If opts.Engine is empty, "dot" is
+// used. The Format field of opts is ignored.
+func Display(g *Graph, opts DrawOptions) DisplayObject {
+	if opts.Engine == "" {
+		opts.Engine = "dot"
+	}
+	opts.Format = "svg"
+	var buf bytes.Buffer
+	err := DrawTo(&buf, g, opts)
+	return DisplayObject{svg: buf.String(), err: err}
+}
+
+// Err returns any error that occurred while rendering the graph.
+func (d DisplayObject) Err() error { return d.err }
+
+// SVG returns the rendered SVG. If rendering failed, the SVG is empty.
+func (d DisplayObject) SVG() string { return d.svg }
+
+// HTML returns an HTML fragment holding the rendered SVG, or a description
+// of the rendering error.
+func (d DisplayObject) HTML() string {
+	if d.err != nil {
+		return `<pre class="error">` + html.EscapeString(d.err.Error()) + `</pre>`
+	}
+	return `<div>` + d.svg + `</div>`
+}
+
+// MIMEBundle returns the rendered graph as a Jupyter MIME bundle keyed by
+// MIME type.
+func (d DisplayObject) MIMEBundle() map[string]interface{} {
+	b := map[string]interface{}{"text/html": d.HTML()}
+	if d.err == nil {
+		b["image/svg+xml"] = d.svg
+	} else {
+		b["text/plain"] = d.err.Error()
+	}
+	return b
+}
@@ -0,0 +1,45 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var displayObjectTests = []struct {
+	obj      DisplayObject
+	wantHTML string
+	wantMIME map[string]interface{}
+}{
+	{
+		obj:      DisplayObject{svg: "<svg></svg>"},
+		wantHTML: "<div><svg></svg></div>",
+		wantMIME: map[string]interface{}{
+			"text/html":     "<div><svg></svg></div>",
+			"image/svg+xml": "<svg></svg>",
+		},
+	},
+	{
+		obj:      DisplayObject{err: errors.New(`exec: "dot" <missing>`)},
+		wantHTML: `<pre class="error">exec: &#34;dot&#34; &lt;missing&gt;</pre>`,
+		wantMIME: map[string]interface{}{
+			"text/html":  `<pre class="error">exec: &#34;dot&#34; &lt;missing&gt;</pre>`,
+			"text/plain": `exec: "dot" <missing>`,
+		},
+	},
+}
+
+func TestDisplayObject(t *testing.T) {
+	for i, test := range displayObjectTests {
+		if got := test.obj.HTML(); got != test.wantHTML {
+			t.Errorf("unexpected HTML for test %d: got:%s want:%s", i, got, test.wantHTML)
+		}
+		if got := test.obj.MIMEBundle(); !reflect.DeepEqual(got, test.wantMIME) {
+			t.Errorf("unexpected MIME bundle for test %d: got:%v want:%v", i, got, test.wantMIME)
+		}
+	}
+}
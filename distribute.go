@@ -0,0 +1,355 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// WorkerAddr is the base URL of a betweenness worker served by
+// BetweennessWorker, for example "http://node1:8080".
+type WorkerAddr string
+
+// protobufType is the content type of protocol buffer messages.
+const protobufType = "application/x-protobuf"
+
+// workerClient is the default client used to make requests to workers.
+// The timeout bounds the time taken by a worker that has stopped
+// responding, and allows for the analysis of large graphs.
+var workerClient = &http.Client{Timeout: 10 * time.Minute}
+
+// maxWorkerRequest is the maximum size of a request to a worker in bytes.
+var maxWorkerRequest int64 = 256 << 20
+
+// Field numbers of the betweenness messages in graph.proto.
+const (
+	requestGraph   protowire.Number = 1
+	requestSources protowire.Number = 2
+
+	responseIDs          protowire.Number = 1
+	responseDependencies protowire.Number = 2
+)
+
+// DistributeBetweenness performs a betweenness centrality analysis on g,
+// sharing the work between the provided workers. The source nodes of the
+// shortest paths are partitioned between the workers, each worker returns
+// the dependencies of every node on its sources, and the dependencies are
+// summed to give the betweenness. The structure of g and the IDs of the
+// sources are sent to workers as the protocol buffer messages described in
+// graph.proto.
+//
+// The betweenness centrality value is written into the "betweenness" attribute of each node.
+func DistributeBetweenness(g *Graph, workers []WorkerAddr) error {
+	return DistributeBetweennessWithOptions(g, workers, DistributeOptions{})
+}
+
+// DistributeOptions holds options for DistributeBetweennessWithOptions.
+type DistributeOptions struct {
+	// Client is the client used to make requests
+	// to the workers. If Client is nil, a client
+	// with a timeout of ten minutes is used.
+	Client *http.Client
+}
+
+// DistributeBetweennessWithOptions performs a distributed betweenness
+// centrality analysis on g as described for DistributeBetweenness using
+// the provided options.
+func DistributeBetweennessWithOptions(g *Graph, workers []WorkerAddr, opts DistributeOptions) (err error) {
+	client := opts.Client
+	if client == nil {
+		client = workerClient
+	}
+	if len(workers) == 0 {
+		return fmt.Errorf("no workers")
+	}
+	done, err := trace(g, "DistributeBetweenness", []string{"betweenness"}, "workers", len(workers))
+	if err != nil {
+		return err
+	}
+	defer func() { done(err) }()
+
+	nodes := NodesOf(g)
+	sources := make([][]int64, len(workers))
+	for i, n := range nodes {
+		w := i % len(workers)
+		sources[w] = append(sources[w], n.ID())
+	}
+	structure := toProto(g, false)
+
+	var (
+		mu   sync.Mutex
+		cb   = make(map[int64]float64, len(nodes))
+		errs []string
+		wg   sync.WaitGroup
+	)
+	for i, addr := range workers {
+		if len(sources[i]) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(addr WorkerAddr, sources []int64) {
+			defer wg.Done()
+			dep, err := requestDependencies(client, addr, structure, sources)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", addr, err))
+				return
+			}
+			for id, d := range dep {
+				cb[id] += d
+			}
+		}(addr, sources[i])
+	}
+	wg.Wait()
+	if errs != nil {
+		return fmt.Errorf("betweenness workers failed: %s", strings.Join(errs, "; "))
+	}
+
+	for _, n := range nodes {
+		n.SetAttribute(encoding.Attribute{Key: "betweenness", Value: fmt.Sprint(cb[n.ID()])})
+	}
+	return nil
+}
+
+// requestDependencies returns the dependencies of the nodes of the graph
+// encoded in structure on the given sources, calculated by the worker at
+// addr and requested with client.
+func requestDependencies(client *http.Client, addr WorkerAddr, structure []byte, sources []int64) (map[int64]float64, error) {
+	var req []byte
+	req = protowire.AppendTag(req, requestGraph, protowire.BytesType)
+	req = protowire.AppendBytes(req, structure)
+	var packed []byte
+	for _, id := range sources {
+		packed = protowire.AppendVarint(packed, uint64(id))
+	}
+	req = protowire.AppendTag(req, requestSources, protowire.BytesType)
+	req = protowire.AppendBytes(req, packed)
+
+	resp, err := client.Post(strings.TrimSuffix(string(addr), "/")+"/betweenness", protobufType, bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	var (
+		ids []int64
+		dep []float64
+	)
+	err = consumeFields(body, func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+		switch num {
+		case responseIDs:
+			return consumeInt64s(typ, v, &ids)
+		case responseDependencies:
+			return consumeFloat64s(typ, v, &dep)
+		}
+		return 0, errUnknownField
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) != len(dep) {
+		return nil, fmt.Errorf("mismatched response lengths: %d ids and %d dependencies", len(ids), len(dep))
+	}
+	m := make(map[int64]float64, len(ids))
+	for i, id := range ids {
+		m[id] = dep[i]
+	}
+	return m, nil
+}
+
+// BetweennessWorker returns an http.Handler that serves requests from
+// DistributeBetweenness at the path /betweenness. A worker process can be
+// run with
+//
+//	log.Fatal(http.ListenAndServe(":8080", graphprac.BetweennessWorker()))
+func BetweennessWorker() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/betweenness", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxWorkerRequest))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var (
+			g       *Graph
+			sources []int64
+		)
+		err = consumeFields(body, func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+			switch {
+			case num == requestGraph && typ == protowire.BytesType:
+				m, n := protowire.ConsumeBytes(v)
+				if n < 0 {
+					return n, nil
+				}
+				var err error
+				g, err = FromProto(m)
+				return n, err
+			case num == requestSources:
+				return consumeInt64s(typ, v, &sources)
+			}
+			return 0, errUnknownField
+		})
+		if err == nil && g == nil {
+			err = fmt.Errorf("missing graph")
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		x := g.Index()
+		idx := make([]int, len(sources))
+		for i, id := range sources {
+			idx[i] = x.Index(id)
+			if idx[i] < 0 {
+				http.Error(w, fmt.Sprintf("invalid request: no source node %d", id), http.StatusBadRequest)
+				return
+			}
+		}
+		dep := dependencies(x.adjacency(g), idx)
+
+		var ids, vals []byte
+		for i, d := range dep {
+			ids = protowire.AppendVarint(ids, uint64(x.ID(i)))
+			vals = protowire.AppendFixed64(vals, math.Float64bits(d))
+		}
+		var resp []byte
+		resp = protowire.AppendTag(resp, responseIDs, protowire.BytesType)
+		resp = protowire.AppendBytes(resp, ids)
+		resp = protowire.AppendTag(resp, responseDependencies, protowire.BytesType)
+		resp = protowire.AppendBytes(resp, vals)
+		w.Header().Set("Content-Type", protobufType)
+		w.Write(resp)
+	})
+	return mux
+}
+
+// dependencies returns the sum of the dependencies of each node in the
+// graph with adjacency lists adj on the given sources, using the
+// accumulation step of Brandes' algorithm. Summed over all sources, the
+// dependencies are the betweenness centralities of the nodes.
+func dependencies(adj [][]int, sources []int) []float64 {
+	n := len(adj)
+	var (
+		cb    = make([]float64, n)
+		sigma = make([]float64, n)
+		dist  = make([]int, n)
+		delta = make([]float64, n)
+		pred  = make([][]int, n)
+		stack = make([]int, 0, n)
+		queue = make([]int, 0, n)
+	)
+	for _, s := range sources {
+		for i := range dist {
+			sigma[i] = 0
+			dist[i] = -1
+			delta[i] = 0
+			pred[i] = pred[i][:0]
+		}
+		sigma[s] = 1
+		dist[s] = 0
+		stack = stack[:0]
+		queue = append(queue[:0], s)
+		for len(queue) != 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+			for _, w := range adj[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					pred[w] = append(pred[w], v)
+				}
+			}
+		}
+		for i := len(stack) - 1; i >= 0; i-- {
+			w := stack[i]
+			for _, v := range pred[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			if w != s {
+				cb[w] += delta[w]
+			}
+		}
+	}
+	return cb
+}
+
+// consumeInt64s appends the packed or unpacked int64 field values in b
+// to dst, returning the number of bytes consumed.
+func consumeInt64s(typ protowire.Type, b []byte, dst *[]int64) (int, error) {
+	switch typ {
+	case protowire.VarintType:
+		v, n := protowire.ConsumeVarint(b)
+		if n >= 0 {
+			*dst = append(*dst, int64(v))
+		}
+		return n, nil
+	case protowire.BytesType:
+		packed, n := protowire.ConsumeBytes(b)
+		for len(packed) != 0 {
+			v, m := protowire.ConsumeVarint(packed)
+			if m < 0 {
+				return m, nil
+			}
+			*dst = append(*dst, int64(v))
+			packed = packed[m:]
+		}
+		return n, nil
+	}
+	return 0, errUnknownField
+}
+
+// consumeFloat64s appends the packed or unpacked double field values in
+// b to dst, returning the number of bytes consumed.
+func consumeFloat64s(typ protowire.Type, b []byte, dst *[]float64) (int, error) {
+	switch typ {
+	case protowire.Fixed64Type:
+		v, n := protowire.ConsumeFixed64(b)
+		if n >= 0 {
+			*dst = append(*dst, math.Float64frombits(v))
+		}
+		return n, nil
+	case protowire.BytesType:
+		packed, n := protowire.ConsumeBytes(b)
+		for len(packed) != 0 {
+			v, m := protowire.ConsumeFixed64(packed)
+			if m < 0 {
+				return m, nil
+			}
+			*dst = append(*dst, math.Float64frombits(v))
+			packed = packed[m:]
+		}
+		return n, nil
+	}
+	return 0, errUnknownField
+}
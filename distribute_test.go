@@ -0,0 +1,84 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDistributeBetweenness(t *testing.T) {
+	const src = `graph { a -- b -- c -- d -- a; c -- e -- f; g -- h; i }`
+	want := graphFromDOT(t, src)
+	Betweenness(want)
+
+	for _, workers := range []int{1, 3, 12} {
+		var addrs []WorkerAddr
+		for i := 0; i < workers; i++ {
+			s := httptest.NewServer(BetweennessWorker())
+			defer s.Close()
+			addrs = append(addrs, WorkerAddr(s.URL))
+		}
+		g := graphFromDOT(t, src)
+		err := DistributeBetweenness(g, addrs)
+		if err != nil {
+			t.Errorf("unexpected error with %d workers: %v", workers, err)
+			continue
+		}
+		for _, n := range NodesOf(want) {
+			w := floatAttr(t, want, n.Name, "betweenness")
+			got := floatAttr(t, g, n.Name, "betweenness")
+			if math.Abs(got-w) > 1e-12 {
+				t.Errorf("unexpected betweenness for %s with %d workers: got:%v want:%v", n.Name, workers, got, w)
+			}
+		}
+	}
+
+	g := graphFromDOT(t, src)
+	if err := DistributeBetweenness(g, nil); err == nil {
+		t.Error("expected error with no workers")
+	}
+	s := httptest.NewServer(BetweennessWorker())
+	s.Close()
+	if err := DistributeBetweenness(g, []WorkerAddr{WorkerAddr(s.URL)}); err == nil {
+		t.Error("expected error with unreachable worker")
+	}
+
+	stalled := make(chan struct{})
+	s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stalled
+	}))
+	defer s.Close()
+	defer close(stalled)
+	opts := DistributeOptions{Client: &http.Client{Timeout: 100 * time.Millisecond}}
+	if err := DistributeBetweennessWithOptions(g, []WorkerAddr{WorkerAddr(s.URL)}, opts); err == nil {
+		t.Error("expected error with stalled worker")
+	}
+}
+
+func TestBetweennessWorkerRequestLimit(t *testing.T) {
+	defer func(n int64) { maxWorkerRequest = n }(maxWorkerRequest)
+	maxWorkerRequest = 16
+
+	s := httptest.NewServer(BetweennessWorker())
+	defer s.Close()
+	resp, err := http.Post(s.URL+"/betweenness", protobufType, bytes.NewReader(make([]byte, 17)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unexpected status for oversized request: got:%d want:%d", resp.StatusCode, http.StatusBadRequest)
+	}
+
+	g := graphFromDOT(t, `graph { a -- b }`)
+	if err := DistributeBetweenness(g, []WorkerAddr{WorkerAddr(s.URL)}); err == nil {
+		t.Error("expected error for request exceeding worker limit")
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/flow"
+	"gonum.org/v1/gonum/graph/simple"
+	"gonum.org/v1/gonum/mat"
+)
+
+// DominanceTree returns the dominator tree of g rooted at the node with the
+// DOT ID root. A node u dominates a node v when every path from root to v
+// passes through u, and the tree holds an edge from the immediate dominator
+// of each node reachable from root to the node. Nodes that cannot be
+// reached from root are not included. The returned graph is a *Digraph
+// sharing its nodes with g.
+func DominanceTree(g *Digraph, root string) (graph.Directed, error) {
+	r := g.NodeNamed(root)
+	if r == nil {
+		return nil, fmt.Errorf("no node %q", root)
+	}
+	dom := flow.Dominators(r, g)
+	t := &Digraph{DirectedGraph: simple.NewDirectedGraph(), Name: g.Name}
+	t.AddNode(r)
+	for _, n := range graph.NodesOf(g.Nodes()) {
+		if n.ID() == r.ID() {
+			continue
+		}
+		d := dom.DominatorOf(n.ID())
+		if d == nil {
+			continue
+		}
+		if t.Node(d.ID()) == nil {
+			t.AddNode(d)
+		}
+		if t.Node(n.ID()) == nil {
+			t.AddNode(n)
+		}
+		t.SetEdge(&Edge{F: d.(*Node), T: n.(*Node)})
+	}
+	return t, nil
+}
+
+// CopelandRank performs a Copeland ranking of the nodes of g from the
+// pairwise interactions held in its edges, where an edge u→v records that
+// u dominated v in the number of interactions given by the "weight"
+// attribute of the edge, or 1 if it is not set. The score of a node is
+// the number of nodes it dominated more often than it was dominated by,
+// less the number of nodes that dominated it more often, with ties
+// scoring zero.
+//
+// The score is written into the "copeland" attribute of each node.
+func CopelandRank(g *Digraph) (err error) {
+	done, err := trace(g, "CopelandRank", []string{"copeland"})
+	if err != nil {
+		return err
+	}
+	defer func() { done(err) }()
+
+	x, wins, err := dominanceMatrix(g)
+	if err != nil {
+		return err
+	}
+	n := x.Len()
+	score := make([]int, n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			switch wij, wji := wins.At(i, j), wins.At(j, i); {
+			case wij > wji:
+				score[i]++
+				score[j]--
+			case wij < wji:
+				score[i]--
+				score[j]++
+			}
+		}
+	}
+	for i, s := range score {
+		x.Node(i).SetAttribute(encoding.Attribute{Key: "copeland", Value: fmt.Sprint(s)})
+	}
+	return nil
+}
+
+// ColleyRank performs a Colley ranking (Colley 2002) of the nodes of g
+// from the pairwise interactions held in its edges, with interactions
+// counted as for CopelandRank. The ratings r solve Cr = b, where C has
+// diagonal elements 2 + t_i, the number of interactions of node i plus
+// two, and off-diagonal elements -t_ij, the negated number of
+// interactions between nodes i and j, and b_i = 1 + (w_i - l_i)/2 for the
+// w_i interactions won and l_i lost by node i. Ratings are centred on
+// one half, and nodes without interactions are rated one half.
+//
+// The rating is written into the "colley" attribute of each node.
+func ColleyRank(g *Digraph) (err error) {
+	done, err := trace(g, "ColleyRank", []string{"colley"})
+	if err != nil {
+		return err
+	}
+	defer func() { done(err) }()
+
+	x, wins, err := dominanceMatrix(g)
+	if err != nil {
+		return err
+	}
+	n := x.Len()
+	if n == 0 {
+		return nil
+	}
+	c := mat.NewSymDense(n, nil)
+	b := mat.NewVecDense(n, nil)
+	for i := 0; i < n; i++ {
+		c.SetSym(i, i, 2)
+		b.SetVec(i, 1)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			wij, wji := wins.At(i, j), wins.At(j, i)
+			t := wij + wji
+			if t == 0 {
+				continue
+			}
+			c.SetSym(i, i, c.At(i, i)+t)
+			c.SetSym(j, j, c.At(j, j)+t)
+			c.SetSym(i, j, -t)
+			b.SetVec(i, b.AtVec(i)+(wij-wji)/2)
+			b.SetVec(j, b.AtVec(j)+(wji-wij)/2)
+		}
+	}
+	var chol mat.Cholesky
+	if !chol.Factorize(c) {
+		return fmt.Errorf("failed to factorize colley matrix")
+	}
+	var r mat.VecDense
+	err = chol.SolveVecTo(&r, b)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		x.Node(i).SetAttribute(encoding.Attribute{Key: "colley", Value: fmt.Sprint(r.AtVec(i))})
+	}
+	return nil
+}
+
+// dominanceMatrix returns the node index of g and a matrix holding the
+// number of times each node dominated each other node, with rows and
+// columns in index order.
+func dominanceMatrix(g *Digraph) (NodeIndex, *mat.Dense, error) {
+	x := g.Index()
+	n := x.Len()
+	if n == 0 {
+		return x, nil, nil
+	}
+	wins := mat.NewDense(n, n, nil)
+	for i, to := range x.adjacency(g) {
+		for _, j := range to {
+			e := g.Edge(x.ID(i), x.ID(j)).(*Edge)
+			w, err := edgeWeight(e, "weight")
+			if err != nil {
+				return x, nil, err
+			}
+			if w < 0 {
+				return x, nil, fmt.Errorf("negative weight for edge %q->%q: %v", e.F.Name, e.T.Name, w)
+			}
+			wins.Set(i, j, w)
+		}
+	}
+	return x, wins, nil
+}
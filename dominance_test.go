@@ -0,0 +1,118 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+func TestDominanceTree(t *testing.T) {
+	g := digraphFromDOT(t, `digraph {
+	r -> a; r -> b;
+	a -> c; b -> c;
+	c -> d; d -> a;
+	x -> r;
+}`)
+	dt, err := DominanceTree(g, "r")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tree, ok := dt.(*Digraph)
+	if !ok {
+		t.Fatalf("unexpected dominator tree type: %T", dt)
+	}
+	var got []string
+	for _, e := range graph.EdgesOf(tree.Edges()) {
+		got = append(got, e.From().(*Node).Name+"->"+e.To().(*Node).Name)
+	}
+	sort.Strings(got)
+	want := []string{"c->d", "r->a", "r->b", "r->c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected dominator tree: got:%q want:%q", got, want)
+	}
+	if n := tree.Nodes().Len(); n != 5 {
+		t.Errorf("unexpected number of nodes: got:%d want:5", n)
+	}
+	if tree.Node(g.NodeNamed("a").ID()) != g.NodeNamed("a") {
+		t.Error("expected tree to share nodes with graph")
+	}
+
+	if _, err := DominanceTree(g, "missing"); err == nil {
+		t.Error("expected error for missing root")
+	}
+}
+
+var rankTests = []struct {
+	dot      string
+	copeland map[string]int
+	colley   map[string]float64
+}{
+	{
+		dot:      `digraph { a -> b }`,
+		copeland: map[string]int{"a": 1, "b": -1},
+		colley:   map[string]float64{"a": 5.0 / 8, "b": 3.0 / 8},
+	},
+	{
+		dot:      `digraph { a -> b; b -> c; a -> c; d }`,
+		copeland: map[string]int{"a": 2, "b": 0, "c": -2, "d": 0},
+		colley:   map[string]float64{"a": 0.7, "b": 0.5, "c": 0.3, "d": 0.5},
+	},
+	{
+		dot:      `digraph { a -> b [weight=2]; b -> a }`,
+		copeland: map[string]int{"a": 1, "b": -1},
+		colley:   map[string]float64{"a": 9.0 / 16, "b": 7.0 / 16},
+	},
+	{
+		dot:      `digraph { a -> b -> c -> a }`,
+		copeland: map[string]int{"a": 0, "b": 0, "c": 0},
+		colley:   map[string]float64{"a": 0.5, "b": 0.5, "c": 0.5},
+	},
+}
+
+func TestDominanceRanks(t *testing.T) {
+	for i, test := range rankTests {
+		g := digraphFromDOT(t, test.dot)
+		if err := CopelandRank(g); err != nil {
+			t.Errorf("unexpected Copeland error for test %d: %v", i, err)
+			continue
+		}
+		if err := ColleyRank(g); err != nil {
+			t.Errorf("unexpected Colley error for test %d: %v", i, err)
+			continue
+		}
+		for _, n := range g.NodeMap() {
+			if got, want := n.Get("copeland"), strconv.Itoa(test.copeland[n.Name]); got != want {
+				t.Errorf("unexpected Copeland score of %s for test %d: got:%s want:%s", n.Name, i, got, want)
+			}
+			got, err := strconv.ParseFloat(n.Get("colley"), 64)
+			if err != nil {
+				t.Errorf("failed to parse Colley rating of %s for test %d: %v", n.Name, i, err)
+				continue
+			}
+			if want := test.colley[n.Name]; math.Abs(got-want) > 1e-12 {
+				t.Errorf("unexpected Colley rating of %s for test %d: got:%v want:%v", n.Name, i, got, want)
+			}
+		}
+	}
+
+	for _, dot := range []string{
+		`digraph { a -> b [weight=-1] }`,
+		`digraph { a -> b [weight=heavy] }`,
+	} {
+		g := digraphFromDOT(t, dot)
+		if err := CopelandRank(g); err == nil {
+			t.Errorf("expected Copeland error for %s", dot)
+		}
+		if err := ColleyRank(g); err == nil {
+			t.Errorf("expected Colley error for %s", dot)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/encoding/dot"
+	"gonum.org/v1/gonum/graph/iterator"
+)
+
+// DOTOptions controls how graphs are rendered as DOT.
+type DOTOptions struct {
+	// Indent is the indentation used for each level
+	// of nesting. The default is two spaces.
+	Indent string
+
+	// SortAttributes specifies that attributes are
+	// written in key order rather than the order in
+	// which they were set.
+	SortAttributes bool
+
+	// OmitComputed specifies that node and edge
+	// attributes written by the analysis functions
+	// of this package are not included.
+	OmitComputed bool
+
+	// Include, if not nil, lists the only node and
+	// edge attribute keys that are written.
+	Include []string
+
+	// Exclude lists node and edge attribute keys
+	// that are not written.
+	Exclude []string
+}
+
+// keep returns whether the node or edge attribute key should be written.
+func (o DOTOptions) keep(key string) bool {
+	if o.OmitComputed && isComputed(key) {
+		return false
+	}
+	if o.Include != nil && !contains(o.Include, key) {
+		return false
+	}
+	return !contains(o.Exclude, key)
+}
+
+func contains(s []string, e string) bool {
+	for _, v := range s {
+		if v == e {
+			return true
+		}
+	}
+	return false
+}
+
+// computedAttrs are the attributes written by the analysis
+// functions.
+var computedAttrs = map[string]bool{
+	"rank":                true,
+	"closeness":           true,
+	"closeness_approx":    true,
+	"farness":             true,
+	"harmonic":            true,
+	"eccentricity":        true,
+	"betweenness":         true,
+	"edge_betweenness":    true,
+	"community":           true,
+	"community_consensus": true,
+	"stability":           true,
+	"signed_community":    true,
+	"role":                true,
+	"participation":       true,
+	"multiplex_rank":      true,
+	"versatility":         true,
+	"clique":              true,
+	"clique_count":        true,
+	"link_community":      true,
+	"membership_count":    true,
+	"triangles":           true,
+	"clustering":          true,
+	"communicability":     true,
+	"strength":            true,
+	"weighted_clustering": true,
+	"degree":              true,
+	"component":           true,
+	"ffl":                 true,
+	"feedback":            true,
+	"trophic":             true,
+	"agony":               true,
+	"copeland":            true,
+	"colley":              true,
+}
+
+// isComputed returns whether the attribute key is written by an
+// analysis function.
+func isComputed(key string) bool {
+	return computedAttrs[key] || strings.HasSuffix(key, "_bin") || strings.HasPrefix(key, "community_") || strings.HasPrefix(key, "role_")
+}
+
+// DOTWithOptions renders the graph as a DOT language representation
+// using the provided options. Nodes are written in order of their DOT ID
+// and edges in order of their end points' DOT IDs, so the output for a
+// graph is stable between runs. The name of a *Graph or *Digraph is
+// written as the DOT ID of the graph, and the graph is written as strict
+// if its Strict field is set. Other graphs are written as strict and
+// unnamed. Directed graphs are written as digraphs.
+func DOTWithOptions(g graph.Graph, opts DOTOptions) string {
+	if opts.Indent == "" {
+		opts.Indent = "  "
+	}
+	d := newDOTGraph(g, opts)
+	var dg graph.Graph = d
+	if d.to != nil {
+		dg = dotDigraph{d}
+	}
+	b, _ := dot.Marshal(dg, d.name, "", opts.Indent)
+	if !d.strict {
+		// The gonum DOT encoder writes all simple
+		// graphs as strict.
+		b = bytes.TrimPrefix(b, []byte("strict "))
+	}
+	return string(b)
+}
+
+// dotHeader returns the DOT ID of g and whether it is strict.
+func (g *Graph) dotHeader() (name string, strict bool) {
+	return g.Name, g.Strict
+}
+
+// dotGraph is a copy of a graph prepared for DOT marshaling. Node IDs
+// are assigned in DOT ID order so that the ID ordering used by the
+// marshaler results in stable output.
+type dotGraph struct {
+	nodes []*dotNode
+	edges map[[2]int64]*dotEdge
+	adj   [][]graph.Node
+
+	// to holds the predecessors of each
+	// node if the graph is directed.
+	to [][]graph.Node
+
+	clusters []dot.Graph
+
+	name   string
+	strict bool
+
+	graphAttrs, nodeAttrs, edgeAttrs dotAttributes
+}
+
+func newDOTGraph(g graph.Graph, opts DOTOptions) *dotGraph {
+	src := graph.NodesOf(g.Nodes())
+	nodes := make([]*dotNode, len(src))
+	for i, n := range src {
+		nodes[i] = &dotNode{
+			dotID: dotIDOf(n),
+			orig:  n.ID(),
+			attrs: filterAttributes(attributesOf(n), opts),
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].dotID != nodes[j].dotID {
+			return nodes[i].dotID < nodes[j].dotID
+		}
+		return nodes[i].orig < nodes[j].orig
+	})
+	index := make(map[int64]int64, len(nodes))
+	for i, n := range nodes {
+		n.id = int64(i)
+		index[n.orig] = n.id
+	}
+
+	d := &dotGraph{
+		nodes:  nodes,
+		edges:  make(map[[2]int64]*dotEdge),
+		adj:    make([][]graph.Node, len(nodes)),
+		strict: true,
+	}
+	if h, ok := g.(interface{ dotHeader() (string, bool) }); ok {
+		d.name, d.strict = h.dotHeader()
+	}
+	if _, ok := g.(graph.Directed); ok {
+		d.to = make([][]graph.Node, len(nodes))
+	}
+	for _, u := range nodes {
+		for _, v := range graph.NodesOf(g.From(u.orig)) {
+			vid := index[v.ID()]
+			d.adj[u.id] = append(d.adj[u.id], nodes[vid])
+			if d.to != nil {
+				d.to[vid] = append(d.to[vid], u)
+			}
+			d.edges[[2]int64{u.id, vid}] = &dotEdge{
+				from:  u,
+				to:    nodes[vid],
+				attrs: filterAttributes(attributesOf(g.Edge(u.orig, v.ID())), opts),
+			}
+		}
+	}
+	if a, ok := g.(dot.Attributers); ok {
+		ga, na, ea := a.DOTAttributers()
+		global := DOTOptions{SortAttributes: opts.SortAttributes}
+		d.graphAttrs = filterAttributes(ga.Attributes(), global)
+		d.nodeAttrs = filterAttributes(na.Attributes(), global)
+		d.edgeAttrs = filterAttributes(ea.Attributes(), global)
+	}
+	if g, ok := g.(*Graph); ok && len(g.clusters) != 0 {
+		byName := make(map[string]*dotNode, len(nodes))
+		for _, n := range nodes {
+			byName[n.dotID] = n
+		}
+		d.clusters = newDOTClusters(g.clusters, byName, DOTOptions{SortAttributes: opts.SortAttributes})
+	}
+	return d
+}
+
+func (g *dotGraph) Node(id int64) graph.Node {
+	if id < 0 || id >= int64(len(g.nodes)) {
+		return nil
+	}
+	return g.nodes[id]
+}
+
+func (g *dotGraph) Nodes() graph.Nodes {
+	nodes := make([]graph.Node, len(g.nodes))
+	for i, n := range g.nodes {
+		nodes[i] = n
+	}
+	return iterator.NewOrderedNodes(nodes)
+}
+
+func (g *dotGraph) From(id int64) graph.Nodes {
+	if g.Node(id) == nil {
+		return graph.Empty
+	}
+	return iterator.NewOrderedNodes(g.adj[id])
+}
+
+func (g *dotGraph) HasEdgeBetween(xid, yid int64) bool {
+	_, ok := g.edges[[2]int64{xid, yid}]
+	return ok
+}
+
+func (g *dotGraph) Edge(uid, vid int64) graph.Edge {
+	e, ok := g.edges[[2]int64{uid, vid}]
+	if !ok {
+		return nil
+	}
+	return e
+}
+
+// dotDigraph is a dotGraph of a directed graph.
+type dotDigraph struct {
+	*dotGraph
+}
+
+func (g dotDigraph) To(id int64) graph.Nodes {
+	if g.Node(id) == nil {
+		return graph.Empty
+	}
+	return iterator.NewOrderedNodes(g.to[id])
+}
+
+func (g dotDigraph) HasEdgeFromTo(uid, vid int64) bool {
+	return g.HasEdgeBetween(uid, vid)
+}
+
+func (g *dotGraph) Structure() []dot.Graph {
+	return g.clusters
+}
+
+func (g *dotGraph) DOTAttributers() (graph, node, edge encoding.Attributer) {
+	return g.graphAttrs, g.nodeAttrs, g.edgeAttrs
+}
+
+type dotNode struct {
+	id    int64
+	orig  int64
+	dotID string
+	attrs dotAttributes
+}
+
+func (n *dotNode) ID() int64                        { return n.id }
+func (n *dotNode) DOTID() string                    { return n.dotID }
+func (n *dotNode) Attributes() []encoding.Attribute { return n.attrs }
+
+type dotEdge struct {
+	from, to *dotNode
+	attrs    dotAttributes
+}
+
+func (e *dotEdge) From() graph.Node                 { return e.from }
+func (e *dotEdge) To() graph.Node                   { return e.to }
+func (e *dotEdge) ReversedEdge() graph.Edge         { return &dotEdge{from: e.to, to: e.from, attrs: e.attrs} }
+func (e *dotEdge) Attributes() []encoding.Attribute { return e.attrs }
+
+type dotAttributes []encoding.Attribute
+
+func (a dotAttributes) Attributes() []encoding.Attribute { return a }
+
+// dotIDOf returns the DOT ID of n, or its ID if it has no DOT ID.
+func dotIDOf(n graph.Node) string {
+	if n, ok := n.(dot.Node); ok {
+		return n.DOTID()
+	}
+	return fmt.Sprint(n.ID())
+}
+
+// attributesOf returns the attributes held by v.
+func attributesOf(v interface{}) []encoding.Attribute {
+	switch v := v.(type) {
+	case *Node:
+		return v.Attributes
+	case *Edge:
+		return v.Attributes
+	case encoding.Attributer:
+		return v.Attributes()
+	default:
+		return nil
+	}
+}
+
+// filterAttributes returns a copy of attrs filtered and ordered
+// according to opts, with the export policy applied.
+func filterAttributes(attrs []encoding.Attribute, opts DOTOptions) dotAttributes {
+	var a dotAttributes
+	for _, kv := range exportAttributes(attrs) {
+		if !opts.keep(kv.Key) {
+			continue
+		}
+		a = append(a, kv)
+	}
+	if opts.SortAttributes {
+		sort.SliceStable(a, func(i, j int) bool { return a[i].Key < a[j].Key })
+	}
+	return a
+}
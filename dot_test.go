@@ -0,0 +1,171 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"strings"
+	"testing"
+)
+
+var dotOrderTests = []struct {
+	dot  string
+	opts DOTOptions
+	want string
+}{
+	{
+		dot: `graph G { c [z=1, a=2]; b; c -- a [w=1]; b -- a }`,
+		want: `graph G {
+  // Node definitions.
+  a;
+  b;
+  c [
+    z=1
+    a=2
+  ];
+
+  // Edge definitions.
+  a -- b;
+  a -- c [w=1];
+}`,
+	},
+	{
+		dot:  `graph G { c [z=1, a=2]; b; c -- a [w=1]; b -- a }`,
+		opts: DOTOptions{Indent: "\t", SortAttributes: true},
+		want: `graph G {
+	// Node definitions.
+	a;
+	b;
+	c [
+		a=2
+		z=1
+	];
+
+	// Edge definitions.
+	a -- b;
+	a -- c [w=1];
+}`,
+	},
+	{
+		dot: `strict graph { b -- a }`,
+		want: `strict graph {
+  // Node definitions.
+  a;
+  b;
+
+  // Edge definitions.
+  a -- b;
+}`,
+	},
+}
+
+func TestDOTOrder(t *testing.T) {
+	for i, test := range dotOrderTests {
+		g := graphFromDOT(t, test.dot)
+		got := DOTWithOptions(g, test.opts)
+		if got != test.want {
+			t.Errorf("unexpected DOT for test %d:\ngot:\n%s\nwant:\n%s", i, got, test.want)
+		}
+		// Reading the output back must
+		// give the same output.
+		got = DOTWithOptions(graphFromDOT(t, got), test.opts)
+		if got != test.want {
+			t.Errorf("unexpected round trip DOT for test %d:\ngot:\n%s\nwant:\n%s", i, got, test.want)
+		}
+	}
+}
+
+var dotEmitTests = []struct {
+	opts DOTOptions
+	want string
+}{
+	{
+		opts: DOTOptions{OmitComputed: true},
+		want: `graph {
+  // Node definitions.
+  a [color=red];
+  b;
+
+  // Edge definitions.
+  a -- b [weight=2];
+}`,
+	},
+	{
+		opts: DOTOptions{Include: []string{"rank", "weight"}},
+		want: `graph {
+  // Node definitions.
+  a [rank=0.5];
+  b [rank=0.5];
+
+  // Edge definitions.
+  a -- b [weight=2];
+}`,
+	},
+	{
+		opts: DOTOptions{Exclude: []string{"color", "weight", "a_bin"}},
+		want: `graph {
+  // Node definitions.
+  a [rank=0.5];
+  b [rank=0.5];
+
+  // Edge definitions.
+  a -- b [edge_betweenness=1];
+}`,
+	},
+	{
+		opts: DOTOptions{Include: []string{}},
+		want: `graph {
+  // Node definitions.
+  a;
+  b;
+
+  // Edge definitions.
+  a -- b;
+}`,
+	},
+}
+
+func TestDOTEmission(t *testing.T) {
+	for i, test := range dotEmitTests {
+		g := graphFromDOT(t, `graph { a [color=red, rank=0.5]; b [rank=0.5, a_bin=1]; a -- b [weight=2, edge_betweenness=1] }`)
+		got := DOTWithOptions(g, test.opts)
+		if got != test.want {
+			t.Errorf("unexpected DOT for test %d:\ngot:\n%s\nwant:\n%s", i, got, test.want)
+		}
+	}
+}
+
+var dotHeaderTests = []struct {
+	dot    string
+	name   string
+	strict bool
+	header string
+}{
+	{dot: `graph { a }`, header: "graph {"},
+	{dot: `graph G { a }`, name: "G", header: "graph G {"},
+	{dot: `strict graph { a }`, strict: true, header: "strict graph {"},
+	{dot: `STRICT graph "my graph" { a }`, name: "my graph", strict: true, header: `strict graph "my graph" {`},
+	{dot: "// strict\ngraph strictly { a }", name: "strictly", header: "graph strictly {"},
+	{dot: "/* c */ strict\ngraph G { a }", name: "G", strict: true, header: "strict graph G {"},
+}
+
+func TestDOTHeader(t *testing.T) {
+	for i, test := range dotHeaderTests {
+		g := graphFromDOT(t, test.dot)
+		if g.Name != test.name {
+			t.Errorf("unexpected name for test %d: got:%q want:%q", i, g.Name, test.name)
+		}
+		if g.Strict != test.strict {
+			t.Errorf("unexpected strictness for test %d: got:%t want:%t", i, g.Strict, test.strict)
+		}
+		got := DOT(g)
+		if header := got[:strings.IndexByte(got, '\n')]; header != test.header {
+			t.Errorf("unexpected header for test %d: got:%s want:%s", i, header, test.header)
+		}
+		r := graphFromDOT(t, got)
+		if r.Name != test.name || r.Strict != test.strict {
+			t.Errorf("unexpected round trip header for test %d: got:%q strict=%t want:%q strict=%t", i, r.Name, r.Strict, test.name, test.strict)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Ego returns a copy of the subgraph of g induced by the nodes within
+// radius hops of the node with the DOT ID center. Node, edge and graph
+// attributes are copied from g.
+func Ego(g *Graph, center string, radius int) (*Graph, error) {
+	c := g.NodeNamed(center)
+	if c == nil {
+		return nil, fmt.Errorf("no node %q", center)
+	}
+	if radius < 0 {
+		return nil, fmt.Errorf("invalid radius: %d", radius)
+	}
+	return subgraph(g, hopsWithin(g, c.ID(), radius)), nil
+}
+
+// DrawNeighborhood renders the neighborhood of the node with the DOT ID
+// center as an SVG using GraphViz with the provided options. Nodes within
+// radius hops of center are drawn with their own styling, and center is
+// drawn bold. The nodes one hop beyond the neighborhood and the edges
+// leading to them are drawn dimmed to show the boundary of the
+// neighborhood. If opts.Engine is empty, "neato" is used.
+func DrawNeighborhood(g *Graph, center string, radius int, opts DrawOptions) (string, error) {
+	c := g.NodeNamed(center)
+	if c == nil {
+		return "", fmt.Errorf("no node %q", center)
+	}
+	if radius < 0 {
+		return "", fmt.Errorf("invalid radius: %d", radius)
+	}
+	dist := hopsWithin(g, c.ID(), radius+1)
+	ego := subgraph(g, dist)
+
+	dimmed := []encoding.Attribute{
+		{Key: "color", Value: "gray"},
+		{Key: "fontcolor", Value: "gray"},
+		{Key: "style", Value: "dashed"},
+	}
+	for _, n := range graph.NodesOf(ego.Nodes()) {
+		n := n.(*Node)
+		if dist[n.ID()] <= radius {
+			continue
+		}
+		for _, a := range dimmed {
+			n.SetAttribute(a)
+		}
+		for _, v := range graph.NodesOf(ego.From(n.ID())) {
+			if dist[v.ID()] > radius {
+				// Edges between boundary nodes are not
+				// part of the neighborhood's context.
+				ego.RemoveEdge(n.ID(), v.ID())
+				continue
+			}
+			e := ego.EdgeBetween(n.ID(), v.ID()).(*Edge)
+			for _, a := range dimmed {
+				e.SetAttribute(a)
+			}
+		}
+	}
+	ego.Node(c.ID()).(*Node).SetAttribute(encoding.Attribute{Key: "penwidth", Value: "3"})
+
+	if opts.Engine == "" {
+		opts.Engine = "neato"
+	}
+	return DrawWithOptions(ego, opts)
+}
+
+// hopsWithin returns the number of hops from the node with the given ID
+// to each node within max hops of it in g.
+func hopsWithin(g graph.Graph, from int64, max int) map[int64]int {
+	dist := map[int64]int{from: 0}
+	queue := []int64{from}
+	for len(queue) != 0 {
+		u := queue[0]
+		queue = queue[1:]
+		if dist[u] == max {
+			continue
+		}
+		for _, v := range graph.NodesOf(g.From(u)) {
+			vid := v.ID()
+			if _, ok := dist[vid]; ok {
+				continue
+			}
+			dist[vid] = dist[u] + 1
+			queue = append(queue, vid)
+		}
+	}
+	return dist
+}
+
+// subgraph returns a copy of the subgraph of g induced by the nodes with
+// IDs in keep. Node IDs and attributes are retained.
+func subgraph(g *Graph, keep map[int64]int) *Graph {
+	dst := subgraphNodes(g, keep)
+	for uid := range keep {
+		for _, v := range graph.NodesOf(g.From(uid)) {
+			vid := v.ID()
+			if _, ok := keep[vid]; !ok || vid < uid {
+				continue
+			}
+			e := g.EdgeBetween(uid, vid).(*Edge)
+			dst.SetEdge(&Edge{
+				F:          dst.Node(uid).(*Node),
+				T:          dst.Node(vid).(*Node),
+				Attributes: append(Attributes(nil), e.Attributes...),
+			})
+		}
+	}
+	return dst
+}
+
+// subgraphNodes returns a copy of the nodes of g with IDs in keep and no
+// edges. Node IDs and attributes are retained.
+func subgraphNodes(g *Graph, keep map[int64]int) *Graph {
+	dst := &Graph{
+		UndirectedGraph: simple.NewUndirectedGraph(),
+		GraphAttrs:      append(Attributes(nil), g.GraphAttrs...),
+		NodeAttrs:       append(Attributes(nil), g.NodeAttrs...),
+		EdgeAttrs:       append(Attributes(nil), g.EdgeAttrs...),
+	}
+	for attr, p := range g.provenance {
+		dst.setProvenanceRecord(attr, p)
+	}
+	for id := range keep {
+		n := g.Node(id).(*Node)
+		dst.AddNode(&Node{
+			NodeID:     n.NodeID,
+			Name:       n.Name,
+			Attributes: append(Attributes(nil), n.Attributes...),
+		})
+	}
+	return dst
+}
+
+// DrawCommunities renders the subgraph induced by each community of g as
+// an SVG using GraphViz with the provided options. Community membership is
+// given by the value of the attr attribute of each node, for example the
+// "community" attribute written by Communities. The returned map is keyed
+// by community. Nodes without the attribute are not drawn. If opts.Engine
+// is empty, "neato" is used.
+func DrawCommunities(g *Graph, attr string, opts DrawOptions) (map[string]string, error) {
+	members := make(map[string]map[int64]int)
+	for _, n := range NodesOf(g) {
+		c := n.GetUnquoted(attr)
+		if c == "" {
+			continue
+		}
+		if members[c] == nil {
+			members[c] = make(map[int64]int)
+		}
+		members[c][n.ID()] = 0
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no node has attribute %q", attr)
+	}
+
+	if opts.Engine == "" {
+		opts.Engine = "neato"
+	}
+	svg := make(map[string]string, len(members))
+	for c, keep := range members {
+		s, err := DrawWithOptions(subgraph(g, keep), opts)
+		if err != nil {
+			return nil, fmt.Errorf("community %s: %v", c, err)
+		}
+		svg[c] = s
+	}
+	return svg, nil
+}
@@ -0,0 +1,75 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"testing"
+)
+
+var egoTests = []struct {
+	center string
+	radius int
+	want   string
+}{
+	{
+		center: "c",
+		radius: 0,
+		want: `graph {
+  // Node definitions.
+  c [color=blue];
+}`,
+	},
+	{
+		center: "c",
+		radius: 1,
+		want: `graph {
+  // Node definitions.
+  b;
+  c [color=blue];
+  d;
+  f;
+
+  // Edge definitions.
+  b -- c [weight=2];
+  c -- d;
+  c -- f;
+  d -- f;
+}`,
+	},
+	{
+		center: "a",
+		radius: 2,
+		want: `graph {
+  // Node definitions.
+  a;
+  b;
+  c [color=blue];
+
+  // Edge definitions.
+  a -- b;
+  b -- c [weight=2];
+}`,
+	},
+}
+
+func TestEgo(t *testing.T) {
+	g := graphFromDOT(t, `graph { c [color=blue]; a -- b; b -- c [weight=2]; c -- d -- e; c -- f; d -- f }`)
+	for i, test := range egoTests {
+		ego, err := Ego(g, test.center, test.radius)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		if got := DOT(ego); got != test.want {
+			t.Errorf("unexpected ego graph for test %d:\ngot:\n%s\nwant:\n%s", i, got, test.want)
+		}
+	}
+	if _, err := Ego(g, "x", 1); err == nil {
+		t.Error("expected error for missing center")
+	}
+	if _, err := Ego(g, "a", -1); err == nil {
+		t.Error("expected error for negative radius")
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// EnrichmentResult is the result of a test for the over-representation
+// of a label among the members of a group.
+type EnrichmentResult struct {
+	// Group and Label are the tested group
+	// and label.
+	Group, Label string
+
+	// Count is the number of members of the
+	// group with the label, GroupSize is the
+	// number of members of the group, and
+	// LabelCount is the number of nodes with
+	// the label out of Total nodes.
+	Count, GroupSize, LabelCount, Total int
+
+	// P is the hypergeometric p-value of the
+	// test and Q is the p-value adjusted for
+	// multiple testing by the Benjamini-Hochberg
+	// procedure.
+	P, Q float64
+}
+
+// Enrichment tests whether the labels held in the labelAttr attribute of
+// the nodes of g are over-represented among the members of the groups
+// given by the groupAttr attribute, for example whether the Gene Ontology
+// terms written into "go" by AnnotateProteins are over-represented in the
+// communities written into "community" by Communities. A node may hold
+// several labels separated by commas. Only nodes with both attributes set
+// are considered.
+//
+// For each group and each label held by at least one of its members, the
+// probability of at least as many members holding the label by chance is
+// found from the hypergeometric distribution. The p-values of all the
+// tests are adjusted with the Benjamini-Hochberg procedure to control the
+// false discovery rate. The results are ordered by adjusted p-value, then
+// by p-value, group and label.
+func Enrichment(g *Graph, groupAttr, labelAttr string) []EnrichmentResult {
+	groupSize := make(map[string]int)
+	labelCount := make(map[string]int)
+	counts := make(map[[2]string]int)
+	var total int
+	for _, n := range NodesOf(g) {
+		group := n.GetUnquoted(groupAttr)
+		labels := nodeLabels(n.GetUnquoted(labelAttr))
+		if group == "" || len(labels) == 0 {
+			continue
+		}
+		total++
+		groupSize[group]++
+		for _, l := range labels {
+			labelCount[l]++
+			counts[[2]string{group, l}]++
+		}
+	}
+
+	results := make([]EnrichmentResult, 0, len(counts))
+	for gl, k := range counts {
+		r := EnrichmentResult{
+			Group:      gl[0],
+			Label:      gl[1],
+			Count:      k,
+			GroupSize:  groupSize[gl[0]],
+			LabelCount: labelCount[gl[1]],
+			Total:      total,
+		}
+		r.P = hypergeometricUpper(k, r.LabelCount, r.GroupSize, total)
+		results = append(results, r)
+	}
+
+	// Adjust p-values in decreasing order, keeping the
+	// adjusted values monotonic.
+	sort.Slice(results, func(i, j int) bool { return results[i].P > results[j].P })
+	m := float64(len(results))
+	q := 1.0
+	for i := range results {
+		rank := m - float64(i)
+		q = math.Min(q, results[i].P*m/rank)
+		results[i].Q = q
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		switch {
+		case a.Q != b.Q:
+			return a.Q < b.Q
+		case a.P != b.P:
+			return a.P < b.P
+		case a.Group != b.Group:
+			return a.Group < b.Group
+		default:
+			return a.Label < b.Label
+		}
+	})
+	return results
+}
+
+// nodeLabels returns the distinct non-empty comma separated labels in s.
+func nodeLabels(s string) []string {
+	var labels []string
+	seen := make(map[string]bool)
+	for _, l := range strings.Split(s, ",") {
+		l = strings.TrimSpace(l)
+		if l == "" || seen[l] {
+			continue
+		}
+		seen[l] = true
+		labels = append(labels, l)
+	}
+	return labels
+}
+
+// hypergeometricUpper returns the probability of drawing at least k
+// successes in n draws without replacement from a population of size
+// total holding successes successes.
+func hypergeometricUpper(k, successes, n, total int) float64 {
+	max := n
+	if successes < max {
+		max = successes
+	}
+	denom := logChoose(total, n)
+	var p float64
+	for i := k; i <= max; i++ {
+		if n-i > total-successes {
+			continue
+		}
+		p += math.Exp(logChoose(successes, i) + logChoose(total-successes, n-i) - denom)
+	}
+	return math.Min(p, 1)
+}
+
+// logChoose returns the natural logarithm of the binomial coefficient
+// n choose k.
+func logChoose(n, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
@@ -0,0 +1,101 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+var hypergeometricUpperTests = []struct {
+	k, successes, n, total int
+	want                   float64
+}{
+	{k: 0, successes: 4, n: 4, total: 8, want: 1},
+	{k: 1, successes: 1, n: 1, total: 2, want: 0.5},
+
+	// Fisher's lady tasting tea.
+	{k: 4, successes: 4, n: 4, total: 8, want: 1.0 / 70},
+	{k: 3, successes: 4, n: 4, total: 8, want: 17.0 / 70},
+
+	{k: 2, successes: 5, n: 3, total: 10, want: 0.5},
+	{k: 3, successes: 2, n: 4, total: 8, want: 0},
+	{k: 4, successes: 6, n: 4, total: 6, want: 1},
+}
+
+func TestHypergeometricUpper(t *testing.T) {
+	const tol = 1e-12
+	for i, test := range hypergeometricUpperTests {
+		got := hypergeometricUpper(test.k, test.successes, test.n, test.total)
+		if math.Abs(got-test.want) > tol {
+			t.Errorf("unexpected p-value for test %d: got:%v want:%v", i, got, test.want)
+		}
+	}
+}
+
+var nodeLabelsTests = []struct {
+	in   string
+	want []string
+}{
+	{in: "", want: nil},
+	{in: "x", want: []string{"x"}},
+	{in: "x, y,x,,z ", want: []string{"x", "y", "z"}},
+}
+
+func TestNodeLabels(t *testing.T) {
+	for i, test := range nodeLabelsTests {
+		got := nodeLabels(test.in)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected labels for test %d: got:%q want:%q", i, got, test.want)
+		}
+	}
+}
+
+func TestEnrichment(t *testing.T) {
+	g := graphFromDOT(t, `graph {
+	a1 [g=A, l="x,y"];
+	a2 [g=A, l="x,x"];
+	a3 [g=A, l=x];
+	a4 [g=A, l=x];
+	b1 [g=B, l=y];
+	b2 [g=B, l=z];
+	b3 [g=B, l=z];
+	b4 [g=B, l=z];
+	c1 [l=x];
+	c2 [g=B];
+	a1 -- b1;
+}`)
+	got := Enrichment(g, "g", "l")
+
+	// P-values are from the hypergeometric distribution over
+	// the 8 nodes holding both attributes. Q-values are adjusted
+	// over the 4 tests by the Benjamini-Hochberg procedure, so
+	// 4/3 × 11/14 is capped by the larger p-value's 11/14.
+	want := []EnrichmentResult{
+		{Group: "A", Label: "x", Count: 4, GroupSize: 4, LabelCount: 4, Total: 8, P: 1.0 / 70, Q: 2.0 / 35},
+		{Group: "B", Label: "z", Count: 3, GroupSize: 4, LabelCount: 3, Total: 8, P: 1.0 / 14, Q: 1.0 / 7},
+		{Group: "A", Label: "y", Count: 1, GroupSize: 4, LabelCount: 2, Total: 8, P: 11.0 / 14, Q: 11.0 / 14},
+		{Group: "B", Label: "y", Count: 1, GroupSize: 4, LabelCount: 2, Total: 8, P: 11.0 / 14, Q: 11.0 / 14},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected number of results: got:%d want:%d\n%+v", len(got), len(want), got)
+	}
+	const tol = 1e-12
+	for i := range want {
+		r, w := got[i], want[i]
+		if math.Abs(r.P-w.P) > tol || math.Abs(r.Q-w.Q) > tol {
+			t.Errorf("unexpected p-values for result %d: got:P=%v Q=%v want:P=%v Q=%v", i, r.P, r.Q, w.P, w.Q)
+		}
+		r.P, r.Q, w.P, w.Q = 0, 0, 0, 0
+		if r != w {
+			t.Errorf("unexpected result %d: got:%+v want:%+v", i, r, w)
+		}
+	}
+
+	if r := Enrichment(g, "missing", "l"); len(r) != 0 {
+		t.Errorf("unexpected results for missing group attribute: %+v", r)
+	}
+}
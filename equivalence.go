@@ -0,0 +1,206 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/mat"
+)
+
+// StructuralEquivalence partitions the nodes of g into k roles of
+// approximately structurally equivalent nodes, nodes that are adjacent to
+// the same other nodes, and returns the image matrix of the resulting
+// blockmodel. The distance between two nodes is the Euclidean distance
+// between their rows of the adjacency matrix, ignoring the entries for the
+// two nodes themselves, and roles are found by average-linkage
+// hierarchical clustering of the distances, cut to give k clusters. Roles
+// are numbered in order of their first node in node ID order.
+//
+// Element [r,s] of the returned image matrix is the density of edges
+// between nodes in roles r and s, the number of edges divided by the
+// number of possible edges. The clustering holds a distance for every
+// pair of nodes, so memory use grows with the square of the number of
+// nodes.
+//
+// StructuralEquivalence panics if k is less than one or greater than the
+// number of nodes in g.
+//
+// The role identity value is written into the "role" attribute of each node.
+func StructuralEquivalence(g *Graph, k int) *mat.SymDense {
+	x := g.Index()
+	n := x.Len()
+	if k < 1 || k > n {
+		panic("graphprac: invalid number of roles")
+	}
+	done, err := trace(g, "StructuralEquivalence", []string{"role"}, "k", k)
+	if err != nil {
+		return nil
+	}
+	defer done(nil)
+
+	adj := x.adjacency(g)
+	d := newTriangular(n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			d.set(i, j, float64(len(adj[i])+len(adj[j])))
+		}
+	}
+	// Remove the shared neighbours of each pair, found
+	// as the middle nodes of two-hop paths, and the
+	// contribution of each edge.
+	for _, to := range adj {
+		for a, i := range to {
+			for _, j := range to[a+1:] {
+				d.set(i, j, d.at(i, j)-2)
+			}
+		}
+	}
+	for i, to := range adj {
+		for _, j := range to {
+			if i < j {
+				d.set(i, j, d.at(i, j)-2)
+			}
+		}
+	}
+	for i := range d {
+		d[i] = math.Sqrt(math.Max(d[i], 0))
+	}
+
+	uf := newUnionFind(n)
+	merges := averageLinkage(d, n)
+	for _, m := range merges[:n-k] {
+		uf.union(m.a, m.b)
+	}
+	label := make(map[int]int)
+	role := make([]int, n)
+	for i := range role {
+		r := uf.find(i)
+		l, ok := label[r]
+		if !ok {
+			l = len(label)
+			label[r] = l
+		}
+		role[i] = l
+		x.Node(i).SetAttribute(encoding.Attribute{Key: "role", Value: fmt.Sprint(l)})
+	}
+
+	size := make([]float64, k)
+	for _, r := range role {
+		size[r]++
+	}
+	image := mat.NewSymDense(k, nil)
+	for i, to := range adj {
+		for _, j := range to {
+			if i < j {
+				r, s := role[i], role[j]
+				image.SetSym(r, s, image.At(r, s)+1)
+			}
+		}
+	}
+	for r := 0; r < k; r++ {
+		for s := r; s < k; s++ {
+			pairs := size[r] * size[s]
+			if r == s {
+				pairs = size[r] * (size[r] - 1) / 2
+			}
+			if pairs != 0 {
+				image.SetSym(r, s, image.At(r, s)/pairs)
+			}
+		}
+	}
+	return image
+}
+
+// triangular is a packed strictly upper triangular matrix.
+type triangular []float64
+
+func newTriangular(n int) triangular { return make(triangular, n*(n-1)/2) }
+
+// offset returns the offset of element [i,j], i < j, of t.
+func (t triangular) offset(i, j int) int { return j*(j-1)/2 + i }
+
+// at returns the element [i,j] of the symmetric matrix represented by t.
+func (t triangular) at(i, j int) float64 {
+	if i > j {
+		i, j = j, i
+	}
+	return t[t.offset(i, j)]
+}
+
+// set sets the element [i,j] of the symmetric matrix represented by t.
+func (t triangular) set(i, j int, v float64) {
+	if i > j {
+		i, j = j, i
+	}
+	t[t.offset(i, j)] = v
+}
+
+// merge is a merge of the clusters represented by a and b at the given
+// distance.
+type merge struct {
+	a, b     int
+	distance float64
+}
+
+// averageLinkage returns the merges of an average-linkage hierarchical
+// clustering of n items with the pairwise distances in d, ordered by
+// distance. Clusters are represented by one of their items. The
+// clustering uses the nearest-neighbour chain algorithm, and d is
+// overwritten.
+func averageLinkage(d triangular, n int) []merge {
+	size := make([]int, n)
+	active := make([]bool, n)
+	for i := range size {
+		size[i] = 1
+		active[i] = true
+	}
+	merges := make([]merge, 0, n-1)
+	var chain []int
+	for len(merges) < n-1 {
+		if len(chain) == 0 {
+			for i, ok := range active {
+				if ok {
+					chain = append(chain, i)
+					break
+				}
+			}
+		}
+		a := chain[len(chain)-1]
+
+		// Prefer the previous cluster in the chain on
+		// ties so that the chain always terminates.
+		b, best := -1, math.Inf(1)
+		if len(chain) > 1 {
+			b = chain[len(chain)-2]
+			best = d.at(a, b)
+		}
+		for i, ok := range active {
+			if ok && i != a && d.at(a, i) < best {
+				b, best = i, d.at(a, i)
+			}
+		}
+		if len(chain) < 2 || b != chain[len(chain)-2] {
+			chain = append(chain, b)
+			continue
+		}
+
+		chain = chain[:len(chain)-2]
+		merges = append(merges, merge{a: a, b: b, distance: best})
+		// Keep the merged cluster in b.
+		active[a] = false
+		for i, ok := range active {
+			if ok && i != b {
+				d.set(b, i, (float64(size[a])*d.at(a, i)+float64(size[b])*d.at(b, i))/float64(size[a]+size[b]))
+			}
+		}
+		size[b] += size[a]
+	}
+	sort.SliceStable(merges, func(i, j int) bool { return merges[i].distance < merges[j].distance })
+	return merges
+}
@@ -0,0 +1,69 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+var equivalenceTests = []struct {
+	dot    string
+	k      int
+	groups [][]string
+	image  *mat.SymDense
+}{
+	{
+		// The complete bipartite graph K(2,3).
+		dot:    `graph { a -- c; a -- d; a -- e; b -- c; b -- d; b -- e }`,
+		k:      2,
+		groups: [][]string{{"a", "b"}, {"c", "d", "e"}},
+		image:  mat.NewSymDense(2, []float64{0, 1, 1, 0}),
+	},
+	{
+		// A star with a clique of leaves.
+		dot:    `graph { a -- b; a -- c; a -- d; b -- c; b -- d; c -- d; e -- d }`,
+		k:      3,
+		groups: [][]string{{"a", "b", "c"}, {"d"}, {"e"}},
+		image: mat.NewSymDense(3, []float64{
+			1, 1, 0,
+			1, 0, 1,
+			0, 1, 0,
+		}),
+	},
+	{
+		dot:    `graph { a -- b -- c; d }`,
+		k:      1,
+		groups: [][]string{{"a", "b", "c", "d"}},
+		image:  mat.NewSymDense(1, []float64{2.0 / 6}),
+	},
+}
+
+func TestStructuralEquivalence(t *testing.T) {
+	for i, test := range equivalenceTests {
+		g := graphFromDOT(t, test.dot)
+		image := StructuralEquivalence(g, test.k)
+		if !mat.EqualApprox(image, test.image, 1e-12) {
+			t.Errorf("unexpected image matrix for test %d:\ngot:\n%v\nwant:\n%v",
+				i, mat.Formatted(image), mat.Formatted(test.image))
+		}
+		checkPartition(t, g, "role", test.groups)
+		if got := g.NodeNamed(test.groups[0][0]).Get("role"); got != "0" {
+			t.Errorf("unexpected role for first node in test %d: got:%s want:0", i, got)
+		}
+	}
+
+	for _, k := range []int{0, 4} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for k=%d", k)
+				}
+			}()
+			StructuralEquivalence(graphFromDOT(t, `graph { a -- b -- c }`), k)
+		}()
+	}
+}
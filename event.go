@@ -0,0 +1,164 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// Op is a kind of graph mutation.
+type Op int
+
+const (
+	// NodeAdded indicates that Node was added to the graph.
+	NodeAdded Op = iota
+	// NodeRemoved indicates that Node was removed from the
+	// graph. Its edges are removed first.
+	NodeRemoved
+	// EdgeAdded indicates that Edge was added to the graph.
+	EdgeAdded
+	// EdgeRemoved indicates that Edge was removed from the
+	// graph.
+	EdgeRemoved
+	// AttributeSet indicates that Attribute was set on Node or
+	// Edge. An empty Attribute value indicates that the
+	// attribute was removed.
+	AttributeSet
+	// Restored indicates that the graph was returned to an
+	// earlier state by Restore. No other events are sent for
+	// the changes made by Restore.
+	Restored
+)
+
+// Event describes a change made to a graph.
+type Event struct {
+	Op Op
+
+	// Node and Edge are the node or edge
+	// that was changed. Only one is set.
+	Node *Node
+	Edge *Edge
+
+	// Attribute is the attribute set by an
+	// AttributeSet event.
+	Attribute encoding.Attribute
+}
+
+// Subscribe registers fn to be called with each subsequent change to the
+// nodes and edges of g and their attributes, and returns a function that
+// cancels the subscription. Calls to fn are made synchronously by the
+// goroutine making the change, so fn must not modify g.
+//
+// Changes are only seen when they are made through the methods of g and
+// of its nodes and edges. Attribute changes made by indexing into
+// Attributes directly or through the embedded Attributes of a Node or
+// Edge are not seen. Subscribe and the returned function must not be
+// called concurrently with other methods of g.
+func (g *Graph) Subscribe(fn func(Event)) (cancel func()) {
+	if g.subscribers == nil {
+		g.subscribers = make(map[int]func(Event))
+	}
+	id := g.nextSubscriber
+	g.nextSubscriber++
+	g.subscribers[id] = fn
+	return func() { delete(g.subscribers, id) }
+}
+
+// notify sends ev to the subscribers of g.
+func (g *Graph) notify(ev Event) {
+	for _, fn := range g.subscribers {
+		fn(ev)
+	}
+}
+
+// AddNode adds n to the graph. It panics if the added node ID matches an
+// existing node ID.
+func (g *Graph) AddNode(n graph.Node) {
+	g.UndirectedGraph.AddNode(n)
+	if n, ok := n.(*Node); ok {
+		n.graph = g
+		if len(g.subscribers) != 0 {
+			g.notify(Event{Op: NodeAdded, Node: n})
+		}
+	}
+}
+
+// RemoveNode removes the node with the given ID and its edges from the
+// graph. If the node is not in the graph it is a no-op.
+func (g *Graph) RemoveNode(id int64) {
+	if len(g.subscribers) == 0 {
+		g.UndirectedGraph.RemoveNode(id)
+		return
+	}
+	n, ok := g.Node(id).(*Node)
+	if !ok {
+		g.UndirectedGraph.RemoveNode(id)
+		return
+	}
+	for _, v := range graph.NodesOf(g.From(id)) {
+		g.RemoveEdge(id, v.ID())
+	}
+	g.UndirectedGraph.RemoveNode(id)
+	g.notify(Event{Op: NodeRemoved, Node: n})
+}
+
+// SetEdge adds e to the graph, replacing any existing edge between the
+// same nodes. It panics if the IDs of the edge's nodes are equal.
+func (g *Graph) SetEdge(e graph.Edge) {
+	g.UndirectedGraph.SetEdge(e)
+	if e, ok := e.(*Edge); ok && len(g.subscribers) != 0 {
+		g.notify(Event{Op: EdgeAdded, Edge: e})
+	}
+}
+
+// RemoveEdge removes the edge between the nodes with IDs fid and tid from
+// the graph. If the edge is not in the graph it is a no-op.
+func (g *Graph) RemoveEdge(fid, tid int64) {
+	if len(g.subscribers) == 0 {
+		g.UndirectedGraph.RemoveEdge(fid, tid)
+		return
+	}
+	e, ok := g.EdgeBetween(fid, tid).(*Edge)
+	g.UndirectedGraph.RemoveEdge(fid, tid)
+	if ok {
+		g.notify(Event{Op: EdgeRemoved, Edge: e})
+	}
+}
+
+// SetAttribute sets the given attribute of the node. If the attr Value
+// field is the empty string, the attribute is unset.
+func (n *Node) SetAttribute(attr encoding.Attribute) error {
+	err := n.Attributes.SetAttribute(attr)
+	if g := n.graph; g != nil && len(g.subscribers) != 0 && g.Node(n.NodeID) == graph.Node(n) {
+		g.notify(Event{Op: AttributeSet, Node: n, Attribute: attr})
+	}
+	return err
+}
+
+// SetQuoted sets the given attribute of the node to the specified value
+// quoted as a DOT string.
+func (n *Node) SetQuoted(attr, value string) error {
+	return n.SetAttribute(encoding.Attribute{Key: attr, Value: quoteDOT(value)})
+}
+
+// SetAttribute sets the given attribute of the edge. If the attr Value
+// field is the empty string, the attribute is unset.
+func (e *Edge) SetAttribute(attr encoding.Attribute) error {
+	err := e.Attributes.SetAttribute(attr)
+	if e.F == nil || e.T == nil {
+		return err
+	}
+	if g := e.F.graph; g != nil && len(g.subscribers) != 0 && g.EdgeBetween(e.F.NodeID, e.T.NodeID) == graph.Edge(e) {
+		g.notify(Event{Op: AttributeSet, Edge: e, Attribute: attr})
+	}
+	return err
+}
+
+// SetQuoted sets the given attribute of the edge to the specified value
+// quoted as a DOT string.
+func (e *Edge) SetQuoted(attr, value string) error {
+	return e.SetAttribute(encoding.Attribute{Key: attr, Value: quoteDOT(value)})
+}
@@ -0,0 +1,108 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// eventString returns a compact description of ev for comparison.
+func eventString(ev Event) string {
+	switch {
+	case ev.Node != nil:
+		return fmt.Sprintf("%d:%s:%s=%s", ev.Op, ev.Node.Name, ev.Attribute.Key, ev.Attribute.Value)
+	case ev.Edge != nil:
+		return fmt.Sprintf("%d:%s--%s:%s=%s", ev.Op, ev.Edge.F.Name, ev.Edge.T.Name, ev.Attribute.Key, ev.Attribute.Value)
+	default:
+		return fmt.Sprintf("%d", ev.Op)
+	}
+}
+
+var subscribeTests = []struct {
+	mutate func(g *Graph)
+	want   []string
+}{
+	{
+		mutate: func(g *Graph) {
+			n := g.NewNode().(*Node)
+			n.Name = "c"
+			g.AddNode(n)
+			g.NewEdge(g.NodeNamed("b"), n)
+		},
+		want: []string{
+			fmt.Sprintf("%d:c:=", NodeAdded),
+			fmt.Sprintf("%d:b--c:=", EdgeAdded),
+		},
+	},
+	{
+		mutate: func(g *Graph) {
+			g.NodeNamed("a").SetAttribute(encoding.Attribute{Key: "x", Value: "1"})
+			a, b := g.NodeNamed("a"), g.NodeNamed("b")
+			g.EdgeBetween(a.ID(), b.ID()).(*Edge).SetAttribute(encoding.Attribute{Key: "w", Value: "2"})
+			g.NodeNamed("a").SetAttribute(encoding.Attribute{Key: "x", Value: ""})
+		},
+		want: []string{
+			fmt.Sprintf("%d:a:x=1", AttributeSet),
+			fmt.Sprintf("%d:a--b:w=2", AttributeSet),
+			fmt.Sprintf("%d:a:x=", AttributeSet),
+		},
+	},
+	{
+		mutate: func(g *Graph) {
+			g.RemoveNode(g.NodeNamed("a").ID())
+		},
+		want: []string{
+			fmt.Sprintf("%d:a--b:=", EdgeRemoved),
+			fmt.Sprintf("%d:a:=", NodeRemoved),
+		},
+	},
+	{
+		mutate: func(g *Graph) {
+			a, b := g.NodeNamed("a"), g.NodeNamed("b")
+			g.RemoveEdge(a.ID(), b.ID())
+			g.RemoveEdge(a.ID(), b.ID())
+			g.RemoveNode(-1)
+		},
+		want: []string{
+			fmt.Sprintf("%d:a--b:=", EdgeRemoved),
+		},
+	},
+}
+
+func TestSubscribe(t *testing.T) {
+	for i, test := range subscribeTests {
+		g := graphFromDOT(t, `graph { a -- b }`)
+		var got []string
+		cancel := g.Subscribe(func(ev Event) { got = append(got, eventString(ev)) })
+		test.mutate(g)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected events for test %d: got:%q want:%q", i, got, test.want)
+		}
+
+		cancel()
+		got = nil
+		g.NodeNamed("b").SetAttribute(encoding.Attribute{Key: "y", Value: "1"})
+		if got != nil {
+			t.Errorf("unexpected events after cancel for test %d: %q", i, got)
+		}
+	}
+}
+
+func TestSubscribeDetached(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b }`)
+	var n int
+	g.Subscribe(func(Event) { n++ })
+	a := g.NodeNamed("a")
+	g.RemoveNode(a.ID())
+	n = 0
+	a.SetAttribute(encoding.Attribute{Key: "x", Value: "1"})
+	if n != 0 {
+		t.Errorf("unexpected event for node removed from graph: got %d events", n)
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Format is a graph file format.
+type Format int
+
+// Graph file formats recognised by Detect. EdgeListFormat is the plain
+// text format read by ReadEdgeList.
+const (
+	UnknownFormat Format = iota
+	DOTFormat
+	GraphMLFormat
+	GMLFormat
+	PajekFormat
+	EdgeListFormat
+)
+
+func (f Format) String() string {
+	switch f {
+	case UnknownFormat:
+		return "unknown"
+	case DOTFormat:
+		return "DOT"
+	case GraphMLFormat:
+		return "GraphML"
+	case GMLFormat:
+		return "GML"
+	case PajekFormat:
+		return "Pajek"
+	case EdgeListFormat:
+		return "edge list"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// sniffLen is the number of bytes read by Detect.
+const sniffLen = 8 << 10
+
+// Detect returns the format of the graph data in r, determined from at
+// most the first 8kB of the data. Leading comments and white space are
+// skipped. GraphML is recognised by its XML graphml element, Pajek by a
+// leading section header such as "*Vertices", and DOT and GML by their
+// graph keywords, DOT graphs opening with a brace and GML graphs with a
+// bracket. Data where every line holds two or three fields separated by
+// white space or commas is an edge list. Detect returns UnknownFormat and
+// an error if the format is not recognised.
+func Detect(r io.Reader) (Format, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(r, sniffLen))
+	if err != nil {
+		return UnknownFormat, err
+	}
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+
+	text := skipComments(b)
+	switch {
+	case len(text) == 0:
+		return UnknownFormat, fmt.Errorf("no graph data")
+	case text[0] == '<':
+		if bytes.Contains(b, []byte("<graphml")) {
+			return GraphMLFormat, nil
+		}
+		return UnknownFormat, fmt.Errorf("unrecognised XML document")
+	case text[0] == '*':
+		word := strings.ToLower(string(leadingWord(text[1:])))
+		switch word {
+		case "network", "vertices", "arcs", "edges", "arcslist", "edgeslist", "matrix":
+			return PajekFormat, nil
+		}
+	}
+
+	word := strings.ToLower(string(leadingWord(text)))
+	if word == "strict" || word == "graph" || word == "digraph" {
+		rest := text[len(word):]
+		if word == "strict" {
+			rest = bytes.TrimLeftFunc(rest, unicode.IsSpace)
+			rest = rest[len(leadingWord(rest)):]
+		}
+		rest = bytes.TrimLeftFunc(rest, unicode.IsSpace)
+		if len(rest) != 0 && rest[0] == '[' {
+			return GMLFormat, nil
+		}
+		return DOTFormat, nil
+	}
+	if isGMLKey(word) && bytes.Contains(b, []byte("graph")) && bytes.Contains(b, []byte("[")) {
+		// GML files often start with Creator
+		// or Version keys before the graph.
+		return GMLFormat, nil
+	}
+
+	if isEdgeList(b) {
+		return EdgeListFormat, nil
+	}
+	return UnknownFormat, fmt.Errorf("unrecognised graph format")
+}
+
+// skipComments returns b with any leading white space and DOT, GML and
+// Pajek comments removed.
+func skipComments(b []byte) []byte {
+	for {
+		b = bytes.TrimLeftFunc(b, unicode.IsSpace)
+		switch {
+		case bytes.HasPrefix(b, []byte("//")), bytes.HasPrefix(b, []byte("#")), bytes.HasPrefix(b, []byte("%")):
+			i := bytes.IndexByte(b, '\n')
+			if i < 0 {
+				return nil
+			}
+			b = b[i+1:]
+		case bytes.HasPrefix(b, []byte("/*")):
+			i := bytes.Index(b[2:], []byte("*/"))
+			if i < 0 {
+				return nil
+			}
+			b = b[i+4:]
+		default:
+			return b
+		}
+	}
+}
+
+// leadingWord returns the leading run of letters in b.
+func leadingWord(b []byte) []byte {
+	i := bytes.IndexFunc(b, func(r rune) bool { return !unicode.IsLetter(r) })
+	if i < 0 {
+		return b
+	}
+	return b[:i]
+}
+
+// isGMLKey returns whether word is a key that may precede the graph in a
+// GML file.
+func isGMLKey(word string) bool {
+	switch word {
+	case "creator", "version":
+		return true
+	}
+	return false
+}
+
+// isEdgeList returns whether every non-comment line of b, except possibly
+// a truncated final line, holds two or three fields.
+func isEdgeList(b []byte) bool {
+	lines := strings.Split(string(b), "\n")
+	if len(b) == sniffLen && len(lines) > 1 {
+		lines = lines[:len(lines)-1]
+	}
+	var n int
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		if l == "" || l[0] == '#' || l[0] == '%' {
+			continue
+		}
+		f := edgeListFields(l)
+		if len(f) < 2 || len(f) > 3 {
+			return false
+		}
+		n++
+	}
+	return n != 0
+}
+
+// edgeListFields returns the fields of an edge list line, separated by
+// white space or commas.
+func edgeListFields(line string) []string {
+	return strings.FieldsFunc(line, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+}
+
+// ReadEdgeList reads a plain text edge list from r and returns the graph
+// it describes. Each line holds the DOT IDs of the ends of an edge and
+// optionally a numerical weight, separated by white space or commas, and
+// lines starting with '#' or '%' are comments. Weights are written into
+// the "weight" attribute of the edges. Nodes are given node IDs in the
+// order they first appear. Self edges are discarded, and the last weight
+// given for a repeated edge is retained.
+func ReadEdgeList(r io.Reader) (*Graph, error) {
+	g := &Graph{UndirectedGraph: simple.NewUndirectedGraph()}
+	nodes := make(map[string]*Node)
+	node := func(name string) *Node {
+		n, ok := nodes[name]
+		if !ok {
+			n = &Node{NodeID: int64(len(nodes)), Name: name}
+			g.AddNode(n)
+			nodes[name] = n
+		}
+		return n
+	}
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if text == "" || text[0] == '#' || text[0] == '%' {
+			continue
+		}
+		f := edgeListFields(text)
+		if len(f) < 2 || len(f) > 3 {
+			return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("expected two or three fields, got %d", len(f))}
+		}
+		var w float64
+		if len(f) == 3 {
+			var err error
+			w, err = strconv.ParseFloat(f[2], 64)
+			if err != nil {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("invalid weight %q", f[2])}
+			}
+		}
+		u, v := node(f[0]), node(f[1])
+		if u == v {
+			continue
+		}
+		e := g.NewEdge(u, v).(*Edge)
+		if len(f) == 3 {
+			e.SetAttribute(encoding.Attribute{Key: "weight", Value: fmt.Sprint(w)})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Load reads the graph in the file at path, detecting its format with
+// Detect. DOT, GraphML, Pajek and edge list files can be loaded.
+func Load(path string) (*Graph, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := Detect(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	switch f {
+	case DOTFormat:
+		return readDOT(b, LoadOptions{})
+	case GraphMLFormat:
+		return ReadGraphML(bytes.NewReader(b))
+	case PajekFormat:
+		return ReadPajek(bytes.NewReader(b))
+	case EdgeListFormat:
+		return ReadEdgeList(bytes.NewReader(b))
+	default:
+		return nil, fmt.Errorf("%s: no reader for %v format", path, f)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var detectTests = []struct {
+	src  string
+	want Format
+}{
+	{src: `graph { a -- b }`, want: DOTFormat},
+	{src: "\xef\xbb\xbf// A comment.\n/* Another\ncomment. */\nstrict digraph G { a -> b }", want: DOTFormat},
+	{src: `Graph{a--b}`, want: DOTFormat},
+	{src: "graph [\n  node [ id 1 ]\n]", want: GMLFormat},
+	{src: "# Exported.\nCreator \"yEd\"\nVersion 2.2\ngraph\n[\n]", want: GMLFormat},
+	{src: `<?xml version="1.0"?><graphml xmlns="http://graphml.graphdrawing.org/xmlns"></graphml>`, want: GraphMLFormat},
+	{src: "% Pajek.\n*Vertices 2\n1 \"a\"\n", want: PajekFormat},
+	{src: "*network test\n", want: PajekFormat},
+	{src: "a b\nb c 1.5\n", want: EdgeListFormat},
+	{src: "# Header.\na,b\n\nb,c,2\n", want: EdgeListFormat},
+	{src: "1\t2\n", want: EdgeListFormat},
+	{src: "", want: UnknownFormat},
+	{src: "// Only a comment.\n", want: UnknownFormat},
+	{src: `<html></html>`, want: UnknownFormat},
+	{src: "*unknown\n", want: UnknownFormat},
+	{src: "a b c d\n", want: UnknownFormat},
+}
+
+func TestDetect(t *testing.T) {
+	for i, test := range detectTests {
+		got, err := Detect(strings.NewReader(test.src))
+		if got != test.want {
+			t.Errorf("unexpected format for test %d: got:%v want:%v", i, got, test.want)
+		}
+		if (err != nil) != (test.want == UnknownFormat) {
+			t.Errorf("unexpected error state for test %d: %v", i, err)
+		}
+	}
+}
+
+func TestDetectTruncated(t *testing.T) {
+	// The final line of a long edge list may
+	// be cut short by the sniffing limit.
+	var buf strings.Builder
+	for buf.Len() < 2*sniffLen {
+		buf.WriteString("node_a node_b 1\n")
+	}
+	for _, n := range []int{sniffLen - 3, sniffLen + 7} {
+		got, err := Detect(strings.NewReader(buf.String()[:n]))
+		if err != nil || got != EdgeListFormat {
+			t.Errorf("unexpected format for %d bytes: got:%v err:%v", n, got, err)
+		}
+	}
+}
+
+var edgeListTests = []struct {
+	src  string
+	want string
+}{
+	{
+		src: "\ufeff# Header.\nb a\na,c,2\n% Comment.\n\nc a 3\nc c\n",
+		want: `graph {
+  // Node definitions.
+  a;
+  b;
+  c;
+
+  // Edge definitions.
+  a -- b;
+  a -- c [weight=3];
+}`,
+	},
+	{
+		src: "x\ty\t0.5\n",
+		want: `graph {
+  // Node definitions.
+  x;
+  y;
+
+  // Edge definitions.
+  x -- y [weight=0.5];
+}`,
+	},
+}
+
+func TestReadEdgeList(t *testing.T) {
+	for i, test := range edgeListTests {
+		g, err := ReadEdgeList(strings.NewReader(test.src))
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		if got := DOT(g); got != test.want {
+			t.Errorf("unexpected graph for test %d:\ngot:\n%s\nwant:\n%s", i, got, test.want)
+		}
+	}
+	g, err := ReadEdgeList(strings.NewReader("b a\nc b\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for id, name := range []string{"b", "a", "c"} {
+		if got := g.Node(int64(id)).(*Node).Name; got != name {
+			t.Errorf("unexpected node for ID %d: got:%s want:%s", id, got, name)
+		}
+	}
+	for _, src := range []string{"a\n", "a b c d\n", "a b heavy\n"} {
+		if _, err := ReadEdgeList(strings.NewReader(src)); err == nil {
+			t.Errorf("expected error for %q", src)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graphprac-load")
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"g.dot":     `graph { a -- b; b -- c }`,
+		"g.graphml": `<?xml version="1.0"?><graphml xmlns="http://graphml.graphdrawing.org/xmlns"><graph edgedefault="undirected"><node id="a"/><node id="b"/><node id="c"/><edge source="a" target="b"/><edge source="b" target="c"/></graph></graphml>`,
+		"g.net":     "*Vertices 3\n1 \"a\"\n2 \"b\"\n3 \"c\"\n*Edges\n1 2\n2 3\n",
+		"g.txt":     "a b\nb c\n",
+	}
+	want := graphFromDOT(t, `graph { a -- b; b -- c }`)
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		err := ioutil.WriteFile(path, []byte(src), 0644)
+		if err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		g, err := Load(path)
+		if err != nil {
+			t.Errorf("unexpected error loading %s: %v", name, err)
+			continue
+		}
+		if got := DOT(g); got != DOT(want) {
+			t.Errorf("unexpected graph for %s:\ngot:\n%s\nwant:\n%s", name, got, DOT(want))
+		}
+	}
+
+	for name, src := range map[string]string{
+		"g.gml":     "graph [\n  node [ id 1 ]\n]",
+		"g.unknown": "a b c d\n",
+	} {
+		path := filepath.Join(dir, name)
+		err := ioutil.WriteFile(path, []byte(src), 0644)
+		if err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("expected error loading %s", name)
+		}
+	}
+	if _, err := Load(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected error loading missing file")
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// Projection is a map projection from latitude and longitude to the plane.
+type Projection int
+
+const (
+	// Equirectangular is the equirectangular projection with
+	// the standard parallel at the mean latitude of the nodes.
+	Equirectangular Projection = iota
+
+	// Mercator is the Mercator projection.
+	Mercator
+)
+
+// earthRadius is the mean radius of the Earth in kilometres.
+const earthRadius = 6371.0088
+
+// geoExtent is the size of the larger dimension of a geographic
+// layout in points.
+const geoExtent = 10 * pointsPerInch
+
+// GeoLayout writes the projected coordinates of each node of g into the
+// "pos" attribute of the node. The coordinates of a node are given by its
+// "lat" and "lon" attributes in decimal degrees. The layout is scaled so
+// that its larger dimension is 10 inches, and positions are pinned so the
+// graph can be drawn with the coordinates unchanged using neato or fdp.
+func GeoLayout(g *Graph, projection Projection) error {
+	nodes := NodesOf(g)
+	if len(nodes) == 0 {
+		return nil
+	}
+	lat := make([]float64, len(nodes))
+	lon := make([]float64, len(nodes))
+	var meanLat float64
+	for i, n := range nodes {
+		var err error
+		lat[i], lon[i], err = latLon(n)
+		if err != nil {
+			return err
+		}
+		meanLat += lat[i]
+	}
+	meanLat /= float64(len(nodes))
+
+	x := make([]float64, len(nodes))
+	y := make([]float64, len(nodes))
+	for i := range nodes {
+		switch projection {
+		case Equirectangular:
+			x[i] = lon[i] * math.Cos(meanLat*math.Pi/180)
+			y[i] = lat[i]
+		case Mercator:
+			x[i] = lon[i]
+			y[i] = math.Log(math.Tan(math.Pi/4+lat[i]*math.Pi/360)) * 180 / math.Pi
+		default:
+			return fmt.Errorf("invalid projection: %d", projection)
+		}
+	}
+
+	minX, maxX := floatRange(x)
+	minY, maxY := floatRange(y)
+	scale := math.Max(maxX-minX, maxY-minY)
+	if scale == 0 {
+		scale = 1
+	}
+	scale = geoExtent / scale
+	for i, n := range nodes {
+		px := strconv.FormatFloat((x[i]-minX)*scale, 'f', 2, 64)
+		py := strconv.FormatFloat((y[i]-minY)*scale, 'f', 2, 64)
+		n.SetAttribute(encoding.Attribute{Key: "pos", Value: quoteDOT(px + "," + py + "!")})
+	}
+	return nil
+}
+
+// GeoDistance writes the great circle distance in kilometres between the
+// ends of each edge of g into the "distance" attribute of the edge. The
+// coordinates of a node are given by its "lat" and "lon" attributes in
+// decimal degrees.
+func GeoDistance(g *Graph) error {
+	for _, u := range NodesOf(g) {
+		for _, v := range graph.NodesOf(g.From(u.ID())) {
+			if v.ID() < u.ID() {
+				continue
+			}
+			d, err := geoDistance(u, v.(*Node))
+			if err != nil {
+				return err
+			}
+			e := g.EdgeBetween(u.ID(), v.ID()).(*Edge)
+			e.SetAttribute(encoding.Attribute{Key: "distance", Value: fmt.Sprint(d)})
+		}
+	}
+	return nil
+}
+
+// GeoConnect adds an edge between each pair of nodes of g that are within
+// radius kilometres of each other, and writes the great circle distance
+// between them into the "distance" attribute of the edge. The coordinates
+// of a node are given by its "lat" and "lon" attributes in decimal
+// degrees.
+func GeoConnect(g *Graph, radius float64) error {
+	nodes := NodesOf(g)
+	for i, u := range nodes {
+		for _, v := range nodes[i+1:] {
+			d, err := geoDistance(u, v)
+			if err != nil {
+				return err
+			}
+			if d > radius {
+				continue
+			}
+			e := g.NewEdge(u, v).(*Edge)
+			e.SetAttribute(encoding.Attribute{Key: "distance", Value: fmt.Sprint(d)})
+		}
+	}
+	return nil
+}
+
+// geoDistance returns the great circle distance in kilometres between
+// u and v using the haversine formula.
+func geoDistance(u, v *Node) (float64, error) {
+	lat1, lon1, err := latLon(u)
+	if err != nil {
+		return 0, err
+	}
+	lat2, lon2, err := latLon(v)
+	if err != nil {
+		return 0, err
+	}
+	const rad = math.Pi / 180
+	dLat := (lat2 - lat1) * rad / 2
+	dLon := (lon2 - lon1) * rad / 2
+	h := math.Sin(dLat)*math.Sin(dLat) + math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon)*math.Sin(dLon)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h))), nil
+}
+
+// latLon returns the latitude and longitude of n held in its "lat"
+// and "lon" attributes.
+func latLon(n *Node) (lat, lon float64, err error) {
+	lat, err = strconv.ParseFloat(n.GetUnquoted("lat"), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid latitude for node %q: %v", n.Name, err)
+	}
+	lon, err = strconv.ParseFloat(n.GetUnquoted("lon"), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid longitude for node %q: %v", n.Name, err)
+	}
+	return lat, lon, nil
+}
+
+func floatRange(v []float64) (min, max float64) {
+	min, max = math.Inf(1), math.Inf(-1)
+	for _, f := range v {
+		min = math.Min(min, f)
+		max = math.Max(max, f)
+	}
+	return min, max
+}
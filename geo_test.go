@@ -0,0 +1,80 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestGeoDistance(t *testing.T) {
+	g := graphFromDOT(t, `graph {
+	a [lat=0, lon=0];
+	b [lat=0, lon=1];
+	c [lat=90, lon=0];
+	a -- b; a -- c;
+}`)
+	err := GeoDistance(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, test := range []struct {
+		u, v string
+		want float64
+	}{
+		{u: "a", v: "b", want: 2 * math.Pi * earthRadius / 360},
+		{u: "a", v: "c", want: math.Pi * earthRadius / 2},
+	} {
+		u, v := g.NodeNamed(test.u), g.NodeNamed(test.v)
+		got, err := strconv.ParseFloat(g.EdgeBetween(u.ID(), v.ID()).(*Edge).Get("distance"), 64)
+		if err != nil {
+			t.Errorf("failed to parse distance for %s--%s: %v", test.u, test.v, err)
+			continue
+		}
+		if math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("unexpected distance for %s--%s: got:%v want:%v", test.u, test.v, got, test.want)
+		}
+	}
+}
+
+func TestGeoConnect(t *testing.T) {
+	g := graphFromDOT(t, `graph { a [lat=0, lon=0]; b [lat=0, lon=1]; c [lat=0, lon=3] }`)
+	err := GeoConnect(g, 200)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a, b, c := g.NodeNamed("a"), g.NodeNamed("b"), g.NodeNamed("c")
+	if !g.HasEdgeBetween(a.ID(), b.ID()) {
+		t.Error("expected edge between a and b")
+	}
+	if g.HasEdgeBetween(a.ID(), c.ID()) || g.HasEdgeBetween(b.ID(), c.ID()) {
+		t.Error("unexpected edge to c")
+	}
+}
+
+func TestGeoLayout(t *testing.T) {
+	for _, projection := range []Projection{Equirectangular, Mercator} {
+		g := graphFromDOT(t, `graph { a [lat=0, lon=0]; b [lat=0, lon=1]; c [lat=0, lon=2] }`)
+		err := GeoLayout(g, projection)
+		if err != nil {
+			t.Errorf("unexpected error for projection %d: %v", projection, err)
+			continue
+		}
+		for name, want := range map[string]string{
+			"a": `"0.00,0.00!"`,
+			"b": `"360.00,0.00!"`,
+			"c": `"720.00,0.00!"`,
+		} {
+			if got := g.NodeNamed(name).Get("pos"); got != want {
+				t.Errorf("unexpected position of %s for projection %d: got:%s want:%s", name, projection, got, want)
+			}
+		}
+	}
+	g := graphFromDOT(t, `graph { a [lat=0] }`)
+	if err := GeoLayout(g, Mercator); err == nil {
+		t.Error("expected error for missing longitude")
+	}
+}
@@ -2,4 +2,12 @@ module github.com/kortschak/graphprac
 
 go 1.13
 
-require gonum.org/v1/gonum v0.0.0-20191013192725-1459092b91f2
+require (
+	github.com/mattn/go-sqlite3 v1.14.14
+	github.com/xitongsys/parquet-go v1.6.2
+	github.com/xitongsys/parquet-go-source v0.0.0-20200817004010-026bad9b25d0
+	golang.org/x/exp v0.0.0-20200224162631-6cc2880d07d6
+	gonum.org/v1/gonum v0.0.0-20191013192725-1459092b91f2
+	gonum.org/v1/plot v0.0.0-20190515093506-e2840ee46a6b
+	google.golang.org/protobuf v1.31.0
+)
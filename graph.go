@@ -7,13 +7,11 @@ package graphprac
 import (
 	"bytes"
 	"fmt"
-	"io/ioutil"
-	"os/exec"
+	"io"
 	"strings"
 
 	"gonum.org/v1/gonum/graph"
 	"gonum.org/v1/gonum/graph/encoding"
-	"gonum.org/v1/gonum/graph/encoding/dot"
 	"gonum.org/v1/gonum/graph/iterator"
 	"gonum.org/v1/gonum/graph/simple"
 )
@@ -22,23 +20,35 @@ import (
 type Graph struct {
 	*simple.UndirectedGraph
 	GraphAttrs, NodeAttrs, EdgeAttrs Attributes
+
+	// Name is the DOT ID of the graph and
+	// Strict is whether it is a strict graph.
+	Name   string
+	Strict bool
+
+	// analysisState holds the provenance
+	// of attributes written by analyses
+	// and the last refusal to overwrite
+	// an attribute.
+	analysisState
+
+	// subscribers holds the functions
+	// registered by Subscribe.
+	subscribers    map[int]func(Event)
+	nextSubscriber int
+
+	// views holds the views defined
+	// by DefineView.
+	views map[string]*view
+
+	// clusters holds the DOT cluster
+	// hierarchy read with the graph.
+	clusters []*clusterSpec
 }
 
 // ReadGraph reads a DOT file and returns the encoded graph.
 func NewGraph(file string) (*Graph, error) {
-	b, err := ioutil.ReadFile(file)
-	if err != nil {
-		return nil, err
-	}
-
-	g := &Graph{UndirectedGraph: simple.NewUndirectedGraph()}
-
-	err = dot.Unmarshal(b, g)
-	if err != nil {
-		return nil, err
-	}
-
-	return g, nil
+	return NewGraphWithOptions(file, LoadOptions{})
 }
 
 // NewNode adds a new node with a unique node ID to the graph.
@@ -57,11 +67,21 @@ func (g *Graph) NewEdge(from, to graph.Node) graph.Edge {
 	return e
 }
 
+// SetDOTID sets the graph's DOT ID.
+func (g *Graph) SetDOTID(id string) {
+	g.Name = id
+}
+
 // DOTAttributers returns the global DOT attributes for the graph.
 func (g *Graph) DOTAttributers() (graph, node, edge encoding.Attributer) {
 	return g.GraphAttrs, g.NodeAttrs, g.EdgeAttrs
 }
 
+// graphAttributes returns the graph attributes of g.
+func (g *Graph) graphAttributes() *Attributes {
+	return &g.GraphAttrs
+}
+
 // NodeMap returns a mapping of ID integers to nodes in the graph.
 func (g *Graph) NodeMap() map[int64]*Node {
 	nodes := make(map[int64]*Node)
@@ -71,11 +91,26 @@ func (g *Graph) NodeMap() map[int64]*Node {
 	return nodes
 }
 
+// NodeNamed returns the node in g with the given DOT ID, or nil if there
+// is no such node.
+func (g *Graph) NodeNamed(name string) *Node {
+	for _, n := range graph.NodesOf(g.Nodes()) {
+		if n := n.(*Node); n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
+
 // Node is a graph node able to handle DOT attributes.
 type Node struct {
 	NodeID int64
 	Name   string
 	Attributes
+
+	// graph is the graph the node was
+	// last added to.
+	graph *Graph
 }
 
 // ID returns the ID of a node.
@@ -104,8 +139,17 @@ func (e *Edge) From() graph.Node { return e.F }
 func (e *Edge) To() graph.Node { return e.T }
 
 // ReversedEdge returns a copy of the edge with the 'from' and 'to'
-// nodes swapped.
-func (e *Edge) ReversedEdge() graph.Edge { e.F, e.T = e.T, e.F; return e }
+// nodes swapped. The "tailport" and "headport" attributes are swapped
+// with the nodes so that each port remains with its node.
+func (e *Edge) ReversedEdge() graph.Edge {
+	e.F, e.T = e.T, e.F
+	tail, head := e.Get("tailport"), e.Get("headport")
+	if tail != head {
+		e.Attributes.SetAttribute(encoding.Attribute{Key: "tailport", Value: head})
+		e.Attributes.SetAttribute(encoding.Attribute{Key: "headport", Value: tail})
+	}
+	return e
+}
 
 // Attributes is a type to help handle DOT attributes.
 type Attributes []encoding.Attribute
@@ -131,6 +175,12 @@ func (a Attributes) Attributes() []encoding.Attribute {
 func (a *Attributes) SetAttribute(attr encoding.Attribute) error {
 	for i, kv := range *a {
 		if kv.Key == attr.Key {
+			// Attributes without spare capacity may be
+			// shared with a Checkpoint, so copy before
+			// modifying in place.
+			if len(*a) == cap(*a) {
+				*a = append(make(Attributes, 0, len(*a)+1), *a...)
+			}
 			if attr.Value != "" {
 				(*a)[i].Value = attr.Value
 			} else {
@@ -143,6 +193,36 @@ func (a *Attributes) SetAttribute(attr encoding.Attribute) error {
 	return nil
 }
 
+// GetUnquoted returns the value of the given attribute with any DOT string
+// quoting removed. Escaped quotes and backslashes are unescaped, and other
+// backslash escapes, such as the GraphViz line break escapes, are retained.
+// If the attribute is not set, the empty string is returned.
+func (a Attributes) GetUnquoted(attr string) string {
+	v := a.Get(attr)
+	if len(v) < 2 || v[0] != '"' || v[len(v)-1] != '"' {
+		return v
+	}
+	return dotUnescaper.Replace(v[1 : len(v)-1])
+}
+
+// SetQuoted sets the given attribute to the specified value quoted as a DOT
+// string. Unlike SetAttribute, an empty value is retained as an empty DOT
+// string.
+func (a *Attributes) SetQuoted(attr, value string) error {
+	return a.SetAttribute(encoding.Attribute{Key: attr, Value: quoteDOT(value)})
+}
+
+var (
+	dotEscaper   = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	dotUnescaper = strings.NewReplacer(`\\`, `\`, `\"`, `"`)
+)
+
+// quoteDOT returns s as a quoted DOT string, with quotes and backslashes
+// escaped.
+func quoteDOT(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
 // DOTAttributes returns the DOT attributes for the receiver.
 func (a Attributes) DOTAttributes() []encoding.Attribute { return []encoding.Attribute(a) }
 
@@ -225,29 +305,53 @@ func (g nodeInducedGraph) EdgeBetween(xid, yid int64) graph.Edge {
 	return g.Graph.EdgeBetween(xid, yid)
 }
 
-// DOT renders the graph as a DOT language representation.
+// DOT renders the graph as a DOT language representation using the
+// default DOTOptions.
 func DOT(g graph.Graph) string {
-	b, _ := dot.Marshal(g, "", "", "  ")
-	return string(b)
+	return DOTWithOptions(g, DOTOptions{})
 }
 
 // Draw renders the graph as an SVG using the GraphViz command in format.
 // The format parameter can be one of "dot", "neato", "fdp" and "sfdp".
 // See https://www.graphviz.org/ for a description of these commands.
 func Draw(g graph.Graph, format string) (string, error) {
-	switch format {
+	return DrawWithOptions(g, DrawOptions{Engine: format})
+}
+
+// DrawOptions controls how graphs are rendered by GraphViz.
+type DrawOptions struct {
+	// Engine is the GraphViz layout command. It can be
+	// one of "dot", "neato", "fdp" and "sfdp".
+	Engine string
+
+	// Format is the GraphViz output format, for
+	// example "svg" or "png". The default is "svg".
+	Format string
+
+	// DOT controls the DOT representation of the graph
+	// passed to GraphViz. Excluding long attributes that
+	// are not used for rendering reduces layout time.
+	DOT DOTOptions
+}
+
+// DrawWithOptions renders the graph as an SVG using GraphViz with the
+// provided options.
+func DrawWithOptions(g graph.Graph, opts DrawOptions) (string, error) {
+	var buf bytes.Buffer
+	err := DrawTo(&buf, g, opts)
+	return buf.String(), err
+}
+
+// DrawTo renders the graph using GraphViz with the provided options,
+// writing the output to w.
+func DrawTo(w io.Writer, g graph.Graph, opts DrawOptions) error {
+	switch opts.Engine {
 	case "dot", "neato", "fdp", "sfdp":
 	default:
-		return "", fmt.Errorf("invalid format: %q", format)
+		return fmt.Errorf("invalid format: %q", opts.Engine)
 	}
-	path, err := exec.LookPath(format)
-	if err != nil {
-		return "", err
+	if opts.Format == "" {
+		opts.Format = "svg"
 	}
-	cmd := exec.Command(path, "-Tsvg", "-Gsize=10!")
-	cmd.Stdin = strings.NewReader(DOT(g))
-	var buf bytes.Buffer
-	cmd.Stdout = &buf
-	err = cmd.Run()
-	return buf.String(), err
+	return graphviz(w, opts.Engine, opts.Format, DOTWithOptions(g, opts.DOT))
 }
@@ -0,0 +1,81 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// graphFromDOT returns the graph described by the DOT in src, failing
+// the test if it cannot be read.
+func graphFromDOT(t *testing.T, src string) *Graph {
+	t.Helper()
+	g, err := ReadDOT(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("failed to read graph: %v", err)
+	}
+	return g
+}
+
+// floatAttr returns the float64 value of the attribute of the node
+// with the given name, failing the test if it cannot be parsed.
+func floatAttr(t *testing.T, g *Graph, name, attr string) float64 {
+	t.Helper()
+	n := g.NodeNamed(name)
+	if n == nil {
+		t.Fatalf("no node named %q", name)
+	}
+	v, err := strconv.ParseFloat(n.GetUnquoted(attr), 64)
+	if err != nil {
+		t.Fatalf("failed to parse %s of %s: %v", attr, name, err)
+	}
+	return v
+}
+
+var quoteTests = []struct {
+	value  string
+	quoted string
+}{
+	{value: ``, quoted: `""`},
+	{value: `plain`, quoted: `"plain"`},
+	{value: `say "hi"`, quoted: `"say \"hi\""`},
+	{value: `C:\path`, quoted: `"C:\\path"`},
+	{value: `end\`, quoted: `"end\\"`},
+	{value: `\"`, quoted: `"\\\""`},
+}
+
+func TestQuoteRoundTrip(t *testing.T) {
+	for i, test := range quoteTests {
+		var a Attributes
+		a.SetQuoted("k", test.value)
+		if got := a.Get("k"); got != test.quoted {
+			t.Errorf("unexpected quoted value for test %d: got:%s want:%s", i, got, test.quoted)
+		}
+		if got := a.GetUnquoted("k"); got != test.value {
+			t.Errorf("unexpected unquoted value for test %d: got:%s want:%s", i, got, test.value)
+		}
+	}
+}
+
+var unquoteTests = []struct {
+	raw  string
+	want string
+}{
+	{raw: `bare`, want: `bare`},
+	{raw: `"line\nbreak"`, want: `line\nbreak`},
+	{raw: `"a\\b"`, want: `a\b`},
+	{raw: `"`, want: `"`},
+}
+
+func TestGetUnquoted(t *testing.T) {
+	for i, test := range unquoteTests {
+		a := Attributes{{Key: "k", Value: test.raw}}
+		if got := a.GetUnquoted("k"); got != test.want {
+			t.Errorf("unexpected result for test %d: got:%s want:%s", i, got, test.want)
+		}
+	}
+}
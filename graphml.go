@@ -0,0 +1,342 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// graphMLDoc is a GraphML document.
+type graphMLDoc struct {
+	XMLName xml.Name       `xml:"graphml"`
+	Xmlns   string         `xml:"xmlns,attr,omitempty"`
+	Keys    []graphMLKey   `xml:"key"`
+	Graphs  []graphMLGraph `xml:"graph"`
+}
+
+// graphMLKey is a GraphML attribute declaration.
+type graphMLKey struct {
+	ID      string  `xml:"id,attr"`
+	For     string  `xml:"for,attr"`
+	Name    string  `xml:"attr.name,attr,omitempty"`
+	Type    string  `xml:"attr.type,attr,omitempty"`
+	Default *string `xml:"default,omitempty"`
+
+	// YType is the yfiles.type of yEd keys.
+	YType string `xml:"yfiles.type,attr,omitempty"`
+}
+
+// graphMLGraph is a GraphML graph element.
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr,omitempty"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Data        []graphMLData `xml:"data"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+// graphMLNode is a GraphML node element.
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+// graphMLEdge is a GraphML edge element.
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+// graphMLData is a GraphML attribute value. The labels of yEd node and
+// edge graphics are collected from the elements held by the data.
+type graphMLData struct {
+	Key  string `xml:"key,attr"`
+	Text string `xml:",chardata"`
+
+	Graphics []struct {
+		NodeLabels []string `xml:"NodeLabel"`
+		EdgeLabels []string `xml:"EdgeLabel"`
+	} `xml:",any"`
+}
+
+// value returns the value held by d, or the first yEd label if d holds
+// only graphics elements.
+func (d graphMLData) value() string {
+	if v := strings.TrimSpace(d.Text); v != "" {
+		return v
+	}
+	for _, g := range d.Graphics {
+		for _, l := range append(g.NodeLabels, g.EdgeLabels...) {
+			if l = strings.TrimSpace(l); l != "" {
+				return l
+			}
+		}
+	}
+	return ""
+}
+
+// NewGraphFromGraphML reads a GraphML file, such as those exported by
+// Cytoscape and yEd, and returns the encoded graph.
+//
+// The id of each node is used as its DOT ID, and the data of the graph,
+// its nodes and its edges are held in the GraphAttrs field of the graph
+// and the attributes of the nodes and edges, named by the attr.name of
+// their keys, or the key id if it has no name. Values that are not
+// numbers are held as quoted DOT strings. Key defaults for nodes and edges
+// are held in the NodeAttrs and EdgeAttrs fields of the graph, in the same
+// way as global attributes of DOT graphs. The labels of yEd node and edge
+// graphics are held in the "label" attribute. Edge directions are ignored,
+// self edges are discarded and the data of repeated edges are merged, with
+// later values replacing earlier ones. Only the first graph of the file is
+// read, and nested graphs and hyperedges are ignored.
+func NewGraphFromGraphML(file string) (*Graph, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadGraphML(f)
+}
+
+// ReadGraphML reads GraphML data from r and returns the encoded graph, as
+// described for NewGraphFromGraphML.
+func ReadGraphML(r io.Reader) (*Graph, error) {
+	var doc graphMLDoc
+	err := xml.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Graphs) == 0 {
+		return nil, fmt.Errorf("no graph in GraphML data")
+	}
+	src := doc.Graphs[0]
+
+	g := &Graph{UndirectedGraph: simple.NewUndirectedGraph(), Name: src.ID}
+	names := make(map[string]string, len(doc.Keys))
+	for _, k := range doc.Keys {
+		name := k.Name
+		switch {
+		case name != "":
+		case k.YType == "nodegraphics", k.YType == "edgegraphics":
+			name = "label"
+		default:
+			name = k.ID
+		}
+		names[k.ID] = name
+		if k.Default == nil {
+			continue
+		}
+		attr := encoding.Attribute{Key: name, Value: graphMLValue(strings.TrimSpace(*k.Default))}
+		switch k.For {
+		case "node":
+			g.NodeAttrs.SetAttribute(attr)
+		case "edge":
+			g.EdgeAttrs.SetAttribute(attr)
+		}
+	}
+	setData := func(a *Attributes, data []graphMLData) error {
+		for _, d := range data {
+			name, ok := names[d.Key]
+			if !ok {
+				return fmt.Errorf("undeclared GraphML key %q", d.Key)
+			}
+			a.SetAttribute(encoding.Attribute{Key: name, Value: graphMLValue(d.value())})
+		}
+		return nil
+	}
+	err = setData(&g.GraphAttrs, src.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*Node, len(src.Nodes))
+	for _, sn := range src.Nodes {
+		if _, ok := nodes[sn.ID]; ok {
+			return nil, fmt.Errorf("duplicate GraphML node %q", sn.ID)
+		}
+		n := &Node{NodeID: int64(len(nodes)), Name: sn.ID}
+		err = setData(&n.Attributes, sn.Data)
+		if err != nil {
+			return nil, err
+		}
+		g.AddNode(n)
+		nodes[sn.ID] = n
+	}
+	for _, se := range src.Edges {
+		u, ok := nodes[se.Source]
+		if !ok {
+			return nil, fmt.Errorf("edge from unknown GraphML node %q", se.Source)
+		}
+		v, ok := nodes[se.Target]
+		if !ok {
+			return nil, fmt.Errorf("edge to unknown GraphML node %q", se.Target)
+		}
+		if u == v {
+			continue
+		}
+		e := g.NewEdge(u, v).(*Edge)
+		err = setData(&e.Attributes, se.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// graphMLValue returns the GraphML value s as a DOT attribute value,
+// quoting it unless it is a number.
+func graphMLValue(s string) string {
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return s
+	}
+	return quoteDOT(s)
+}
+
+// WriteGraphML writes g to w as GraphML. The DOT ID of each node is
+// written as its id, and the graph, node and edge attributes of g are
+// written as data with keys named by the attribute. Keys are declared as
+// doubles if every value of the attribute is a number, and as strings
+// otherwise, with any DOT quoting removed. The NodeAttrs and EdgeAttrs of
+// g are written as the defaults of their keys. Nodes are written in node
+// ID order and edges in order of the node IDs of their ends.
+func WriteGraphML(g *Graph, w io.Writer) error {
+	nodes := NodesOf(g)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID() < nodes[j].ID() })
+	var edges []*Edge
+	for _, e := range graph.EdgesOf(g.Edges()) {
+		e := e.(*Edge)
+		if e.F.ID() > e.T.ID() {
+			e = &Edge{F: e.T, T: e.F, Attributes: e.Attributes}
+		}
+		edges = append(edges, e)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		return a.F.ID() < b.F.ID() || (a.F.ID() == b.F.ID() && a.T.ID() < b.T.ID())
+	})
+
+	keys := newGraphMLKeys()
+	keys.add("graph", exportAttributes(g.GraphAttrs))
+	keys.add("node", exportAttributes(g.NodeAttrs))
+	keys.add("edge", exportAttributes(g.EdgeAttrs))
+	for _, n := range nodes {
+		keys.add("node", exportAttributes(n.Attributes))
+	}
+	for _, e := range edges {
+		keys.add("edge", exportAttributes(e.Attributes))
+	}
+
+	doc := graphMLDoc{Xmlns: "http://graphml.graphdrawing.org/xmlns"}
+	doc.Keys = keys.declare(exportAttributes(g.NodeAttrs), exportAttributes(g.EdgeAttrs))
+	dst := graphMLGraph{
+		ID:          g.Name,
+		EdgeDefault: "undirected",
+		Data:        keys.data("graph", exportAttributes(g.GraphAttrs)),
+	}
+	for _, n := range nodes {
+		dst.Nodes = append(dst.Nodes, graphMLNode{ID: n.Name, Data: keys.data("node", exportAttributes(n.Attributes))})
+	}
+	for _, e := range edges {
+		dst.Edges = append(dst.Edges, graphMLEdge{
+			Source: e.F.Name,
+			Target: e.T.Name,
+			Data:   keys.data("edge", exportAttributes(e.Attributes)),
+		})
+	}
+	doc.Graphs = []graphMLGraph{dst}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	enc := xml.NewEncoder(bw)
+	enc.Indent("", "  ")
+	err := enc.Encode(doc)
+	if err != nil {
+		return err
+	}
+	bw.WriteString("\n")
+	return bw.Flush()
+}
+
+// graphMLKeys collects the attribute keys written by WriteGraphML and
+// whether all their values are numeric.
+type graphMLKeys struct {
+	numeric map[[2]string]bool
+	ids     map[[2]string]string
+}
+
+func newGraphMLKeys() *graphMLKeys {
+	return &graphMLKeys{numeric: make(map[[2]string]bool)}
+}
+
+// add records the attributes of a GraphML element of the given kind.
+func (k *graphMLKeys) add(kind string, a Attributes) {
+	for _, kv := range a {
+		key := [2]string{kind, kv.Key}
+		_, err := strconv.ParseFloat(a.GetUnquoted(kv.Key), 64)
+		isNum, seen := k.numeric[key]
+		k.numeric[key] = err == nil && (isNum || !seen)
+	}
+}
+
+// declare returns the GraphML key declarations, ordered by kind and then
+// name, with the defaults of node and edge keys given by nodeAttrs and
+// edgeAttrs.
+func (k *graphMLKeys) declare(nodeAttrs, edgeAttrs Attributes) []graphMLKey {
+	order := map[string]int{"graph": 0, "node": 1, "edge": 2}
+	all := make([][2]string, 0, len(k.numeric))
+	for key := range k.numeric {
+		all = append(all, key)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i][0] != all[j][0] {
+			return order[all[i][0]] < order[all[j][0]]
+		}
+		return all[i][1] < all[j][1]
+	})
+	k.ids = make(map[[2]string]string, len(all))
+	decls := make([]graphMLKey, len(all))
+	for i, key := range all {
+		id := fmt.Sprintf("d%d", i)
+		k.ids[key] = id
+		typ := "string"
+		if k.numeric[key] {
+			typ = "double"
+		}
+		decls[i] = graphMLKey{ID: id, For: key[0], Name: key[1], Type: typ}
+		var defaults Attributes
+		switch key[0] {
+		case "node":
+			defaults = nodeAttrs
+		case "edge":
+			defaults = edgeAttrs
+		}
+		if v := defaults.GetUnquoted(key[1]); v != "" {
+			decls[i].Default = &v
+		}
+	}
+	return decls
+}
+
+// data returns the GraphML data for the attributes of an element of the
+// given kind.
+func (k *graphMLKeys) data(kind string, a Attributes) []graphMLData {
+	var d []graphMLData
+	for _, kv := range a {
+		d = append(d, graphMLData{Key: k.ids[[2]string{kind, kv.Key}], Text: a.GetUnquoted(kv.Key)})
+	}
+	return d
+}
@@ -0,0 +1,170 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"strings"
+	"testing"
+)
+
+const yEdGraphML = `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:y="http://www.yworks.com/xml/graphml">
+  <key id="g0" for="graph" attr.name="title" attr.type="string"/>
+  <key id="d0" for="node" attr.name="rank" attr.type="double"><default>1</default></key>
+  <key id="d1" for="node" yfiles.type="nodegraphics"/>
+  <key id="d2" for="edge" attr.name="weight" attr.type="double"/>
+  <key id="w" for="edge"/>
+  <graph id="G" edgedefault="directed">
+    <data key="g0">test graph</data>
+    <node id="a"><data key="d0">2</data><data key="d1"><y:ShapeNode><y:NodeLabel>Alpha</y:NodeLabel></y:ShapeNode></data></node>
+    <node id="b"/>
+    <node id="c"><data key="d0">3.5</data></node>
+    <edge source="a" target="b"><data key="d2">1</data></edge>
+    <edge source="b" target="a"><data key="d2">4</data><data key="w">x</data></edge>
+    <edge source="b" target="c"/>
+    <edge source="c" target="c"/>
+  </graph>
+  <graph id="H" edgedefault="undirected">
+    <node id="z"/>
+  </graph>
+</graphml>
+`
+
+func TestReadGraphML(t *testing.T) {
+	g, err := ReadGraphML(strings.NewReader(yEdGraphML))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `graph G {
+  graph [
+    title="test graph"
+  ];
+  node [
+    rank=1
+  ];
+
+  // Node definitions.
+  a [
+    label="Alpha"
+    rank=2
+  ];
+  b;
+  c [rank=3.5];
+
+  // Edge definitions.
+  a -- b [
+    w="x"
+    weight=4
+  ];
+  b -- c;
+}`
+	got := DOTWithOptions(g, DOTOptions{SortAttributes: true})
+	if got != want {
+		t.Errorf("unexpected graph:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteGraphML(t *testing.T) {
+	g := graphFromDOT(t, `graph G {
+	b [label="B node"];
+	a [rank=2];
+	b -- a [weight=4];
+	a -- c [weight=high];
+}`)
+	g.NodeAttrs = Attributes{{Key: "rank", Value: "1"}}
+	var buf strings.Builder
+	if err := WriteGraphML(g, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="node" attr.name="label" attr.type="string"></key>
+  <key id="d1" for="node" attr.name="rank" attr.type="double">
+    <default>1</default>
+  </key>
+  <key id="d2" for="edge" attr.name="weight" attr.type="string"></key>
+  <graph id="G" edgedefault="undirected">
+    <node id="b">
+      <data key="d0">B node</data>
+    </node>
+    <node id="a">
+      <data key="d1">2</data>
+    </node>
+    <node id="c"></node>
+    <edge source="b" target="a">
+      <data key="d2">4</data>
+    </edge>
+    <edge source="a" target="c">
+      <data key="d2">high</data>
+    </edge>
+  </graph>
+</graphml>
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected GraphML:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+var graphMLRoundTripTests = []struct {
+	src               string
+	graph, node, edge Attributes
+}{
+	{src: `graph { a -- b; }`},
+	{
+		src: `graph G {
+	a [label="<A>", rank=0.5];
+	b;
+	c [label="C \"x\""];
+	a -- b [weight=2];
+	b -- c [note="n & m"];
+}`,
+		graph: Attributes{{Key: "title", Value: `"a & b"`}},
+		node:  Attributes{{Key: "rank", Value: "1"}},
+		edge:  Attributes{{Key: "weight", Value: "1"}},
+	},
+}
+
+func TestGraphMLRoundTrip(t *testing.T) {
+	for i, test := range graphMLRoundTripTests {
+		g := graphFromDOT(t, test.src)
+		g.GraphAttrs, g.NodeAttrs, g.EdgeAttrs = test.graph, test.node, test.edge
+		var buf strings.Builder
+		if err := WriteGraphML(g, &buf); err != nil {
+			t.Errorf("unexpected error writing test %d: %v", i, err)
+			continue
+		}
+		h, err := ReadGraphML(strings.NewReader(buf.String()))
+		if err != nil {
+			t.Errorf("unexpected error reading test %d: %v", i, err)
+			continue
+		}
+		got := DOTWithOptions(h, DOTOptions{SortAttributes: true})
+		want := DOTWithOptions(g, DOTOptions{SortAttributes: true})
+		if got != want {
+			t.Errorf("unexpected round trip for test %d:\ngot:\n%s\nwant:\n%s", i, got, want)
+		}
+	}
+}
+
+var readGraphMLErrorTests = []struct {
+	name string
+	src  string
+}{
+	{name: "syntax", src: `<graphml><graph>`},
+	{name: "no graph", src: `<graphml></graphml>`},
+	{name: "undeclared key", src: `<graphml><graph><node id="a"><data key="d0">1</data></node></graph></graphml>`},
+	{name: "duplicate node", src: `<graphml><graph><node id="a"/><node id="a"/></graph></graphml>`},
+	{name: "unknown source", src: `<graphml><graph><node id="a"/><edge source="b" target="a"/></graph></graphml>`},
+	{name: "unknown target", src: `<graphml><graph><node id="a"/><edge source="a" target="b"/></graph></graphml>`},
+}
+
+func TestReadGraphMLErrors(t *testing.T) {
+	for _, test := range readGraphMLErrorTests {
+		_, err := ReadGraphML(strings.NewReader(test.src))
+		if err == nil {
+			t.Errorf("expected error for %s", test.name)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// WriteGraphSON writes g to w in the GraphSON 3.0 adjacency list format
+// read by TinkerPop's GraphSONReader, for example with
+//
+//	graph.io(graphson()).readGraph("graph.json")
+//
+// in the Gremlin console. Each line holds a vertex with its incident
+// edges, and vertices are written in DOT ID order. Vertices have the
+// label "vertex" and the IDs of the nodes of g, and each undirected edge
+// of g is written as a single edge labelled "edge", out of the end with
+// the DOT ID that sorts first. The DOT ID of each node is written as its
+// "name" property, taking precedence over any "name" attribute, and node
+// and edge attributes are written as properties, with numerical values
+// written as doubles and other values as strings with any DOT quoting
+// removed.
+func WriteGraphSON(g *Graph, w io.Writer) error {
+	x := g.Index()
+	nodes := make([]*Node, x.Len())
+	for i := range nodes {
+		nodes[i] = x.Node(i)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	rank := make(map[int64]int, len(nodes))
+	for i, n := range nodes {
+		rank[n.ID()] = i
+	}
+
+	// Edges are numbered in order of their
+	// out vertex and then their in vertex.
+	outE := make([][]*Node, len(nodes))
+	for i, u := range nodes {
+		for _, v := range graph.NodesOf(g.From(u.ID())) {
+			if rank[v.ID()] > i {
+				outE[i] = append(outE[i], v.(*Node))
+			}
+		}
+		sort.Slice(outE[i], func(a, b int) bool { return rank[outE[i][a].ID()] < rank[outE[i][b].ID()] })
+	}
+	edgeID := make(map[[2]int64]int64)
+	var nextEdge int64
+	for i, u := range nodes {
+		for _, v := range outE[i] {
+			edgeID[[2]int64{u.ID(), v.ID()}] = nextEdge
+			nextEdge++
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	nextProperty := nextEdge
+	for i, u := range nodes {
+		vtx := graphSONVertex{
+			ID:         graphSONInt64(u.ID()),
+			Label:      "vertex",
+			Properties: make(map[string][]graphSONVertexProperty),
+		}
+		vtx.Properties["name"] = []graphSONVertexProperty{{ID: graphSONInt64(nextProperty), Value: u.Name}}
+		nextProperty++
+		attrs := exportAttributes(u.Attributes)
+		for _, kv := range attrs {
+			if kv.Key == "name" {
+				continue
+			}
+			vtx.Properties[kv.Key] = []graphSONVertexProperty{{ID: graphSONInt64(nextProperty), Value: graphSONProperty(attrs.GetUnquoted(kv.Key))}}
+			nextProperty++
+		}
+
+		for _, v := range graph.NodesOf(g.From(u.ID())) {
+			e := g.Edge(u.ID(), v.ID()).(*Edge)
+			var props map[string]interface{}
+			if attrs := exportAttributes(e.Attributes); len(attrs) != 0 {
+				props = make(map[string]interface{}, len(attrs))
+				for _, kv := range attrs {
+					props[kv.Key] = graphSONProperty(attrs.GetUnquoted(kv.Key))
+				}
+			}
+			other := graphSONInt64(v.ID())
+			if rank[v.ID()] > i {
+				vtx.OutE = append(vtx.OutE, graphSONEdge{ID: graphSONInt64(edgeID[[2]int64{u.ID(), v.ID()}]), InV: &other, Properties: props})
+			} else {
+				vtx.InE = append(vtx.InE, graphSONEdge{ID: graphSONInt64(edgeID[[2]int64{v.ID(), u.ID()}]), OutV: &other, Properties: props})
+			}
+		}
+		sort.Slice(vtx.OutE, func(a, b int) bool { return vtx.OutE[a].ID.Value.(int64) < vtx.OutE[b].ID.Value.(int64) })
+		sort.Slice(vtx.InE, func(a, b int) bool { return vtx.InE[a].ID.Value.(int64) < vtx.InE[b].ID.Value.(int64) })
+
+		err := enc.Encode(vtx)
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// graphSONVertex is a vertex in the GraphSON adjacency list format.
+type graphSONVertex struct {
+	ID         graphSONValue                       `json:"id"`
+	Label      string                              `json:"label"`
+	OutE       graphSONEdges                       `json:"outE,omitempty"`
+	InE        graphSONEdges                       `json:"inE,omitempty"`
+	Properties map[string][]graphSONVertexProperty `json:"properties,omitempty"`
+}
+
+// graphSONEdges is a list of edges incident to a vertex, all labelled
+// "edge".
+type graphSONEdges []graphSONEdge
+
+func (e graphSONEdges) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string][]graphSONEdge{"edge": e})
+}
+
+// graphSONEdge is an edge incident to a vertex, identified by the vertex
+// at its other end.
+type graphSONEdge struct {
+	ID         graphSONValue          `json:"id"`
+	InV        *graphSONValue         `json:"inV,omitempty"`
+	OutV       *graphSONValue         `json:"outV,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// graphSONVertexProperty is a property of a vertex.
+type graphSONVertexProperty struct {
+	ID    graphSONValue `json:"id"`
+	Value interface{}   `json:"value"`
+}
+
+// graphSONValue is a GraphSON typed value.
+type graphSONValue struct {
+	Type  string      `json:"@type"`
+	Value interface{} `json:"@value"`
+}
+
+func graphSONInt64(v int64) graphSONValue {
+	return graphSONValue{Type: "g:Int64", Value: v}
+}
+
+// graphSONProperty returns s as a GraphSON double if it is a finite
+// number, and as a string otherwise.
+func graphSONProperty(s string) interface{} {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return s
+	}
+	return graphSONValue{Type: "g:Double", Value: v}
+}
@@ -0,0 +1,151 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestWriteGraphSONEdge(t *testing.T) {
+	g := graphFromDOT(t, `graph { b -- a [w=2] }`)
+	var buf strings.Builder
+	if err := WriteGraphSON(g, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"id":{"@type":"g:Int64","@value":1},"label":"vertex","outE":{"edge":[{"id":{"@type":"g:Int64","@value":0},"inV":{"@type":"g:Int64","@value":0},"properties":{"w":{"@type":"g:Double","@value":2}}}]},"properties":{"name":[{"id":{"@type":"g:Int64","@value":1},"value":"a"}]}}
+{"id":{"@type":"g:Int64","@value":0},"label":"vertex","inE":{"edge":[{"id":{"@type":"g:Int64","@value":0},"outV":{"@type":"g:Int64","@value":1},"properties":{"w":{"@type":"g:Double","@value":2}}}]},"properties":{"name":[{"id":{"@type":"g:Int64","@value":2},"value":"b"}]}}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected GraphSON:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// graphSONLine is a decoded GraphSON adjacency list line.
+type graphSONLine struct {
+	ID    graphSONID `json:"id"`
+	Label string     `json:"label"`
+	OutE  map[string][]struct {
+		ID         graphSONID             `json:"id"`
+		InV        graphSONID             `json:"inV"`
+		Properties map[string]interface{} `json:"properties"`
+	} `json:"outE"`
+	InE map[string][]struct {
+		ID   graphSONID `json:"id"`
+		OutV graphSONID `json:"outV"`
+	} `json:"inE"`
+	Properties map[string][]struct {
+		ID    graphSONID  `json:"id"`
+		Value interface{} `json:"value"`
+	} `json:"properties"`
+}
+
+type graphSONID struct {
+	Type  string `json:"@type"`
+	Value int64  `json:"@value"`
+}
+
+func TestWriteGraphSON(t *testing.T) {
+	g := graphFromDOT(t, `graph {
+	c [name=other, label="C x"];
+	a [rank=0.5];
+	b;
+	d;
+	c -- a [weight=2];
+	a -- b;
+	b -- c [kind=x];
+}`)
+	var buf strings.Builder
+	if err := WriteGraphSON(g, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var (
+		names  []string
+		nameOf = make(map[int64]string)
+		out    = make(map[int64]string)
+		in     = make(map[int64]string)
+		props  = make(map[string]interface{})
+		ids    = make(map[int64]bool)
+	)
+	var lines []graphSONLine
+	sc := bufio.NewScanner(strings.NewReader(buf.String()))
+	for sc.Scan() {
+		var l graphSONLine
+		if err := json.Unmarshal(sc.Bytes(), &l); err != nil {
+			t.Fatalf("failed to decode line %q: %v", sc.Text(), err)
+		}
+		if l.ID.Type != "g:Int64" || l.Label != "vertex" {
+			t.Errorf("unexpected vertex header: %+v", l)
+		}
+		name := l.Properties["name"][0].Value.(string)
+		names = append(names, name)
+		nameOf[l.ID.Value] = name
+		if got := g.Node(l.ID.Value).(*Node).Name; got != name {
+			t.Errorf("unexpected vertex ID for %s: got node %s", name, got)
+		}
+		for key, p := range l.Properties {
+			if ids[p[0].ID.Value] {
+				t.Errorf("repeated property ID %d", p[0].ID.Value)
+			}
+			ids[p[0].ID.Value] = true
+			props[name+"."+key] = p[0].Value
+		}
+		lines = append(lines, l)
+	}
+	for _, l := range lines {
+		for _, e := range l.OutE["edge"] {
+			if ids[e.ID.Value] {
+				t.Errorf("repeated edge ID %d", e.ID.Value)
+			}
+			ids[e.ID.Value] = true
+			out[e.ID.Value] = nameOf[l.ID.Value] + "->" + nameOf[e.InV.Value]
+			for key, v := range e.Properties {
+				props[out[e.ID.Value]+"."+key] = v
+			}
+		}
+		for _, e := range l.InE["edge"] {
+			in[e.ID.Value] = nameOf[e.OutV.Value] + "->" + nameOf[l.ID.Value]
+		}
+	}
+
+	if want := []string{"a", "b", "c", "d"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("unexpected vertex order: got:%q want:%q", names, want)
+	}
+	wantEdges := map[int64]string{0: "a->b", 1: "a->c", 2: "b->c"}
+	if !reflect.DeepEqual(out, wantEdges) {
+		t.Errorf("unexpected out edges: got:%v want:%v", out, wantEdges)
+	}
+	if !reflect.DeepEqual(in, wantEdges) {
+		t.Errorf("unexpected in edges: got:%v want:%v", in, wantEdges)
+	}
+
+	double := func(v float64) interface{} {
+		return map[string]interface{}{"@type": "g:Double", "@value": v}
+	}
+	wantProps := map[string]interface{}{
+		"a.name":      "a",
+		"a.rank":      double(0.5),
+		"b.name":      "b",
+		"c.name":      "c",
+		"c.label":     "C x",
+		"d.name":      "d",
+		"a->c.weight": double(2),
+		"b->c.kind":   "x",
+	}
+	if !reflect.DeepEqual(props, wantProps) {
+		var keys []string
+		for k, v := range props {
+			keys = append(keys, fmt.Sprintf("%s=%v", k, v))
+		}
+		sort.Strings(keys)
+		t.Errorf("unexpected properties: got:%v", keys)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !js
+// +build !js
+
+package graphprac
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// graphviz runs the GraphViz layout command engine on the DOT graph in
+// dot, writing output in the given format to w.
+func graphviz(w io.Writer, engine, format, dot string) error {
+	path, err := exec.LookPath(engine)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(path, "-T"+format, "-Gsize=10!")
+	cmd.Stdin = strings.NewReader(dot)
+	cmd.Stdout = w
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil && stderr.Len() != 0 {
+		return fmt.Errorf("%v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return err
+}
@@ -0,0 +1,19 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js
+// +build js
+
+package graphprac
+
+import (
+	"errors"
+	"io"
+)
+
+// graphviz returns an error since GraphViz cannot be run from
+// JavaScript. DOT output should be rendered by the host instead.
+func graphviz(w io.Writer, engine, format, dot string) error {
+	return errors.New("graphviz is not available under js")
+}
@@ -0,0 +1,144 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"strconv"
+
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/topo"
+)
+
+// FlowHierarchy returns the flow hierarchy of g (Luo and Magee
+// doi:10.1002/cplx.20368), the fraction of the edges of g that are not
+// part of a directed cycle. A directed acyclic graph has a flow hierarchy
+// of 1. FlowHierarchy returns NaN if g has no edges.
+func FlowHierarchy(g *Digraph) float64 {
+	component := make(map[int64]int)
+	for i, c := range topo.TarjanSCC(g) {
+		for _, n := range c {
+			component[n.ID()] = i
+		}
+	}
+	var m, acyclic int
+	edges := g.Edges()
+	for edges.Next() {
+		e := edges.Edge()
+		m++
+		if component[e.From().ID()] != component[e.To().ID()] {
+			acyclic++
+		}
+	}
+	return float64(acyclic) / float64(m)
+}
+
+// AgonyRank assigns hierarchy levels to the nodes of g that minimise the
+// agony of g (Gupte et al. doi:10.1145/1963405.1963483) and returns the
+// minimum agony. Edges are expected to point from lower to higher levels,
+// and an edge u→v where the level of u is not lower than the level of v
+// contributes an agony of one more than the difference in their levels.
+// The levels start from zero, and the agony of a directed acyclic graph is
+// zero.
+//
+// The minimum agony is the size of the largest Eulerian subgraph of g, and
+// is found by cycle cancelling in the residual graph of a unit capacity
+// circulation, which may be slow for large graphs with many cycles.
+//
+// The level of each node is written into its "agony" attribute. If the
+// analysis refuses to overwrite the attribute, as described by
+// SetAnalysisOptions, AgonyRank returns zero.
+func AgonyRank(g *Digraph) int {
+	done, err := trace(g, "AgonyRank", []string{"agony"})
+	if err != nil {
+		return 0
+	}
+	defer done(nil)
+
+	x := g.Index()
+	out := x.adjacency(g)
+	n := x.Len()
+
+	type arc struct{ from, to int }
+	var arcs []arc
+	for i, to := range out {
+		for _, j := range to {
+			arcs = append(arcs, arc{from: i, to: j})
+		}
+	}
+
+	// An arc in the circulation has a residual edge in
+	// its reverse direction with cost +1, otherwise it
+	// has a residual edge in its own direction with
+	// cost -1.
+	inFlow := make([]bool, len(arcs))
+	residual := func(k int) (from, to, cost int) {
+		a := arcs[k]
+		if inFlow[k] {
+			return a.to, a.from, 1
+		}
+		return a.from, a.to, -1
+	}
+
+	dist := make([]int, n)
+	pred := make([]int, n)
+	var agony int
+	for {
+		// Find a negative cycle in the residual graph by
+		// Bellman-Ford from a virtual source joined to all
+		// nodes with zero cost edges.
+		for i := range dist {
+			dist[i] = 0
+			pred[i] = -1
+		}
+		last := -1
+		for iter := 0; iter < n; iter++ {
+			last = -1
+			for k := range arcs {
+				u, v, c := residual(k)
+				if dist[u]+c < dist[v] {
+					dist[v] = dist[u] + c
+					pred[v] = k
+					last = v
+				}
+			}
+			if last < 0 {
+				break
+			}
+		}
+		if last < 0 {
+			break
+		}
+
+		// Walk back into the cycle and cancel it.
+		v := last
+		for i := 0; i < n; i++ {
+			u, _, _ := residual(pred[v])
+			v = u
+		}
+		for start := v; ; {
+			k := pred[v]
+			u, _, c := residual(k)
+			agony -= c
+			inFlow[k] = !inFlow[k]
+			v = u
+			if v == start {
+				break
+			}
+		}
+	}
+
+	// The shortest path distances in the residual graph
+	// without negative cycles give the optimal levels.
+	min := 0
+	for _, d := range dist {
+		if -d < min {
+			min = -d
+		}
+	}
+	for i, d := range dist {
+		x.Node(i).SetAttribute(encoding.Attribute{Key: "agony", Value: strconv.Itoa(-d - min)})
+	}
+	return agony
+}
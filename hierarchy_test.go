@@ -0,0 +1,115 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+var flowHierarchyTests = []struct {
+	dot  string
+	want float64
+}{
+	{dot: `digraph { a -> b -> c; a -> c }`, want: 1},
+	{dot: `digraph { a -> b -> c -> a; c -> d }`, want: 0.25},
+	{dot: `digraph { a -> b -> a; b -> c; c -> d -> c }`, want: 0.2},
+	{dot: `digraph { a -> b -> a }`, want: 0},
+	{dot: `digraph { a; b }`, want: math.NaN()},
+}
+
+func TestFlowHierarchy(t *testing.T) {
+	for i, test := range flowHierarchyTests {
+		got := FlowHierarchy(digraphFromDOT(t, test.dot))
+		if got != test.want && !(math.IsNaN(got) && math.IsNaN(test.want)) {
+			t.Errorf("unexpected flow hierarchy for test %d: got:%v want:%v", i, got, test.want)
+		}
+	}
+}
+
+// agonyOf returns the agony of g with the given node levels.
+func agonyOf(g *Digraph, level map[int64]int) int {
+	var agony int
+	for _, e := range graph.EdgesOf(g.Edges()) {
+		if d := level[e.From().ID()] - level[e.To().ID()]; d >= 0 {
+			agony += d + 1
+		}
+	}
+	return agony
+}
+
+// minAgony returns the minimum agony of g over all assignments of
+// levels in [0,n) to its n nodes.
+func minAgony(g *Digraph) int {
+	nodes := graph.NodesOf(g.Nodes())
+	level := make(map[int64]int)
+	best := -1
+	var assign func(k int)
+	assign = func(k int) {
+		if k == len(nodes) {
+			if a := agonyOf(g, level); best < 0 || a < best {
+				best = a
+			}
+			return
+		}
+		for l := 0; l < len(nodes); l++ {
+			level[nodes[k].ID()] = l
+			assign(k + 1)
+		}
+	}
+	assign(0)
+	return best
+}
+
+// agonyTests holds graphs and their minimum agony, or -1 where the
+// minimum is only found by brute force.
+var agonyTests = []struct {
+	dot  string
+	want int
+}{
+	{dot: `digraph { a -> b -> c; a -> c }`, want: 0},
+	{dot: `digraph { a -> b -> a }`, want: 2},
+	{dot: `digraph { a -> b -> c -> a }`, want: 3},
+	{dot: `digraph { a -> b -> c -> a; c -> d; d -> e; e -> c }`, want: -1},
+	{dot: `digraph { a -> b -> c -> d -> a; b -> a; d -> b; a -> e }`, want: -1},
+	{dot: `digraph { a -> b; b -> c; c -> a; a -> c; c -> b; b -> a }`, want: -1},
+	{dot: `digraph { a; b }`, want: 0},
+}
+
+func TestAgonyRank(t *testing.T) {
+	for i, test := range agonyTests {
+		g := digraphFromDOT(t, test.dot)
+		want := minAgony(g)
+		if test.want >= 0 && want != test.want {
+			t.Fatalf("unexpected brute force agony for test %d: got:%d want:%d", i, want, test.want)
+		}
+		got := AgonyRank(g)
+		if got != want {
+			t.Errorf("unexpected agony for test %d: got:%d want:%d", i, got, want)
+		}
+
+		level := make(map[int64]int)
+		min := -1
+		for _, n := range g.NodeMap() {
+			l, err := strconv.Atoi(n.Get("agony"))
+			if err != nil {
+				t.Fatalf("failed to parse level of %s for test %d: %v", n.Name, i, err)
+			}
+			level[n.ID()] = l
+			if min < 0 || l < min {
+				min = l
+			}
+		}
+		if min != 0 {
+			t.Errorf("unexpected lowest level for test %d: got:%d want:0", i, min)
+		}
+		if a := agonyOf(g, level); a != got {
+			t.Errorf("unexpected agony of levels for test %d: got:%d want:%d", i, a, got)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// HighlightStyle specifies the GraphViz styling used to emphasise nodes
+// and edges.
+type HighlightStyle struct {
+	// Color is the GraphViz colour of highlighted
+	// nodes and edges. The default is "red".
+	Color string
+
+	// PenWidth is the GraphViz line width of highlighted
+	// nodes and edges. The default is 3.
+	PenWidth float64
+
+	// FillColor, if not empty, is used to fill
+	// highlighted nodes.
+	FillColor string
+}
+
+// Highlight sets the "color" and "penwidth" attributes of the nodes and
+// edges of g named by nodes and edges to emphasise them when drawn. Edges
+// are given as pairs of node DOT IDs. If style.FillColor is not empty,
+// highlighted nodes are also filled. An error is returned if a node or
+// edge is not in g; in that case no attributes are changed.
+func Highlight(g *Graph, nodes []string, edges [][2]string, style HighlightStyle) error {
+	if style.Color == "" {
+		style.Color = "red"
+	}
+	if style.PenWidth == 0 {
+		style.PenWidth = 3
+	}
+
+	var hn []*Node
+	for _, name := range nodes {
+		n := g.NodeNamed(name)
+		if n == nil {
+			return fmt.Errorf("no node %q", name)
+		}
+		hn = append(hn, n)
+	}
+	var he []*Edge
+	for _, uv := range edges {
+		u := g.NodeNamed(uv[0])
+		v := g.NodeNamed(uv[1])
+		if u == nil || v == nil {
+			return fmt.Errorf("no edge %q--%q", uv[0], uv[1])
+		}
+		e := g.EdgeBetween(u.ID(), v.ID())
+		if e == nil {
+			return fmt.Errorf("no edge %q--%q", uv[0], uv[1])
+		}
+		he = append(he, e.(*Edge))
+	}
+
+	color := encoding.Attribute{Key: "color", Value: style.Color}
+	width := encoding.Attribute{Key: "penwidth", Value: fmt.Sprint(style.PenWidth)}
+	for _, n := range hn {
+		n.SetAttribute(color)
+		n.SetAttribute(width)
+		if style.FillColor != "" {
+			n.SetAttribute(encoding.Attribute{Key: "style", Value: "filled"})
+			n.SetAttribute(encoding.Attribute{Key: "fillcolor", Value: style.FillColor})
+		}
+	}
+	for _, e := range he {
+		e.SetAttribute(color)
+		e.SetAttribute(width)
+	}
+	return nil
+}
+
+// PathEdges returns the edges joining consecutive nodes in path, suitable
+// for passing to Highlight.
+func PathEdges(path []string) [][2]string {
+	if len(path) < 2 {
+		return nil
+	}
+	edges := make([][2]string, len(path)-1)
+	for i := range edges {
+		edges[i] = [2]string{path[i], path[i+1]}
+	}
+	return edges
+}
@@ -0,0 +1,78 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b -- c -- d }`)
+	path := []string{"a", "b", "c"}
+	edges := PathEdges(path)
+	if want := [][2]string{{"a", "b"}, {"b", "c"}}; !reflect.DeepEqual(edges, want) {
+		t.Errorf("unexpected path edges: got:%v want:%v", edges, want)
+	}
+	err := Highlight(g, path, edges, HighlightStyle{FillColor: "yellow"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `graph {
+  // Node definitions.
+  a [
+    color=red
+    penwidth=3
+    style=filled
+    fillcolor=yellow
+  ];
+  b [
+    color=red
+    penwidth=3
+    style=filled
+    fillcolor=yellow
+  ];
+  c [
+    color=red
+    penwidth=3
+    style=filled
+    fillcolor=yellow
+  ];
+  d;
+
+  // Edge definitions.
+  a -- b [
+    color=red
+    penwidth=3
+  ];
+  b -- c [
+    color=red
+    penwidth=3
+  ];
+  c -- d;
+}`
+	if got := DOT(g); got != want {
+		t.Errorf("unexpected highlighted graph:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHighlightMissing(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b -- c }`)
+	for i, test := range []struct {
+		nodes []string
+		edges [][2]string
+	}{
+		{nodes: []string{"a", "x"}},
+		{nodes: []string{"a"}, edges: [][2]string{{"a", "c"}}},
+		{edges: [][2]string{{"a", "x"}}},
+	} {
+		if err := Highlight(g, test.nodes, test.edges, HighlightStyle{}); err == nil {
+			t.Errorf("expected error for test %d", i)
+		}
+	}
+	if got, want := DOT(g), DOT(graphFromDOT(t, `graph { a -- b -- c }`)); got != want {
+		t.Errorf("unexpected change to graph after failed highlight:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
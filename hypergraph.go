@@ -0,0 +1,181 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Hypergraph is a graph whose edges, hyperedges, may join any number of
+// nodes. Nodes and hyperedges are identified by name.
+type Hypergraph struct {
+	// edges holds the hyperedges in the
+	// order they were read and members
+	// holds the sorted node names of each.
+	edges   []string
+	members map[string][]string
+
+	// nodes holds the sorted node names.
+	nodes []string
+}
+
+// ReadHypergraph reads a hypergraph in a simple incidence format from r.
+// Each line holds the name of a hyperedge followed by the names of the
+// nodes it joins, separated by white space:
+//
+//	paper1 alice bob carol
+//	paper2 bob dave
+//	paper3 carol
+//
+// Blank lines and lines starting with '#' are ignored. A node that is
+// named more than once in a hyperedge is a member only once. ReadHypergraph
+// returns an error if a hyperedge has no nodes, if a hyperedge name is
+// repeated, or if a name is used for both a hyperedge and a node, since
+// the hyperedges become nodes of the star expansion.
+func ReadHypergraph(r io.Reader) (*Hypergraph, error) {
+	h := &Hypergraph{members: make(map[string][]string)}
+	isNode := make(map[string]bool)
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		f := strings.Fields(text)
+		id := f[0]
+		if len(f) < 2 {
+			return nil, fmt.Errorf("line %d: no nodes for hyperedge %q", line, id)
+		}
+		if _, ok := h.members[id]; ok {
+			return nil, fmt.Errorf("line %d: duplicate hyperedge %q", line, id)
+		}
+		seen := make(map[string]bool, len(f)-1)
+		var members []string
+		for _, name := range f[1:] {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			members = append(members, name)
+			if !isNode[name] {
+				isNode[name] = true
+				h.nodes = append(h.nodes, name)
+			}
+		}
+		sort.Strings(members)
+		h.edges = append(h.edges, id)
+		h.members[id] = members
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	for _, id := range h.edges {
+		if isNode[id] {
+			return nil, fmt.Errorf("hyperedge %q is also a node", id)
+		}
+	}
+	sort.Strings(h.nodes)
+	return h, nil
+}
+
+// Nodes returns the names of the nodes of h in lexical order.
+func (h *Hypergraph) Nodes() []string {
+	return append([]string(nil), h.nodes...)
+}
+
+// Hyperedges returns the names of the hyperedges of h in the order they
+// were read.
+func (h *Hypergraph) Hyperedges() []string {
+	return append([]string(nil), h.edges...)
+}
+
+// Members returns the names of the nodes joined by the named hyperedge in
+// lexical order, or nil if there is no such hyperedge.
+func (h *Hypergraph) Members(hyperedge string) []string {
+	m, ok := h.members[hyperedge]
+	if !ok {
+		return nil
+	}
+	return append([]string(nil), m...)
+}
+
+// CliqueExpansion returns the clique expansion of h, the graph with the
+// nodes of h and an edge between each pair of nodes that share a
+// hyperedge. The "hyperedge" attribute of each edge holds a
+// comma-separated list of the names of the hyperedges the pair shares, in
+// the order they were read, and its "weight" attribute holds the number of
+// those hyperedges. Node IDs are the indices of the nodes in h.Nodes().
+func (h *Hypergraph) CliqueExpansion() *Graph {
+	g := &Graph{UndirectedGraph: simple.NewUndirectedGraph()}
+	byName := h.addNodes(g)
+	shared := make(map[[2]int64][]string)
+	var pairs [][2]int64
+	for _, id := range h.edges {
+		m := h.members[id]
+		for i, u := range m {
+			for _, v := range m[i+1:] {
+				k := [2]int64{byName[u].ID(), byName[v].ID()}
+				if _, ok := shared[k]; !ok {
+					pairs = append(pairs, k)
+				}
+				shared[k] = append(shared[k], id)
+			}
+		}
+	}
+	for _, k := range pairs {
+		e := &Edge{F: g.Node(k[0]).(*Node), T: g.Node(k[1]).(*Node)}
+		e.SetQuoted("hyperedge", strings.Join(shared[k], ","))
+		e.SetAttribute(encoding.Attribute{Key: "weight", Value: fmt.Sprint(len(shared[k]))})
+		g.SetEdge(e)
+	}
+	return g
+}
+
+// StarExpansion returns the star expansion of h, the bipartite graph with
+// a node for each node of h and a node for each hyperedge of h, and an
+// edge between each hyperedge node and the nodes of its members. The DOT
+// ID of a hyperedge node is the name of the hyperedge, and hyperedge nodes
+// are distinguished by their "hyperedge" attribute, which holds the same
+// name. The "hyperedge" attribute of each edge holds the name of the
+// hyperedge at its end. Node IDs of the nodes of h are the indices of the
+// nodes in h.Nodes(), followed by the hyperedge nodes in the order of
+// h.Hyperedges().
+//
+// Unlike the clique expansion, the star expansion retains the size of
+// each hyperedge and distinguishes hyperedges with the same members.
+func (h *Hypergraph) StarExpansion() *Graph {
+	g := &Graph{UndirectedGraph: simple.NewUndirectedGraph()}
+	byName := h.addNodes(g)
+	for i, id := range h.edges {
+		hn := &Node{NodeID: int64(len(h.nodes) + i), Name: id}
+		hn.SetQuoted("hyperedge", id)
+		g.AddNode(hn)
+		for _, name := range h.members[id] {
+			e := &Edge{F: byName[name], T: hn}
+			e.SetQuoted("hyperedge", id)
+			g.SetEdge(e)
+		}
+	}
+	return g
+}
+
+// addNodes adds the nodes of h to g with node IDs given by their index in
+// h.nodes and returns them keyed by name.
+func (h *Hypergraph) addNodes(g *Graph) map[string]*Node {
+	byName := make(map[string]*Node, len(h.nodes))
+	for id, name := range h.nodes {
+		n := &Node{NodeID: int64(id), Name: name}
+		g.AddNode(n)
+		byName[name] = n
+	}
+	return byName
+}
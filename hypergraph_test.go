@@ -0,0 +1,127 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+const papers = `# Authorship.
+paper1 alice bob carol
+paper2 bob dave bob
+
+paper3 carol
+paper4 bob alice
+`
+
+// hyperedgeAttrs returns the named attribute of each edge of g keyed by the
+// sorted DOT IDs of its ends joined by "--".
+func hyperedgeAttrs(g *Graph, attr string) map[string]string {
+	m := make(map[string]string)
+	for _, e := range graph.EdgesOf(g.Edges()) {
+		e := e.(*Edge)
+		u, v := e.F.Name, e.T.Name
+		if v < u {
+			u, v = v, u
+		}
+		m[u+"--"+v] = e.Get(attr)
+	}
+	return m
+}
+
+func TestReadHypergraph(t *testing.T) {
+	h, err := ReadHypergraph(strings.NewReader(papers))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := h.Nodes(), []string{"alice", "bob", "carol", "dave"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected nodes: got:%q want:%q", got, want)
+	}
+	if got, want := h.Hyperedges(), []string{"paper1", "paper2", "paper3", "paper4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected hyperedges: got:%q want:%q", got, want)
+	}
+	if got, want := h.Members("paper2"), []string{"bob", "dave"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected members: got:%q want:%q", got, want)
+	}
+	if h.Members("paper5") != nil {
+		t.Error("unexpected members for missing hyperedge")
+	}
+
+	for _, src := range []string{
+		"paper1\n",
+		"paper1 alice\npaper1 bob\n",
+		"paper1 alice\npaper2 paper1\n",
+	} {
+		if _, err := ReadHypergraph(strings.NewReader(src)); err == nil {
+			t.Errorf("expected error for %q", src)
+		}
+	}
+}
+
+func TestCliqueExpansion(t *testing.T) {
+	h, err := ReadHypergraph(strings.NewReader(papers))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g := h.CliqueExpansion()
+	if n := g.Nodes().Len(); n != 4 {
+		t.Errorf("unexpected number of nodes: got:%d want:4", n)
+	}
+	wantHyperedges := map[string]string{
+		"alice--bob":   `"paper1,paper4"`,
+		"alice--carol": `"paper1"`,
+		"bob--carol":   `"paper1"`,
+		"bob--dave":    `"paper2"`,
+	}
+	if got := hyperedgeAttrs(g, "hyperedge"); !reflect.DeepEqual(got, wantHyperedges) {
+		t.Errorf("unexpected hyperedges: got:%v want:%v", got, wantHyperedges)
+	}
+	wantWeights := map[string]string{"alice--bob": "2", "alice--carol": "1", "bob--carol": "1", "bob--dave": "1"}
+	if got := hyperedgeAttrs(g, "weight"); !reflect.DeepEqual(got, wantWeights) {
+		t.Errorf("unexpected weights: got:%v want:%v", got, wantWeights)
+	}
+	for id, name := range h.Nodes() {
+		if got := g.Node(int64(id)).(*Node).Name; got != name {
+			t.Errorf("unexpected node for ID %d: got:%s want:%s", id, got, name)
+		}
+	}
+}
+
+func TestStarExpansion(t *testing.T) {
+	h, err := ReadHypergraph(strings.NewReader(papers))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g := h.StarExpansion()
+	if n := g.Nodes().Len(); n != 8 {
+		t.Errorf("unexpected number of nodes: got:%d want:8", n)
+	}
+	want := map[string]string{
+		"alice--paper1": `"paper1"`,
+		"bob--paper1":   `"paper1"`,
+		"carol--paper1": `"paper1"`,
+		"bob--paper2":   `"paper2"`,
+		"dave--paper2":  `"paper2"`,
+		"carol--paper3": `"paper3"`,
+		"alice--paper4": `"paper4"`,
+		"bob--paper4":   `"paper4"`,
+	}
+	if got := hyperedgeAttrs(g, "hyperedge"); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected edges: got:%v want:%v", got, want)
+	}
+	for i, id := range h.Hyperedges() {
+		n := g.Node(int64(len(h.Nodes()) + i)).(*Node)
+		if n.Name != id || n.GetUnquoted("hyperedge") != id {
+			t.Errorf("unexpected hyperedge node %d: got:%s[%s] want:%s", i, n.Name, n.Get("hyperedge"), id)
+		}
+	}
+	if g.NodeNamed("alice").Get("hyperedge") != "" {
+		t.Error("unexpected hyperedge attribute on member node")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"sort"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// NodeIndex is a dense mapping between the nodes of a graph and the
+// integers 0 to n-1, ordered by node ID. It can be used to hold per-node
+// values in a []float64 or to label the rows and columns of a matrix.
+//
+// A NodeIndex is only valid until nodes are added to or removed from the
+// graph it was created from.
+type NodeIndex struct {
+	nodes []graph.Node
+	index map[int64]int
+}
+
+// Index returns a dense node index for the nodes of g.
+func (g *Graph) Index() NodeIndex {
+	return newNodeIndex(g)
+}
+
+func newNodeIndex(g graph.Graph) NodeIndex {
+	nodes := graph.NodesOf(g.Nodes())
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID() < nodes[j].ID() })
+	index := make(map[int64]int, len(nodes))
+	for i, n := range nodes {
+		index[n.ID()] = i
+	}
+	return NodeIndex{nodes: nodes, index: index}
+}
+
+// Len returns the number of nodes in the index.
+func (x NodeIndex) Len() int { return len(x.nodes) }
+
+// Node returns the node at index i.
+func (x NodeIndex) Node(i int) *Node { return x.nodes[i].(*Node) }
+
+// ID returns the ID of the node at index i.
+func (x NodeIndex) ID(i int) int64 { return x.nodes[i].ID() }
+
+// Index returns the index of the node with the given ID, or -1 if the node
+// is not in the index.
+func (x NodeIndex) Index(id int64) int {
+	i, ok := x.index[id]
+	if !ok {
+		return -1
+	}
+	return i
+}
+
+// adjacency returns the adjacency lists of g in terms of node indices.
+// Each list is sorted so that analyses using random choices of neighbours
+// are reproducible.
+func (x NodeIndex) adjacency(g graph.Graph) [][]int {
+	adj := make([][]int, len(x.nodes))
+	for i, n := range x.nodes {
+		for _, v := range graph.NodesOf(g.From(n.ID())) {
+			adj[i] = append(adj[i], x.index[v.ID()])
+		}
+		sort.Ints(adj[i])
+	}
+	return adj
+}
@@ -0,0 +1,40 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNodeIndex(t *testing.T) {
+	g := graphFromDOT(t, `graph { c -- a -- b; d }`)
+	g.RemoveNode(g.NodeNamed("a").ID())
+	x := g.Index()
+	if x.Len() != 3 {
+		t.Fatalf("unexpected index length: got:%d want:3", x.Len())
+	}
+	for i := 0; i < x.Len(); i++ {
+		if i > 0 && x.ID(i) <= x.ID(i-1) {
+			t.Errorf("index not ordered by ID at %d: %d <= %d", i, x.ID(i), x.ID(i-1))
+		}
+		if got := x.Index(x.ID(i)); got != i {
+			t.Errorf("unexpected index for node %d: got:%d want:%d", x.ID(i), got, i)
+		}
+		if got := x.Node(i); got != g.Node(x.ID(i)) {
+			t.Errorf("unexpected node at %d: got:%v want:%v", i, got, g.Node(x.ID(i)))
+		}
+	}
+	var names []string
+	for i := 0; i < x.Len(); i++ {
+		names = append(names, x.Node(i).Name)
+	}
+	if want := []string{"c", "b", "d"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("unexpected node order: got:%v want:%v", names, want)
+	}
+	if got := x.Index(g.NodeNamed("b").ID() + 100); got != -1 {
+		t.Errorf("unexpected index for missing node: got:%d want:-1", got)
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// Infomap partitions g into modules that minimise the two-level map
+// equation of Rosvall and Bergstrom (doi:10.1073/pnas.0706851105), the
+// expected number of bits per step needed to describe a random walk on g
+// using a code book for each module and an index code book for moves
+// between modules. Modules are regions in which a random walker tends to
+// stay for a long time, in contrast to the modularity optimised by
+// Communities and Leiden, which compares edge densities with a null
+// model. The map equation is minimised by repeatedly moving nodes to the
+// module of a neighbour and aggregating modules into nodes, in the same
+// way as the Louvain algorithm. This is repeated trials times with node
+// orders determined by seed, and the partition with the shortest
+// description length is kept. The description length in bits is returned.
+// Edges are weighted by their "weight" attribute, or 1 if it is not set.
+// Infomap returns an error if a weight is negative.
+//
+// Infomap panics if trials is less than one.
+//
+// The module identity value is written into the "community_infomap" attribute of each node.
+func Infomap(g *Graph, trials int, seed int64) (codelength float64, err error) {
+	if trials < 1 {
+		panic("graphprac: invalid number of trials")
+	}
+	done, err := trace(g, "Infomap", []string{"community_infomap"}, "trials", trials, "seed", seed)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { done(err) }()
+
+	x := g.Index()
+	adj := x.adjacency(g)
+	w, err := edgeWeights(g, x, adj)
+	if err != nil {
+		return 0, err
+	}
+	n := len(adj)
+	k := make([]float64, n)
+	var m2 float64
+	for i := range adj {
+		k[i] = sum(w[i])
+		m2 += k[i]
+	}
+
+	best := make([]int, n)
+	for i := range best {
+		best[i] = i
+	}
+	codelength = math.Inf(1)
+	if m2 == 0 {
+		codelength = 0
+		trials = 0
+	}
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	for t := 0; t < trials; t++ {
+		l, module := mapEquationTrial(adj, w, k, m2, rnd)
+		if l < codelength {
+			codelength = l
+			copy(best, module)
+		}
+	}
+
+	for i, m := range best {
+		x.Node(i).SetAttribute(encoding.Attribute{Key: "community_infomap", Value: fmt.Sprint(m)})
+	}
+	return codelength, nil
+}
+
+// mapEquationTrial returns a partition of the graph with adjacency lists
+// adj, edge weights w, node strengths k and total strength m2 that locally
+// minimises the two-level map equation, and its description length. The
+// modules are numbered from zero in order of their first node.
+func mapEquationTrial(adj [][]int, w [][]float64, k []float64, m2 float64, rnd *rand.Rand) (codelength float64, module []int) {
+	// nodeEntropy is the entropy of the stationary
+	// distribution of the random walk over the nodes,
+	// which is the same for all partitions.
+	var nodeEntropy float64
+	for _, v := range k {
+		nodeEntropy += plogp(v / m2)
+	}
+
+	n := len(adj)
+	node := make([]int, n)
+	for i := range node {
+		node[i] = i
+	}
+	var p *flowPartition
+	for {
+		comm := make([]int, len(adj))
+		for i := range comm {
+			comm[i] = i
+		}
+		p = newFlowPartition(adj, w, k, m2)
+		p.assign(comm)
+		if !p.moveNodes(rnd) {
+			break
+		}
+		labels := p.labels()
+		modules := len(adj) - len(p.free)
+		adj, w, k = aggregateNodes(adj, w, k, labels, modules)
+		for i, v := range node {
+			node[i] = labels[v]
+		}
+	}
+
+	label := make(map[int]int)
+	module = make([]int, n)
+	for i, v := range node {
+		c := p.comm[v]
+		l, ok := label[c]
+		if !ok {
+			l = len(label)
+			label[c] = l
+		}
+		module[i] = l
+	}
+	return p.codelength() - nodeEntropy, module
+}
+
+// flowPartition is a partition of the nodes of a weighted graph into
+// modules for map equation optimisation.
+type flowPartition struct {
+	*partition
+
+	// out is the weight of edges from each
+	// node to other nodes, and exit is the
+	// weight of edges leaving each module.
+	out  []float64
+	exit []float64
+
+	// sumExit is the sum of exit, and
+	// sumExitLog and sumTotalLog are the sums
+	// over modules of plogp of the exit flow
+	// and of the exit flow plus the flow
+	// within the module.
+	sumExit     float64
+	sumExitLog  float64
+	sumTotalLog float64
+}
+
+// newFlowPartition returns an unassigned partition of the graph with the
+// adjacency lists adj, edge weights w, node strengths k including the
+// weight of any self loops and total strength m2.
+func newFlowPartition(adj [][]int, w [][]float64, k []float64, m2 float64) *flowPartition {
+	out := make([]float64, len(adj))
+	for i := range adj {
+		out[i] = sum(w[i])
+	}
+	return &flowPartition{
+		partition: newPartitionOf(adj, w, k, m2, 1),
+		out:       out,
+		exit:      make([]float64, len(adj)),
+	}
+}
+
+// assign sets the module of each node to the values in comm, which must
+// be in [0, n).
+func (p *flowPartition) assign(comm []int) {
+	p.partition.assign(comm)
+	for c := range p.exit {
+		p.exit[c] = 0
+	}
+	for i, to := range p.adj {
+		for h, j := range to {
+			if comm[j] != comm[i] {
+				p.exit[comm[i]] += p.w[i][h]
+			}
+		}
+	}
+	p.sumExit, p.sumExitLog, p.sumTotalLog = 0, 0, 0
+	for c, e := range p.exit {
+		p.sumExit += e
+		p.sumExitLog += plogp(e / p.m2)
+		p.sumTotalLog += plogp((e + p.tot[c]) / p.m2)
+	}
+}
+
+// codelength returns the description length of the partition, excluding
+// the entropy of the stationary distribution over the nodes.
+func (p *flowPartition) codelength() float64 {
+	return plogp(p.sumExit/p.m2) - 2*p.sumExitLog + p.sumTotalLog
+}
+
+// delta returns the change in the description length of moving node i to
+// module c. wTo must hold the weights from i.
+func (p *flowPartition) delta(i, c int) float64 {
+	a := p.comm[i]
+	if c == a {
+		return 0
+	}
+	exitA := p.exit[a] - p.out[i] + 2*p.wTo[a]
+	exitC := p.exit[c] + p.out[i] - 2*p.wTo[c]
+	totA := p.tot[a] - p.k[i]
+	totC := p.tot[c] + p.k[i]
+
+	sumExit := p.sumExit - p.exit[a] - p.exit[c] + exitA + exitC
+	sumExitLog := p.sumExitLog -
+		plogp(p.exit[a]/p.m2) - plogp(p.exit[c]/p.m2) +
+		plogp(exitA/p.m2) + plogp(exitC/p.m2)
+	sumTotalLog := p.sumTotalLog -
+		plogp((p.exit[a]+p.tot[a])/p.m2) - plogp((p.exit[c]+p.tot[c])/p.m2) +
+		plogp((exitA+totA)/p.m2) + plogp((exitC+totC)/p.m2)
+
+	return plogp(sumExit/p.m2) - 2*sumExitLog + sumTotalLog - p.codelength()
+}
+
+// move moves node i to module c. wTo must hold the weights from i.
+func (p *flowPartition) move(i, c int) {
+	a := p.comm[i]
+	if c == a {
+		return
+	}
+	p.sumExitLog -= plogp(p.exit[a]/p.m2) + plogp(p.exit[c]/p.m2)
+	p.sumTotalLog -= plogp((p.exit[a]+p.tot[a])/p.m2) + plogp((p.exit[c]+p.tot[c])/p.m2)
+	p.sumExit -= p.exit[a] + p.exit[c]
+	p.exit[a] += 2*p.wTo[a] - p.out[i]
+	p.exit[c] += p.out[i] - 2*p.wTo[c]
+	p.partition.move(i, c)
+	p.sumExit += p.exit[a] + p.exit[c]
+	p.sumExitLog += plogp(p.exit[a]/p.m2) + plogp(p.exit[c]/p.m2)
+	p.sumTotalLog += plogp((p.exit[a]+p.tot[a])/p.m2) + plogp((p.exit[c]+p.tot[c])/p.m2)
+}
+
+// moveNodes repeatedly visits the nodes in random order, moving each to
+// the module of a neighbour, or a new module, that most reduces the
+// description length, until no move reduces it. It returns whether any
+// node was moved.
+func (p *flowPartition) moveNodes(rnd *rand.Rand) bool {
+	const tol = 1e-10
+	var movedAny bool
+	for moved := true; moved; {
+		moved = false
+		for _, i := range rnd.Perm(len(p.adj)) {
+			p.weightsTo(i)
+			best, bestDelta := p.comm[i], -tol
+			for _, c := range p.touched {
+				if d := p.delta(i, c); d < bestDelta {
+					best, bestDelta = c, d
+				}
+			}
+			if p.size[p.comm[i]] > 1 {
+				if c := p.empty(); p.delta(i, c) < bestDelta {
+					best = c
+				}
+			}
+			if best != p.comm[i] {
+				p.move(i, best)
+				moved = true
+				movedAny = true
+			}
+		}
+	}
+	return movedAny
+}
+
+// plogp returns p log2 p, or zero if p is zero.
+func plogp(p float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	return p * math.Log2(p)
+}
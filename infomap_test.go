@@ -0,0 +1,93 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"testing"
+)
+
+// mapEquation returns the two-level map equation for a partition of an
+// undirected graph with total strength m2 into modules with the given
+// exit weights and total strengths, and node strengths k.
+func mapEquation(m2 float64, exit, tot, k []float64) float64 {
+	var sumExit float64
+	for _, e := range exit {
+		sumExit += e
+	}
+	l := plogp(sumExit / m2)
+	for i, e := range exit {
+		l += -2*plogp(e/m2) + plogp((e+tot[i])/m2)
+	}
+	for _, v := range k {
+		l -= plogp(v / m2)
+	}
+	return l
+}
+
+var infomapTests = []struct {
+	dot        string
+	codelength float64
+	groups     [][]string
+}{
+	{
+		// Each triangle is a module with one edge leaving it.
+		dot:        twoTriangles,
+		codelength: mapEquation(14, []float64{1, 1}, []float64{7, 7}, []float64{2, 2, 3, 3, 2, 2}),
+		groups:     [][]string{{"a", "b", "c"}, {"d", "e", "f"}},
+	},
+	{
+		// A single module needs no index code book, so
+		// the description length is the entropy of the
+		// stationary distribution, 1 bit for one edge.
+		dot:        `graph { a -- b }`,
+		codelength: 1,
+		groups:     [][]string{{"a", "b"}},
+	},
+	{
+		dot:        `graph { a; b }`,
+		codelength: 0,
+		groups:     [][]string{{"a"}, {"b"}},
+	},
+	{
+		dot:    ringOfCliques(4, 5),
+		groups: cliqueGroups(4, 5),
+		codelength: mapEquation(88,
+			[]float64{2, 2, 2, 2}, []float64{22, 22, 22, 22},
+			[]float64{
+				5, 5, 4, 4, 4,
+				5, 5, 4, 4, 4,
+				5, 5, 4, 4, 4,
+				5, 5, 4, 4, 4,
+			}),
+	},
+}
+
+func TestInfomap(t *testing.T) {
+	for i, test := range infomapTests {
+		g := graphFromDOT(t, test.dot)
+		l, err := Infomap(g, 3, 1)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		if math.Abs(l-test.codelength) > 1e-10 {
+			t.Errorf("unexpected codelength for test %d: got:%v want:%v", i, l, test.codelength)
+		}
+		checkPartition(t, g, "community_infomap", test.groups)
+	}
+
+	g := graphFromDOT(t, `graph { a -- b [weight=-1] }`)
+	if _, err := Infomap(g, 1, 1); err == nil {
+		t.Error("expected error for negative weight")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for no trials")
+		}
+	}()
+	Infomap(graphFromDOT(t, twoTriangles), 0, 1)
+}
@@ -0,0 +1,156 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// pointsPerInch is the number of GraphViz points per inch.
+const pointsPerInch = 72
+
+// LayoutWithGraphviz computes a layout of g using the GraphViz command
+// engine, one of "dot", "neato", "fdp" and "sfdp", and writes the node
+// coordinates into the "pos" attribute of each node as "x,y" in points,
+// and node sizes into the "width" and "height" attributes in inches,
+// following the GraphViz conventions. The bounding box of the layout is
+// written into the "bb" graph attribute.
+//
+// A graph with "pos" attributes can be drawn without further layout
+// using neato with the -n flag, or used for coordinate-based analyses.
+func LayoutWithGraphviz(g *Graph, engine string) error {
+	var buf bytes.Buffer
+	err := DrawTo(&buf, g, DrawOptions{Engine: engine, Format: "plain"})
+	if err != nil {
+		return err
+	}
+
+	nodes := make(map[string]*Node)
+	for _, n := range NodesOf(g) {
+		nodes[dotIDOf(n)] = n
+	}
+
+	sc := bufio.NewScanner(&buf)
+	sc.Buffer(nil, 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		f, err := plainFields(sc.Text(), 6)
+		if err != nil {
+			return fmt.Errorf("graphviz output line %d: %v", line, err)
+		}
+		if len(f) == 0 {
+			continue
+		}
+		switch f[0] {
+		case "graph":
+			if len(f) < 4 {
+				return fmt.Errorf("graphviz output line %d: short graph statement", line)
+			}
+			v, err := parseFloats(f[2:4])
+			if err != nil {
+				return fmt.Errorf("graphviz output line %d: %v", line, err)
+			}
+			g.GraphAttrs.SetAttribute(encoding.Attribute{
+				Key:   "bb",
+				Value: quoteDOT(fmt.Sprintf("0,0,%s,%s", points(v[0]), points(v[1]))),
+			})
+		case "node":
+			if len(f) < 6 {
+				return fmt.Errorf("graphviz output line %d: short node statement", line)
+			}
+			n, ok := nodes[f[1]]
+			if !ok {
+				return fmt.Errorf("graphviz output line %d: unknown node %q", line, f[1])
+			}
+			v, err := parseFloats(f[2:6])
+			if err != nil {
+				return fmt.Errorf("graphviz output line %d: %v", line, err)
+			}
+			n.SetAttribute(encoding.Attribute{Key: "pos", Value: quoteDOT(points(v[0]) + "," + points(v[1]))})
+			n.SetAttribute(encoding.Attribute{Key: "width", Value: strconv.FormatFloat(v[2], 'g', -1, 64)})
+			n.SetAttribute(encoding.Attribute{Key: "height", Value: strconv.FormatFloat(v[3], 'g', -1, 64)})
+		case "stop":
+			return nil
+		}
+	}
+	return sc.Err()
+}
+
+// Position returns the coordinates held in the "pos" attribute of n.
+func Position(n *Node) (x, y float64, err error) {
+	pos := n.GetUnquoted("pos")
+	if pos == "" {
+		return 0, 0, fmt.Errorf("node %q has no position", n.Name)
+	}
+	c := strings.Split(strings.TrimSuffix(pos, "!"), ",")
+	if len(c) < 2 {
+		return 0, 0, fmt.Errorf("invalid position for node %q: %q", n.Name, pos)
+	}
+	v, err := parseFloats(c[:2])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid position for node %q: %v", n.Name, err)
+	}
+	return v[0], v[1], nil
+}
+
+// points returns the GraphViz point representation of v inches.
+func points(v float64) string {
+	return strconv.FormatFloat(v*pointsPerInch, 'f', -1, 64)
+}
+
+func parseFloats(s []string) ([]float64, error) {
+	v := make([]float64, len(s))
+	for i, f := range s {
+		var err error
+		v[i], err = strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// plainFields returns up to max whitespace separated fields of a line
+// of GraphViz plain output. Quoted fields are unquoted.
+func plainFields(line string, max int) ([]string, error) {
+	var f []string
+	for len(f) < max {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" {
+			break
+		}
+		if line[0] != '"' {
+			i := strings.IndexAny(line, " \t")
+			if i < 0 {
+				i = len(line)
+			}
+			f = append(f, line[:i])
+			line = line[i:]
+			continue
+		}
+		var b strings.Builder
+		i := 1
+		for ; i < len(line) && line[i] != '"'; i++ {
+			if line[i] == '\\' && i+1 < len(line) {
+				i++
+				if line[i] != '"' && line[i] != '\\' {
+					b.WriteByte('\\')
+				}
+			}
+			b.WriteByte(line[i])
+		}
+		if i == len(line) {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		f = append(f, b.String())
+		line = line[i+1:]
+	}
+	return f, nil
+}
@@ -0,0 +1,86 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+var positionTests = []struct {
+	dot    string
+	x, y   float64
+	wantOK bool
+}{
+	{dot: `graph { n [pos="27,18"] }`, x: 27, y: 18, wantOK: true},
+	{dot: `graph { n [pos="1.5,-2!"] }`, x: 1.5, y: -2, wantOK: true},
+	{dot: `graph { n [pos="1,2,3"] }`, x: 1, y: 2, wantOK: true},
+	{dot: `graph { n [pos="1"] }`, wantOK: false},
+	{dot: `graph { n [pos="a,b"] }`, wantOK: false},
+	{dot: `graph { n }`, wantOK: false},
+}
+
+func TestPosition(t *testing.T) {
+	for i, test := range positionTests {
+		g := graphFromDOT(t, test.dot)
+		x, y, err := Position(g.NodeNamed("n"))
+		if (err == nil) != test.wantOK {
+			t.Errorf("unexpected error state for test %d: got:%v want ok:%t", i, err, test.wantOK)
+			continue
+		}
+		if err == nil && (x != test.x || y != test.y) {
+			t.Errorf("unexpected position for test %d: got:(%v,%v) want:(%v,%v)", i, x, y, test.x, test.y)
+		}
+	}
+}
+
+var plainFieldsTests = []struct {
+	line string
+	max  int
+	want []string
+}{
+	{line: `graph 1 2.5 3`, max: 6, want: []string{"graph", "1", "2.5", "3"}},
+	{line: `node a 1 2 0.75 0.5 a solid`, max: 6, want: []string{"node", "a", "1", "2", "0.75", "0.5"}},
+	{line: `node "a b" 1 2`, max: 6, want: []string{"node", "a b", "1", "2"}},
+	{line: `node "say \"hi\"" 1`, max: 6, want: []string{"node", `say "hi"`, "1"}},
+	{line: `node "a\nb" 1`, max: 6, want: []string{"node", `a\nb`, "1"}},
+	{line: `  `, max: 6, want: nil},
+}
+
+func TestPlainFields(t *testing.T) {
+	for i, test := range plainFieldsTests {
+		got, err := plainFields(test.line, test.max)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected fields for test %d: got:%q want:%q", i, got, test.want)
+		}
+	}
+	if _, err := plainFields(`node "a 1 2`, 6); err == nil {
+		t.Error("expected error for unterminated string")
+	}
+}
+
+func TestLayoutWithGraphviz(t *testing.T) {
+	if _, err := exec.LookPath("neato"); err != nil {
+		t.Skip("graphviz not available")
+	}
+	g := graphFromDOT(t, `graph { a -- b -- c }`)
+	err := LayoutWithGraphviz(g, "neato")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, n := range NodesOf(g) {
+		if _, _, err := Position(n); err != nil {
+			t.Errorf("unexpected error for %s: %v", n.Name, err)
+		}
+	}
+	if g.GraphAttrs.Get("bb") == "" {
+		t.Error("missing bounding box")
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// Leiden performs a community modularisation of g at the specified
+// resolution using the Leiden algorithm (Traag, Waltman and van Eck,
+// doi:10.1038/s41598-019-41695-z). Like the Louvain algorithm used by
+// Communities, it alternates moving nodes between communities with
+// aggregating communities into nodes, but before aggregation each
+// community is refined into well-connected sub-communities, so every
+// community found is guaranteed to be connected. The order in which nodes
+// are visited and the refinement are determined by seed. Edges are
+// weighted by their "weight" attribute, or 1 if it is not set. Leiden
+// returns an error if a weight is negative.
+//
+// The community identity value is written into the "community_leiden" attribute of each node.
+func Leiden(g *Graph, resolution float64, seed int64) (err error) {
+	done, err := trace(g, "Leiden", []string{"community_leiden"}, "resolution", resolution, "seed", seed)
+	if err != nil {
+		return err
+	}
+	defer func() { done(err) }()
+
+	x := g.Index()
+	adj := x.adjacency(g)
+	w, err := edgeWeights(g, x, adj)
+	if err != nil {
+		return err
+	}
+	n := len(adj)
+	k := make([]float64, n)
+	var m2 float64
+	for i := range adj {
+		k[i] = sum(w[i])
+		m2 += k[i]
+	}
+
+	// node holds the node of the current aggregate
+	// graph that holds each node of g, and comm holds
+	// the community of each aggregate node.
+	node := make([]int, n)
+	comm := make([]int, n)
+	for i := range node {
+		node[i] = i
+		comm[i] = i
+	}
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	for m2 != 0 {
+		p := newPartitionOf(adj, w, k, m2, resolution)
+		p.assign(comm)
+		p.moveNodesFast(rnd)
+		comm = p.comm
+		if len(p.free) == 0 {
+			// Every community is a single aggregate node.
+			break
+		}
+
+		refined, r := p.refine(rnd)
+		if r == len(adj) {
+			// No refined community has more than one node, so
+			// aggregate by community to ensure progress.
+			refined = p.labels()
+			r = len(adj) - len(p.free)
+		}
+		adj, w, k = aggregateNodes(adj, w, k, refined, r)
+
+		label := make(map[int]int)
+		next := make([]int, r)
+		for i, c := range comm {
+			l, ok := label[c]
+			if !ok {
+				l = len(label)
+				label[c] = l
+			}
+			next[refined[i]] = l
+		}
+		comm = next
+		for i, v := range node {
+			node[i] = refined[v]
+		}
+	}
+
+	label := make(map[int]int)
+	for i, v := range node {
+		c := comm[v]
+		l, ok := label[c]
+		if !ok {
+			l = len(label)
+			label[c] = l
+		}
+		x.Node(i).SetAttribute(encoding.Attribute{Key: "community_leiden", Value: fmt.Sprint(l)})
+	}
+	return nil
+}
+
+// moveNodesFast moves nodes to the community of their neighbours, or a new
+// community, that most increases the modularity, visiting nodes from a
+// queue that initially holds all nodes in random order. When a node is
+// moved, its neighbours outside its new community are queued again.
+func (p *partition) moveNodesFast(rnd *rand.Rand) {
+	const tol = 1e-12
+	queue := rnd.Perm(len(p.adj))
+	queued := make([]bool, len(p.adj))
+	for i := range queued {
+		queued[i] = true
+	}
+	for len(queue) != 0 {
+		i := queue[0]
+		queue = queue[1:]
+		queued[i] = false
+
+		p.weightsTo(i)
+		best, bestGain := p.comm[i], tol
+		for _, c := range p.touched {
+			if dq := p.gain(i, c); dq > bestGain {
+				best, bestGain = c, dq
+			}
+		}
+		if p.size[p.comm[i]] > 1 {
+			if c := p.empty(); p.gain(i, c) > bestGain {
+				best = c
+			}
+		}
+		if best == p.comm[i] {
+			continue
+		}
+		p.move(i, best)
+		for _, j := range p.adj[i] {
+			if p.comm[j] != best && !queued[j] {
+				queued[j] = true
+				queue = append(queue, j)
+			}
+		}
+	}
+}
+
+// refine returns a refinement of the partition into sub-communities and
+// the number of sub-communities. Starting from singletons, each node that
+// is still alone in its sub-community and is well connected to the rest of
+// its community is merged into a randomly chosen well-connected
+// sub-community of its community that does not reduce the modularity,
+// with merges that increase the modularity more being more likely.
+func (p *partition) refine(rnd *rand.Rand) (refined []int, n int) {
+	// theta controls the randomness of merges, with
+	// smaller values favouring the best merge.
+	const theta = 0.01
+
+	single := make([]int, len(p.adj))
+	for i := range single {
+		single[i] = i
+	}
+	r := newPartitionOf(p.adj, p.w, p.k, p.m2, p.res)
+	r.assign(single)
+
+	// in holds the weight of edges from each node to
+	// the rest of its community, and cut holds the
+	// weight of edges from each sub-community to the
+	// rest of its community.
+	in := make([]float64, len(p.adj))
+	for i, to := range p.adj {
+		for k, j := range to {
+			if p.comm[j] == p.comm[i] {
+				in[i] += p.w[i][k]
+			}
+		}
+	}
+	cut := append([]float64(nil), in...)
+
+	var (
+		cands []int
+		gains []float64
+	)
+	for _, v := range rnd.Perm(len(p.adj)) {
+		if r.size[r.comm[v]] != 1 {
+			continue
+		}
+		c := p.comm[v]
+		if in[v] < p.res*p.k[v]*(p.tot[c]-p.k[v])/p.m2 {
+			continue
+		}
+		r.weightsTo(v)
+		cands, gains = cands[:0], gains[:0]
+		maxGain := math.Inf(-1)
+		for _, s := range r.touched {
+			// Sub-communities keep the label of the node
+			// they started from, so s is in the same
+			// community as v if node s is.
+			if s == r.comm[v] || p.comm[s] != c {
+				continue
+			}
+			if cut[s] < p.res*r.tot[s]*(p.tot[c]-r.tot[s])/p.m2 {
+				continue
+			}
+			dh := r.gain(v, s) * p.m2 / 2
+			if dh < 0 {
+				continue
+			}
+			cands = append(cands, s)
+			gains = append(gains, dh)
+			maxGain = math.Max(maxGain, dh)
+		}
+		if len(cands) == 0 {
+			continue
+		}
+		var total float64
+		for i, dh := range gains {
+			gains[i] = math.Exp((dh - maxGain) / theta)
+			total += gains[i]
+		}
+		s := cands[len(cands)-1]
+		u := rnd.Float64() * total
+		for i, pr := range gains {
+			if u < pr {
+				s = cands[i]
+				break
+			}
+			u -= pr
+		}
+		r.move(v, s)
+		cut[s] += in[v] - 2*r.wTo[s]
+	}
+	refined = r.labels()
+	return refined, len(r.adj) - len(r.free)
+}
+
+// aggregateNodes returns the graph with n nodes formed by merging the nodes of
+// the graph with adjacency lists adj, edge weights w and node strengths k
+// according to group. Edges within a group are retained in the strength of
+// the merged node.
+func aggregateNodes(adj [][]int, w [][]float64, k []float64, group []int, n int) ([][]int, [][]float64, []float64) {
+	rows := make([]map[int]float64, n)
+	aggK := make([]float64, n)
+	for i, to := range adj {
+		a := group[i]
+		aggK[a] += k[i]
+		if rows[a] == nil {
+			rows[a] = make(map[int]float64)
+		}
+		for h, j := range to {
+			if b := group[j]; b != a {
+				rows[a][b] += w[i][h]
+			}
+		}
+	}
+	aggAdj := make([][]int, n)
+	aggW := make([][]float64, n)
+	for a, row := range rows {
+		for b := range row {
+			aggAdj[a] = append(aggAdj[a], b)
+		}
+		sort.Ints(aggAdj[a])
+		aggW[a] = make([]float64, len(aggAdj[a]))
+		for h, b := range aggAdj[a] {
+			aggW[a][h] = row[b]
+		}
+	}
+	return aggAdj, aggW, aggK
+}
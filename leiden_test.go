@@ -0,0 +1,83 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"testing"
+)
+
+var leidenTests = []struct {
+	dot        string
+	resolution float64
+	groups     [][]string
+}{
+	{
+		dot:        twoTriangles,
+		resolution: 1,
+		groups:     [][]string{{"a", "b", "c"}, {"d", "e", "f"}},
+	},
+	{
+		dot:        ringOfCliques(4, 5),
+		resolution: 1,
+		groups:     cliqueGroups(4, 5),
+	},
+	{
+		dot:        twoTriangles,
+		resolution: 0.01,
+		groups:     [][]string{{"a", "b", "c", "d", "e", "f"}},
+	},
+	{
+		dot:        twoTriangles,
+		resolution: 100,
+		groups:     [][]string{{"a"}, {"b"}, {"c"}, {"d"}, {"e"}, {"f"}},
+	},
+	{
+		dot:        `graph { a -- b; c }`,
+		resolution: 1,
+		groups:     [][]string{{"a", "b"}, {"c"}},
+	},
+}
+
+func TestLeiden(t *testing.T) {
+	for i, test := range leidenTests {
+		for seed := int64(0); seed < 3; seed++ {
+			g := graphFromDOT(t, test.dot)
+			if err := Leiden(g, test.resolution, seed); err != nil {
+				t.Errorf("unexpected error for test %d: %v", i, err)
+				continue
+			}
+			checkPartition(t, g, "community_leiden", test.groups)
+		}
+	}
+
+	g := graphFromDOT(t, `graph { a -- b [weight=-1] }`)
+	if err := Leiden(g, 1, 1); err == nil {
+		t.Error("expected error for negative weight")
+	}
+}
+
+func TestLeidenConnected(t *testing.T) {
+	// Every community found by the Leiden algorithm
+	// is connected, even in graphs with little
+	// community structure.
+	base := graphFromDOT(t, ringOfCliques(8, 4))
+	for seed := int64(0); seed < 10; seed++ {
+		g := ErdosRenyi(base, seed)
+		if err := Leiden(g, 1, seed); err != nil {
+			t.Fatalf("unexpected error for seed %d: %v", seed, err)
+		}
+		members := make(map[string][]*Node)
+		for _, n := range NodesOf(g) {
+			c := n.Get("community_leiden")
+			members[c] = append(members[c], n)
+		}
+		for c, nodes := range members {
+			sub := Induce(g, nodes)
+			if got := len(hops(sub, nodes[0].ID())); got != len(nodes) {
+				t.Errorf("community %s is not connected for seed %d: reached %d of %d nodes", c, seed, got, len(nodes))
+			}
+		}
+	}
+}
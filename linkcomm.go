@@ -0,0 +1,127 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// LinkCommunities performs an overlapping community analysis on g by
+// clustering its edges rather than its nodes, using the method of Ahn,
+// Bagrow and Lehmann. Two edges sharing a node are placed in the same link
+// community when the Jaccard similarity of the inclusive neighbourhoods of
+// their other end points is at least threshold. A node belongs to every
+// link community that one of its edges belongs to, so nodes at the
+// boundary between communities may have many memberships.
+//
+// The link community membership values are written as a comma-separated
+// list into the "link_community" attribute of each node and the number of
+// link communities a node is a member of is written into
+// "membership_count". Nodes without edges are not given either attribute.
+func LinkCommunities(g *Graph, threshold float64) {
+	done, err := trace(g, "LinkCommunities", []string{"membership_count", "link_community"}, "threshold", threshold)
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
+	x := g.Index()
+	adj := x.adjacency(g)
+	for i := range adj {
+		adj[i] = append(adj[i], i)
+		sort.Ints(adj[i])
+	}
+
+	// Enumerate the edges so they can be clustered
+	// in a union-find forest.
+	type edge struct{ u, v int }
+	var edges []edge
+	incident := make([][]int, len(adj))
+	for u, nu := range adj {
+		for _, v := range nu {
+			if v <= u {
+				continue
+			}
+			incident[u] = append(incident[u], len(edges))
+			incident[v] = append(incident[v], len(edges))
+			edges = append(edges, edge{u: u, v: v})
+		}
+	}
+	other := func(e, k int) int {
+		if edges[e].u == k {
+			return edges[e].v
+		}
+		return edges[e].u
+	}
+
+	uf := newUnionFind(len(edges))
+	for k, inc := range incident {
+		for a, e := range inc {
+			i := other(e, k)
+			for _, f := range inc[a+1:] {
+				j := other(f, k)
+				if jaccard(adj[i], adj[j]) >= threshold {
+					uf.union(e, f)
+				}
+			}
+		}
+	}
+
+	label := make(map[int]int)
+	for e := range edges {
+		r := uf.find(e)
+		if _, ok := label[r]; !ok {
+			label[r] = len(label)
+		}
+	}
+	for k, inc := range incident {
+		if len(inc) == 0 {
+			continue
+		}
+		seen := make(map[int]bool)
+		var member []int
+		for _, e := range inc {
+			c := label[uf.find(e)]
+			if !seen[c] {
+				seen[c] = true
+				member = append(member, c)
+			}
+		}
+		sort.Ints(member)
+		vals := make([]string, len(member))
+		for i, c := range member {
+			vals[i] = fmt.Sprint(c)
+		}
+		n := x.Node(k)
+		n.SetAttribute(encoding.Attribute{Key: "link_community", Value: strings.Join(vals, ",")})
+		n.SetAttribute(encoding.Attribute{Key: "membership_count", Value: fmt.Sprint(len(member))})
+	}
+}
+
+// jaccard returns the Jaccard similarity of the sorted sets a and b.
+func jaccard(a, b []int) float64 {
+	var i, j, common int
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			common++
+			i++
+			j++
+		}
+	}
+	union := len(a) + len(b) - common
+	if union == 0 {
+		return 0
+	}
+	return float64(common) / float64(union)
+}
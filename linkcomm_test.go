@@ -0,0 +1,40 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestLinkCommunities(t *testing.T) {
+	// Two triangles sharing the node c, and an isolated node f.
+	g := graphFromDOT(t, `graph { a -- b -- c -- a; c -- d -- e -- c; f }`)
+	LinkCommunities(g, 0.5)
+
+	for name, want := range map[string]string{"a": "1", "b": "1", "c": "2", "d": "1", "e": "1"} {
+		if got := g.NodeNamed(name).Get("membership_count"); got != want {
+			t.Errorf("unexpected membership count for %s: got:%s want:%s", name, got, want)
+		}
+	}
+	checkPartition(t, g, "link_community", [][]string{{"a", "b"}, {"d", "e"}})
+	c := strings.Split(g.NodeNamed("c").GetUnquoted("link_community"), ",")
+	want := []string{g.NodeNamed("a").GetUnquoted("link_community"), g.NodeNamed("d").GetUnquoted("link_community")}
+	sort.Strings(c)
+	sort.Strings(want)
+	if strings.Join(c, ",") != strings.Join(want, ",") {
+		t.Errorf("unexpected link communities for c: got:%v want:%v", c, want)
+	}
+	f := g.NodeNamed("f")
+	if f.Get("link_community") != "" || f.Get("membership_count") != "" {
+		t.Error("unexpected link community for isolated node")
+	}
+
+	// At a low threshold the triangles are joined.
+	g = graphFromDOT(t, `graph { a -- b -- c -- a; c -- d -- e -- c }`)
+	LinkCommunities(g, 0.1)
+	checkPartition(t, g, "link_community", [][]string{{"a", "b", "c", "d", "e"}})
+}
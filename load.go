@@ -0,0 +1,368 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/encoding/dot"
+	dotfmt "gonum.org/v1/gonum/graph/formats/dot"
+	"gonum.org/v1/gonum/graph/formats/dot/ast"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Aggregation specifies how repeated numeric edge attributes are combined
+// during loading.
+type Aggregation int
+
+const (
+	// LastValue retains the last value seen for an attribute.
+	LastValue Aggregation = iota
+	// SumValues sums the values seen for an attribute.
+	SumValues
+	// MeanValues averages the values seen for an attribute.
+	MeanValues
+	// MaxValues retains the largest value seen for an attribute.
+	MaxValues
+)
+
+// LoadOptions holds options controlling how graphs are loaded.
+type LoadOptions struct {
+	// Aggregate specifies how numeric attribute values
+	// are combined when an edge is defined more than once.
+	// Attribute values that are not numeric are always
+	// replaced by the last value seen.
+	Aggregate Aggregation
+
+	// NumericKeys lists edge attribute keys that must hold
+	// numeric values. Values for these keys are written in
+	// canonical form and loading fails if a value cannot be
+	// parsed as a number.
+	NumericKeys []string
+
+	// ExpectedNodes and ExpectedEdges are hints for the
+	// number of nodes and edges in the graph. When set,
+	// nodes and edges are allocated in blocks of the
+	// given size.
+	ExpectedNodes, ExpectedEdges int
+}
+
+// NewGraphWithOptions reads a DOT file and returns the encoded graph, using
+// the provided options to control how edge attributes are handled. Syntax
+// errors in the file are returned as a *ParseError.
+func NewGraphWithOptions(file string, opts LoadOptions) (*Graph, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return readDOT(b, opts)
+}
+
+// ReadDOT reads DOT data from r and returns the encoded graph. Syntax
+// errors in the data are returned as a *ParseError.
+//
+// The ports of edge end points, for example the fields of record nodes or
+// the cells of HTML-like labels, are held in the "tailport" and "headport"
+// attributes of the edge, and are written back when the graph is rendered
+// as DOT. Record and HTML-like labels are held unchanged in the "label"
+// attribute of the node. Self edges, such as between two fields of the
+// same record node, are discarded since the graph cannot hold them.
+func ReadDOT(r io.Reader) (*Graph, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return readDOT(b, LoadOptions{})
+}
+
+// ReadAllDOT reads DOT data holding one or more graphs from r and returns
+// the encoded graphs in the order they appear. The DOT ID of each graph
+// is held in its Name field, and whether it is strict in its Strict
+// field.
+func ReadAllDOT(r io.Reader) ([]*Graph, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	f, err := dotfmt.ParseBytes(b)
+	if err != nil {
+		return nil, dotError(b, err)
+	}
+	graphs := make([]*Graph, len(f.Graphs))
+	for i, src := range f.Graphs {
+		one := &ast.File{Graphs: []*ast.Graph{src}}
+		graphs[i], err = readDOT([]byte(one.String()), LoadOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("graph %d: %v", i, err)
+		}
+	}
+	return graphs, nil
+}
+
+// readDOT returns the graph encoded in the DOT data in b.
+func readDOT(b []byte, opts LoadOptions) (*Graph, error) {
+	g := &Graph{UndirectedGraph: simple.NewUndirectedGraph(), Strict: isStrictDOT(b)}
+
+	clusters, err := readClusters(b)
+	if err != nil {
+		return nil, dotError(b, err)
+	}
+	g.clusters = clusters
+
+	err = dot.Unmarshal(b, newLoader(g, opts))
+	if err != nil {
+		return nil, dotError(b, err)
+	}
+
+	return g, nil
+}
+
+// isStrictDOT returns whether the first graph in the DOT data in b is
+// strict. The gonum DOT decoder does not report the strict keyword.
+func isStrictDOT(b []byte) bool {
+	b = b[skipDOTComments(b):]
+	const keyword = "strict"
+	if len(b) <= len(keyword) || !bytes.EqualFold(b[:len(keyword)], []byte(keyword)) {
+		return false
+	}
+	c := b[len(keyword)]
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '/' || c == '#'
+}
+
+// skipDOTComments returns the offset of the first byte of b that is not
+// white space or part of a DOT comment.
+func skipDOTComments(b []byte) int {
+	var off int
+	for {
+		i := len(b) - len(bytes.TrimLeft(b, " \t\r\n"))
+		off += i
+		b = b[i:]
+		switch {
+		case bytes.HasPrefix(b, []byte("//")), bytes.HasPrefix(b, []byte("#")):
+			i = bytes.IndexByte(b, '\n')
+			if i < 0 {
+				return off + len(b)
+			}
+			off += i + 1
+			b = b[i+1:]
+		case bytes.HasPrefix(b, []byte("/*")):
+			i = bytes.Index(b, []byte("*/"))
+			if i < 0 {
+				return off + len(b)
+			}
+			off += i + 2
+			b = b[i+2:]
+		default:
+			return off
+		}
+	}
+}
+
+// loader is an encoding.Builder that applies LoadOptions to
+// the edges of the graph being built.
+type loader struct {
+	*Graph
+
+	opts    LoadOptions
+	numeric map[string]bool
+	vals    map[edgeKey]*aggregate
+
+	nodes nodeArena
+	edges edgeArena
+}
+
+type edgeKey struct {
+	edge *Edge
+	key  string
+}
+
+type aggregate struct {
+	n        int
+	sum, max float64
+}
+
+func newLoader(g *Graph, opts LoadOptions) *loader {
+	l := &loader{
+		Graph:   g,
+		opts:    opts,
+		numeric: make(map[string]bool),
+		vals:    make(map[edgeKey]*aggregate),
+		nodes:   nodeArena{block: opts.ExpectedNodes},
+		edges:   edgeArena{block: opts.ExpectedEdges},
+	}
+	for _, k := range opts.NumericKeys {
+		l.numeric[k] = true
+	}
+	return l
+}
+
+// NewNode returns a new node allocated from the loader's node arena.
+func (l *loader) NewNode() graph.Node {
+	n := l.nodes.alloc()
+	n.NodeID = l.Graph.UndirectedGraph.NewNode().ID()
+	return n
+}
+
+// NewEdge returns an edge that aggregates its attributes according
+// to the loader's options. New edges are allocated from the loader's
+// edge arena. Self edges, for example between the fields of a record
+// node, cannot be held by the graph, so they are returned unattached
+// and are discarded by SetEdge.
+func (l *loader) NewEdge(from, to graph.Node) graph.Edge {
+	if from.ID() == to.ID() {
+		return loadEdge{Edge: &Edge{F: from.(*Node), T: to.(*Node)}, l: l}
+	}
+	e, ok := l.Graph.Edge(from.ID(), to.ID()).(*Edge)
+	if !ok {
+		e = l.edges.alloc()
+		e.F, e.T = from.(*Node), to.(*Node)
+		l.Graph.SetEdge(e)
+	}
+	return loadEdge{Edge: e, l: l}
+}
+
+// SetEdge adds the edge e to the graph being built.
+func (l *loader) SetEdge(e graph.Edge) {
+	if le, ok := e.(loadEdge); ok {
+		e = le.Edge
+	}
+	if e.From().ID() == e.To().ID() {
+		return
+	}
+	l.Graph.SetEdge(e)
+}
+
+// loadEdge is an *Edge wrapper used during loading.
+type loadEdge struct {
+	*Edge
+	l *loader
+}
+
+// SetAttribute sets the given attribute, combining numeric values
+// with any previously set value for the attribute.
+func (e loadEdge) SetAttribute(attr encoding.Attribute) error {
+	l := e.l
+	if attr.Value == "" || (l.opts.Aggregate == LastValue && !l.numeric[attr.Key]) {
+		return e.Edge.SetAttribute(attr)
+	}
+	v, err := strconv.ParseFloat(attr.Value, 64)
+	if err != nil {
+		if l.numeric[attr.Key] {
+			return fmt.Errorf("non-numeric value for %s: %v", attr.Key, err)
+		}
+		return e.Edge.SetAttribute(attr)
+	}
+
+	k := edgeKey{edge: e.Edge, key: attr.Key}
+	a, ok := l.vals[k]
+	if !ok {
+		a = &aggregate{max: math.Inf(-1)}
+		l.vals[k] = a
+	}
+	a.n++
+	a.sum += v
+	a.max = math.Max(a.max, v)
+
+	switch l.opts.Aggregate {
+	case LastValue:
+	case SumValues:
+		v = a.sum
+	case MeanValues:
+		v = a.sum / float64(a.n)
+	case MaxValues:
+		v = a.max
+	default:
+		return fmt.Errorf("invalid aggregation: %d", l.opts.Aggregate)
+	}
+	if a.n == 1 && !l.numeric[attr.Key] {
+		return e.Edge.SetAttribute(attr)
+	}
+	attr.Value = strconv.FormatFloat(v, 'g', -1, 64)
+	return e.Edge.SetAttribute(attr)
+}
+
+// SetFromPort sets the "tailport" attribute of the edge to the port of
+// its from node, for example a field of a record node.
+func (e loadEdge) SetFromPort(port, compass string) error {
+	return e.Edge.SetAttribute(encoding.Attribute{Key: "tailport", Value: portOf(port, compass)})
+}
+
+// SetToPort sets the "headport" attribute of the edge to the port of its
+// to node.
+func (e loadEdge) SetToPort(port, compass string) error {
+	return e.Edge.SetAttribute(encoding.Attribute{Key: "headport", Value: portOf(port, compass)})
+}
+
+// portOf returns the DOT port attribute value for the given port and
+// compass point.
+func portOf(port, compass string) string {
+	switch {
+	case compass == "":
+		return port
+	case port == "":
+		return compass
+	default:
+		return port + ":" + compass
+	}
+}
+
+// Default arena block sizes and the initial attribute
+// capacity of nodes and edges allocated from arenas.
+const (
+	defaultBlock = 256
+	nodeAttrCap  = 4
+	edgeAttrCap  = 2
+)
+
+// nodeArena allocates nodes and their attribute slices in blocks.
+type nodeArena struct {
+	block int
+	nodes []Node
+	attrs []encoding.Attribute
+}
+
+func (a *nodeArena) alloc() *Node {
+	if len(a.nodes) == 0 {
+		if a.block <= 0 {
+			a.block = defaultBlock
+		}
+		a.nodes = make([]Node, a.block)
+		a.attrs = make([]encoding.Attribute, a.block*nodeAttrCap)
+	}
+	n := &a.nodes[0]
+	n.Attributes = a.attrs[:0:nodeAttrCap]
+	a.nodes = a.nodes[1:]
+	a.attrs = a.attrs[nodeAttrCap:]
+	return n
+}
+
+// edgeArena allocates edges and their attribute slices in blocks.
+type edgeArena struct {
+	block int
+	edges []Edge
+	attrs []encoding.Attribute
+}
+
+func (a *edgeArena) alloc() *Edge {
+	if len(a.edges) == 0 {
+		if a.block <= 0 {
+			a.block = defaultBlock
+		}
+		a.edges = make([]Edge, a.block)
+		a.attrs = make([]encoding.Attribute, a.block*edgeAttrCap)
+	}
+	e := &a.edges[0]
+	e.Attributes = a.attrs[:0:edgeAttrCap]
+	a.edges = a.edges[1:]
+	a.attrs = a.attrs[edgeAttrCap:]
+	return e
+}
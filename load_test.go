@@ -0,0 +1,217 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+var aggregateTests = []struct {
+	dot  string
+	opts LoadOptions
+	want map[string]string
+}{
+	{
+		dot:  `graph { a -- b [w=1, c=red]; a -- b [w=3, c=blue]; a -- b [w=2] }`,
+		opts: LoadOptions{Aggregate: LastValue},
+		want: map[string]string{"w": "2", "c": "blue"},
+	},
+	{
+		dot:  `graph { a -- b [w=1, c=red]; a -- b [w=3, c=blue]; a -- b [w=2] }`,
+		opts: LoadOptions{Aggregate: SumValues},
+		want: map[string]string{"w": "6", "c": "blue"},
+	},
+	{
+		dot:  `graph { a -- b [w=1, c=red]; a -- b [w=3, c=blue]; a -- b [w=2] }`,
+		opts: LoadOptions{Aggregate: MeanValues},
+		want: map[string]string{"w": "2", "c": "blue"},
+	},
+	{
+		dot:  `graph { a -- b [w=1, c=red]; a -- b [w=3, c=blue]; a -- b [w=2] }`,
+		opts: LoadOptions{Aggregate: MaxValues},
+		want: map[string]string{"w": "3", "c": "blue"},
+	},
+	{
+		dot:  `graph { a -- b [w=1.50] }`,
+		opts: LoadOptions{Aggregate: SumValues},
+		want: map[string]string{"w": "1.50"},
+	},
+	{
+		dot:  `graph { a -- b [w=1.50] }`,
+		opts: LoadOptions{NumericKeys: []string{"w"}},
+		want: map[string]string{"w": "1.5"},
+	},
+}
+
+func TestLoadAggregation(t *testing.T) {
+	for i, test := range aggregateTests {
+		g, err := readDOT([]byte(test.dot), test.opts)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		a, b := g.NodeNamed("a"), g.NodeNamed("b")
+		e := g.EdgeBetween(a.ID(), b.ID()).(*Edge)
+		for k, want := range test.want {
+			if got := e.Get(k); got != want {
+				t.Errorf("unexpected value of %s for test %d: got:%s want:%s", k, i, got, want)
+			}
+		}
+	}
+}
+
+func TestLoadNumericKeys(t *testing.T) {
+	_, err := readDOT([]byte(`graph { a -- b [w=heavy] }`), LoadOptions{NumericKeys: []string{"w"}})
+	if err == nil {
+		t.Error("expected error for non-numeric value of numeric key")
+	}
+}
+
+func TestLoadArena(t *testing.T) {
+	const src = `graph {
+	a [p=1, q=2, r=3, s=4, t=5, u=6];
+	b [p=7];
+	c;
+	a -- b [w=1, x=2, y=3];
+	b -- c [w=4];
+	c -- a;
+}`
+	ref, err := readDOT([]byte(src), LoadOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, block := range []int{1, 2, 1000} {
+		g, err := readDOT([]byte(src), LoadOptions{ExpectedNodes: block, ExpectedEdges: block})
+		if err != nil {
+			t.Errorf("unexpected error for block size %d: %v", block, err)
+			continue
+		}
+		g.NodeNamed("b").SetAttribute(encoding.Attribute{Key: "q", Value: "8"})
+		if got := g.NodeNamed("a").Get("q"); got != "2" {
+			t.Errorf("unexpected attribute change for block size %d: got:%s want:2", block, got)
+		}
+		g.NodeNamed("b").SetAttribute(encoding.Attribute{Key: "q", Value: ""})
+		if got, want := DOT(g), DOT(ref); got != want {
+			t.Errorf("unexpected graph for block size %d:\ngot:\n%s\nwant:\n%s", block, got, want)
+		}
+	}
+}
+
+var portTests = []struct {
+	dot        string
+	tail, head string
+	edges      int
+}{
+	{
+		dot:   `graph { a -- b }`,
+		edges: 1,
+	},
+	{
+		dot:   `graph { a:f0 -- b }`,
+		tail:  "f0",
+		edges: 1,
+	},
+	{
+		dot:   `graph { b:w -- a:ne }`,
+		tail:  "ne",
+		head:  "w",
+		edges: 1,
+	},
+	{
+		dot:   `graph { a [shape=record, label="<f0> x|<f1> y"]; b; a:f0 -- b:w; b:e -- a:f1 }`,
+		tail:  "f1",
+		head:  "e",
+		edges: 1,
+	},
+	{
+		dot:   `digraph { a [shape=record, label="<f0> x|<f1> y"]; a:f0:s -> b:n; a:f0 -> a:f1; b -> a:f1:e }`,
+		tail:  "f1:e",
+		head:  "n",
+		edges: 1,
+	},
+}
+
+func TestLoadPorts(t *testing.T) {
+	for i, test := range portTests {
+		g := graphFromDOT(t, test.dot)
+		if n := g.Edges().Len(); n != test.edges {
+			t.Errorf("unexpected number of edges for test %d: got:%d want:%d", i, n, test.edges)
+		}
+		a, b := g.NodeNamed("a"), g.NodeNamed("b")
+		e := g.EdgeBetween(a.ID(), b.ID()).(*Edge)
+		if e.F != a {
+			e = e.ReversedEdge().(*Edge)
+		}
+		if got := e.Get("tailport"); got != test.tail {
+			t.Errorf("unexpected tail port for test %d: got:%s want:%s", i, got, test.tail)
+		}
+		if got := e.Get("headport"); got != test.head {
+			t.Errorf("unexpected head port for test %d: got:%s want:%s", i, got, test.head)
+		}
+
+		// Ports must survive a round trip through DOT.
+		want := DOT(g)
+		if got := DOT(graphFromDOT(t, want)); got != want {
+			t.Errorf("unexpected round trip DOT for test %d:\ngot:\n%s\nwant:\n%s", i, got, want)
+		}
+	}
+}
+
+func TestLoadLabels(t *testing.T) {
+	g := graphFromDOT(t, `graph { a [shape=record, label="<f0> x|{<f1> y|z}"]; b [label=<<b>B</b><br/>c>]; a:f0 -- b }`)
+	if got, want := g.NodeNamed("a").GetUnquoted("label"), "<f0> x|{<f1> y|z}"; got != want {
+		t.Errorf("unexpected record label: got:%s want:%s", got, want)
+	}
+	if got, want := g.NodeNamed("b").Get("label"), "<<b>B</b><br/>c>"; got != want {
+		t.Errorf("unexpected HTML-like label: got:%s want:%s", got, want)
+	}
+}
+
+func TestReadAllDOT(t *testing.T) {
+	const src = `graph first { a -- b }
+// A comment between graphs.
+strict graph "second one" { c -- d; d -- e }
+graph { x }
+`
+	want := []struct {
+		name         string
+		strict       bool
+		nodes, edges int
+	}{
+		{name: "first", nodes: 2, edges: 1},
+		{name: "second one", strict: true, nodes: 3, edges: 2},
+		{nodes: 1},
+	}
+	graphs, err := ReadAllDOT(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(graphs) != len(want) {
+		t.Fatalf("unexpected number of graphs: got:%d want:%d", len(graphs), len(want))
+	}
+	for i, g := range graphs {
+		if g.Name != want[i].name || g.Strict != want[i].strict {
+			t.Errorf("unexpected header for graph %d: got:%q strict=%t want:%q strict=%t", i, g.Name, g.Strict, want[i].name, want[i].strict)
+		}
+		if n := g.Nodes().Len(); n != want[i].nodes {
+			t.Errorf("unexpected number of nodes for graph %d: got:%d want:%d", i, n, want[i].nodes)
+		}
+		if n := g.Edges().Len(); n != want[i].edges {
+			t.Errorf("unexpected number of edges for graph %d: got:%d want:%d", i, n, want[i].edges)
+		}
+	}
+
+	// Each graph must be read independently.
+	if graphs[1].NodeNamed("a") != nil {
+		t.Error("unexpected node from first graph in second graph")
+	}
+
+	if _, err := ReadAllDOT(strings.NewReader("graph { a -- b }\ngraph { c -- }")); err == nil {
+		t.Error("expected error for malformed second graph")
+	}
+}
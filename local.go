@@ -0,0 +1,195 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"sort"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// LocalMethod is a seeded local community detection method.
+type LocalMethod int
+
+const (
+	// PPRSweep ranks nodes by their approximate personalised
+	// PageRank from the seed, normalised by degree, and returns
+	// the prefix of the ranking with the lowest conductance.
+	PPRSweep LocalMethod = iota
+
+	// GreedyConductance grows the community from the seed by
+	// repeatedly adding the neighbouring node that most reduces
+	// the community's conductance, stopping when no node does.
+	GreedyConductance
+)
+
+const (
+	// pprAlpha is the teleport probability used by PPRSweep.
+	pprAlpha = 0.15
+	// pprEpsilon is the residual tolerance used by PPRSweep.
+	pprEpsilon = 1e-4
+)
+
+// LocalCommunity returns the community around the node with the DOT ID
+// seed found using the given method, without computing a partition of the
+// whole graph. The seed is always included in the returned community. If
+// there is no node named seed in g, LocalCommunity returns nil.
+func LocalCommunity(g *Graph, seed string, method LocalMethod) []*Node {
+	s := g.NodeNamed(seed)
+	if s == nil {
+		return nil
+	}
+	switch method {
+	case PPRSweep:
+		return pprSweep(g, s)
+	case GreedyConductance:
+		return greedyConductance(g, s)
+	default:
+		panic("graphprac: invalid local method")
+	}
+}
+
+// pprSweep returns the lowest conductance sweep cut of the degree
+// normalised approximate personalised PageRank vector from s.
+func pprSweep(g *Graph, s *Node) []*Node {
+	p := approxPPR(g, s.ID(), pprAlpha, pprEpsilon)
+	order := make([]int64, 0, len(p))
+	for id := range p {
+		order = append(order, id)
+	}
+	deg := func(id int64) int { return g.From(id).Len() }
+	sort.Slice(order, func(i, j int) bool {
+		pi := p[order[i]] / float64(deg(order[i]))
+		pj := p[order[j]] / float64(deg(order[j]))
+		if pi != pj {
+			return pi > pj
+		}
+		return order[i] < order[j]
+	})
+
+	total := 2 * g.Edges().Len()
+	in := make(map[int64]bool)
+	var cut, vol int
+	best, bestPhi := 1, 2.0
+	for i, id := range order {
+		cut, vol = addToCut(g, in, id, cut, vol)
+		if vol >= total {
+			break
+		}
+		phi := conductance(cut, vol, total)
+		if phi < bestPhi {
+			best, bestPhi = i+1, phi
+		}
+	}
+
+	nodes := make([]*Node, best)
+	for i, id := range order[:best] {
+		nodes[i] = g.Node(id).(*Node)
+	}
+	return nodes
+}
+
+// approxPPR returns an approximate personalised PageRank vector seeded at
+// the node with ID s using the push algorithm of Andersen, Chung and Lang.
+// Only nodes with non-zero rank are included in the returned map.
+func approxPPR(g graph.Graph, s int64, alpha, eps float64) map[int64]float64 {
+	p := make(map[int64]float64)
+	r := map[int64]float64{s: 1}
+	queue := []int64{s}
+	queued := map[int64]bool{s: true}
+	for len(queue) != 0 {
+		u := queue[0]
+		queue = queue[1:]
+		queued[u] = false
+		d := float64(g.From(u).Len())
+		if d == 0 {
+			p[u] += r[u]
+			r[u] = 0
+			continue
+		}
+		if r[u] < eps*d {
+			continue
+		}
+		ru := r[u]
+		p[u] += alpha * ru
+		r[u] = (1 - alpha) * ru / 2
+		share := (1 - alpha) * ru / (2 * d)
+		if !queued[u] && r[u] >= eps*d {
+			queue = append(queue, u)
+			queued[u] = true
+		}
+		for _, v := range graph.NodesOf(g.From(u)) {
+			vid := v.ID()
+			r[vid] += share
+			if !queued[vid] && r[vid] >= eps*float64(g.From(vid).Len()) {
+				queue = append(queue, vid)
+				queued[vid] = true
+			}
+		}
+	}
+	return p
+}
+
+// greedyConductance grows a community from s by adding the neighbouring
+// node that gives the lowest conductance until no addition reduces it.
+func greedyConductance(g *Graph, s *Node) []*Node {
+	total := 2 * g.Edges().Len()
+	in := make(map[int64]bool)
+	cut, vol := addToCut(g, in, s.ID(), 0, 0)
+	phi := conductance(cut, vol, total)
+	nodes := []*Node{s}
+	for {
+		var (
+			next             int64 = -1
+			nextCut, nextVol int
+		)
+		nextPhi := phi
+		frontier := make(map[int64]bool)
+		for id := range in {
+			for _, v := range graph.NodesOf(g.From(id)) {
+				if !in[v.ID()] {
+					frontier[v.ID()] = true
+				}
+			}
+		}
+		for id := range frontier {
+			c, v := cutDelta(g, in, id, cut, vol)
+			if v >= total {
+				continue
+			}
+			p := conductance(c, v, total)
+			if p < nextPhi || (p == nextPhi && next >= 0 && id < next) {
+				next, nextCut, nextVol, nextPhi = id, c, v, p
+			}
+		}
+		if next < 0 {
+			return nodes
+		}
+		in[next] = true
+		cut, vol, phi = nextCut, nextVol, nextPhi
+		nodes = append(nodes, g.Node(next).(*Node))
+	}
+}
+
+// addToCut adds the node with the given ID to the set in, returning the
+// updated cut size and volume of the set.
+func addToCut(g graph.Graph, in map[int64]bool, id int64, cut, vol int) (int, int) {
+	cut, vol = cutDelta(g, in, id, cut, vol)
+	in[id] = true
+	return cut, vol
+}
+
+// cutDelta returns the cut size and volume of the set in after the
+// addition of the node with the given ID, without modifying in.
+func cutDelta(g graph.Graph, in map[int64]bool, id int64, cut, vol int) (int, int) {
+	var d, k int
+	for _, v := range graph.NodesOf(g.From(id)) {
+		d++
+		if in[v.ID()] {
+			k++
+		}
+	}
+	return cut + d - 2*k, vol + d
+}
@@ -0,0 +1,151 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// Logger is the interface used to report analysis events. It is satisfied
+// by *slog.Logger from the standard library's log/slog package.
+type Logger interface {
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+}
+
+var (
+	loggerMu sync.RWMutex
+	logger   Logger
+)
+
+// SetLogger sets the logger used to report calls to the registered
+// analyses, whether they are called directly or with Run. Each call logs
+// an "analysis start" event with the analysis name, the size of the graph
+// and the analysis parameters, and an "analysis done" event with the wall
+// clock duration and a summary of the values written. Passing nil disables
+// logging, which is the default.
+func SetLogger(l Logger) {
+	loggerMu.Lock()
+	logger = l
+	loggerMu.Unlock()
+}
+
+// analysed is a graph whose analyses are traced by trace. It is
+// implemented by *Graph and *Digraph.
+type analysed interface {
+	Nodes() graph.Nodes
+	Edges() graph.Edges
+
+	// tracing returns the state
+	// used to trace analyses.
+	tracing() *analysisState
+
+	// graphAttributes returns the
+	// graph attributes of the graph.
+	graphAttributes() *Attributes
+}
+
+// trace logs the start of the named analysis of g with the provided
+// parameter key-value pairs and returns a function that is called with
+// the error returned by the analysis. If the error is nil, the function
+// records the resource use of the analysis and the provenance of the
+// attributes it wrote, and logs its completion with a summary of the
+// first of attrs; otherwise it only logs the failure. The returned
+// function is intended to be deferred at the start of an analysis. If the
+// analysis would overwrite any of attrs, trace performs the checks
+// described by SetAnalysisOptions, and returns the *OverwriteError if the
+// analysis is refused.
+func trace(g analysed, name string, attrs []string, params ...interface{}) (func(error), error) {
+	err := checkOverwrite(g, attrs, name, params...)
+	if err != nil {
+		return nil, err
+	}
+	loggerMu.RLock()
+	l := logger
+	loggerMu.RUnlock()
+	if l != nil {
+		args := []interface{}{"analysis", name, "nodes", g.Nodes().Len(), "edges", g.Edges().Len()}
+		l.Info("analysis start", append(args, params...)...)
+	}
+
+	// Reading memory statistics stops the world,
+	// so only do it when the result is used.
+	measure := l != nil || recordingTimings()
+	var before runtime.MemStats
+	if measure {
+		runtime.ReadMemStats(&before)
+	}
+	start := time.Now()
+	return func(err error) {
+		d := time.Since(start)
+		if err != nil {
+			if l != nil {
+				l.Warn("analysis failed", "analysis", name, "duration", d, "error", err)
+			}
+			return
+		}
+		var bytes, allocs uint64
+		if measure {
+			var after runtime.MemStats
+			runtime.ReadMemStats(&after)
+			bytes = after.TotalAlloc - before.TotalAlloc
+			allocs = after.Mallocs - before.Mallocs
+		}
+		record(g, name, d, bytes, allocs)
+		setProvenance(g, attrs, name, params...)
+
+		if l != nil {
+			args := []interface{}{"analysis", name, "duration", d, "bytes", bytes, "allocs", allocs, "attribute", attrs[0]}
+			l.Info("analysis done", append(args, summarize(g, attrs[0])...)...)
+		}
+	}, nil
+}
+
+// summarize returns key-value pairs summarising the values of the attr
+// attribute of the nodes of g, or of its edges if no node has the
+// attribute. The summary holds the number of values and of distinct
+// values, and the range and mean if all the values are numerical.
+func summarize(g analysed, attr string) []interface{} {
+	var vals []string
+	for _, n := range graph.NodesOf(g.Nodes()) {
+		if v := n.(*Node).GetUnquoted(attr); v != "" {
+			vals = append(vals, v)
+		}
+	}
+	if len(vals) == 0 {
+		for _, e := range graph.EdgesOf(g.Edges()) {
+			if v := e.(*Edge).GetUnquoted(attr); v != "" {
+				vals = append(vals, v)
+			}
+		}
+	}
+
+	distinct := make(map[string]bool)
+	for _, v := range vals {
+		distinct[v] = true
+	}
+	summary := []interface{}{"count", len(vals), "distinct", len(distinct)}
+	if len(vals) == 0 {
+		return summary
+	}
+	min, max := math.Inf(1), math.Inf(-1)
+	var sum float64
+	for _, v := range vals {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return summary
+		}
+		min = math.Min(min, f)
+		max = math.Max(max, f)
+		sum += f
+	}
+	return append(summary, "min", min, "mean", sum/float64(len(vals)), "max", max)
+}
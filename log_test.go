@@ -0,0 +1,84 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"sync"
+	"testing"
+)
+
+// testLogger is a Logger that records logged events.
+type testLogger struct {
+	mu     sync.Mutex
+	events []logEvent
+}
+
+type logEvent struct {
+	level, msg string
+	args       []interface{}
+}
+
+func (l *testLogger) Info(msg string, args ...interface{}) { l.log("info", msg, args) }
+func (l *testLogger) Warn(msg string, args ...interface{}) { l.log("warn", msg, args) }
+
+func (l *testLogger) log(level, msg string, args []interface{}) {
+	l.mu.Lock()
+	l.events = append(l.events, logEvent{level: level, msg: msg, args: args})
+	l.mu.Unlock()
+}
+
+// arg returns the value logged for key.
+func (e logEvent) arg(key string) interface{} {
+	for i := 0; i+1 < len(e.args); i += 2 {
+		if e.args[i] == key {
+			return e.args[i+1]
+		}
+	}
+	return nil
+}
+
+func TestLogger(t *testing.T) {
+	l := &testLogger{}
+	SetLogger(l)
+	defer SetLogger(nil)
+
+	g := graphFromDOT(t, `graph { a -- b -- c }`)
+	Eccentricity(g)
+
+	if len(l.events) != 2 {
+		t.Fatalf("unexpected number of events: got:%d want:2\n%+v", len(l.events), l.events)
+	}
+	start, done := l.events[0], l.events[1]
+	if start.msg != "analysis start" || done.msg != "analysis done" {
+		t.Errorf("unexpected messages: got:%q and %q want:%q and %q", start.msg, done.msg, "analysis start", "analysis done")
+	}
+	for _, test := range []struct {
+		event logEvent
+		key   string
+		want  interface{}
+	}{
+		{event: start, key: "analysis", want: "Eccentricity"},
+		{event: start, key: "nodes", want: 3},
+		{event: start, key: "edges", want: 2},
+		{event: done, key: "analysis", want: "Eccentricity"},
+		{event: done, key: "attribute", want: "eccentricity"},
+		{event: done, key: "count", want: 3},
+		{event: done, key: "distinct", want: 2},
+		{event: done, key: "min", want: 1.0},
+		{event: done, key: "mean", want: 5.0 / 3},
+		{event: done, key: "max", want: 2.0},
+	} {
+		if got := test.event.arg(test.key); got != test.want {
+			t.Errorf("unexpected %s in %q event: got:%v want:%v", test.key, test.event.msg, got, test.want)
+		}
+	}
+
+	l.events = nil
+	SetLogger(nil)
+	Eccentricity(g)
+	if len(l.events) != 0 {
+		t.Errorf("unexpected events after logging disabled: %+v", l.events)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// The matrix constructors below return matrices with rows and columns
+// ordered by the dense node index returned by g.Index().
+
+// degrees returns the degree of each node of g ordered by x.
+func degrees(g *Graph, x NodeIndex) []float64 {
+	d := make([]float64, x.Len())
+	for i := range d {
+		d[i] = float64(g.From(x.ID(i)).Len())
+	}
+	return d
+}
+
+// AdjacencyMatrix returns the adjacency matrix of g, A, where A[i,j] is 1
+// if there is an edge between nodes i and j and 0 otherwise.
+func AdjacencyMatrix(g *Graph) *mat.SymDense {
+	x := g.Index()
+	a := mat.NewSymDense(x.Len(), nil)
+	for i, to := range x.adjacency(g) {
+		for _, j := range to {
+			a.SetSym(i, j, 1)
+		}
+	}
+	return a
+}
+
+// LaplacianMatrix returns the graph Laplacian of g, L = D - A, where D is
+// the diagonal degree matrix and A is the adjacency matrix.
+func LaplacianMatrix(g *Graph) *mat.SymDense {
+	x := g.Index()
+	l := mat.NewSymDense(x.Len(), nil)
+	for i, to := range x.adjacency(g) {
+		l.SetSym(i, i, float64(len(to)))
+		for _, j := range to {
+			l.SetSym(i, j, -1)
+		}
+	}
+	return l
+}
+
+// NormalizedLaplacian returns the symmetric normalised graph Laplacian of
+// g, L = I - D^-1/2 A D^-1/2. The diagonal elements for isolated nodes are
+// zero.
+func NormalizedLaplacian(g *Graph) *mat.SymDense {
+	x := g.Index()
+	n := x.Len()
+	deg := degrees(g, x)
+	l := mat.NewSymDense(n, nil)
+	for i, to := range x.adjacency(g) {
+		if deg[i] != 0 {
+			l.SetSym(i, i, 1)
+		}
+		for _, j := range to {
+			l.SetSym(i, j, -1/math.Sqrt(deg[i]*deg[j]))
+		}
+	}
+	return l
+}
+
+// TransitionMatrix returns the random walk transition matrix of g,
+// P = D^-1 A, where P[i,j] is the probability of a step from node i to
+// node j. The rows for isolated nodes are zero.
+func TransitionMatrix(g *Graph) *mat.Dense {
+	x := g.Index()
+	n := x.Len()
+	p := mat.NewDense(n, n, nil)
+	for i, to := range x.adjacency(g) {
+		for _, j := range to {
+			p.Set(i, j, 1/float64(len(to)))
+		}
+	}
+	return p
+}
@@ -0,0 +1,73 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// The graph a -- b -- c with the isolated node d.
+const matrixGraph = `graph { a -- b -- c; d }`
+
+var matrixTests = []struct {
+	name string
+	fn   func(*Graph) mat.Matrix
+	want *mat.Dense
+}{
+	{
+		name: "adjacency",
+		fn:   func(g *Graph) mat.Matrix { return AdjacencyMatrix(g) },
+		want: mat.NewDense(4, 4, []float64{
+			0, 1, 0, 0,
+			1, 0, 1, 0,
+			0, 1, 0, 0,
+			0, 0, 0, 0,
+		}),
+	},
+	{
+		name: "laplacian",
+		fn:   func(g *Graph) mat.Matrix { return LaplacianMatrix(g) },
+		want: mat.NewDense(4, 4, []float64{
+			1, -1, 0, 0,
+			-1, 2, -1, 0,
+			0, -1, 1, 0,
+			0, 0, 0, 0,
+		}),
+	},
+	{
+		name: "normalized laplacian",
+		fn:   func(g *Graph) mat.Matrix { return NormalizedLaplacian(g) },
+		want: mat.NewDense(4, 4, []float64{
+			1, -1 / math.Sqrt2, 0, 0,
+			-1 / math.Sqrt2, 1, -1 / math.Sqrt2, 0,
+			0, -1 / math.Sqrt2, 1, 0,
+			0, 0, 0, 0,
+		}),
+	},
+	{
+		name: "transition",
+		fn:   func(g *Graph) mat.Matrix { return TransitionMatrix(g) },
+		want: mat.NewDense(4, 4, []float64{
+			0, 1, 0, 0,
+			0.5, 0, 0.5, 0,
+			0, 1, 0, 0,
+			0, 0, 0, 0,
+		}),
+	},
+}
+
+func TestMatrices(t *testing.T) {
+	for _, test := range matrixTests {
+		g := graphFromDOT(t, matrixGraph)
+		got := test.fn(g)
+		if !mat.EqualApprox(got, test.want, 1e-12) {
+			t.Errorf("unexpected %s matrix:\ngot:\n%v\nwant:\n%v",
+				test.name, mat.Formatted(got), mat.Formatted(test.want))
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// ReadMETIS reads a graph in the METIS graph file format from r. The file
+// holds a header line "n m [fmt [ncon]]" followed by a line for each of the
+// n vertices listing its neighbours, numbered from 1, with each of the m
+// edges listed by both of its ends. Lines starting with '%' are comments.
+// The fmt flags specify whether each vertex line starts with a vertex size
+// and ncon vertex weights, and whether each neighbour is followed by an
+// edge weight.
+//
+// The nodes of the returned graph have DOT IDs and node IDs given by their
+// vertex number and vertex number less one. Vertex sizes are written into
+// the "size" attribute of each node and vertex weights into the "weight"
+// attribute, or when there is more than one weight per vertex, into the
+// "weight_i" attributes for the weights numbered from 0. Edge weights are
+// written into the "weight" attribute of each edge. ReadMETIS returns an
+// error if the number of edges does not match the header.
+func ReadMETIS(r io.Reader) (*Graph, error) {
+	sc := bufio.NewScanner(r)
+	line := 0
+	next := func() (string, bool) {
+		for sc.Scan() {
+			line++
+			text := sc.Text()
+			if strings.HasPrefix(text, "%") {
+				continue
+			}
+			return text, true
+		}
+		return "", false
+	}
+
+	var header string
+	for {
+		text, ok := next()
+		if !ok {
+			if err := sc.Err(); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("missing header")
+		}
+		if strings.TrimSpace(text) != "" {
+			header = text
+			break
+		}
+	}
+	f := strings.Fields(header)
+	if len(f) < 2 || len(f) > 4 {
+		return nil, &ParseError{Line: line, Text: header, Err: fmt.Errorf("invalid header")}
+	}
+	var h [4]int
+	for i, s := range f {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			return nil, &ParseError{Line: line, Text: header, Err: fmt.Errorf("invalid header field %q", s)}
+		}
+		h[i] = v
+	}
+	n, m := h[0], h[1]
+	var hasSize, hasVertexWeight, hasEdgeWeight bool
+	if len(f) > 2 {
+		flags := fmt.Sprintf("%03s", f[2])
+		if len(flags) != 3 || strings.Trim(flags, "01") != "" {
+			return nil, &ParseError{Line: line, Text: header, Err: fmt.Errorf("invalid format %q", f[2])}
+		}
+		hasSize, hasVertexWeight, hasEdgeWeight = flags[0] == '1', flags[1] == '1', flags[2] == '1'
+	}
+	ncon := 0
+	if hasVertexWeight {
+		ncon = 1
+		if len(f) > 3 {
+			ncon = h[3]
+		}
+	}
+	weightKeys := []string{"weight"}
+	if ncon > 1 {
+		weightKeys = make([]string, ncon)
+		for i := range weightKeys {
+			weightKeys[i] = fmt.Sprintf("weight_%d", i)
+		}
+	}
+
+	g := numberedGraph(n)
+	for i := 0; i < n; i++ {
+		text, ok := next()
+		if !ok {
+			if err := sc.Err(); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("unexpected number of vertices: got=%d want=%d", i, n)
+		}
+		f := strings.Fields(text)
+		vals := make([]int64, len(f))
+		for j, s := range f {
+			v, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("invalid field %q", s)}
+			}
+			vals[j] = v
+		}
+		u := g.Node(int64(i)).(*Node)
+		if hasSize {
+			if len(vals) == 0 {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("missing vertex size")}
+			}
+			u.SetAttribute(encoding.Attribute{Key: "size", Value: strconv.FormatInt(vals[0], 10)})
+			vals = vals[1:]
+		}
+		if len(vals) < ncon {
+			return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("missing vertex weights")}
+		}
+		for j, key := range weightKeys[:ncon] {
+			u.SetAttribute(encoding.Attribute{Key: key, Value: strconv.FormatInt(vals[j], 10)})
+		}
+		vals = vals[ncon:]
+
+		step := 1
+		if hasEdgeWeight {
+			step = 2
+			if len(vals)%2 != 0 {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("missing edge weight")}
+			}
+		}
+		for j := 0; j < len(vals); j += step {
+			v, ok := g.Node(vals[j] - 1).(*Node)
+			if !ok {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("vertex %d out of range [1,%d]", vals[j], n)}
+			}
+			if v == u {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("self edge")}
+			}
+			e := g.NewEdge(u, v).(*Edge)
+			if hasEdgeWeight {
+				e.SetAttribute(encoding.Attribute{Key: "weight", Value: strconv.FormatInt(vals[j+1], 10)})
+			}
+		}
+	}
+	for {
+		text, ok := next()
+		if !ok {
+			break
+		}
+		if strings.TrimSpace(text) != "" {
+			return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("unexpected vertex line")}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if got := g.Edges().Len(); got != m {
+		return nil, fmt.Errorf("unexpected number of edges: got=%d want=%d", got, m)
+	}
+	return g, nil
+}
+
+// WriteMETIS writes g to w in the METIS graph file format, with vertices
+// numbered from 1 in the order of g.Index(). If any node has a "weight"
+// attribute, vertex weights are written, with a weight of 1 for nodes
+// without one, and if any edge has a "weight" attribute, edge weights are
+// written, with a weight of 1 for edges without one. WriteMETIS returns an
+// error if a weight is not a non-negative integer, as required by METIS.
+func WriteMETIS(g *Graph, w io.Writer) error {
+	x := g.Index()
+	adj := x.adjacency(g)
+
+	var hasVertexWeight, hasEdgeWeight bool
+	vw := make([]int64, x.Len())
+	for i := range vw {
+		n := x.Node(i)
+		vw[i] = 1
+		weight := exportAttributes(n.Attributes).GetUnquoted("weight")
+		if weight == "" {
+			continue
+		}
+		hasVertexWeight = true
+		v, err := metisWeight(weight)
+		if err != nil {
+			return fmt.Errorf("invalid weight for node %q: %v", n.Name, err)
+		}
+		vw[i] = v
+	}
+	ew := make([][]int64, len(adj))
+	for i, to := range adj {
+		ew[i] = make([]int64, len(to))
+		for k, j := range to {
+			e := g.Edge(x.ID(i), x.ID(j)).(*Edge)
+			ew[i][k] = 1
+			weight := exportAttributes(e.Attributes).GetUnquoted("weight")
+			if weight == "" {
+				continue
+			}
+			hasEdgeWeight = true
+			v, err := metisWeight(weight)
+			if err != nil {
+				return fmt.Errorf("invalid weight for edge %q--%q: %v", e.F.Name, e.T.Name, err)
+			}
+			ew[i][k] = v
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%d %d", x.Len(), g.Edges().Len())
+	switch {
+	case hasVertexWeight && hasEdgeWeight:
+		fmt.Fprint(bw, " 011")
+	case hasVertexWeight:
+		fmt.Fprint(bw, " 010")
+	case hasEdgeWeight:
+		fmt.Fprint(bw, " 001")
+	}
+	fmt.Fprintln(bw)
+	for i, to := range adj {
+		var fields []string
+		if hasVertexWeight {
+			fields = append(fields, strconv.FormatInt(vw[i], 10))
+		}
+		for k, j := range to {
+			fields = append(fields, strconv.Itoa(j+1))
+			if hasEdgeWeight {
+				fields = append(fields, strconv.FormatInt(ew[i][k], 10))
+			}
+		}
+		fmt.Fprintln(bw, strings.Join(fields, " "))
+	}
+	return bw.Flush()
+}
+
+// metisWeight returns the METIS weight in s, which must be a non-negative
+// integer.
+func metisWeight(s string) (int64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 || v != math.Trunc(v) || v > math.MaxInt64 {
+		return 0, fmt.Errorf("not a non-negative integer: %v", v)
+	}
+	return int64(v), nil
+}
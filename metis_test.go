@@ -0,0 +1,100 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"strings"
+	"testing"
+)
+
+var metisRoundTripTests = []string{
+	"0 0\n",
+	"3 2\n2\n1 3\n2\n",
+	"3 1\n\n3\n2\n",
+	"3 2 010\n4 2\n1 1 3\n2 2\n",
+	"3 2 001\n2 7\n1 7 3 1\n2 1\n",
+	"4 3 011\n1 2 3 3 3\n2 1 3\n1 1 3 4 1\n7 3 1\n",
+}
+
+func TestMETISRoundTrip(t *testing.T) {
+	for i, src := range metisRoundTripTests {
+		g, err := ReadMETIS(strings.NewReader(src))
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		var buf strings.Builder
+		if err := WriteMETIS(g, &buf); err != nil {
+			t.Errorf("unexpected error writing test %d: %v", i, err)
+			continue
+		}
+		if got := buf.String(); got != src {
+			t.Errorf("unexpected METIS for test %d:\ngot:\n%s\nwant:\n%s", i, got, src)
+		}
+	}
+}
+
+func TestReadMETIS(t *testing.T) {
+	g, err := ReadMETIS(strings.NewReader("% Sizes and two weights.\n3 2 111 2\n4 1 2 2 7\n1 0 0 1 7 3 1\n1 5 5 2 1\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `graph {
+  // Node definitions.
+  1 [
+    size=4
+    weight_0=1
+    weight_1=2
+  ];
+  2 [
+    size=1
+    weight_0=0
+    weight_1=0
+  ];
+  3 [
+    size=1
+    weight_0=5
+    weight_1=5
+  ];
+
+  // Edge definitions.
+  1 -- 2 [weight=7];
+  2 -- 3 [weight=1];
+}`
+	if got := DOTWithOptions(g, DOTOptions{SortAttributes: true}); got != want {
+		t.Errorf("unexpected graph:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+var metisErrorTests = []string{
+	"",
+	"3\n",
+	"3 2\n2\n1 3\n",
+	"3 3\n2\n1 3\n2\n",
+	"3 2\n2\n1 4\n2\n",
+	"3 2 001\n2\n1 7 3 1\n2 1\n",
+	"3 2 010\nheavy 2\n1 1 3\n2 2\n",
+}
+
+func TestReadMETISErrors(t *testing.T) {
+	for i, src := range metisErrorTests {
+		if _, err := ReadMETIS(strings.NewReader(src)); err == nil {
+			t.Errorf("expected error for test %d", i)
+		}
+	}
+}
+
+func TestWriteMETISInvalidWeight(t *testing.T) {
+	for i, dot := range []string{
+		`graph { a [weight=1.5]; a -- b }`,
+		`graph { a [weight=-1]; a -- b }`,
+		`graph { a -- b [weight=heavy] }`,
+	} {
+		g := graphFromDOT(t, dot)
+		if err := WriteMETIS(g, &strings.Builder{}); err == nil {
+			t.Errorf("expected error for test %d", i)
+		}
+	}
+}
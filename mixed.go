@@ -0,0 +1,272 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/encoding/dot"
+	dotfmt "gonum.org/v1/gonum/graph/formats/dot"
+	"gonum.org/v1/gonum/graph/formats/dot/ast"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Mixed is a graph holding both directed and undirected edges, as read
+// from DOT data that uses both the "->" and "--" edge operators. Each edge
+// has a "directed" attribute of "true" or "false", and directed edges are
+// held from their tail to their head. Analyses are run on the directed or
+// undirected projections of the graph returned by Directed and
+// Undirected.
+type Mixed struct {
+	// Name is the DOT ID of the graph and
+	// Strict is whether it is a strict graph.
+	Name   string
+	Strict bool
+
+	nodes []*Node
+	edges []*Edge
+}
+
+// ReadMixedDOT reads DOT data from r and returns the encoded graph. Unlike
+// ReadDOT, the data may mix directed and undirected edges in either a
+// graph or a digraph. Repeated edges are merged, with later attribute
+// values replacing earlier ones, and self edges are discarded. Syntax
+// errors in the data are returned as a *ParseError.
+func ReadMixedDOT(r io.Reader) (*Mixed, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// The DOT parser does not allow directed edges
+	// in an undirected graph, so all graphs are read
+	// as digraphs.
+	b = asDigraph(b)
+	f, err := dotfmt.ParseBytes(b)
+	if err != nil {
+		return nil, dotError(b, err)
+	}
+	if len(f.Graphs) != 1 {
+		return nil, fmt.Errorf("invalid number of graphs; expected 1, got %d", len(f.Graphs))
+	}
+	src := f.Graphs[0]
+	src.Stmts = splitMixedEdges(src.Stmts)
+
+	m := &Mixed{Strict: src.Strict}
+	mb := &mixedBuilder{DirectedGraph: simple.NewDirectedGraph(), m: m}
+	err = dot.Unmarshal([]byte(src.String()), mb)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range graph.NodesOf(mb.Nodes()) {
+		m.nodes = append(m.nodes, n.(*Node))
+	}
+	sort.Slice(m.nodes, func(i, j int) bool { return m.nodes[i].ID() < m.nodes[j].ID() })
+	m.edges = mergeMixedEdges(mb.edges)
+	return m, nil
+}
+
+// asDigraph returns the DOT data in b with a leading graph keyword
+// replaced by digraph.
+func asDigraph(b []byte) []byte {
+	i := skipDOTComments(b)
+	const strict = "strict"
+	if len(b[i:]) > len(strict) && bytes.EqualFold(b[i:i+len(strict)], []byte(strict)) {
+		i += len(strict)
+		i += skipDOTComments(b[i:])
+	}
+	const keyword = "graph"
+	if len(b[i:]) < len(keyword) || !bytes.EqualFold(b[i:i+len(keyword)], []byte(keyword)) {
+		return b
+	}
+	d := make([]byte, 0, len(b)+2)
+	d = append(d, b[:i]...)
+	d = append(d, "di"...)
+	return append(d, b[i:]...)
+}
+
+// splitMixedEdges returns stmts with each edge statement split into a
+// directed edge statement for each of its edges, holding the direction
+// of the original edge in its "directed" attribute.
+func splitMixedEdges(stmts []ast.Stmt) []ast.Stmt {
+	out := make([]ast.Stmt, 0, len(stmts))
+	for _, s := range stmts {
+		switch s := s.(type) {
+		case *ast.EdgeStmt:
+			from := splitMixedVertex(s.From)
+			for to := s.To; to != nil; to = to.To {
+				v := splitMixedVertex(to.Vertex)
+				attrs := append(append([]*ast.Attr(nil), s.Attrs...), &ast.Attr{Key: "directed", Val: strconv.FormatBool(to.Directed)})
+				out = append(out, &ast.EdgeStmt{
+					From:  from,
+					To:    &ast.Edge{Directed: true, Vertex: v},
+					Attrs: attrs,
+				})
+				from = v
+			}
+		case *ast.Subgraph:
+			s.Stmts = splitMixedEdges(s.Stmts)
+			out = append(out, s)
+		default:
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// splitMixedVertex splits the edge statements of v if it is a subgraph.
+func splitMixedVertex(v ast.Vertex) ast.Vertex {
+	if s, ok := v.(*ast.Subgraph); ok {
+		s.Stmts = splitMixedEdges(s.Stmts)
+	}
+	return v
+}
+
+// mergeMixedEdges returns edges with self edges removed and repeated
+// edges merged into their first occurrence.
+func mergeMixedEdges(edges []*Edge) []*Edge {
+	type key struct {
+		f, t     int64
+		directed bool
+	}
+	seen := make(map[key]*Edge)
+	var merged []*Edge
+	for _, e := range edges {
+		if e.F == e.T {
+			continue
+		}
+		k := key{f: e.F.ID(), t: e.T.ID(), directed: isDirected(e)}
+		if !k.directed && k.f > k.t {
+			k.f, k.t = k.t, k.f
+		}
+		first, ok := seen[k]
+		if !ok {
+			seen[k] = e
+			merged = append(merged, e)
+			continue
+		}
+		for _, a := range e.Attributes {
+			first.SetAttribute(a)
+		}
+	}
+	return merged
+}
+
+// isDirected returns whether e is a directed edge of a mixed graph.
+func isDirected(e *Edge) bool {
+	return e.Get("directed") == "true"
+}
+
+// Nodes returns the nodes of m in node ID order.
+func (m *Mixed) Nodes() []*Node {
+	return append([]*Node(nil), m.nodes...)
+}
+
+// Edges returns the edges of m in the order they were read.
+func (m *Mixed) Edges() []*Edge {
+	return append([]*Edge(nil), m.edges...)
+}
+
+// Directed returns the directed projection of m, where each undirected
+// edge is replaced by a pair of reciprocal edges. Where a directed edge
+// and an undirected edge join the same nodes in the same direction, the
+// directed edge is used. The nodes and edges of the projection are shared
+// with m, except for the reversed edges of undirected edges, which hold
+// a copy of the attributes of the original edge with its ports swapped.
+func (m *Mixed) Directed() *Digraph {
+	g := &Digraph{DirectedGraph: simple.NewDirectedGraph(), Name: m.Name, Strict: m.Strict}
+	for _, n := range m.nodes {
+		g.AddNode(n)
+	}
+	for _, e := range m.edges {
+		if isDirected(e) {
+			g.SetEdge(e)
+		}
+	}
+	for _, e := range m.edges {
+		if isDirected(e) {
+			continue
+		}
+		if !g.HasEdgeFromTo(e.F.ID(), e.T.ID()) {
+			g.SetEdge(e)
+		}
+		if !g.HasEdgeFromTo(e.T.ID(), e.F.ID()) {
+			r := &Edge{F: e.F, T: e.T, Attributes: append(Attributes(nil), e.Attributes...)}
+			g.SetEdge(r.ReversedEdge())
+		}
+	}
+	return g
+}
+
+// Undirected returns the undirected projection of m, where the direction
+// of directed edges is ignored. Where more than one edge joins the same
+// nodes, the undirected edge, or otherwise the first directed edge, is
+// used. The nodes and edges of the projection are shared with m.
+func (m *Mixed) Undirected() *Graph {
+	g := &Graph{UndirectedGraph: simple.NewUndirectedGraph(), Name: m.Name, Strict: m.Strict}
+	for _, n := range m.nodes {
+		g.AddNode(n)
+	}
+	for _, e := range m.edges {
+		if !isDirected(e) {
+			g.SetEdge(e)
+		}
+	}
+	for _, e := range m.edges {
+		if isDirected(e) && !g.HasEdgeBetween(e.F.ID(), e.T.ID()) {
+			g.SetEdge(e)
+		}
+	}
+	return g
+}
+
+// mixedBuilder is an encoding.Builder that collects the edges of a
+// mixed graph without merging them.
+type mixedBuilder struct {
+	*simple.DirectedGraph
+	m     *Mixed
+	edges []*Edge
+}
+
+// SetDOTID sets the DOT ID of the mixed graph.
+func (b *mixedBuilder) SetDOTID(id string) {
+	b.m.Name = id
+}
+
+// NewNode returns a new node with a unique node ID.
+func (b *mixedBuilder) NewNode() graph.Node {
+	return &Node{NodeID: b.DirectedGraph.NewNode().ID()}
+}
+
+// NewEdge returns a new edge from the source to the destination node.
+func (b *mixedBuilder) NewEdge(from, to graph.Node) graph.Edge {
+	return mixedEdge{&Edge{F: from.(*Node), T: to.(*Node)}}
+}
+
+// SetEdge records the edge e.
+func (b *mixedBuilder) SetEdge(e graph.Edge) {
+	b.edges = append(b.edges, e.(mixedEdge).Edge)
+}
+
+// mixedEdge is an *Edge wrapper used during loading of a mixed graph.
+type mixedEdge struct {
+	*Edge
+}
+
+// SetFromPort sets the "tailport" attribute of the edge.
+func (e mixedEdge) SetFromPort(port, compass string) error {
+	return e.Edge.SetAttribute(encoding.Attribute{Key: "tailport", Value: portOf(port, compass)})
+}
+
+// SetToPort sets the "headport" attribute of the edge.
+func (e mixedEdge) SetToPort(port, compass string) error {
+	return e.Edge.SetAttribute(encoding.Attribute{Key: "headport", Value: portOf(port, compass)})
+}
@@ -0,0 +1,154 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+var mixedTests = []struct {
+	dot        string
+	name       string
+	strict     bool
+	edges      []string
+	directed   []string
+	undirected []string
+}{
+	{
+		dot:        `graph G { a -- b; b -> c }`,
+		name:       "G",
+		edges:      []string{"a--b", "b->c"},
+		directed:   []string{"a->b", "b->a", "b->c"},
+		undirected: []string{"a--b", "b--c"},
+	},
+	{
+		dot:        `strict digraph { a -> b -- c -> a }`,
+		strict:     true,
+		edges:      []string{"a->b", "b--c", "c->a"},
+		directed:   []string{"a->b", "b->c", "c->a", "c->b"},
+		undirected: []string{"a--b", "a--c", "b--c"},
+	},
+	{
+		// Repeated, reversed and self edges.
+		dot:        `graph { a -- b [w=1]; b -- a [w=2]; a -> a; b -> a [w=3]; a -> b }`,
+		edges:      []string{"a--b[w=2]", "b->a[w=3]", "a->b"},
+		directed:   []string{"a->b", "b->a[w=3]"},
+		undirected: []string{"a--b[w=2]"},
+	},
+	{
+		dot:        `/* A comment. */ graph { a -> b; b -> a; c }`,
+		edges:      []string{"a->b", "b->a"},
+		directed:   []string{"a->b", "b->a"},
+		undirected: []string{"a--b"},
+	},
+	{
+		dot:        `graph { a -- { b c }; subgraph s { d -> e } }`,
+		edges:      []string{"a--b", "a--c", "d->e"},
+		directed:   []string{"a->b", "a->c", "b->a", "c->a", "d->e"},
+		undirected: []string{"a--b", "a--c", "d--e"},
+	},
+}
+
+// mixedEdgeString returns a description of e with its non-direction
+// attributes, using op to join the DOT IDs of its ends.
+func mixedEdgeString(e *Edge, op string) string {
+	s := e.F.Name + op + e.T.Name
+	var attrs []string
+	for _, a := range e.Attributes {
+		if a.Key != "directed" {
+			attrs = append(attrs, a.Key+"="+a.Value)
+		}
+	}
+	if len(attrs) != 0 {
+		s += "[" + strings.Join(attrs, ",") + "]"
+	}
+	return s
+}
+
+func TestReadMixedDOT(t *testing.T) {
+	for i, test := range mixedTests {
+		m, err := ReadMixedDOT(strings.NewReader(test.dot))
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		if m.Name != test.name || m.Strict != test.strict {
+			t.Errorf("unexpected header for test %d: got:%q strict=%t want:%q strict=%t", i, m.Name, m.Strict, test.name, test.strict)
+		}
+
+		var edges []string
+		for _, e := range m.Edges() {
+			op := "--"
+			if isDirected(e) {
+				op = "->"
+			}
+			edges = append(edges, mixedEdgeString(e, op))
+		}
+		if !reflect.DeepEqual(edges, test.edges) {
+			t.Errorf("unexpected edges for test %d: got:%q want:%q", i, edges, test.edges)
+		}
+
+		d := m.Directed()
+		var directed []string
+		for _, e := range graph.EdgesOf(d.Edges()) {
+			directed = append(directed, mixedEdgeString(e.(*Edge), "->"))
+		}
+		sort.Strings(directed)
+		if !reflect.DeepEqual(directed, test.directed) {
+			t.Errorf("unexpected directed projection for test %d: got:%q want:%q", i, directed, test.directed)
+		}
+
+		u := m.Undirected()
+		var undirected []string
+		for _, e := range graph.EdgesOf(u.Edges()) {
+			e := e.(*Edge)
+			if e.F.Name > e.T.Name {
+				e = &Edge{F: e.T, T: e.F, Attributes: e.Attributes}
+			}
+			undirected = append(undirected, mixedEdgeString(e, "--"))
+		}
+		sort.Strings(undirected)
+		if !reflect.DeepEqual(undirected, test.undirected) {
+			t.Errorf("unexpected undirected projection for test %d: got:%q want:%q", i, undirected, test.undirected)
+		}
+
+		if d.Nodes().Len() != len(m.Nodes()) || u.Nodes().Len() != len(m.Nodes()) {
+			t.Errorf("unexpected number of projection nodes for test %d", i)
+		}
+	}
+}
+
+func TestMixedDirectedPorts(t *testing.T) {
+	m, err := ReadMixedDOT(strings.NewReader(`graph { a:p -- b:q }`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := m.Directed()
+	a, b := m.Nodes()[0], m.Nodes()[1]
+	fwd := d.Edge(a.ID(), b.ID()).(*Edge)
+	rev := d.Edge(b.ID(), a.ID()).(*Edge)
+	if fwd.Get("tailport") != "p" || fwd.Get("headport") != "q" {
+		t.Errorf("unexpected forward ports: tail=%s head=%s", fwd.Get("tailport"), fwd.Get("headport"))
+	}
+	if rev.Get("tailport") != "q" || rev.Get("headport") != "p" {
+		t.Errorf("unexpected reverse ports: tail=%s head=%s", rev.Get("tailport"), rev.Get("headport"))
+	}
+}
+
+func TestReadMixedDOTErrors(t *testing.T) {
+	for _, src := range []string{
+		`graph { a -- }`,
+		`graph { a } graph { b }`,
+	} {
+		if _, err := ReadMixedDOT(strings.NewReader(src)); err == nil {
+			t.Errorf("expected error for %q", src)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"strconv"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/stat"
+)
+
+// DirectedMotifClasses holds the names of the connected three-node directed
+// motif classes counted by DirectedMotifs, in the order of its result. The
+// names are the MAN codes of Holland and Leinhardt's triad census, giving
+// the numbers of mutual, asymmetric and null dyads of the motif and a
+// letter distinguishing motifs with the same numbers, so that for example
+// "021C" is a chain u→v→w and "030T" is the feed-forward loop.
+var DirectedMotifClasses = [13]string{
+	"021D", "021U", "021C", "111D", "111U", "030T", "030C",
+	"201", "120D", "120U", "120C", "210", "300",
+}
+
+// triadClass maps the six-bit code of the edges among three nodes to its
+// class in the triad census, in the order 003, 012, 102, followed by the
+// connected classes in the order of DirectedMotifClasses.
+var triadClass = [64]int{
+	0, 1, 1, 2, 1, 3, 5, 7, 1, 5, 4, 6, 2, 7, 6, 10,
+	1, 5, 3, 7, 4, 8, 8, 12, 5, 9, 8, 13, 6, 13, 11, 14,
+	1, 4, 5, 6, 5, 8, 9, 13, 3, 8, 8, 11, 7, 12, 13, 14,
+	2, 6, 7, 10, 6, 11, 13, 14, 7, 13, 12, 14, 10, 14, 14, 15,
+}
+
+// DirectedMotifs returns the number of occurrences in g of each of the
+// thirteen connected three-node directed motif classes, in the order of
+// DirectedMotifClasses. Each connected set of three nodes is counted once,
+// as an induced subgraph.
+func DirectedMotifs(g *Digraph) [13]int {
+	x := g.Index()
+	out := x.adjacency(g)
+	n := x.Len()
+	arc := make([]map[int]bool, n)
+	nbrs := make([][]int, n)
+	for i := range arc {
+		arc[i] = make(map[int]bool)
+	}
+	for i, to := range out {
+		for _, j := range to {
+			arc[i][j] = true
+		}
+	}
+	for i, to := range out {
+		for _, j := range to {
+			nbrs[i] = append(nbrs[i], j)
+			if !arc[j][i] {
+				nbrs[j] = append(nbrs[j], i)
+			}
+		}
+	}
+
+	code := func(v, u, w int) int {
+		var c int
+		for _, e := range [...]struct{ from, to, bit int }{
+			{v, u, 1}, {u, v, 2}, {v, w, 4}, {w, v, 8}, {u, w, 16}, {w, u, 32},
+		} {
+			if arc[e.from][e.to] {
+				c |= e.bit
+			}
+		}
+		return c
+	}
+
+	var counts [13]int
+	for v, adj := range nbrs {
+		for a, u := range adj {
+			for _, w := range adj[a+1:] {
+				// Each connected triple is counted at the
+				// node joined to both others, and triangles
+				// only at their lowest indexed node.
+				if (arc[u][w] || arc[w][u]) && (v > u || v > w) {
+					continue
+				}
+				counts[triadClass[code(v, u, w)]-3]++
+			}
+		}
+	}
+	return counts
+}
+
+// MotifZScores returns the z-scores of the counts of the directed motif
+// classes of g, in the order of DirectedMotifClasses, relative to their
+// distributions in trials random graphs drawn by RewireDirected with the
+// reciprocity of g preserved and ten swaps attempted for each edge. The
+// random graphs are determined by seed. The z-score of a class is NaN if
+// its count does not vary in the random graphs.
+//
+// MotifZScores panics if trials is less than two.
+func MotifZScores(g *Digraph, trials int, seed int64) [13]float64 {
+	if trials < 2 {
+		panic("graphprac: invalid number of trials")
+	}
+	observed := DirectedMotifs(g)
+
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	var null [13][]float64
+	swaps := 10 * g.Edges().Len()
+	for t := 0; t < trials; t++ {
+		counts := DirectedMotifs(RewireDirected(g, swaps, true, int64(rnd.Uint64())))
+		for i, c := range counts {
+			null[i] = append(null[i], float64(c))
+		}
+	}
+
+	var z [13]float64
+	for i, dist := range null {
+		mean, std := stat.MeanStdDev(dist, nil)
+		if std == 0 {
+			z[i] = math.NaN()
+			continue
+		}
+		z[i] = (float64(observed[i]) - mean) / std
+	}
+	return z
+}
+
+// FindFFL returns the feed-forward loops of g, each as the nodes a, b and
+// c of the edges a→b, b→c and a→c, with a the regulator, b the
+// intermediate and c the target. Loops are found as subgraphs, so other
+// edges may join their nodes, and are ordered by the node IDs of a, b and
+// c. The number of loops each node and edge is part of is written into
+// its "ffl" attribute. If the analysis refuses to overwrite the attribute,
+// as described by SetAnalysisOptions, FindFFL returns nil.
+func FindFFL(g *Digraph) [][3]*Node {
+	done, err := trace(g, "FindFFL", []string{"ffl"})
+	if err != nil {
+		return nil
+	}
+	defer done(nil)
+
+	x := g.Index()
+	out := x.adjacency(g)
+	arc := make([]map[int]bool, len(out))
+	for i, to := range out {
+		arc[i] = make(map[int]bool, len(to))
+		for _, j := range to {
+			arc[i][j] = true
+		}
+	}
+
+	nodeCount := make([]int, len(out))
+	edgeCount := make(map[[2]int]int)
+	var loops [][3]*Node
+	for a, to := range out {
+		for _, b := range to {
+			for _, c := range out[b] {
+				if c == a || !arc[a][c] {
+					continue
+				}
+				loops = append(loops, [3]*Node{x.Node(a), x.Node(b), x.Node(c)})
+				for _, i := range [...]int{a, b, c} {
+					nodeCount[i]++
+				}
+				for _, e := range [...][2]int{{a, b}, {b, c}, {a, c}} {
+					edgeCount[e]++
+				}
+			}
+		}
+	}
+	tagMotifInstances(g, x, out, "ffl", nodeCount, edgeCount)
+	return loops
+}
+
+// FindFeedbackLoops returns the feedback loops of g with at most maxLen
+// edges, each as the nodes of a directed cycle in the order they are
+// visited, starting at the node with the lowest node ID. Loops of two
+// nodes are reciprocated pairs of edges. Loops are ordered by their nodes'
+// IDs. The number of loops each node and edge is part of is written into
+// its "feedback" attribute. If the analysis refuses to overwrite the
+// attribute, as described by SetAnalysisOptions, FindFeedbackLoops
+// returns nil.
+//
+// The number of loops may grow exponentially with maxLen. FindFeedbackLoops
+// panics if maxLen is less than two.
+func FindFeedbackLoops(g *Digraph, maxLen int) [][]*Node {
+	if maxLen < 2 {
+		panic("graphprac: invalid maximum loop length")
+	}
+	done, err := trace(g, "FindFeedbackLoops", []string{"feedback"}, "maxLen", maxLen)
+	if err != nil {
+		return nil
+	}
+	defer done(nil)
+
+	x := g.Index()
+	out := x.adjacency(g)
+
+	nodeCount := make([]int, len(out))
+	edgeCount := make(map[[2]int]int)
+	var loops [][]*Node
+	onPath := make([]bool, len(out))
+	var path []int
+	var walk func(start, u int)
+	walk = func(start, u int) {
+		path = append(path, u)
+		onPath[u] = true
+		for _, v := range out[u] {
+			switch {
+			case v == start:
+				loop := make([]*Node, len(path))
+				for k, i := range path {
+					loop[k] = x.Node(i)
+					nodeCount[i]++
+					edgeCount[[2]int{i, path[(k+1)%len(path)]}]++
+				}
+				loops = append(loops, loop)
+			case v > start && !onPath[v] && len(path) < maxLen:
+				walk(start, v)
+			}
+		}
+		onPath[u] = false
+		path = path[:len(path)-1]
+	}
+	for s := range out {
+		walk(s, s)
+	}
+	tagMotifInstances(g, x, out, "feedback", nodeCount, edgeCount)
+	return loops
+}
+
+// tagMotifInstances writes the numbers of motif instances each node and
+// edge of g is part of into the attr attribute of the node or edge.
+func tagMotifInstances(g *Digraph, x NodeIndex, out [][]int, attr string, nodeCount []int, edgeCount map[[2]int]int) {
+	for i, to := range out {
+		x.Node(i).SetAttribute(encoding.Attribute{Key: attr, Value: strconv.Itoa(nodeCount[i])})
+		for _, j := range to {
+			e := g.Edge(x.ID(i), x.ID(j)).(*Edge)
+			e.SetAttribute(encoding.Attribute{Key: attr, Value: strconv.Itoa(edgeCount[[2]int{i, j}])})
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// triadArcs holds the arcs of each connected triad class of
+// DirectedMotifClasses among the nodes a, b and c.
+var triadArcs = map[string][]string{
+	"021D": {"ba", "bc"},
+	"021U": {"ab", "cb"},
+	"021C": {"ab", "bc"},
+	"111D": {"ac", "ca", "bc"},
+	"111U": {"ac", "ca", "cb"},
+	"030T": {"ab", "cb", "ac"},
+	"030C": {"ba", "cb", "ac"},
+	"201":  {"ab", "ba", "ac", "ca"},
+	"120D": {"bc", "ba", "ac", "ca"},
+	"120U": {"ab", "cb", "ac", "ca"},
+	"120C": {"ab", "bc", "ac", "ca"},
+	"210":  {"ab", "bc", "cb", "ac", "ca"},
+	"300":  {"ab", "ba", "bc", "cb", "ac", "ca"},
+}
+
+// triadDOT returns a DOT digraph holding the given arcs among the nodes
+// a, b and c, with the nodes renamed by perm.
+func triadDOT(arcs []string, perm string) string {
+	var buf strings.Builder
+	buf.WriteString("digraph { a; b; c; ")
+	for _, a := range arcs {
+		fmt.Fprintf(&buf, "%c -> %c; ", perm[a[0]-'a'], perm[a[1]-'a'])
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+func TestDirectedMotifsTriads(t *testing.T) {
+	for class, arcs := range triadArcs {
+		for _, perm := range []string{"abc", "acb", "bac", "bca", "cab", "cba"} {
+			g := digraphFromDOT(t, triadDOT(arcs, perm))
+			got := DirectedMotifs(g)
+			for i, n := range got {
+				want := 0
+				if DirectedMotifClasses[i] == class {
+					want = 1
+				}
+				if n != want {
+					t.Errorf("unexpected count of %s for %s with nodes %s: got:%d want:%d", DirectedMotifClasses[i], class, perm, n, want)
+				}
+			}
+		}
+	}
+}
+
+var directedMotifTests = []struct {
+	dot  string
+	want map[string]int
+}{
+	{
+		dot:  `digraph { a -> b; c; d }`,
+		want: map[string]int{},
+	},
+	{
+		dot:  `digraph { a -> b; b -> c; a -> c; c -> d }`,
+		want: map[string]int{"030T": 1, "021C": 2},
+	},
+	{
+		dot:  `digraph { a -> b; a -> c; a -> d; a -> e }`,
+		want: map[string]int{"021D": 6},
+	},
+	{
+		dot:  `digraph { a -> b -> c -> d -> a; a -> c }`,
+		want: map[string]int{"030T": 1, "030C": 1, "021C": 2},
+	},
+	{
+		dot:  `digraph { a -> b -> a; b -> c -> b; c -> a -> c; d -> a }`,
+		want: map[string]int{"300": 1, "111D": 2},
+	},
+}
+
+func TestDirectedMotifs(t *testing.T) {
+	for i, test := range directedMotifTests {
+		got := DirectedMotifs(digraphFromDOT(t, test.dot))
+		for j, n := range got {
+			if want := test.want[DirectedMotifClasses[j]]; n != want {
+				t.Errorf("unexpected count of %s for test %d: got:%d want:%d", DirectedMotifClasses[j], i, n, want)
+			}
+		}
+	}
+}
+
+// feedForwardRing returns a DOT digraph of n nodes with arcs from each node
+// to the next two nodes, so that each run of three nodes forms a
+// feed-forward loop.
+func feedForwardRing(n int) string {
+	var buf strings.Builder
+	buf.WriteString("digraph {\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, "\tn%d -> n%d;\n\tn%[1]d -> n%[3]d;\n", i, (i+1)%n, (i+2)%n)
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+func TestMotifZScores(t *testing.T) {
+	g := digraphFromDOT(t, feedForwardRing(30))
+	z := MotifZScores(g, 20, 1)
+	for i, class := range DirectedMotifClasses {
+		if class == "030T" && !(z[i] > 3) {
+			t.Errorf("unexpected z-score for feed-forward loops: got:%v want:>3", z[i])
+		}
+	}
+	// Compare formatted values since z-scores may be NaN.
+	if got := MotifZScores(g, 20, 1); fmt.Sprint(got) != fmt.Sprint(z) {
+		t.Errorf("unexpected z-scores with repeated seed: got:%v want:%v", got, z)
+	}
+
+	// A graph whose rewirings all have the same
+	// counts has undefined z-scores.
+	for i, v := range MotifZScores(digraphFromDOT(t, `digraph { a -> b; c -> d }`), 5, 1) {
+		if !math.IsNaN(v) {
+			t.Errorf("unexpected z-score for %s: got:%v want:NaN", DirectedMotifClasses[i], v)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for too few trials")
+		}
+	}()
+	MotifZScores(g, 1, 1)
+}
+
+// loopStrings returns the DOT IDs of the nodes of each loop joined by
+// spaces.
+func loopStrings(loops [][]*Node) []string {
+	var s []string
+	for _, l := range loops {
+		names := make([]string, len(l))
+		for i, n := range l {
+			names[i] = n.Name
+		}
+		s = append(s, strings.Join(names, " "))
+	}
+	return s
+}
+
+func TestFindFFL(t *testing.T) {
+	g := digraphFromDOT(t, `digraph {
+	x -> y; y -> z; x -> z;
+	x -> w; w -> z;
+	z -> x;
+	v -> x;
+}`)
+	var loops [][]*Node
+	for _, l := range FindFFL(g) {
+		l := l
+		loops = append(loops, l[:])
+	}
+	want := []string{"x y z", "x w z"}
+	if got := loopStrings(loops); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected loops: got:%q want:%q", got, want)
+	}
+
+	m := g.NodeMap()
+	byName := make(map[string]*Node)
+	for _, n := range m {
+		byName[n.Name] = n
+	}
+	for name, want := range map[string]string{"x": "2", "y": "1", "z": "2", "w": "1", "v": "0"} {
+		if got := byName[name].Get("ffl"); got != want {
+			t.Errorf("unexpected ffl count for %s: got:%s want:%s", name, got, want)
+		}
+	}
+	for arc, want := range map[[2]string]string{
+		{"x", "y"}: "1", {"y", "z"}: "1", {"x", "z"}: "2",
+		{"x", "w"}: "1", {"w", "z"}: "1", {"z", "x"}: "0", {"v", "x"}: "0",
+	} {
+		e := g.Edge(byName[arc[0]].ID(), byName[arc[1]].ID()).(*Edge)
+		if got := e.Get("ffl"); got != want {
+			t.Errorf("unexpected ffl count for %s->%s: got:%s want:%s", arc[0], arc[1], got, want)
+		}
+	}
+}
+
+var feedbackTests = []struct {
+	maxLen int
+	want   []string
+}{
+	{maxLen: 2, want: []string{"a b"}},
+	{maxLen: 3, want: []string{"a b", "a b c"}},
+	{maxLen: 4, want: []string{"a b", "a b c", "a b c d"}},
+}
+
+func TestFindFeedbackLoops(t *testing.T) {
+	for i, test := range feedbackTests {
+		g := digraphFromDOT(t, `digraph { a -> b -> a; b -> c -> a; c -> d -> a; e -> a }`)
+		got := loopStrings(FindFeedbackLoops(g, test.maxLen))
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected loops for test %d: got:%q want:%q", i, got, test.want)
+		}
+	}
+
+	g := digraphFromDOT(t, `digraph { a -> b -> a; b -> c -> a; c -> d -> a; e -> a }`)
+	FindFeedbackLoops(g, 3)
+	byName := make(map[string]*Node)
+	for _, n := range g.NodeMap() {
+		byName[n.Name] = n
+	}
+	for name, want := range map[string]string{"a": "2", "b": "2", "c": "1", "d": "0", "e": "0"} {
+		if got := byName[name].Get("feedback"); got != want {
+			t.Errorf("unexpected feedback count for %s: got:%s want:%s", name, got, want)
+		}
+	}
+	for arc, want := range map[[2]string]string{
+		{"a", "b"}: "2", {"b", "a"}: "1", {"b", "c"}: "1", {"c", "a"}: "1",
+		{"c", "d"}: "0", {"d", "a"}: "0", {"e", "a"}: "0",
+	} {
+		e := g.Edge(byName[arc[0]].ID(), byName[arc[1]].ID()).(*Edge)
+		if got := e.Get("feedback"); got != want {
+			t.Errorf("unexpected feedback count for %s->%s: got:%s want:%s", arc[0], arc[1], got, want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for short maximum length")
+		}
+	}()
+	FindFeedbackLoops(g, 1)
+}
@@ -0,0 +1,350 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Multiplex is a multilayer graph with layers of edges of different types
+// between a shared set of nodes. Nodes are identified between layers by
+// their DOT ID, and a node has the same node ID in every layer.
+type Multiplex struct {
+	// layers holds the layer graphs, each
+	// holding every node of the multiplex,
+	// and names holds the sorted layer names.
+	layers map[string]*Graph
+	names  []string
+
+	// nodes holds the sorted DOT IDs of the
+	// nodes, indexed by node ID.
+	nodes []string
+}
+
+// NewMultiplex returns a multiplex with the provided layers, keyed by
+// layer name. The node set of the multiplex is the union of the nodes of
+// the layers, matched by DOT ID. The layers of the multiplex are copies of
+// the provided graphs with any missing nodes added, so the provided graphs
+// are not modified. Node and edge attributes are copied with each layer.
+func NewMultiplex(layers map[string]*Graph) *Multiplex {
+	m := &Multiplex{layers: make(map[string]*Graph, len(layers))}
+	seen := make(map[string]bool)
+	for name, g := range layers {
+		m.names = append(m.names, name)
+		for _, n := range NodesOf(g) {
+			if !seen[n.Name] {
+				seen[n.Name] = true
+				m.nodes = append(m.nodes, n.Name)
+			}
+		}
+	}
+	sort.Strings(m.names)
+	sort.Strings(m.nodes)
+
+	for name, g := range layers {
+		byName := make(map[string]*Node)
+		for _, n := range NodesOf(g) {
+			byName[n.Name] = n
+		}
+		l := &Graph{
+			UndirectedGraph: simple.NewUndirectedGraph(),
+			GraphAttrs:      append(Attributes(nil), g.GraphAttrs...),
+			NodeAttrs:       append(Attributes(nil), g.NodeAttrs...),
+			EdgeAttrs:       append(Attributes(nil), g.EdgeAttrs...),
+		}
+		copied := make(map[int64]*Node, len(m.nodes))
+		for id, dotID := range m.nodes {
+			n := &Node{NodeID: int64(id), Name: dotID}
+			if orig, ok := byName[dotID]; ok {
+				n.Attributes = append(Attributes(nil), orig.Attributes...)
+				copied[orig.ID()] = n
+			}
+			l.AddNode(n)
+		}
+		for _, e := range graph.EdgesOf(g.Edges()) {
+			e := e.(*Edge)
+			l.SetEdge(&Edge{
+				F:          copied[e.F.ID()],
+				T:          copied[e.T.ID()],
+				Attributes: append(Attributes(nil), e.Attributes...),
+			})
+		}
+		m.layers[name] = l
+	}
+	return m
+}
+
+// Layers returns the names of the layers of m in lexical order.
+func (m *Multiplex) Layers() []string {
+	return append([]string(nil), m.names...)
+}
+
+// LayerGraph returns the graph of the named layer of m, or nil if there is
+// no such layer. The returned graph holds every node of m and may be
+// analysed like any other graph. Analyses write their attributes into the
+// nodes of the layer, so the same analysis may be run on each layer.
+func (m *Multiplex) LayerGraph(name string) *Graph {
+	return m.layers[name]
+}
+
+// Aggregate returns the graph formed by collapsing the layers of m into a
+// single layer. The aggregate has an edge between two nodes if they are
+// adjacent in any layer, with a "weight" attribute holding the sum over
+// layers of the weight of the edge in the layer multiplied by the weight
+// of the layer in weights. Edge weights in each layer are given by the
+// "weight" attribute of the edge, or 1 if it is not set, and layers that
+// are not in weights have weight 1. Edges with a total weight of zero are
+// omitted. The nodes of the aggregate have the same node IDs as in the
+// layers, but have no attributes. Aggregate panics if an edge weight is
+// not a number.
+func (m *Multiplex) Aggregate(weights map[string]float64) *Graph {
+	a := &Graph{UndirectedGraph: simple.NewUndirectedGraph()}
+	for id, dotID := range m.nodes {
+		a.AddNode(&Node{NodeID: int64(id), Name: dotID})
+	}
+	total := make(map[[2]int64]float64)
+	var pairs [][2]int64
+	for _, name := range m.names {
+		lw, ok := weights[name]
+		if !ok {
+			lw = 1
+		}
+		for _, e := range graph.EdgesOf(m.layers[name].Edges()) {
+			e := e.(*Edge)
+			w, err := edgeWeight(e, "weight")
+			if err != nil {
+				panic(fmt.Sprintf("graphprac: layer %s: %v", name, err))
+			}
+			u, v := e.F.ID(), e.T.ID()
+			if u > v {
+				u, v = v, u
+			}
+			k := [2]int64{u, v}
+			if _, ok := total[k]; !ok {
+				pairs = append(pairs, k)
+			}
+			total[k] += lw * w
+		}
+	}
+	for _, k := range pairs {
+		w := total[k]
+		if w == 0 {
+			continue
+		}
+		a.SetEdge(&Edge{
+			F:          a.Node(k[0]).(*Node),
+			T:          a.Node(k[1]).(*Node),
+			Attributes: Attributes{{Key: "weight", Value: fmt.Sprint(w)}},
+		})
+	}
+	return a
+}
+
+// Participation returns the multiplex participation coefficient of each
+// node of m, keyed by DOT ID. The participation coefficient of a node is
+// L/(L-1) * (1 - Σ_l (k_l/o)²), where L is the number of layers, k_l is
+// the degree of the node in layer l and o is the sum of its degrees over
+// all layers (Battiston, Nicosia and Latora, doi:10.1103/PhysRevE.89.032804).
+// It is 1 for nodes with the same degree in every layer and 0 for nodes
+// with edges in only one layer. Nodes with no edges, and all nodes of a
+// multiplex with fewer than two layers, have a participation coefficient
+// of 0.
+//
+// The participation coefficient is also written into the "participation"
+// attribute of each node in each layer. If the analysis refuses to
+// overwrite the attribute, as described by SetAnalysisOptions,
+// Participation returns nil.
+func (m *Multiplex) Participation() map[string]float64 {
+	done, err := m.trace("Participation", []string{"participation"})
+	if err != nil {
+		return nil
+	}
+	defer done(nil)
+
+	p := make(map[string]float64, len(m.nodes))
+	layers := float64(len(m.names))
+	for id, dotID := range m.nodes {
+		var o, sq float64
+		for _, name := range m.names {
+			k := float64(m.layers[name].From(int64(id)).Len())
+			o += k
+			sq += k * k
+		}
+		var c float64
+		if o != 0 && layers > 1 {
+			c = layers / (layers - 1) * (1 - sq/(o*o))
+		}
+		p[dotID] = c
+		for _, name := range m.names {
+			m.layers[name].Node(int64(id)).(*Node).SetAttribute(encoding.Attribute{Key: "participation", Value: fmt.Sprint(c)})
+		}
+	}
+	return p
+}
+
+// MultiplexPageRank performs a PageRank analysis on the supra-graph of m, in
+// which each node has a copy in each layer, the copies in a layer are
+// connected by the edges of that layer, and the copies of a node in
+// different layers are connected by edges with the given coupling weight.
+// A random walker may therefore move within a layer or switch to another
+// layer at the same node. Edge weights in each layer are given by the
+// "weight" attribute of the edge, or 1 if it is not set. The damping and
+// tolerance parameters are as for PageRank, with the tolerance applying to
+// the sum of the absolute changes in rank in an iteration.
+//
+// The versatility of a node (De Domenico et al., doi:10.1038/ncomms7868)
+// is the sum of the ranks of its copies. It reflects the importance of the
+// node across all layers, which may differ from its importance in any
+// single layer or in the aggregate graph. The versatility of each node is
+// returned, keyed by DOT ID.
+//
+// MultiplexPageRank returns an error if the ranks do not converge within
+// a bounded number of iterations, or if the analysis refuses to overwrite
+// an attribute, as described by SetAnalysisOptions. It panics if coupling
+// is negative, if damp is not in [0, 1), if tol is not positive, or if an
+// edge weight is not a non-negative number.
+//
+// The rank of the copy of each node in a layer is written into the
+// "multiplex_rank" attribute of the node in that layer, and the
+// versatility is written into the "versatility" attribute of the node in
+// each layer.
+func MultiplexPageRank(m *Multiplex, coupling float64, damp, tol float64) (versatility map[string]float64, err error) {
+	if coupling < 0 {
+		panic("graphprac: invalid multiplex coupling")
+	}
+	if !(damp >= 0 && damp < 1) {
+		panic("graphprac: invalid damping")
+	}
+	if !(tol > 0) {
+		panic("graphprac: invalid tolerance")
+	}
+	done, err := m.trace("MultiplexPageRank", []string{"versatility", "multiplex_rank"}, "coupling", coupling, "damp", damp, "tol", tol)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { done(err) }()
+
+	n := len(m.nodes)
+	layers := len(m.names)
+	size := n * layers
+
+	// Copy i of layer l is supra-node l*n+i.
+	adj := make([][]int, size)
+	w := make([][]float64, size)
+	for l, name := range m.names {
+		for _, e := range graph.EdgesOf(m.layers[name].Edges()) {
+			e := e.(*Edge)
+			v, err := edgeWeight(e, "weight")
+			if err != nil {
+				panic(fmt.Sprintf("graphprac: layer %s: %v", name, err))
+			}
+			if v < 0 {
+				panic(fmt.Sprintf("graphprac: layer %s: negative weight for edge %q--%q: %v", name, e.F.Name, e.T.Name, v))
+			}
+			u, t := l*n+int(e.F.ID()), l*n+int(e.T.ID())
+			adj[u] = append(adj[u], t)
+			w[u] = append(w[u], v)
+			adj[t] = append(adj[t], u)
+			w[t] = append(w[t], v)
+		}
+	}
+	if coupling != 0 {
+		for i := 0; i < n; i++ {
+			for l := 0; l < layers; l++ {
+				for k := 0; k < layers; k++ {
+					if k != l {
+						adj[l*n+i] = append(adj[l*n+i], k*n+i)
+						w[l*n+i] = append(w[l*n+i], coupling)
+					}
+				}
+			}
+		}
+	}
+	strength := make([]float64, size)
+	for i := range w {
+		strength[i] = sum(w[i])
+	}
+
+	rank := make([]float64, size)
+	next := make([]float64, size)
+	for i := range rank {
+		rank[i] = 1 / float64(size)
+	}
+	const maxIter = 10000
+	for iter := 0; size != 0; iter++ {
+		if iter == maxIter {
+			return nil, fmt.Errorf("multiplex PageRank did not converge in %d iterations", maxIter)
+		}
+		var dangling float64
+		for i, r := range rank {
+			if strength[i] == 0 {
+				dangling += r
+			}
+		}
+		base := (1-damp)/float64(size) + damp*dangling/float64(size)
+		for i := range next {
+			next[i] = base
+		}
+		for i, to := range adj {
+			if strength[i] == 0 {
+				continue
+			}
+			for k, j := range to {
+				next[j] += damp * rank[i] * w[i][k] / strength[i]
+			}
+		}
+		var change float64
+		for i := range rank {
+			change += math.Abs(next[i] - rank[i])
+		}
+		rank, next = next, rank
+		if change < tol {
+			break
+		}
+	}
+
+	versatility = make(map[string]float64, n)
+	for i, dotID := range m.nodes {
+		var v float64
+		for l := 0; l < layers; l++ {
+			v += rank[l*n+i]
+		}
+		versatility[dotID] = v
+		for l, name := range m.names {
+			node := m.layers[name].Node(int64(i)).(*Node)
+			node.SetAttribute(encoding.Attribute{Key: "multiplex_rank", Value: fmt.Sprint(rank[l*n+i])})
+			node.SetAttribute(encoding.Attribute{Key: "versatility", Value: fmt.Sprint(v)})
+		}
+	}
+	return versatility, nil
+}
+
+// trace traces the named analysis of each layer of m as described for
+// the trace function, returning a function that completes the trace of
+// every layer. If the analysis is refused in any layer, the traces of the
+// preceding layers are completed with the refusal.
+func (m *Multiplex) trace(name string, attrs []string, params ...interface{}) (func(error), error) {
+	dones := make([]func(error), 0, len(m.names))
+	finish := func(err error) {
+		for _, done := range dones {
+			done(err)
+		}
+	}
+	for _, l := range m.names {
+		done, err := trace(m.layers[l], name, attrs, params...)
+		if err != nil {
+			finish(err)
+			return nil, err
+		}
+		dones = append(dones, done)
+	}
+	return finish, nil
+}
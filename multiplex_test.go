@@ -0,0 +1,245 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// newTestMultiplex returns a multiplex with a "work" layer a--b--c and a
+// "friend" layer a--b with weight 2 and c--d.
+func newTestMultiplex(t *testing.T) *Multiplex {
+	t.Helper()
+	return NewMultiplex(map[string]*Graph{
+		"work":   graphFromDOT(t, `graph { a [role=lead]; a -- b -- c }`),
+		"friend": graphFromDOT(t, `graph { a -- b [weight=2]; c -- d }`),
+	})
+}
+
+func TestMultiplex(t *testing.T) {
+	work := graphFromDOT(t, `graph { a [role=lead]; a -- b -- c }`)
+	want := DOT(work)
+	m := NewMultiplex(map[string]*Graph{
+		"work":   work,
+		"friend": graphFromDOT(t, `graph { a -- b [weight=2]; c -- d }`),
+	})
+	if got := DOT(work); got != want {
+		t.Errorf("unexpected change to layer graph:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got, want := m.Layers(), []string{"friend", "work"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected layers: got:%q want:%q", got, want)
+	}
+	if m.LayerGraph("family") != nil {
+		t.Error("unexpected graph for missing layer")
+	}
+	for _, name := range m.Layers() {
+		l := m.LayerGraph(name)
+		if n := l.Nodes().Len(); n != 4 {
+			t.Errorf("unexpected number of nodes in layer %s: got:%d want:4", name, n)
+		}
+		for _, dotID := range []string{"a", "b", "c", "d"} {
+			if l.NodeNamed(dotID).ID() != m.LayerGraph("work").NodeNamed(dotID).ID() {
+				t.Errorf("node %s has different IDs between layers", dotID)
+			}
+		}
+	}
+	if got := m.LayerGraph("work").NodeNamed("a").Get("role"); got != "lead" {
+		t.Errorf("unexpected copied attribute: got:%q want:lead", got)
+	}
+	if got := m.LayerGraph("friend").NodeNamed("a").Get("role"); got != "" {
+		t.Errorf("unexpected attribute in other layer: got:%q", got)
+	}
+}
+
+var aggregateLayerTests = []struct {
+	weights map[string]float64
+	want    map[[2]string]string
+}{
+	{
+		weights: nil,
+		want:    map[[2]string]string{{"a", "b"}: "3", {"b", "c"}: "1", {"c", "d"}: "1"},
+	},
+	{
+		weights: map[string]float64{"work": 0.5},
+		want:    map[[2]string]string{{"a", "b"}: "2.5", {"b", "c"}: "0.5", {"c", "d"}: "1"},
+	},
+	{
+		weights: map[string]float64{"friend": 0},
+		want:    map[[2]string]string{{"a", "b"}: "1", {"b", "c"}: "1"},
+	},
+}
+
+func TestMultiplexAggregate(t *testing.T) {
+	m := newTestMultiplex(t)
+	for i, test := range aggregateLayerTests {
+		a := m.Aggregate(test.weights)
+		got := make(map[[2]string]string)
+		for _, e := range graph.EdgesOf(a.Edges()) {
+			e := e.(*Edge)
+			u, v := e.F.Name, e.T.Name
+			if v < u {
+				u, v = v, u
+			}
+			got[[2]string{u, v}] = e.Get("weight")
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected aggregate for test %d: got:%v want:%v", i, got, test.want)
+		}
+	}
+}
+
+func TestParticipation(t *testing.T) {
+	m := newTestMultiplex(t)
+	got := m.Participation()
+	want := map[string]float64{"a": 1, "b": 8.0 / 9, "c": 1, "d": 0}
+	for dotID, w := range want {
+		if math.Abs(got[dotID]-w) > 1e-12 {
+			t.Errorf("unexpected participation for %s: got:%v want:%v", dotID, got[dotID], w)
+		}
+		for _, name := range m.Layers() {
+			if v := floatAttr(t, m.LayerGraph(name), dotID, "participation"); math.Abs(v-w) > 1e-12 {
+				t.Errorf("unexpected participation attribute for %s in layer %s: got:%v want:%v", dotID, name, v, w)
+			}
+		}
+	}
+
+	single := NewMultiplex(map[string]*Graph{"only": graphFromDOT(t, `graph { a -- b }`)})
+	for dotID, p := range single.Participation() {
+		if p != 0 {
+			t.Errorf("unexpected participation for %s in single layer: got:%v want:0", dotID, p)
+		}
+	}
+}
+
+func TestMultiplexPageRank(t *testing.T) {
+	// A single layer has the PageRank of its graph.
+	g := graphFromDOT(t, `graph { a -- b -- c -- a; c -- d; e }`)
+	m := NewMultiplex(map[string]*Graph{"only": g})
+	got, err := MultiplexPageRank(m, 1, 0.85, 1e-12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := pageRankFrom(g, 0.85, 1e-12, nil)
+	for _, n := range NodesOf(g) {
+		if w := want[n.ID()]; math.Abs(got[n.Name]-w) > 1e-9 {
+			t.Errorf("unexpected single layer versatility for %s: got:%v want:%v", n.Name, got[n.Name], w)
+		}
+	}
+
+	// Without damping, the rank of each copy of a node is
+	// proportional to its strength in the supra-graph, the
+	// sum of its degree in the layer and its coupling to
+	// the copies in the other layers. Damping must be less
+	// than 1, so use a value close enough for the limit.
+	const (
+		coupling = 0.5
+		damp     = 1 - 1e-12
+	)
+	m = NewMultiplex(map[string]*Graph{
+		"work":   graphFromDOT(t, `graph { a -- b -- c -- a }`),
+		"friend": graphFromDOT(t, `graph { a -- b; c -- d }`),
+	})
+	got, err = MultiplexPageRank(m, coupling, damp, 1e-12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	degrees := map[string]float64{"a": 2 + 1, "b": 2 + 1, "c": 2 + 1, "d": 0 + 1}
+	var total float64
+	for _, k := range degrees {
+		total += k + 2*coupling
+	}
+	for dotID, k := range degrees {
+		w := (k + 2*coupling) / total
+		if math.Abs(got[dotID]-w) > 1e-9 {
+			t.Errorf("unexpected versatility for %s: got:%v want:%v", dotID, got[dotID], w)
+		}
+		var sum float64
+		for _, name := range m.Layers() {
+			sum += floatAttr(t, m.LayerGraph(name), dotID, "multiplex_rank")
+			if v := floatAttr(t, m.LayerGraph(name), dotID, "versatility"); math.Abs(v-got[dotID]) > 1e-12 {
+				t.Errorf("unexpected versatility attribute for %s in layer %s: got:%v want:%v", dotID, name, v, got[dotID])
+			}
+		}
+		if math.Abs(sum-got[dotID]) > 1e-12 {
+			t.Errorf("unexpected sum of layer ranks for %s: got:%v want:%v", dotID, sum, got[dotID])
+		}
+	}
+
+	for _, test := range []struct {
+		name                string
+		coupling, damp, tol float64
+	}{
+		{name: "negative coupling", coupling: -1, damp: 0.85, tol: 1e-6},
+		{name: "invalid damping", coupling: 1, damp: 1.5, tol: 1e-6},
+		{name: "no teleportation", coupling: 1, damp: 1, tol: 1e-6},
+		{name: "negative damping", coupling: 1, damp: -0.1, tol: 1e-6},
+		{name: "zero tolerance", coupling: 1, damp: 0.85, tol: 0},
+		{name: "NaN tolerance", coupling: 1, damp: 0.85, tol: math.NaN()},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for %s", test.name)
+				}
+			}()
+			MultiplexPageRank(m, test.coupling, test.damp, test.tol)
+		}()
+	}
+}
+
+func TestMultiplexPageRankConvergence(t *testing.T) {
+	// A random walk on a path alternates between its
+	// centre and its ends, so without teleportation
+	// the ranks oscillate rather than converge.
+	m := NewMultiplex(map[string]*Graph{"only": graphFromDOT(t, `graph { a -- b -- c }`)})
+	got, err := MultiplexPageRank(m, 0, 1-1e-15, 1e-9)
+	if err == nil {
+		t.Errorf("expected error for oscillating ranks: got:%v", got)
+	}
+	if _, ok := Provenance(m.LayerGraph("only"), "versatility"); ok {
+		t.Error("unexpected provenance for failed analysis")
+	}
+	if v := m.LayerGraph("only").NodeNamed("a").Get("versatility"); v != "" {
+		t.Errorf("unexpected versatility for failed analysis: %s", v)
+	}
+}
+
+func TestMultiplexTrace(t *testing.T) {
+	m := newTestMultiplex(t)
+	m.Participation()
+	_, err := MultiplexPageRank(m, 1, 0.85, 1e-6)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range m.Layers() {
+		g := m.LayerGraph(name)
+		for attr, want := range map[string]string{
+			"participation":  "Participation",
+			"versatility":    "MultiplexPageRank",
+			"multiplex_rank": "MultiplexPageRank",
+		} {
+			p, ok := Provenance(g, attr)
+			if !ok || p.Analysis != want {
+				t.Errorf("unexpected provenance for %s in layer %s: got:%+v want analysis:%s", attr, name, p, want)
+			}
+		}
+	}
+
+	SetAnalysisOptions(AnalysisOptions{Refuse: true})
+	defer SetAnalysisOptions(AnalysisOptions{})
+	want := DOT(m.LayerGraph(m.Layers()[0]))
+	_, err = MultiplexPageRank(m, 0.5, 0.85, 1e-6)
+	if _, ok := err.(*OverwriteError); !ok {
+		t.Errorf("unexpected error for refused analysis: got:%v want:*OverwriteError", err)
+	}
+	if got := DOT(m.LayerGraph(m.Layers()[0])); got != want {
+		t.Errorf("refused analysis altered layer:\ngot: %s\nwant:%s", got, want)
+	}
+}
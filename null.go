@@ -0,0 +1,269 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// NullModel returns a random graph drawn from a null model fitted to g.
+// The returned graph must be a new graph with the same nodes as g, and g
+// must not be modified. The same seed must give the same graph for the
+// same g.
+type NullModel func(g *Graph, seed int64) *Graph
+
+// ConfigurationModel returns a random graph with the same nodes and the
+// same degree sequence as g. The graph is generated by repeated
+// degree-preserving double edge swaps, replacing edges u--v and x--y with
+// u--y and x--v, starting from g and attempting ten swaps for each edge.
+// Swaps that would introduce self loops or multiple edges are rejected,
+// so the returned graph is simple. Node IDs and attributes are retained
+// and edges have no attributes.
+func ConfigurationModel(g *Graph, seed int64) *Graph {
+	x := g.Index()
+	adj := x.adjacency(g)
+	var edges [][2]int
+	present := make(map[[2]int]bool)
+	for i, to := range adj {
+		for _, j := range to {
+			if j > i {
+				edges = append(edges, [2]int{i, j})
+				present[[2]int{i, j}] = true
+			}
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	if len(edges) > 1 {
+		for k := 0; k < 10*len(edges); k++ {
+			a, b := rnd.Intn(len(edges)), rnd.Intn(len(edges))
+			if a == b {
+				continue
+			}
+			u, v := edges[a][0], edges[a][1]
+			s, t := edges[b][0], edges[b][1]
+			if rnd.Intn(2) == 0 {
+				s, t = t, s
+			}
+			// Replace u--v and s--t with u--t and s--v.
+			e, f := orderedPair(u, t), orderedPair(s, v)
+			if u == t || s == v || present[e] || present[f] {
+				continue
+			}
+			delete(present, edges[a])
+			delete(present, edges[b])
+			present[e] = true
+			present[f] = true
+			edges[a], edges[b] = e, f
+		}
+	}
+	return withEdges(g, x, edges)
+}
+
+// ErdosRenyi returns a random graph with the same nodes and the same number
+// of edges as g, with the edges chosen uniformly at random from all pairs
+// of distinct nodes. Node IDs and attributes are retained and edges have no
+// attributes.
+func ErdosRenyi(g *Graph, seed int64) *Graph {
+	x := g.Index()
+	n := x.Len()
+	m := g.Edges().Len()
+
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	present := make(map[[2]int]bool, m)
+	edges := make([][2]int, 0, m)
+	for len(edges) < m {
+		u, v := rnd.Intn(n), rnd.Intn(n)
+		e := orderedPair(u, v)
+		if u == v || present[e] {
+			continue
+		}
+		present[e] = true
+		edges = append(edges, e)
+	}
+	return withEdges(g, x, edges)
+}
+
+// RewireDirected returns a random directed graph with the same nodes and
+// the same in-degree and out-degree sequences as g, for use as a null model
+// for directed network statistics such as motif counts and reciprocity.
+// The graph is generated by making the given number of attempts at
+// degree-preserving swaps, replacing edges u→v and s→t with u→t and s→v,
+// starting from g. Swaps that would introduce self loops or multiple edges
+// are rejected.
+//
+// If preserveReciprocity is true, the number of reciprocated pairs of
+// edges, u→v and v→u, is also preserved. Reciprocated pairs are swapped
+// only with other reciprocated pairs, as undirected edges, and other edges
+// only with other unreciprocated edges, and swaps that would create or
+// break a reciprocated pair are rejected. The in-degree and out-degree
+// sequences of the reciprocated and unreciprocated edges are then each
+// preserved.
+//
+// Node IDs and attributes are retained and edges have no attributes.
+// RewireDirected panics if swaps is negative.
+func RewireDirected(g *Digraph, swaps int, preserveReciprocity bool, seed int64) *Digraph {
+	if swaps < 0 {
+		panic("graphprac: invalid number of swaps")
+	}
+	x := g.Index()
+	adj := x.adjacency(g)
+	present := make(map[[2]int]bool)
+	for i, to := range adj {
+		for _, j := range to {
+			present[[2]int{i, j}] = true
+		}
+	}
+	// single holds the edges that are swapped as
+	// directed edges and mutual holds one edge
+	// of each reciprocated pair when reciprocity
+	// is preserved.
+	var single, mutual [][2]int
+	for i, to := range adj {
+		for _, j := range to {
+			switch {
+			case !preserveReciprocity || !present[[2]int{j, i}]:
+				single = append(single, [2]int{i, j})
+			case i < j:
+				mutual = append(mutual, [2]int{i, j})
+			}
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	if len(single)+len(mutual) < 2 {
+		swaps = 0
+	}
+	for k := 0; k < swaps; k++ {
+		isMutual := rnd.Intn(len(single)+len(mutual)) >= len(single)
+		edges := single
+		if isMutual {
+			edges = mutual
+		}
+		if len(edges) < 2 {
+			continue
+		}
+		a, b := rnd.Intn(len(edges)), rnd.Intn(len(edges))
+		if a == b {
+			continue
+		}
+		u, v := edges[a][0], edges[a][1]
+		s, t := edges[b][0], edges[b][1]
+		if isMutual && rnd.Intn(2) == 0 {
+			s, t = t, s
+		}
+		// Replace u→v and s→t with u→t and s→v.
+		if u == t || s == v || present[[2]int{u, t}] || present[[2]int{s, v}] {
+			continue
+		}
+		if preserveReciprocity && (present[[2]int{t, u}] || present[[2]int{v, s}]) {
+			continue
+		}
+		delete(present, [2]int{u, v})
+		delete(present, [2]int{s, t})
+		present[[2]int{u, t}] = true
+		present[[2]int{s, v}] = true
+		if isMutual {
+			delete(present, [2]int{v, u})
+			delete(present, [2]int{t, s})
+			present[[2]int{t, u}] = true
+			present[[2]int{v, s}] = true
+		}
+		edges[a], edges[b] = [2]int{u, t}, [2]int{s, v}
+	}
+
+	dst := &Digraph{
+		DirectedGraph: simple.NewDirectedGraph(),
+		GraphAttrs:    append(Attributes(nil), g.GraphAttrs...),
+		NodeAttrs:     append(Attributes(nil), g.NodeAttrs...),
+		EdgeAttrs:     append(Attributes(nil), g.EdgeAttrs...),
+	}
+	for i := 0; i < x.Len(); i++ {
+		n := x.Node(i)
+		dst.AddNode(&Node{
+			NodeID:     n.NodeID,
+			Name:       n.Name,
+			Attributes: append(Attributes(nil), n.Attributes...),
+		})
+	}
+	arc := func(u, v int) {
+		dst.SetEdge(&Edge{
+			F: dst.Node(x.ID(u)).(*Node),
+			T: dst.Node(x.ID(v)).(*Node),
+		})
+	}
+	for _, e := range single {
+		arc(e[0], e[1])
+	}
+	for _, e := range mutual {
+		arc(e[0], e[1])
+		arc(e[1], e[0])
+	}
+	return dst
+}
+
+// orderedPair returns the pair of u and v in ascending order.
+func orderedPair(u, v int) [2]int {
+	if u > v {
+		u, v = v, u
+	}
+	return [2]int{u, v}
+}
+
+// withEdges returns a copy of the nodes of g holding the edges between the
+// pairs of nodes indexed by x in edges.
+func withEdges(g *Graph, x NodeIndex, edges [][2]int) *Graph {
+	keep := make(map[int64]int, x.Len())
+	for i := 0; i < x.Len(); i++ {
+		keep[x.ID(i)] = i
+	}
+	dst := subgraphNodes(g, keep)
+	for _, e := range edges {
+		dst.SetEdge(&Edge{
+			F: dst.Node(x.ID(e[0])).(*Node),
+			T: dst.Node(x.ID(e[1])).(*Node),
+		})
+	}
+	return dst
+}
+
+// Significance tests the scalar statistic stat of g against graphs drawn
+// from nullModel, for example ConfigurationModel or ErdosRenyi. The
+// statistic is calculated for g, returned as observed, and for each of
+// trials null graphs, returned in nullDist. The null graphs are
+// determined by seed.
+//
+// The returned p is the two-sided empirical p-value, twice the smaller
+// of the fractions of the null distribution at or above and at or below
+// the observed value, with the observed value counted as one of the
+// null samples so that p is never zero. The smallest possible p-value is
+// therefore 2/(trials+1).
+//
+// Significance panics if trials is less than one.
+func Significance(g *Graph, stat func(*Graph) float64, nullModel NullModel, trials int, seed int64) (observed float64, p float64, nullDist []float64) {
+	if trials < 1 {
+		panic("graphprac: invalid number of trials")
+	}
+	observed = stat(g)
+
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	nullDist = make([]float64, trials)
+	above, below := 1, 1
+	for t := range nullDist {
+		v := stat(nullModel(g, int64(rnd.Uint64())))
+		nullDist[t] = v
+		if v >= observed {
+			above++
+		}
+		if v <= observed {
+			below++
+		}
+	}
+	p = 2 * math.Min(float64(above), float64(below)) / float64(trials+1)
+	return observed, math.Min(p, 1), nullDist
+}
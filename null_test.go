@@ -0,0 +1,249 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// degreeOf returns the degree of each node of g keyed by node name.
+func degreeOf(g *Graph) map[string]int {
+	d := make(map[string]int)
+	for _, n := range NodesOf(g) {
+		d[n.Name] = g.From(n.ID()).Len()
+	}
+	return d
+}
+
+func TestNullModels(t *testing.T) {
+	src := ringOfCliques(4, 5)
+	for _, test := range []struct {
+		name  string
+		model NullModel
+	}{
+		{name: "configuration", model: ConfigurationModel},
+		{name: "erdos-renyi", model: ErdosRenyi},
+	} {
+		g := graphFromDOT(t, src)
+		want := DOT(g)
+		for seed := int64(0); seed < 5; seed++ {
+			r := test.model(g, seed)
+			if got := DOT(g); got != want {
+				t.Fatalf("%s model modified the graph", test.name)
+			}
+			if got, want := r.Nodes().Len(), g.Nodes().Len(); got != want {
+				t.Errorf("unexpected number of nodes for %s seed %d: got:%d want:%d", test.name, seed, got, want)
+			}
+			if got, want := r.Edges().Len(), g.Edges().Len(); got != want {
+				t.Errorf("unexpected number of edges for %s seed %d: got:%d want:%d", test.name, seed, got, want)
+			}
+			for _, n := range NodesOf(g) {
+				if m, ok := r.Node(n.ID()).(*Node); !ok || m.Name != n.Name {
+					t.Errorf("missing node %s for %s seed %d", n.Name, test.name, seed)
+				}
+			}
+			for _, e := range graph.EdgesOf(r.Edges()) {
+				if e.From().ID() == e.To().ID() {
+					t.Errorf("unexpected self loop for %s seed %d", test.name, seed)
+				}
+			}
+			if got, want := DOT(test.model(g, seed)), DOT(r); got != want {
+				t.Errorf("unexpected graph for %s with repeated seed %d", test.name, seed)
+			}
+			if test.name == "configuration" && !reflect.DeepEqual(degreeOf(r), degreeOf(g)) {
+				t.Errorf("unexpected degree sequence for seed %d: got:%v want:%v", seed, degreeOf(r), degreeOf(g))
+			}
+		}
+	}
+}
+
+var significanceTests = []struct {
+	observed float64
+	null     []float64
+	p        float64
+}{
+	{observed: 5, null: []float64{1, 2, 3, 4, 6}, p: 2 * 2.0 / 6},
+	{observed: 5, null: []float64{1, 2, 3, 4}, p: 2 * 1.0 / 5},
+	{observed: 0, null: []float64{1, 2, 3, 4}, p: 2 * 1.0 / 5},
+	{observed: 5, null: []float64{5, 5, 5}, p: 1},
+}
+
+func TestSignificance(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b }`)
+	for i, test := range significanceTests {
+		// The statistic returns the observed value followed
+		// by the null values in turn.
+		vals := append([]float64{test.observed}, test.null...)
+		stat := func(*Graph) float64 {
+			v := vals[0]
+			vals = vals[1:]
+			return v
+		}
+		same := func(g *Graph, _ int64) *Graph { return g }
+		observed, p, null := Significance(g, stat, same, len(test.null), 1)
+		if observed != test.observed {
+			t.Errorf("unexpected observed value for test %d: got:%v want:%v", i, observed, test.observed)
+		}
+		if math.Abs(p-test.p) > 1e-12 {
+			t.Errorf("unexpected p-value for test %d: got:%v want:%v", i, p, test.p)
+		}
+		if !reflect.DeepEqual(null, test.null) {
+			t.Errorf("unexpected null distribution for test %d: got:%v want:%v", i, null, test.null)
+		}
+	}
+}
+
+func TestSignificanceTriangles(t *testing.T) {
+	// A ring of cliques has many more triangles
+	// than random graphs with the same degrees.
+	triangles := func(g *Graph) float64 {
+		Triangles(g)
+		var n float64
+		for _, v := range NodesOf(g) {
+			n += floatAttr(t, g, v.Name, "triangles")
+		}
+		return n / 3
+	}
+	g := graphFromDOT(t, ringOfCliques(4, 5))
+	const trials = 19
+	observed, p, _ := Significance(g, triangles, ConfigurationModel, trials, 1)
+	if observed != 40 {
+		t.Errorf("unexpected number of triangles: got:%v want:40", observed)
+	}
+	if want := 2.0 / (trials + 1); p != want {
+		t.Errorf("unexpected p-value: got:%v want:%v", p, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for no trials")
+		}
+	}()
+	Significance(g, triangles, ConfigurationModel, 0, 1)
+}
+
+// digraphFromDOT returns the directed graph described by the DOT in src,
+// failing the test if it cannot be read.
+func digraphFromDOT(t *testing.T, src string) *Digraph {
+	t.Helper()
+	m, err := ReadMixedDOT(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("failed to read graph: %v", err)
+	}
+	return m.Directed()
+}
+
+// arcRing returns a DOT digraph of n nodes with arcs from each node to
+// the next and the third next nodes, and reciprocated pairs between each
+// even node and the node seven on.
+func arcRing(n int) string {
+	var buf strings.Builder
+	buf.WriteString("digraph {\n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, "\tn%d -> n%d;\n\tn%d -> n%d;\n", i, (i+1)%n, i, (i+3)%n)
+		if i%2 == 0 {
+			fmt.Fprintf(&buf, "\tn%d -> n%d;\n\tn%[2]d -> n%[1]d;\n", i, (i+7)%n)
+		}
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// arcs returns the arcs of g as sorted "u->v" strings.
+func arcs(g *Digraph) []string {
+	var a []string
+	for _, e := range graph.EdgesOf(g.Edges()) {
+		a = append(a, e.From().(*Node).Name+"->"+e.To().(*Node).Name)
+	}
+	sort.Strings(a)
+	return a
+}
+
+// arcStats returns the in-degree and out-degree of each node of g keyed
+// by node name, and the number of reciprocated pairs of arcs in g.
+func arcStats(g *Digraph) (in, out map[string]int, mutual int) {
+	in = make(map[string]int)
+	out = make(map[string]int)
+	for _, n := range graph.NodesOf(g.Nodes()) {
+		name := n.(*Node).Name
+		in[name] = g.To(n.ID()).Len()
+		out[name] = g.From(n.ID()).Len()
+	}
+	for _, e := range graph.EdgesOf(g.Edges()) {
+		if e.From().ID() < e.To().ID() && g.HasEdgeFromTo(e.To().ID(), e.From().ID()) {
+			mutual++
+		}
+	}
+	return in, out, mutual
+}
+
+func TestRewireDirected(t *testing.T) {
+	g := digraphFromDOT(t, arcRing(20))
+	want := DOT(g)
+	wantArcs := arcs(g)
+	wantIn, wantOut, wantMutual := arcStats(g)
+	if wantMutual != 10 {
+		t.Fatalf("unexpected number of reciprocated pairs in test graph: got:%d want:10", wantMutual)
+	}
+
+	for _, preserve := range []bool{false, true} {
+		var changed, reciprocityChanged bool
+		for seed := int64(0); seed < 10; seed++ {
+			r := RewireDirected(g, 500, preserve, seed)
+			if got := DOT(g); got != want {
+				t.Fatalf("rewiring modified the graph")
+			}
+			if got, want := r.Edges().Len(), g.Edges().Len(); got != want {
+				t.Errorf("unexpected number of edges for preserve=%t seed %d: got:%d want:%d", preserve, seed, got, want)
+			}
+			for _, n := range graph.NodesOf(g.Nodes()) {
+				if m, ok := r.Node(n.ID()).(*Node); !ok || m.Name != n.(*Node).Name {
+					t.Errorf("missing node %s for preserve=%t seed %d", n.(*Node).Name, preserve, seed)
+				}
+			}
+			for _, e := range graph.EdgesOf(r.Edges()) {
+				if e.From().ID() == e.To().ID() {
+					t.Errorf("unexpected self loop for preserve=%t seed %d", preserve, seed)
+				}
+			}
+			in, out, mutual := arcStats(r)
+			if !reflect.DeepEqual(in, wantIn) || !reflect.DeepEqual(out, wantOut) {
+				t.Errorf("unexpected degree sequences for preserve=%t seed %d", preserve, seed)
+			}
+			if preserve && mutual != wantMutual {
+				t.Errorf("unexpected number of reciprocated pairs for seed %d: got:%d want:%d", seed, mutual, wantMutual)
+			}
+			reciprocityChanged = reciprocityChanged || mutual != wantMutual
+			changed = changed || !reflect.DeepEqual(arcs(r), wantArcs)
+			if got, want := DOT(RewireDirected(g, 500, preserve, seed)), DOT(r); got != want {
+				t.Errorf("unexpected graph for preserve=%t with repeated seed %d", preserve, seed)
+			}
+		}
+		if !changed {
+			t.Errorf("rewiring did not change the graph for preserve=%t", preserve)
+		}
+		if !preserve && !reciprocityChanged {
+			t.Error("rewiring without preserving reciprocity did not change reciprocity")
+		}
+	}
+
+	if got := arcs(RewireDirected(g, 0, false, 1)); !reflect.DeepEqual(got, wantArcs) {
+		t.Errorf("unexpected arcs for no swaps: got:%q want:%q", got, wantArcs)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for negative swaps")
+		}
+	}()
+	RewireDirected(g, -1, false, 1)
+}
@@ -0,0 +1,114 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"sync"
+)
+
+// AnalysisOptions holds options applying to all analyses.
+type AnalysisOptions struct {
+	// Overwrite allows analyses to overwrite attributes
+	// written by a different analysis, or by the same
+	// analysis with different parameters, without
+	// logging a warning. Attributes without a recorded
+	// provenance may always be overwritten.
+	Overwrite bool
+
+	// Refuse makes analyses refuse to overwrite
+	// attributes unless Overwrite is set.
+	Refuse bool
+}
+
+var (
+	optionsMu sync.RWMutex
+	options   AnalysisOptions
+)
+
+// SetAnalysisOptions sets the options used by all analyses. By default,
+// an analysis that overwrites an attribute written by a different
+// analysis, or by the same analysis with different parameters, logs a
+// warning with the logger set by SetLogger and proceeds. If Refuse is
+// set, the analysis instead logs a warning of the refusal and leaves the graph
+// unaltered, and analyses that return an error, and Run, return an
+// *OverwriteError. This prevents results from different
+// parameterisations being mixed in one attribute. Repeating an analysis
+// with the same parameters is always allowed.
+func SetAnalysisOptions(opts AnalysisOptions) {
+	optionsMu.Lock()
+	options = opts
+	optionsMu.Unlock()
+}
+
+// OverwriteError is the error reported when an analysis refuses to
+// overwrite an attribute.
+type OverwriteError struct {
+	// Analysis and Params are the name and
+	// parameters of the refused analysis.
+	Analysis string
+	Params   map[string]string
+
+	// Existing is the provenance of the
+	// attribute that would be overwritten.
+	Existing AttributeProvenance
+}
+
+func (e *OverwriteError) Error() string {
+	refused := AttributeProvenance{Analysis: e.Analysis, Params: e.Params}
+	return fmt.Sprintf("%s would overwrite %s: set Overwrite with SetAnalysisOptions to allow",
+		refused.call(), e.Existing)
+}
+
+// checkOverwrite checks whether the named analysis of g with the provided
+// parameter key-value pairs may write attrs. If it would overwrite any of
+// attrs and this is not allowed by the analysis options, checkOverwrite
+// logs a warning for each attribute overwritten and returns nil, or, if
+// refusal is requested, logs a warning of the refusal, records it for Run and returns
+// the *OverwriteError.
+func checkOverwrite(g analysed, attrs []string, name string, params ...interface{}) error {
+	optionsMu.RLock()
+	opts := options
+	optionsMu.RUnlock()
+	if opts.Overwrite {
+		return nil
+	}
+	loggerMu.RLock()
+	l := logger
+	loggerMu.RUnlock()
+	m := paramMap(params...)
+	s := g.tracing()
+	for _, attr := range attrs {
+		p, ok := s.provenance[attr]
+		if !ok || (p.Analysis == name && equalParams(p.Params, m)) {
+			continue
+		}
+		if opts.Refuse {
+			err := &OverwriteError{Analysis: name, Params: m, Existing: p}
+			if l != nil {
+				l.Warn("analysis refused", "analysis", name, "error", err)
+			}
+			s.refused = err
+			return err
+		}
+		if l != nil {
+			l.Warn("analysis overwrites attribute", "analysis", name, "existing", p.String())
+		}
+	}
+	return nil
+}
+
+// equalParams returns whether a and b hold the same parameters.
+func equalParams(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,174 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"testing"
+)
+
+// countLevel returns the number of events logged by l at the given level.
+func countLevel(l *testLogger, level string) int {
+	var n int
+	for _, ev := range l.events {
+		if ev.level == level {
+			n++
+		}
+	}
+	return n
+}
+
+func TestOverwriteWarn(t *testing.T) {
+	l := &testLogger{}
+	SetLogger(l)
+	defer SetLogger(nil)
+
+	g := graphFromDOT(t, `graph { a -- b -- c }`)
+	PageRank(g, 0.85, 1e-6)
+	PageRank(g, 0.85, 1e-6)
+	if n := countLevel(l, "warn"); n != 0 {
+		t.Errorf("unexpected warnings for repeated analysis: got:%d want:0", n)
+	}
+
+	PageRank(g, 0.5, 1e-6)
+	if n := countLevel(l, "warn"); n != 1 {
+		t.Fatalf("unexpected number of warnings: got:%d want:1", n)
+	}
+	for _, ev := range l.events {
+		if ev.level == "warn" && ev.arg("analysis") != "PageRank" {
+			t.Errorf("unexpected analysis in warning: got:%v want:PageRank", ev.arg("analysis"))
+		}
+	}
+	if p, _ := Provenance(g, "rank"); p.Params["damp"] != "0.5" {
+		t.Errorf("unexpected damping parameter after overwrite: got:%s want:0.5", p.Params["damp"])
+	}
+
+	// Attributes written by other means may
+	// always be overwritten.
+	g = graphFromDOT(t, `graph { a [rank=1]; a -- b }`)
+	l.events = nil
+	PageRank(g, 0.85, 1e-6)
+	if n := countLevel(l, "warn"); n != 0 {
+		t.Errorf("unexpected warnings for attribute without provenance: got:%d want:0", n)
+	}
+}
+
+func TestOverwriteRefuse(t *testing.T) {
+	l := &testLogger{}
+	SetLogger(l)
+	defer SetLogger(nil)
+	SetAnalysisOptions(AnalysisOptions{Refuse: true})
+	defer SetAnalysisOptions(AnalysisOptions{})
+
+	g := graphFromDOT(t, `graph { a -- b -- c; d }`)
+	PageRank(g, 0.5, 1e-6)
+	want := DOT(g)
+
+	PageRank(g, 0.85, 1e-6)
+	if got := DOT(g); got != want {
+		t.Errorf("unexpected change to graph after refusal:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if n := countLevel(l, "warn"); n != 1 {
+		t.Errorf("unexpected number of warnings for refusal: got:%d want:1", n)
+	}
+	var refused bool
+	for _, ev := range l.events {
+		refused = refused || (ev.level == "warn" && ev.msg == "analysis refused")
+	}
+	if !refused {
+		t.Error("expected refusal to be logged as a warning")
+	}
+
+	err := Run(g, "PageRank", map[string]string{"damp": "0.85"})
+	e, ok := err.(*OverwriteError)
+	if !ok {
+		t.Fatalf("unexpected error from Run: got:%v want:*OverwriteError", err)
+	}
+	if e.Analysis != "PageRank" || e.Params["damp"] != "0.85" || e.Existing.Params["damp"] != "0.5" {
+		t.Errorf("unexpected overwrite error: %+v", e)
+	}
+	if err := Run(g, "PageRank", map[string]string{"damp": "0.5"}); err != nil {
+		t.Errorf("unexpected error for repeated analysis: %v", err)
+	}
+	if err := Run(g, "Eccentricity", nil); err != nil {
+		t.Errorf("unexpected error for analysis after refusal: %v", err)
+	}
+
+	CommunitiesWithOptions(g, CommunityOptions{Resolution: 1})
+	if err := CommunitiesWithOptions(g, CommunityOptions{Resolution: 2}); err == nil {
+		t.Error("expected error from CommunitiesWithOptions")
+	} else if _, ok := err.(*OverwriteError); !ok {
+		t.Errorf("unexpected error type: got:%T want:*OverwriteError", err)
+	}
+
+	SetAnalysisOptions(AnalysisOptions{Refuse: true, Overwrite: true})
+	l.events = nil
+	if err := Run(g, "PageRank", map[string]string{"damp": "0.85"}); err != nil {
+		t.Errorf("unexpected error with overwrite allowed: %v", err)
+	}
+	if p, _ := Provenance(g, "rank"); p.Params["damp"] != "0.85" {
+		t.Errorf("unexpected damping parameter with overwrite allowed: got:%s want:0.85", p.Params["damp"])
+	}
+	if n := countLevel(l, "warn"); n != 0 {
+		t.Errorf("unexpected warnings with overwrite allowed: got:%d want:0", n)
+	}
+}
+
+var digraphOverwriteTests = []struct {
+	name string
+	attr string
+	run  func(*Digraph) error
+
+	// returnsErr is whether the
+	// analysis returns an error.
+	returnsErr bool
+}{
+	{name: "AgonyRank", attr: "agony", run: func(g *Digraph) error { AgonyRank(g); return nil }},
+	{name: "CopelandRank", attr: "copeland", run: CopelandRank, returnsErr: true},
+	{name: "ColleyRank", attr: "colley", run: ColleyRank, returnsErr: true},
+	{name: "TrophicLevels", attr: "trophic", run: TrophicLevels, returnsErr: true},
+	{name: "FindFFL", attr: "ffl", run: func(g *Digraph) error { FindFFL(g); return nil }},
+	{name: "FindFeedbackLoops", attr: "feedback", run: func(g *Digraph) error { FindFeedbackLoops(g, 3); return nil }},
+}
+
+func TestOverwriteDigraph(t *testing.T) {
+	l := &testLogger{}
+	SetLogger(l)
+	defer SetLogger(nil)
+	SetAnalysisOptions(AnalysisOptions{Refuse: true})
+	defer SetAnalysisOptions(AnalysisOptions{})
+
+	const src = `digraph { a -> b -> c; a -> c }`
+	for i, test := range digraphOverwriteTests {
+		g := digraphFromDOT(t, src)
+		err := test.run(g)
+		if err != nil {
+			t.Errorf("unexpected error for test %d (%s): %v", i, test.name, err)
+			continue
+		}
+		if p, ok := g.provenance[test.attr]; !ok || p.Analysis != test.name {
+			t.Errorf("unexpected provenance for test %d: got:%+v want analysis:%s", i, p, test.name)
+		}
+
+		g = digraphFromDOT(t, src)
+		g.setProvenanceRecord(test.attr, AttributeProvenance{Attribute: test.attr, Analysis: "Other"})
+		want := DOT(g)
+		l.events = nil
+		err = test.run(g)
+		if test.returnsErr {
+			if _, ok := err.(*OverwriteError); !ok {
+				t.Errorf("unexpected error for test %d (%s): got:%v want:*OverwriteError", i, test.name, err)
+			}
+		}
+		if got := DOT(g); got != want {
+			t.Errorf("unexpected change to graph after refusal for test %d (%s):\ngot:\n%s\nwant:\n%s", i, test.name, got, want)
+		}
+		if g.refused == nil || g.refused.Analysis != test.name {
+			t.Errorf("unexpected recorded refusal for test %d: got:%v want analysis:%s", i, g.refused, test.name)
+		}
+		if n := countLevel(l, "warn"); n != 1 {
+			t.Errorf("unexpected number of warnings for test %d (%s): got:%d want:1", i, test.name, n)
+		}
+	}
+}
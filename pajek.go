@@ -0,0 +1,548 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Pajek network file sections.
+const (
+	pajekNetwork = iota + 1
+	pajekVertices
+	pajekEdges
+	pajekEdgeList
+	pajekOther
+)
+
+// PajekOptions controls how vertex labels of Pajek networks are read and
+// written.
+type PajekOptions struct {
+	// SplitLabels specifies that the first word
+	// of each vertex label is the DOT ID of the
+	// node and the remainder is held in its "desc"
+	// attribute, as in the yeast protein network,
+	// and that vertices labelled "Unknow protein !!!"
+	// are named Unknown followed by their number.
+	SplitLabels bool
+}
+
+// ReadPajek reads a Pajek network (.net) file from r and returns the graph
+// it describes. The *Network name is held in the Name field of the graph.
+// Vertex i is given node ID i-1, so node indexes match vertex numbers as
+// expected by ReadClu and ReadVec. The label of each vertex is used as the
+// DOT ID of the node, and vertices without a label are named by their
+// number. Vertex coordinates are written into the "pos" attribute, and
+// edge weights into the "weight" attribute of the edge.
+//
+// Arcs are read as edges, self edges are discarded and the last weight
+// given for a repeated edge is retained. Sections other than *Vertices,
+// *Edges, *Arcs, *Edgeslist and *Arcslist are skipped. Data that is not
+// valid UTF-8 is read as Windows-1252. Syntax errors in the data, including
+// vertices with the same name, are returned as a *ParseError.
+func ReadPajek(r io.Reader) (*Graph, error) {
+	return ReadPajekWithOptions(r, PajekOptions{})
+}
+
+// ReadPajekWithOptions reads a Pajek network file from r as described for
+// ReadPajek, using the provided options to control how vertex labels are
+// read.
+func ReadPajekWithOptions(r io.Reader, opts PajekOptions) (*Graph, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	b = toUTF8(b)
+
+	g := &Graph{UndirectedGraph: simple.NewUndirectedGraph()}
+	var n int
+	nodes := make(map[int]*Node)
+	names := make(map[string]int)
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	var state int
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if len(text) == 0 || text[0] == '%' {
+			continue
+		}
+		if text[0] == '*' {
+			f := strings.Fields(text)
+			if state == pajekVertices {
+				err = addMissingVertices(g, nodes, names, n)
+				if err != nil {
+					return nil, &ParseError{Line: line, Text: text, Err: err}
+				}
+			}
+			switch strings.ToLower(f[0]) {
+			case "*network":
+				state = pajekNetwork
+				g.Name = strings.TrimSpace(text[len(f[0]):])
+			case "*vertices":
+				state = pajekVertices
+				if len(f) < 2 {
+					return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("missing number of vertices")}
+				}
+				n, err = strconv.Atoi(f[1])
+				if err != nil {
+					return nil, &ParseError{Line: line, Text: text, Err: err}
+				}
+			case "*edges", "*arcs":
+				// Arcs are treated as edges since
+				// the graph is undirected.
+				state = pajekEdges
+			case "*edgeslist", "*arcslist":
+				state = pajekEdgeList
+			default:
+				// Skip sections such as *matrix
+				// and *partition.
+				state = pajekOther
+			}
+			continue
+		}
+
+		switch state {
+		case pajekNetwork, pajekOther:
+			// Do nothing.
+		case pajekVertices:
+			f, err := pajekFields(text)
+			if err != nil {
+				return nil, &ParseError{Line: line, Text: text, Err: err}
+			}
+			id, err := strconv.Atoi(f[0])
+			if err != nil {
+				return nil, &ParseError{Line: line, Text: text, Err: err}
+			}
+			if id < 1 || id > n {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("vertex %d out of range [1,%d]", id, n)}
+			}
+			label := f[0]
+			if len(f) > 1 {
+				label, err = unquoteLabel(f[1])
+				if err != nil {
+					return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("invalid vertex label %s: %v", f[1], err)}
+				}
+			}
+			name, desc := label, ""
+			switch {
+			case !opts.SplitLabels:
+			case label == "Unknow protein !!!":
+				// The yeast protein network labels
+				// unknown proteins with this text.
+				name = fmt.Sprintf("Unknown%04d", id)
+				desc = label
+			default:
+				attrs := strings.SplitN(label, " ", 2)
+				if len(attrs) == 2 {
+					desc = attrs[1]
+				}
+				name = attrs[0]
+			}
+			if _, ok := nodes[id]; ok {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("duplicate vertex %d", id)}
+			}
+			if prev, ok := names[name]; ok {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("vertex %d has the same name as vertex %d: %q", id, prev, name)}
+			}
+			names[name] = id
+			v := &Node{NodeID: int64(id - 1), Name: name}
+			if desc != "" {
+				v.SetQuoted("desc", desc)
+			}
+			if pos := coordinates(f); pos != "" {
+				v.SetQuoted("pos", pos)
+			}
+			nodes[id] = v
+			g.AddNode(v)
+		case pajekEdges, pajekEdgeList:
+			f := strings.Fields(text)
+			if len(f) < 2 {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("too few parameters for edge")}
+			}
+			ends := f[:2]
+			if state == pajekEdgeList {
+				ends = f
+			}
+			var weight string
+			if state == pajekEdges && len(f) > 2 {
+				w, err := strconv.ParseFloat(f[2], 64)
+				if err != nil {
+					return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("invalid edge weight %q", f[2])}
+				}
+				weight = fmt.Sprint(w)
+			}
+			from, err := pajekVertex(nodes, ends[0])
+			if err != nil {
+				return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("failed to parse from node id: %v", err)}
+			}
+			for _, t := range ends[1:] {
+				to, err := pajekVertex(nodes, t)
+				if err != nil {
+					return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("failed to parse to node id: %v", err)}
+				}
+				if from == to {
+					continue
+				}
+				e := g.NewEdge(from, to).(*Edge)
+				if weight != "" {
+					e.SetAttribute(encoding.Attribute{Key: "weight", Value: weight})
+				}
+			}
+		default:
+			return nil, &ParseError{Line: line, Text: text, Err: fmt.Errorf("data before section header")}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if state == pajekVertices {
+		err = addMissingVertices(g, nodes, names, n)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(nodes) != n {
+		return nil, fmt.Errorf("unexpected number of nodes: got=%d want=%d", len(nodes), n)
+	}
+	return g, nil
+}
+
+// addMissingVertices adds the vertices in [1,n] that were not listed in
+// the *Vertices section to g, named by their number. It returns an error
+// if the number of a missing vertex is the name of another vertex.
+func addMissingVertices(g *Graph, nodes map[int]*Node, names map[string]int, n int) error {
+	for id := 1; id <= n; id++ {
+		if _, ok := nodes[id]; ok {
+			continue
+		}
+		name := strconv.Itoa(id)
+		if prev, ok := names[name]; ok {
+			return fmt.Errorf("unlabelled vertex %d has the same name as vertex %d", id, prev)
+		}
+		names[name] = id
+		v := &Node{NodeID: int64(id - 1), Name: name}
+		nodes[id] = v
+		g.AddNode(v)
+	}
+	return nil
+}
+
+// pajekVertex returns the vertex in nodes with the given number.
+func pajekVertex(nodes map[int]*Node, s string) (*Node, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := nodes[id]
+	if !ok {
+		return nil, fmt.Errorf("no vertex %d", id)
+	}
+	return v, nil
+}
+
+// pajekFields splits a vertex line into white space separated fields,
+// keeping quoted labels, which may contain white space, as one field.
+func pajekFields(s string) ([]string, error) {
+	var f []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return f, nil
+		}
+		if s[0] != '"' {
+			i := strings.IndexAny(s, " \t")
+			if i < 0 {
+				i = len(s)
+			}
+			f = append(f, s[:i])
+			s = s[i:]
+			continue
+		}
+		i := strings.IndexByte(s[1:], '"')
+		if i < 0 {
+			return nil, fmt.Errorf("unterminated label")
+		}
+		f = append(f, s[:i+2])
+		s = s[i+2:]
+	}
+}
+
+// unquoteLabel returns the vertex label s with any quotes removed.
+// Labels are quoted without escapes, so backslashes are kept.
+func unquoteLabel(s string) (string, error) {
+	if s[0] != '"' {
+		return s, nil
+	}
+	if len(s) < 2 || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("unterminated label")
+	}
+	return s[1 : len(s)-1], nil
+}
+
+// coordinates returns the vertex coordinates following the label in the
+// vertex line fields f as a DOT pos value, or the empty string if there
+// are none.
+func coordinates(f []string) string {
+	if len(f) < 4 {
+		return ""
+	}
+	var pos []string
+	for _, c := range f[2:] {
+		if _, err := strconv.ParseFloat(c, 64); err != nil {
+			break
+		}
+		pos = append(pos, c)
+		if len(pos) == 3 {
+			break
+		}
+	}
+	if len(pos) < 2 {
+		return ""
+	}
+	return strings.Join(pos, ",")
+}
+
+// toUTF8 returns b without any UTF-8 byte order mark. If b is not valid
+// UTF-8 it is taken to be Windows-1252, which Pajek writes on Windows, and
+// is converted to UTF-8.
+func toUTF8(b []byte) []byte {
+	b = bytes.TrimPrefix(b, []byte("\xef\xbb\xbf"))
+	if utf8.Valid(b) {
+		return b
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(b))
+	for _, c := range b {
+		if c >= 0x80 && c < 0xa0 {
+			buf.WriteRune(cp1252[c-0x80])
+			continue
+		}
+		// The remaining bytes have the same
+		// values as their Unicode code points.
+		buf.WriteRune(rune(c))
+	}
+	return buf.Bytes()
+}
+
+// cp1252 holds the Unicode code points of Windows-1252 bytes 0x80-0x9f.
+// Undefined bytes are mapped to the replacement character.
+var cp1252 = [32]rune{
+	'€', '\ufffd', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\ufffd', 'Ž', '\ufffd',
+	'\ufffd', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\ufffd', 'ž', 'Ÿ',
+}
+
+// WritePajek writes g to w as a Pajek network (.net) file, with vertices
+// numbered in the order of g.Index(). The name of g is written as the
+// *Network name. Vertex labels are the DOT ID of each node, so that graphs
+// read by ReadPajek are written back unchanged, and the "pos" attribute is
+// written as the vertex coordinates if it holds two or three numbers.
+// Edges are written in an *Edges section, with their "weight" attribute if
+// it is set. WritePajek returns an error if a label holds a double quote,
+// since Pajek labels cannot be escaped, or if an edge weight is not a
+// number.
+func WritePajek(w io.Writer, g *Graph) error {
+	return WritePajekWithOptions(w, g, PajekOptions{})
+}
+
+// WritePajekWithOptions writes g to w as a Pajek network file as described
+// for WritePajek, using the provided options to control how vertex labels
+// are written. If opts.SplitLabels is true, vertex labels are the DOT ID of
+// each node followed by its "desc" attribute, if set, so that graphs read
+// with the same options are written back unchanged, and an error is
+// returned if a DOT ID holds a space.
+func WritePajekWithOptions(w io.Writer, g *Graph, opts PajekOptions) error {
+	x := g.Index()
+	adj := x.adjacency(g)
+	bw := bufio.NewWriter(w)
+	if g.Name != "" {
+		fmt.Fprintf(bw, "*Network %s\n", g.Name)
+	}
+	fmt.Fprintf(bw, "*Vertices %d\n", x.Len())
+	for i := 0; i < x.Len(); i++ {
+		n := x.Node(i)
+		attrs := exportAttributes(n.Attributes)
+		label := n.Name
+		if opts.SplitLabels {
+			if strings.Contains(n.Name, " ") {
+				return fmt.Errorf("cannot write label for node %q: contains space", n.Name)
+			}
+			if desc := attrs.GetUnquoted("desc"); desc != "" {
+				label += " " + desc
+			}
+		}
+		if strings.Contains(label, `"`) {
+			return fmt.Errorf("cannot write label for node %q: contains double quote", n.Name)
+		}
+		fmt.Fprintf(bw, "%d \"%s\"", i+1, label)
+		if pos := pajekCoordinates(attrs.GetUnquoted("pos")); pos != nil {
+			fmt.Fprintf(bw, " %s", strings.Join(pos, " "))
+		}
+		fmt.Fprintln(bw)
+	}
+	fmt.Fprintln(bw, "*Edges")
+	for i, to := range adj {
+		for _, j := range to {
+			if j < i {
+				continue
+			}
+			e := g.EdgeBetween(x.ID(i), x.ID(j)).(*Edge)
+			weight := exportAttributes(e.Attributes).GetUnquoted("weight")
+			if weight == "" {
+				fmt.Fprintf(bw, "%d %d\n", i+1, j+1)
+				continue
+			}
+			if _, err := strconv.ParseFloat(weight, 64); err != nil {
+				return fmt.Errorf("invalid weight for edge %q--%q: %v", e.F.Name, e.T.Name, err)
+			}
+			fmt.Fprintf(bw, "%d %d %s\n", i+1, j+1, weight)
+		}
+	}
+	return bw.Flush()
+}
+
+// pajekCoordinates returns the coordinates in the DOT pos value s, or nil
+// if s does not hold two or three numbers.
+func pajekCoordinates(s string) []string {
+	pos := strings.Split(strings.TrimSuffix(s, "!"), ",")
+	if len(pos) < 2 || len(pos) > 3 {
+		return nil
+	}
+	for _, c := range pos {
+		if _, err := strconv.ParseFloat(c, 64); err != nil {
+			return nil
+		}
+	}
+	return pos
+}
+
+// ReadClu reads a Pajek partition (.clu) file from r and writes the class
+// of each vertex into the attr attribute of the corresponding node of g.
+// The file holds an optional "*Vertices n" header followed by an integer
+// class for each vertex. Vertex i corresponds to the node at index i-1 of
+// g.Index(), so the nodes of a graph converted from a Pajek network are
+// matched with their vertices. ReadClu returns an error if a class is not
+// an integer or if the number of vertices does not match the number of
+// nodes in g. If an error is returned, no attributes are written.
+func ReadClu(g *Graph, r io.Reader, attr string) error {
+	return readPajekValues(g, r, attr, func(s string) (string, error) {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(v), nil
+	})
+}
+
+// ReadVec reads a Pajek vector (.vec) file from r and writes the value for
+// each vertex into the attr attribute of the corresponding node of g. The
+// file is as for ReadClu, but holds a number for each vertex.
+func ReadVec(g *Graph, r io.Reader, attr string) error {
+	return readPajekValues(g, r, attr, func(s string) (string, error) {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprint(v), nil
+	})
+}
+
+// readPajekValues reads the per-vertex values of a Pajek partition or
+// vector file from r, checking and formatting each with parse, and writes
+// them into the attr attribute of the nodes of g.
+func readPajekValues(g *Graph, r io.Reader, attr string, parse func(string) (string, error)) error {
+	x := g.Index()
+	want := -1
+	var vals []string
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || text[0] == '%' {
+			continue
+		}
+		f := strings.Fields(text)
+		if text[0] == '*' {
+			if !strings.EqualFold(f[0], "*vertices") || len(f) < 2 || want >= 0 || len(vals) != 0 {
+				return &ParseError{Line: line, Text: text, Err: fmt.Errorf("unexpected header")}
+			}
+			n, err := strconv.Atoi(f[1])
+			if err != nil {
+				return &ParseError{Line: line, Text: text, Err: err}
+			}
+			want = n
+			continue
+		}
+		for _, s := range f {
+			v, err := parse(s)
+			if err != nil {
+				return &ParseError{Line: line, Text: text, Err: err}
+			}
+			vals = append(vals, v)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if want >= 0 && len(vals) != want {
+		return fmt.Errorf("unexpected number of values: got=%d want=%d", len(vals), want)
+	}
+	if len(vals) != x.Len() {
+		return fmt.Errorf("number of values does not match number of nodes: %d != %d", len(vals), x.Len())
+	}
+	for i, v := range vals {
+		x.Node(i).SetAttribute(encoding.Attribute{Key: attr, Value: v})
+	}
+	return nil
+}
+
+// WriteClu writes the attr attribute of the nodes of g to w as a Pajek
+// partition (.clu) file, with vertices in the order of g.Index(). It
+// returns an error if a node does not have an integer attr attribute.
+func WriteClu(g *Graph, w io.Writer, attr string) error {
+	return writePajekValues(g, w, attr, func(s string) error {
+		_, err := strconv.Atoi(s)
+		return err
+	})
+}
+
+// WriteVec writes the attr attribute of the nodes of g to w as a Pajek
+// vector (.vec) file, with vertices in the order of g.Index(). It returns
+// an error if a node does not have a numerical attr attribute.
+func WriteVec(g *Graph, w io.Writer, attr string) error {
+	return writePajekValues(g, w, attr, func(s string) error {
+		_, err := strconv.ParseFloat(s, 64)
+		return err
+	})
+}
+
+// writePajekValues writes the attr attribute of the nodes of g to w as
+// the values of a Pajek partition or vector file, checking each with
+// check.
+func writePajekValues(g *Graph, w io.Writer, attr string, check func(string) error) error {
+	x := g.Index()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "*Vertices %d\n", x.Len())
+	for i := 0; i < x.Len(); i++ {
+		n := x.Node(i)
+		v := exportAttributes(n.Attributes).GetUnquoted(attr)
+		if v == "" {
+			return fmt.Errorf("no %s attribute for node %q", attr, n.Name)
+		}
+		if err := check(v); err != nil {
+			return fmt.Errorf("invalid %s attribute for node %q: %v", attr, n.Name, err)
+		}
+		fmt.Fprintln(bw, v)
+	}
+	return bw.Flush()
+}
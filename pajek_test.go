@@ -0,0 +1,353 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+var readPajekTests = []struct {
+	net  string
+	opts PajekOptions
+	want string
+}{
+	{
+		net: "*Vertices 2\n1 \"a\"\n2 \"b\"\n*Edges\n1 2\n",
+		want: `graph {
+  // Node definitions.
+  a;
+  b;
+
+  // Edge definitions.
+  a -- b;
+}`,
+	},
+	{
+		// Byte order mark, CRLF line endings, tab
+		// separated fields, coordinates, Windows-1252
+		// text, comments and arcs.
+		net: "\xef\xbb\xbf*Network test net\r\n*Vertices 3\r\n1\t\"a b\"\t0.1\t0.2\r\n2 \"caf\xe9 \x93x\x94\"\r\n% comment\r\n*Arcs\r\n1\t2\t1.5\r\n3 1\r\n",
+		want: `graph "test net" {
+  // Node definitions.
+  3;
+  "a b" [pos="0.1,0.2"];
+  "café “x”";
+
+  // Edge definitions.
+  3 -- "a b";
+  "a b" -- "café “x”" [weight=1.5];
+}`,
+	},
+	{
+		// Labels split into DOT ID and description.
+		net:  "*Network test net\n*Vertices 3\n1 \"a b\" 0.1 0.2\n2 \"caf\xe9 \x93x\x94\"\n*Edges\n1 2 1.5\n3 1\n",
+		opts: PajekOptions{SplitLabels: true},
+		want: `graph "test net" {
+  // Node definitions.
+  3;
+  a [
+    desc="b"
+    pos="0.1,0.2"
+  ];
+  café [desc="“x”"];
+
+  // Edge definitions.
+  3 -- a;
+  a -- café [weight=1.5];
+}`,
+	},
+	{
+		// Edge lists, three dimensional coordinates
+		// with drawing parameters, self edges and
+		// skipped sections.
+		net: "*Vertices 4\n1 \"x\" 0.5 0.5 0.5 ic Red\n*Edgeslist\n1 2 3 4\n2 2\n*Partition p\n1 2\n*Arcslist\n3 2\n*Edges\n4 1 2\n",
+		want: `graph {
+  // Node definitions.
+  2;
+  3;
+  4;
+  x [pos="0.5,0.5,0.5"];
+
+  // Edge definitions.
+  2 -- 3;
+  2 -- x;
+  3 -- x;
+  4 -- x [weight=2];
+}`,
+	},
+	{
+		net:  "*vertices 2\n1 \"Unknow protein !!!\"\n2 plain\n*edges\n1 2 1\n1 2 3\n",
+		opts: PajekOptions{SplitLabels: true},
+		want: `graph {
+  // Node definitions.
+  Unknown0001 [desc="Unknow protein !!!"];
+  plain;
+
+  // Edge definitions.
+  Unknown0001 -- plain [weight=3];
+}`,
+	},
+}
+
+func TestReadPajek(t *testing.T) {
+	for i, test := range readPajekTests {
+		g, err := ReadPajekWithOptions(strings.NewReader(test.net), test.opts)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		got := DOTWithOptions(g, DOTOptions{SortAttributes: true})
+		if got != test.want {
+			t.Errorf("unexpected graph for test %d:\ngot:\n%s\nwant:\n%s", i, got, test.want)
+		}
+	}
+}
+
+func TestReadPajekVertexIDs(t *testing.T) {
+	g, err := ReadPajek(strings.NewReader("*Vertices 3\n3 \"c\"\n1 \"a\"\n*Edges\n1 3\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for id, name := range []string{"a", "2", "c"} {
+		if got := g.Node(int64(id)).(*Node).Name; got != name {
+			t.Errorf("unexpected node for ID %d: got:%s want:%s", id, got, name)
+		}
+	}
+}
+
+var readPajekErrorTests = []string{
+	"*Vertices\n",
+	"*Vertices 2\n3 \"c\"\n",
+	"1 2\n",
+	"*Vertices 2\n*Edges\n1 3\n",
+	"*Vertices 2\n*Edges\n1 2 heavy\n",
+	"*Vertices 2\n*Edges\n1\n",
+	"*Vertices 2\n1 \"a\n",
+	"*Vertices 3\n1 \"New York\"\n2 \"New York\"\n3 \"Boston\"\n",
+	"*Vertices 2\n1 \"a\"\n1 \"b\"\n",
+	"*Vertices 3\n1 \"3\"\n2 \"b\"\n",
+	"*Vertices 2\n1 \"2\"\n*Edges\n1 2\n",
+}
+
+func TestReadPajekErrors(t *testing.T) {
+	for i, net := range readPajekErrorTests {
+		if _, err := ReadPajek(strings.NewReader(net)); err == nil {
+			t.Errorf("expected error for test %d", i)
+		}
+	}
+}
+
+var readCluTests = []struct {
+	clu  string
+	want []string
+	err  bool
+}{
+	{clu: "*Vertices 3\n1\n2\n1\n", want: []string{"1", "2", "1"}},
+	{clu: "% no header\r\n0 1\r\n\r\n01\r\n", want: []string{"0", "1", "1"}},
+	{clu: "*Vertices 4\n1\n2\n1\n", err: true},
+	{clu: "1\n2\n", err: true},
+	{clu: "*Vertices 3\n1\n2.5\n1\n", err: true},
+	{clu: "1\n*Vertices 3\n2\n1\n", err: true},
+}
+
+func TestReadClu(t *testing.T) {
+	for i, test := range readCluTests {
+		g := graphFromDOT(t, `graph { a [class=9]; b [class=9]; c [class=9] }`)
+		err := ReadClu(g, strings.NewReader(test.clu), "class")
+		if (err != nil) != test.err {
+			t.Errorf("unexpected error state for test %d: got:%v want error:%t", i, err, test.err)
+			continue
+		}
+		x := g.Index()
+		for j := 0; j < x.Len(); j++ {
+			n := x.Node(j)
+			want := "9"
+			if !test.err {
+				want = test.want[j]
+			}
+			if got := n.Get("class"); got != want {
+				t.Errorf("unexpected class of %s for test %d: got:%s want:%s", n.Name, i, got, want)
+			}
+		}
+	}
+}
+
+func TestReadVec(t *testing.T) {
+	g := graphFromDOT(t, `graph { a; b; c }`)
+	err := ReadVec(g, strings.NewReader("*Vertices 3\n0.5\n-1e2\n3\n"), "x")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, want := range map[string]float64{"a": 0.5, "b": -100, "c": 3} {
+		if got := floatAttr(t, g, name, "x"); got != want {
+			t.Errorf("unexpected value of %s: got:%v want:%v", name, got, want)
+		}
+	}
+	if err := ReadVec(g, strings.NewReader("0.5\nNaN?\n3\n"), "x"); err == nil {
+		t.Error("expected error for non-numeric value")
+	}
+}
+
+func TestPajekValuesRoundTrip(t *testing.T) {
+	g, err := ReadPajek(strings.NewReader("*Vertices 3\n1 \"a\"\n2 \"b\"\n3 \"c\"\n*Edges\n1 2\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = ReadClu(g, strings.NewReader("*Vertices 3\n2\n1\n2\n"), "class")
+	if err != nil {
+		t.Fatalf("unexpected error reading partition: %v", err)
+	}
+	err = ReadVec(g, strings.NewReader("*Vertices 3\n0.25\n1\n1.5\n"), "x")
+	if err != nil {
+		t.Fatalf("unexpected error reading vector: %v", err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		g.NodeNamed(name).SetAttribute(encoding.Attribute{Key: "name", Value: name})
+	}
+
+	var clu, vec strings.Builder
+	if err := WriteClu(g, &clu, "class"); err != nil {
+		t.Fatalf("unexpected error writing partition: %v", err)
+	}
+	if got, want := clu.String(), "*Vertices 3\n2\n1\n2\n"; got != want {
+		t.Errorf("unexpected partition:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if err := WriteVec(g, &vec, "x"); err != nil {
+		t.Fatalf("unexpected error writing vector: %v", err)
+	}
+	if got, want := vec.String(), "*Vertices 3\n0.25\n1\n1.5\n"; got != want {
+		t.Errorf("unexpected vector:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := WriteClu(g, &clu, "x"); err == nil {
+		t.Error("expected error for non-integer partition")
+	}
+	if err := WriteVec(g, &vec, "name"); err == nil {
+		t.Error("expected error for non-numeric vector")
+	}
+	if err := WriteVec(g, &vec, "missing"); err == nil {
+		t.Error("expected error for missing attribute")
+	}
+}
+
+func TestWritePajek(t *testing.T) {
+	g := graphFromDOT(t, `graph net {
+	b [desc="B node", pos="1,2!"];
+	a [pos="1,2,3"];
+	c [pos="x,y"];
+	b -- a [weight=2.5];
+	a -- c;
+}`)
+	var buf strings.Builder
+	if err := WritePajek(&buf, g); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `*Network net
+*Vertices 3
+1 "b" 1 2
+2 "a" 1 2 3
+3 "c"
+*Edges
+1 2 2.5
+2 3
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected Pajek network:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := WritePajekWithOptions(&buf, g, PajekOptions{SplitLabels: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = `*Network net
+*Vertices 3
+1 "b B node" 1 2
+2 "a" 1 2 3
+3 "c"
+*Edges
+1 2 2.5
+2 3
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unexpected Pajek network with split labels:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+var pajekRoundTripTests = []struct {
+	net  string
+	opts PajekOptions
+}{
+	{net: "*Vertices 2\n1 \"a\"\n2 \"b\"\n*Edges\n1 2\n"},
+	{net: "*Network test net\n*Vertices 3\n1 \"a b\" 0.1 0.2\n2 \"c\"\n3 \"d\"\n*Edges\n1 2 1.5\n1 3\n"},
+	{net: "*Vertices 3\n1 \"New York\"\n2 \"New Jersey\"\n3 \"Boston\"\n*Edges\n1 2\n1 3\n2 3 4\n"},
+	{net: "*Vertices 3\n1 \"x\" 0.5 0.5 0.5\n2 \"y z\"\n3 \"w\"\n*Edges\n1 2\n2 3 4\n", opts: PajekOptions{SplitLabels: true}},
+}
+
+func TestPajekRoundTrip(t *testing.T) {
+	for i, test := range pajekRoundTripTests {
+		g, err := ReadPajekWithOptions(strings.NewReader(test.net), test.opts)
+		if err != nil {
+			t.Errorf("unexpected error reading test %d: %v", i, err)
+			continue
+		}
+		var buf strings.Builder
+		if err := WritePajekWithOptions(&buf, g, test.opts); err != nil {
+			t.Errorf("unexpected error writing test %d: %v", i, err)
+			continue
+		}
+		if got := buf.String(); got != test.net {
+			t.Errorf("unexpected round trip for test %d:\ngot:\n%s\nwant:\n%s", i, got, test.net)
+		}
+	}
+}
+
+var pajekDOTRoundTripTests = []string{
+	`graph { "New York" -- "New Jersey"; "New York" -- Boston; }`,
+	`graph { "a b" -- c [weight=2]; c -- "d e f"; }`,
+}
+
+func TestPajekDOTRoundTrip(t *testing.T) {
+	for i, test := range pajekDOTRoundTripTests {
+		g := graphFromDOT(t, test)
+		var buf strings.Builder
+		if err := WritePajek(&buf, g); err != nil {
+			t.Errorf("unexpected error writing test %d: %v", i, err)
+			continue
+		}
+		h, err := ReadPajek(strings.NewReader(buf.String()))
+		if err != nil {
+			t.Errorf("unexpected error reading test %d: %v", i, err)
+			continue
+		}
+		got := DOTWithOptions(h, DOTOptions{SortAttributes: true})
+		want := DOTWithOptions(g, DOTOptions{SortAttributes: true})
+		if got != want {
+			t.Errorf("unexpected round trip for test %d:\ngot:\n%s\nwant:\n%s", i, got, want)
+		}
+	}
+}
+
+var writePajekErrorTests = []struct {
+	dot  string
+	opts PajekOptions
+}{
+	{dot: `graph { "say \"hi\""; }`},
+	{dot: `graph { a [desc="say \"hi\""]; }`, opts: PajekOptions{SplitLabels: true}},
+	{dot: `graph { "a b"; }`, opts: PajekOptions{SplitLabels: true}},
+	{dot: `graph { a -- b [weight=heavy]; }`},
+}
+
+func TestWritePajekErrors(t *testing.T) {
+	for i, test := range writePajekErrorTests {
+		g := graphFromDOT(t, test.dot)
+		var buf strings.Builder
+		if err := WritePajekWithOptions(&buf, g, test.opts); err == nil {
+			t.Errorf("expected error for test %d", i)
+		}
+	}
+}
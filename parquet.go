@@ -0,0 +1,299 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !js
+// +build !js
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/writer"
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// WriteParquet writes the nodes and edges of g as Parquet tables to the
+// files at nodePath and edgePath, so that analysis results can be read
+// by data frame libraries such as pandas, polars and Arrow. The node table
+// has an "id" column holding the node ID and a "name" column holding the
+// DOT ID of each node, and the edge table has "source" and "target"
+// columns holding the node IDs of the ends of each edge. Each attribute
+// is written as a nullable column of its table, as doubles if every value
+// of the attribute is a number and as strings with any DOT quoting removed
+// otherwise. WriteParquet returns an error if an attribute has the name of
+// one of the ID columns of its table.
+func WriteParquet(g *Graph, nodePath, edgePath string) error {
+	nodes := NodesOf(g)
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID() < nodes[j].ID() })
+	nodeRows := make([]parquetRow, len(nodes))
+	for i, n := range nodes {
+		nodeRows[i] = parquetRow{
+			ids:   []int64{n.ID()},
+			name:  n.Name,
+			attrs: exportAttributes(n.Attributes),
+		}
+	}
+	err := writeParquetTable(nodePath, []string{"id", "name"}, nodeRows)
+	if err != nil {
+		return err
+	}
+
+	edges := graph.EdgesOf(g.Edges())
+	edgeRows := make([]parquetRow, len(edges))
+	for i, e := range edges {
+		e := e.(*Edge)
+		edgeRows[i] = parquetRow{
+			ids:   []int64{e.F.ID(), e.T.ID()},
+			attrs: exportAttributes(e.Attributes),
+		}
+	}
+	sort.Slice(edgeRows, func(i, j int) bool {
+		a, b := edgeRows[i].ids, edgeRows[j].ids
+		return a[0] < b[0] || (a[0] == b[0] && a[1] < b[1])
+	})
+	return writeParquetTable(edgePath, []string{"source", "target"}, edgeRows)
+}
+
+// parquetRow is a row of a node or edge table.
+type parquetRow struct {
+	ids   []int64
+	name  string
+	attrs Attributes
+}
+
+// writeParquetTable writes rows to a Parquet file at path. The leading
+// columns of the table are the int64 ID columns named by key and, if key
+// includes "name", the string name column, followed by a column for each
+// attribute in lexical order.
+func writeParquetTable(path string, key []string, rows []parquetRow) error {
+	reserved := make(map[string]bool)
+	for _, k := range key {
+		reserved[k] = true
+	}
+	numeric := make(map[string]bool)
+	for _, r := range rows {
+		for _, kv := range r.attrs {
+			if reserved[kv.Key] {
+				return fmt.Errorf("attribute %q clashes with %q column in %s", kv.Key, kv.Key, path)
+			}
+			isNum, seen := numeric[kv.Key]
+			if seen && !isNum {
+				continue
+			}
+			v, err := strconv.ParseFloat(r.attrs.GetUnquoted(kv.Key), 64)
+			numeric[kv.Key] = err == nil && !math.IsNaN(v)
+		}
+	}
+	attrs := make([]string, 0, len(numeric))
+	for k := range numeric {
+		attrs = append(attrs, k)
+	}
+	sort.Strings(attrs)
+
+	var md []string
+	for _, k := range key {
+		if k == "name" {
+			md = append(md, "name=name, type=BYTE_ARRAY, convertedtype=UTF8")
+		} else {
+			md = append(md, fmt.Sprintf("name=%s, type=INT64", k))
+		}
+	}
+	for _, k := range attrs {
+		if numeric[k] {
+			md = append(md, fmt.Sprintf("name=%s, type=DOUBLE, repetitiontype=OPTIONAL", k))
+		} else {
+			md = append(md, fmt.Sprintf("name=%s, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL", k))
+		}
+	}
+
+	f, err := local.NewLocalFileWriter(path)
+	if err != nil {
+		return err
+	}
+	pw, err := writer.NewCSVWriter(md, f, 1)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	for _, r := range rows {
+		rec := make([]*string, 0, len(md))
+		ids := r.ids
+		for _, k := range key {
+			var v string
+			if k == "name" {
+				v = r.name
+			} else {
+				v = strconv.FormatInt(ids[0], 10)
+				ids = ids[1:]
+			}
+			rec = append(rec, &v)
+		}
+		for _, k := range attrs {
+			var v *string
+			if r.attrs.Get(k) != "" {
+				s := r.attrs.GetUnquoted(k)
+				v = &s
+			}
+			rec = append(rec, v)
+		}
+		err = pw.WriteString(rec)
+		if err != nil {
+			f.Close()
+			return err
+		}
+	}
+	err = pw.WriteStop()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadParquet returns a graph read from the node and edge Parquet tables
+// in the files at nodePath and edgePath, as written by WriteParquet. The
+// node table must have an integer "id" column and a string "name" column,
+// and the edge table must have integer "source" and "target" columns.
+// All other columns are read as attributes, with null values leaving the
+// attribute unset. Numerical values are formatted with fmt.Sprint and
+// other values are DOT quoted. ReadParquet returns an error if an edge
+// refers to a node that is not in the node table or is a self edge.
+func ReadParquet(nodePath, edgePath string) (*Graph, error) {
+	g := &Graph{UndirectedGraph: simple.NewUndirectedGraph()}
+
+	nodeCols, nodeRows, err := readParquetTable(nodePath)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := parquetIDs(nodePath, nodeCols, "id", nodeRows)
+	if err != nil {
+		return nil, err
+	}
+	names, ok := nodeCols["name"]
+	if !ok {
+		return nil, fmt.Errorf("no name column in %s", nodePath)
+	}
+	delete(nodeCols, "name")
+	nodes := make(map[int64]*Node, nodeRows)
+	for i, id := range ids {
+		if _, exists := nodes[id]; exists {
+			return nil, fmt.Errorf("duplicate node id %d in %s", id, nodePath)
+		}
+		n := &Node{NodeID: id}
+		if name, ok := names[i].(string); ok {
+			n.Name = name
+		} else {
+			n.Name = fmt.Sprint(names[i])
+		}
+		setParquetAttributes(&n.Attributes, nodeCols, i)
+		g.AddNode(n)
+		nodes[id] = n
+	}
+
+	edgeCols, edgeRows, err := readParquetTable(edgePath)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := parquetIDs(edgePath, edgeCols, "source", edgeRows)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := parquetIDs(edgePath, edgeCols, "target", edgeRows)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sources {
+		u, ok := nodes[sources[i]]
+		v, ok2 := nodes[targets[i]]
+		if !ok || !ok2 || u == v {
+			return nil, fmt.Errorf("invalid edge %d--%d in %s", sources[i], targets[i], edgePath)
+		}
+		e := g.NewEdge(u, v).(*Edge)
+		setParquetAttributes(&e.Attributes, edgeCols, i)
+	}
+	return g, nil
+}
+
+// readParquetTable returns the columns of the Parquet file at path, keyed
+// by column name, and the number of rows in the table.
+func readParquetTable(path string) (map[string][]interface{}, int, error) {
+	f, err := local.NewLocalFileReader(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	pr, err := reader.NewParquetColumnReader(f, 1)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not read %s: %v", path, err)
+	}
+	defer pr.ReadStop()
+
+	rows := int(pr.GetNumRows())
+	cols := make(map[string][]interface{})
+	for i, p := range pr.SchemaHandler.ValueColumns {
+		name := pr.SchemaHandler.Infos[pr.SchemaHandler.MapIndex[p]].ExName
+		if rows == 0 {
+			cols[name] = nil
+			continue
+		}
+		vals, _, _, err := pr.ReadColumnByIndex(int64(i), int64(rows))
+		if err != nil {
+			return nil, 0, fmt.Errorf("could not read column %q of %s: %v", name, path, err)
+		}
+		if len(vals) != rows {
+			return nil, 0, fmt.Errorf("column %q of %s is not flat", name, path)
+		}
+		cols[name] = vals
+	}
+	return cols, rows, nil
+}
+
+// parquetIDs returns the integer values of the named column and removes
+// the column from cols.
+func parquetIDs(path string, cols map[string][]interface{}, name string, rows int) ([]int64, error) {
+	col, ok := cols[name]
+	if !ok {
+		return nil, fmt.Errorf("no %s column in %s", name, path)
+	}
+	delete(cols, name)
+	ids := make([]int64, rows)
+	for i, v := range col {
+		switch v := v.(type) {
+		case int64:
+			ids[i] = v
+		case int32:
+			ids[i] = int64(v)
+		default:
+			return nil, fmt.Errorf("invalid %s value %v in row %d of %s", name, v, i, path)
+		}
+	}
+	return ids, nil
+}
+
+// setParquetAttributes sets the attributes in a from row i of the columns
+// in cols.
+func setParquetAttributes(a *Attributes, cols map[string][]interface{}, i int) {
+	keys := make([]string, 0, len(cols))
+	for k := range cols {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch v := cols[k][i].(type) {
+		case nil:
+		case string:
+			a.SetQuoted(k, v)
+		default:
+			a.SetAttribute(encoding.Attribute{Key: k, Value: fmt.Sprint(v)})
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js
+// +build js
+
+package graphprac
+
+import "errors"
+
+// errNoParquet is returned by the Parquet functions since the Parquet
+// library depends on system calls that are not available under js.
+var errNoParquet = errors.New("parquet is not available under js")
+
+// WriteParquet returns an error since Parquet files cannot be written
+// under js.
+func WriteParquet(g *Graph, nodePath, edgePath string) error {
+	return errNoParquet
+}
+
+// ReadParquet returns an error since Parquet files cannot be read under
+// js.
+func ReadParquet(nodePath, edgePath string) (*Graph, error) {
+	return nil, errNoParquet
+}
@@ -0,0 +1,112 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !js
+// +build !js
+
+package graphprac
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var parquetTests = []struct {
+	dot  string
+	want string
+}{
+	{
+		dot: `graph { }`,
+		want: `graph {
+}`,
+	},
+	{
+		dot: `graph {
+	a [rank=0.25, label="A \"quoted\" node"];
+	b [rank=1, label=plain];
+	c;
+	d [label=2];
+	a -- b [weight=2, kind=x];
+	b -- c [weight=1.5];
+	c -- a;
+}`,
+		want: `graph {
+  // Node definitions.
+  a [
+    label="A \"quoted\" node"
+    rank=0.25
+  ];
+  b [
+    label="plain"
+    rank=1
+  ];
+  c;
+  d [label="2"];
+
+  // Edge definitions.
+  a -- b [
+    kind="x"
+    weight=2
+  ];
+  a -- c;
+  b -- c [weight=1.5];
+}`,
+	},
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graphprac-parquet")
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	nodePath := filepath.Join(dir, "nodes.parquet")
+	edgePath := filepath.Join(dir, "edges.parquet")
+
+	for i, test := range parquetTests {
+		g := graphFromDOT(t, test.dot)
+		err := WriteParquet(g, nodePath, edgePath)
+		if err != nil {
+			t.Errorf("unexpected error writing test %d: %v", i, err)
+			continue
+		}
+		got, err := ReadParquet(nodePath, edgePath)
+		if err != nil {
+			t.Errorf("unexpected error reading test %d: %v", i, err)
+			continue
+		}
+		for _, n := range NodesOf(g) {
+			m, ok := got.Node(n.ID()).(*Node)
+			if !ok || m.Name != n.Name {
+				t.Errorf("node ID %d not retained for %s in test %d", n.ID(), n.Name, i)
+			}
+		}
+		if s := DOTWithOptions(got, DOTOptions{SortAttributes: true}); s != test.want {
+			t.Errorf("unexpected graph for test %d:\ngot:\n%s\nwant:\n%s", i, s, test.want)
+		}
+	}
+}
+
+func TestWriteParquetReservedName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graphprac-parquet")
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	nodePath := filepath.Join(dir, "nodes.parquet")
+	edgePath := filepath.Join(dir, "edges.parquet")
+
+	for i, dot := range []string{
+		`graph { a [id=1] }`,
+		`graph { a [name=x] }`,
+		`graph { a -- b [source=x] }`,
+	} {
+		g := graphFromDOT(t, dot)
+		if err := WriteParquet(g, nodePath, edgePath); err == nil {
+			t.Errorf("expected error for test %d", i)
+		}
+	}
+}
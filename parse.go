@@ -0,0 +1,117 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParseError is an error in the syntax of an input file. The error
+// message includes the offending line with its position marked.
+type ParseError struct {
+	// Line and Column are the 1-based position
+	// of the error. Column is the byte offset
+	// within the line, or zero if the position
+	// within the line is not known.
+	Line, Column int
+
+	// Text is the text of the offending line.
+	Text string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// newParseError returns a ParseError for the given 1-based line and
+// column of the data in b.
+func newParseError(b []byte, line, column int, err error) *ParseError {
+	return &ParseError{Line: line, Column: column, Text: lineOf(b, line), Err: err}
+}
+
+func (e *ParseError) Error() string {
+	var buf strings.Builder
+	if e.Column > 0 {
+		fmt.Fprintf(&buf, "line %d, column %d: %v", e.Line, e.Column, e.Err)
+	} else {
+		fmt.Fprintf(&buf, "line %d: %v", e.Line, e.Err)
+	}
+	if e.Text == "" {
+		return buf.String()
+	}
+	fmt.Fprintf(&buf, "\n\t%s", e.Text)
+	if e.Column > 0 && e.Column <= len(e.Text)+1 {
+		// Keep tabs and count runes so that the
+		// marker lines up with the offending text.
+		buf.WriteString("\n\t")
+		for _, c := range e.Text[:e.Column-1] {
+			if c == '\t' {
+				buf.WriteByte('\t')
+			} else {
+				buf.WriteByte(' ')
+			}
+		}
+		buf.WriteByte('^')
+	}
+	return buf.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// lineOf returns the text of the 1-based line of b without its line
+// ending, or the empty string if there is no such line.
+func lineOf(b []byte, line int) string {
+	for i := 1; i < line; i++ {
+		n := bytes.IndexByte(b, '\n')
+		if n < 0 {
+			return ""
+		}
+		b = b[n+1:]
+	}
+	if n := bytes.IndexByte(b, '\n'); n >= 0 {
+		b = b[:n]
+	}
+	return string(bytes.TrimRight(b, "\r"))
+}
+
+// dotSyntaxError matches the syntax errors returned by the gonum DOT
+// parser, capturing the literal text of the offending token, its byte
+// offset and the expected tokens or lexical error.
+var dotSyntaxError = regexp.MustCompile(`(?s)^Error in S\d+: .*?\(\d+,(.*)\), Pos\(offset=(\d+), line=(\d+), column=\d+\)(?:, |: )?(.*)$`)
+
+// dotError returns err as a *ParseError if it is a syntax error from
+// parsing the DOT data in b, and err otherwise. The column reported by
+// the gonum parser counts tabs as several columns, so the column is
+// recalculated from the byte offset.
+func dotError(b []byte, err error) error {
+	m := dotSyntaxError.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	lit := m[1]
+	offset, _ := strconv.Atoi(m[2])
+	line, _ := strconv.Atoi(m[3])
+	detail := strings.TrimSpace(m[4])
+	if offset > len(b) {
+		offset = len(b)
+	}
+	column := offset - (bytes.LastIndexByte(b[:offset], '\n') + 1) + 1
+
+	var msg string
+	if lit == "" {
+		msg = "unexpected end of input"
+	} else {
+		msg = fmt.Sprintf("unexpected %q", lit)
+	}
+	if detail != "" {
+		msg += ", " + detail
+	}
+	return newParseError(b, line, column, errors.New(msg))
+}
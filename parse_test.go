@@ -0,0 +1,122 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var parseErrorTests = []struct {
+	read   func(io.Reader) (*Graph, error)
+	src    string
+	line   int
+	column int
+	text   string
+}{
+	{
+		read:   ReadDOT,
+		src:    "graph {\n\ta -- ;\n}",
+		line:   2,
+		column: 7,
+		text:   "\ta -- ;",
+	},
+	{
+		read:   ReadDOT,
+		src:    "graph { a -- b",
+		line:   1,
+		column: 15,
+		text:   "graph { a -- b",
+	},
+	{
+		read:   ReadDOT,
+		src:    "graph {\r\n  a [x=\"1]\r\n}",
+		line:   2,
+		column: 8,
+		text:   `  a [x="1]`,
+	},
+	{
+		read: func(r io.Reader) (*Graph, error) {
+			g, err := ReadAllDOT(r)
+			if err != nil {
+				return nil, err
+			}
+			return g[0], nil
+		},
+		src:    "graph { a -- b }\ngraph { c -- }",
+		line:   2,
+		column: 14,
+		text:   "graph { c -- }",
+	},
+	{
+		read: ReadPajek,
+		src:  "*Vertices 2\n1 \"a\"\n2 \"b\"\n*Edges\n1 x\n",
+		line: 5,
+		text: "1 x",
+	},
+	{
+		read: ReadPajek,
+		src:  "*Vertices two\n",
+		line: 1,
+		text: "*Vertices two",
+	},
+}
+
+func TestParseError(t *testing.T) {
+	for i, test := range parseErrorTests {
+		_, err := test.read(strings.NewReader(test.src))
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("expected parse error for test %d: got:%v", i, err)
+			continue
+		}
+		if perr.Line != test.line || perr.Column != test.column {
+			t.Errorf("unexpected position for test %d: got:%d:%d want:%d:%d", i, perr.Line, perr.Column, test.line, test.column)
+		}
+		if perr.Text != test.text {
+			t.Errorf("unexpected text for test %d: got:%q want:%q", i, perr.Text, test.text)
+		}
+	}
+}
+
+var parseErrorMessageTests = []struct {
+	err  *ParseError
+	want string
+}{
+	{
+		err:  &ParseError{Line: 3, Err: errors.New("bad")},
+		want: "line 3: bad",
+	},
+	{
+		err:  &ParseError{Line: 3, Text: "1 x", Err: errors.New("bad")},
+		want: "line 3: bad\n\t1 x",
+	},
+	{
+		err:  &ParseError{Line: 2, Column: 7, Text: "\ta -- ;", Err: errors.New("bad")},
+		want: "line 2, column 7: bad\n\t\ta -- ;\n\t\t     ^",
+	},
+	{
+		err:  &ParseError{Line: 1, Column: 4, Text: "é -;", Err: errors.New("bad")},
+		want: "line 1, column 4: bad\n\té -;\n\t  ^",
+	},
+	{
+		err:  &ParseError{Line: 1, Column: 20, Text: "short", Err: errors.New("bad")},
+		want: "line 1, column 20: bad\n\tshort",
+	},
+}
+
+func TestParseErrorMessage(t *testing.T) {
+	for i, test := range parseErrorMessageTests {
+		if got := test.err.Error(); got != test.want {
+			t.Errorf("unexpected message for test %d:\ngot:\n%s\nwant:\n%s", i, got, test.want)
+		}
+	}
+	cause := errors.New("cause")
+	if !errors.Is(&ParseError{Err: cause}, cause) {
+		t.Error("expected parse error to wrap its cause")
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/mat"
+)
+
+// allPaths holds the shortest path lengths between all pairs of nodes
+// in an unweighted graph. It is safe for concurrent use once built.
+type allPaths struct {
+	NodeIndex
+
+	// adj is the adjacency list of the graph
+	// in terms of node indices.
+	adj [][]int
+
+	// dist holds the number of hops between
+	// nodes i and j at dist[i*n+j], or -1 if
+	// there is no path between them.
+	dist []int32
+}
+
+// allShortestPaths returns the shortest path lengths between all pairs of
+// nodes in g. Edges are treated as having unit weight. The breadth first
+// searches from each source node are sharded over GOMAXPROCS goroutines.
+func allShortestPaths(g graph.Graph) *allPaths {
+	x := newNodeIndex(g)
+	p := &allPaths{
+		NodeIndex: x,
+		adj:       x.adjacency(g),
+		dist:      make([]int32, x.Len()*x.Len()),
+	}
+
+	sources := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			queue := make([]int, 0, x.Len())
+			for s := range sources {
+				queue = p.bfs(s, queue)
+			}
+		}()
+	}
+	for s := 0; s < x.Len(); s++ {
+		sources <- s
+	}
+	close(sources)
+	wg.Wait()
+
+	return p
+}
+
+// bfs fills the row of p.dist for the source node s, using queue as
+// working space. The queue is returned for reuse.
+func (p *allPaths) bfs(s int, queue []int) []int {
+	return hopsFrom(p.adj, s, p.row(s), queue)
+}
+
+// hopsFrom fills dist with the number of hops from the node with index s
+// to each node in the graph with adjacency lists adj, or -1 for nodes that
+// cannot be reached, using queue as working space. The queue is returned
+// for reuse.
+func hopsFrom(adj [][]int, s int, dist []int32, queue []int) []int {
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[s] = 0
+	queue = append(queue[:0], s)
+	for len(queue) != 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, v := range adj[u] {
+			if dist[v] >= 0 {
+				continue
+			}
+			dist[v] = dist[u] + 1
+			queue = append(queue, v)
+		}
+	}
+	return queue[:0]
+}
+
+// row returns the path lengths from the node with index i.
+func (p *allPaths) row(i int) []int32 {
+	n := len(p.nodes)
+	return p.dist[i*n : (i+1)*n]
+}
+
+// closeness returns the closeness centrality of each node. Unreachable
+// nodes are not considered.
+func (p *allPaths) closeness() map[int64]float64 {
+	f := p.farness()
+	for id, w := range f {
+		f[id] = 1 / w
+	}
+	return f
+}
+
+// farness returns the farness of each node. Unreachable nodes are not
+// considered.
+func (p *allPaths) farness() map[int64]float64 {
+	f := make(map[int64]float64, len(p.nodes))
+	for i, n := range p.nodes {
+		var sum float64
+		for _, d := range p.row(i) {
+			if d > 0 {
+				sum += float64(d)
+			}
+		}
+		f[n.ID()] = sum
+	}
+	return f
+}
+
+// harmonic returns the harmonic centrality of each node. Unreachable
+// nodes are not considered.
+func (p *allPaths) harmonic() map[int64]float64 {
+	h := make(map[int64]float64, len(p.nodes))
+	for i, n := range p.nodes {
+		var sum float64
+		for _, d := range p.row(i) {
+			if d > 0 {
+				sum += 1 / float64(d)
+			}
+		}
+		h[n.ID()] = sum
+	}
+	return h
+}
+
+// eccentricity returns the eccentricity of each node. Unreachable nodes
+// are not considered.
+func (p *allPaths) eccentricity() map[int64]float64 {
+	e := make(map[int64]float64, len(p.nodes))
+	for i, n := range p.nodes {
+		var max int32
+		for _, d := range p.row(i) {
+			if d > max {
+				max = d
+			}
+		}
+		e[n.ID()] = float64(max)
+	}
+	return e
+}
+
+// AllPaths holds the shortest paths between all pairs of nodes of a graph.
+// Edges are treated as having unit weight, so path lengths are numbers of
+// hops. An AllPaths is only valid until the graph it was created from is
+// changed.
+type AllPaths struct {
+	g *Graph
+	p *allPaths
+}
+
+// Paths returns the shortest paths between all pairs of nodes in g. This
+// is the structure used by the Closeness, Farness, Harmonic and
+// Eccentricity analyses.
+func Paths(g *Graph) *AllPaths {
+	return &AllPaths{g: g, p: allShortestPaths(g)}
+}
+
+// Between returns a shortest path between the nodes with the DOT IDs u and
+// v, and its length. When there is more than one shortest path, each step
+// of the returned path is to the candidate node with the lowest ID. If either node is not
+// in the graph or there is no path between them, Between returns nil and
+// +Inf.
+func (a *AllPaths) Between(u, v string) ([]*Node, float64) {
+	s := a.g.NodeNamed(u)
+	t := a.g.NodeNamed(v)
+	if s == nil || t == nil {
+		return nil, math.Inf(1)
+	}
+	i, j := a.p.Index(s.ID()), a.p.Index(t.ID())
+	if i < 0 || j < 0 {
+		return nil, math.Inf(1)
+	}
+	// Distances are symmetric, so the row for t
+	// gives the distance from each node to t.
+	to := a.p.row(j)
+	if to[i] < 0 {
+		return nil, math.Inf(1)
+	}
+	path := []*Node{a.p.Node(i)}
+	for k := i; k != j; {
+		next := -1
+		for _, w := range a.p.adj[k] {
+			if to[w] == to[k]-1 && (next < 0 || w < next) {
+				next = w
+			}
+		}
+		k = next
+		path = append(path, a.p.Node(k))
+	}
+	return path, float64(to[i])
+}
+
+// DistanceMatrix returns the shortest path lengths between all pairs of
+// nodes, with rows and columns ordered by the dense node index returned by
+// g.Index(). The distance between unconnected nodes is +Inf.
+func (a *AllPaths) DistanceMatrix() *mat.SymDense {
+	n := a.p.Len()
+	d := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		row := a.p.row(i)
+		for j := i + 1; j < n; j++ {
+			if row[j] < 0 {
+				d.SetSym(i, j, math.Inf(1))
+				continue
+			}
+			d.SetSym(i, j, float64(row[j]))
+		}
+	}
+	return d
+}
+
+// PathLengthDistribution returns the distribution of shortest path lengths
+// between pairs of distinct nodes in g, and the mean path length. Edges
+// are treated as having unit weight. Each unordered pair of connected
+// nodes is counted once in hist, keyed by the number of hops between
+// them. Unconnected pairs are not counted, and the mean is NaN if there
+// are no connected pairs.
+func PathLengthDistribution(g *Graph) (hist map[int]int, mean float64) {
+	p := allShortestPaths(g)
+	hist = make(map[int]int)
+	var sum, pairs int
+	for i := 0; i < p.Len(); i++ {
+		for _, d := range p.row(i)[i+1:] {
+			if d > 0 {
+				hist[int(d)]++
+				sum += int(d)
+				pairs++
+			}
+		}
+	}
+	if pairs == 0 {
+		return hist, math.NaN()
+	}
+	return hist, float64(sum) / float64(pairs)
+}
+
+// ClosenessApprox performs an approximate closeness centrality analysis on
+// g using the pivot sampling method of Eppstein and Wang. Breadth first
+// searches are run from about pivots nodes chosen at random using seed,
+// with the pivots shared between connected components in proportion to
+// their size and at least one pivot in each component. The farness of
+// each node is estimated by scaling the sum of its distances to the
+// pivots in its component up to the size of the component, and the
+// farness of the pivots themselves is exact. Components with no more
+// nodes than their share of pivots are analysed exactly. As for Closeness,
+// unreachable nodes are not considered.
+//
+// The estimated closeness centrality value is written into the
+// "closeness_approx" attribute of each node.
+func ClosenessApprox(g *Graph, pivots int, seed int64) {
+	if pivots < 1 {
+		panic("graphprac: invalid number of pivots")
+	}
+	done, err := trace(g, "ClosenessApprox", []string{"closeness_approx"}, "pivots", pivots, "seed", seed)
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
+	x := g.Index()
+	adj := x.adjacency(g)
+	n := len(adj)
+	comp := components(adj)
+	var members [][]int
+	for i, c := range comp {
+		if c == len(members) {
+			members = append(members, nil)
+		}
+		members[c] = append(members[c], i)
+	}
+
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	var (
+		queue   = make([]int, 0, n)
+		dist    = make([]int32, n)
+		farness = make([]float64, n)
+		isPivot = make([]bool, n)
+	)
+	for _, m := range members {
+		k := (pivots*len(m) + n - 1) / n
+		if k > len(m) {
+			k = len(m)
+		}
+		rnd.Shuffle(len(m), func(i, j int) { m[i], m[j] = m[j], m[i] })
+		for _, s := range m[:k] {
+			isPivot[s] = true
+		}
+		for _, s := range m[:k] {
+			queue = hopsFrom(adj, s, dist, queue)
+			for _, v := range m {
+				d := float64(dist[v])
+				farness[s] += d
+				if !isPivot[v] {
+					farness[v] += d
+				}
+			}
+		}
+		if k == len(m) {
+			continue
+		}
+		for _, v := range m {
+			if !isPivot[v] {
+				farness[v] *= float64(len(m)-1) / float64(k)
+			}
+		}
+	}
+
+	for i, f := range farness {
+		x.Node(i).SetAttribute(encoding.Attribute{Key: "closeness_approx", Value: fmt.Sprint(1 / f)})
+	}
+}
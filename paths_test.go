@@ -0,0 +1,201 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+var pathCentralityTests = []struct {
+	name     string
+	analysis func(*Graph)
+	want     map[string]float64
+}{
+	{
+		name:     "farness",
+		analysis: Farness,
+		want:     map[string]float64{"a": 6, "b": 4, "c": 4, "d": 6, "e": 1, "f": 1},
+	},
+	{
+		name:     "closeness",
+		analysis: Closeness,
+		want:     map[string]float64{"a": 1.0 / 6, "b": 1.0 / 4, "c": 1.0 / 4, "d": 1.0 / 6, "e": 1, "f": 1},
+	},
+	{
+		name:     "harmonic",
+		analysis: Harmonic,
+		want:     map[string]float64{"a": 11.0 / 6, "b": 2.5, "c": 2.5, "d": 11.0 / 6, "e": 1, "f": 1},
+	},
+	{
+		name:     "eccentricity",
+		analysis: Eccentricity,
+		want:     map[string]float64{"a": 3, "b": 2, "c": 2, "d": 3, "e": 1, "f": 1},
+	},
+}
+
+func TestPathCentralities(t *testing.T) {
+	for _, test := range pathCentralityTests {
+		g := graphFromDOT(t, `graph { a -- b -- c -- d; e -- f }`)
+		test.analysis(g)
+		for name, want := range test.want {
+			got := floatAttr(t, g, name, test.name)
+			if math.Abs(got-want) > 1e-12 {
+				t.Errorf("unexpected %s for %s: got:%v want:%v", test.name, name, got, want)
+			}
+		}
+	}
+}
+
+// diamondGraph is the 4-cycle a, b, d, c with the pendant e on d
+// and the isolated node f.
+const diamondGraph = `graph { a -- b; a -- c; b -- d; c -- d; d -- e; f }`
+
+var betweenTests = []struct {
+	u, v   string
+	path   []string
+	length float64
+}{
+	{u: "a", v: "e", path: []string{"a", "b", "d", "e"}, length: 3},
+	{u: "e", v: "a", path: []string{"e", "d", "b", "a"}, length: 3},
+	{u: "c", v: "b", path: []string{"c", "a", "b"}, length: 2},
+	{u: "a", v: "a", path: []string{"a"}, length: 0},
+	{u: "a", v: "f", path: nil, length: math.Inf(1)},
+	{u: "a", v: "z", path: nil, length: math.Inf(1)},
+}
+
+func TestPathsBetween(t *testing.T) {
+	g := graphFromDOT(t, diamondGraph)
+	p := Paths(g)
+	for i, test := range betweenTests {
+		path, length := p.Between(test.u, test.v)
+		if length != test.length {
+			t.Errorf("unexpected length for test %d: got:%v want:%v", i, length, test.length)
+		}
+		var got []string
+		for _, n := range path {
+			got = append(got, n.Name)
+		}
+		if !reflect.DeepEqual(got, test.path) {
+			t.Errorf("unexpected path for test %d: got:%q want:%q", i, got, test.path)
+		}
+	}
+}
+
+func TestDistanceMatrix(t *testing.T) {
+	g := graphFromDOT(t, diamondGraph)
+	d := Paths(g).DistanceMatrix()
+	inf := math.Inf(1)
+	want := map[string][]float64{
+		"a": {0, 1, 1, 2, 3, inf},
+		"b": {1, 0, 2, 1, 2, inf},
+		"c": {1, 2, 0, 1, 2, inf},
+		"d": {2, 1, 1, 0, 1, inf},
+		"e": {3, 2, 2, 1, 0, inf},
+		"f": {inf, inf, inf, inf, inf, 0},
+	}
+	names := []string{"a", "b", "c", "d", "e", "f"}
+	x := g.Index()
+	if r, _ := d.Dims(); r != len(names) {
+		t.Fatalf("unexpected matrix size: got:%d want:%d", r, len(names))
+	}
+	for _, u := range names {
+		i := x.Index(g.NodeNamed(u).ID())
+		for k, v := range names {
+			j := x.Index(g.NodeNamed(v).ID())
+			if got := d.At(i, j); got != want[u][k] {
+				t.Errorf("unexpected distance between %s and %s: got:%v want:%v", u, v, got, want[u][k])
+			}
+		}
+	}
+}
+
+var pathLengthTests = []struct {
+	dot  string
+	hist map[int]int
+	mean float64
+}{
+	{
+		dot:  `graph { a -- b -- c -- d; e -- f }`,
+		hist: map[int]int{1: 4, 2: 2, 3: 1},
+		mean: 11.0 / 7,
+	},
+	{
+		dot:  diamondGraph,
+		hist: map[int]int{1: 5, 2: 4, 3: 1},
+		mean: 16.0 / 10,
+	},
+	{
+		dot:  `graph { a; b }`,
+		hist: map[int]int{},
+		mean: math.NaN(),
+	},
+}
+
+func TestPathLengthDistribution(t *testing.T) {
+	for i, test := range pathLengthTests {
+		g := graphFromDOT(t, test.dot)
+		hist, mean := PathLengthDistribution(g)
+		if !reflect.DeepEqual(hist, test.hist) {
+			t.Errorf("unexpected histogram for test %d: got:%v want:%v", i, hist, test.hist)
+		}
+		if math.IsNaN(test.mean) != math.IsNaN(mean) || (!math.IsNaN(mean) && math.Abs(mean-test.mean) > 1e-12) {
+			t.Errorf("unexpected mean for test %d: got:%v want:%v", i, mean, test.mean)
+		}
+	}
+}
+
+func TestClosenessApprox(t *testing.T) {
+	const dot = `graph { a -- b -- c -- d -- e -- f -- g -- h; b -- i -- j; k -- l }`
+
+	// With at least as many pivots as nodes
+	// the analysis is exact.
+	g := graphFromDOT(t, dot)
+	Closeness(g)
+	ClosenessApprox(g, 12, 1)
+	for _, n := range NodesOf(g) {
+		got, want := floatAttr(t, g, n.Name, "closeness_approx"), floatAttr(t, g, n.Name, "closeness")
+		if math.Abs(got-want) > 1e-12 {
+			t.Errorf("unexpected exact closeness for %s: got:%v want:%v", n.Name, got, want)
+		}
+	}
+
+	for seed := int64(0); seed < 5; seed++ {
+		g := graphFromDOT(t, dot)
+		Closeness(g)
+		ClosenessApprox(g, 6, seed)
+		var exact int
+		for _, n := range NodesOf(g) {
+			got, want := floatAttr(t, g, n.Name, "closeness_approx"), floatAttr(t, g, n.Name, "closeness")
+			if math.Abs(got-want) < 1e-12 {
+				exact++
+			}
+			if got < want/3 || 3*want < got {
+				t.Errorf("unexpected estimate for %s with seed %d: got:%v want:%v", n.Name, seed, got, want)
+			}
+		}
+		// The five pivots of the large component and both
+		// nodes of the two-node component are exact.
+		if exact < 7 {
+			t.Errorf("unexpected number of exact values with seed %d: got:%d want at least 7", seed, exact)
+		}
+
+		r := graphFromDOT(t, dot)
+		ClosenessApprox(r, 6, seed)
+		for _, n := range NodesOf(g) {
+			if got, want := r.NodeNamed(n.Name).Get("closeness_approx"), n.Get("closeness_approx"); got != want {
+				t.Errorf("unexpected estimate for %s with repeated seed %d: got:%s want:%s", n.Name, seed, got, want)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for no pivots")
+		}
+	}()
+	ClosenessApprox(graphFromDOT(t, dot), 0, 1)
+}
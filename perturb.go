@@ -0,0 +1,149 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat"
+)
+
+// StabilityReport holds the rank correlations between a node metric
+// calculated for a graph and for randomly perturbed copies of it.
+type StabilityReport struct {
+	// Spearman and Kendall hold the Spearman
+	// rank correlation and Kendall's tau-b for
+	// each perturbed copy. Values close to 1
+	// indicate that the ranking of nodes by the
+	// metric is stable under perturbation.
+	Spearman []float64
+	Kendall  []float64
+}
+
+// Perturb measures the stability of the ranking of nodes by the node
+// metric calculated by the analysis metric when the edges of g are
+// randomly perturbed. For each of trials perturbed copies of g, a fraction
+// edgeNoise of the edges of g are removed and the same number of edges
+// are added between randomly chosen pairs of nodes that are not adjacent
+// in g, and the rank correlations between the metric for g and for the
+// perturbed copy are calculated over the nodes given a value in both.
+// The perturbations are determined by seed, and g is not modified.
+//
+// Perturb panics if edgeNoise is not in [0, 1], if trials is less than one
+// or if the analysis fails.
+func Perturb(g *Graph, edgeNoise float64, trials int, seed int64, metric Analysis) StabilityReport {
+	if edgeNoise < 0 || edgeNoise > 1 {
+		panic("graphprac: invalid edge noise")
+	}
+	if trials < 1 {
+		panic("graphprac: invalid number of trials")
+	}
+	nodes := g.Index().nodes
+	edges := sortedEdges(g)
+	keep := make(map[int64]int, len(nodes))
+	for i, n := range nodes {
+		keep[n.ID()] = i
+	}
+	count := make([]int, len(edges))
+	for i := range count {
+		count[i] = 1
+	}
+
+	base, err := resampled(g, keep, metric.Attr, edges, count)
+	if err != nil {
+		panic("graphprac: " + err.Error())
+	}
+	want, err := metric.values(base)
+	if err != nil {
+		panic("graphprac: " + err.Error())
+	}
+
+	// Edges can only be added between nodes
+	// that are not adjacent in g.
+	k := int(math.Round(edgeNoise * float64(len(edges))))
+	if free := len(nodes)*(len(nodes)-1)/2 - len(edges); k > free {
+		k = free
+	}
+
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	perm := make([]int, len(edges))
+	for i := range perm {
+		perm[i] = i
+	}
+	report := StabilityReport{
+		Spearman: make([]float64, trials),
+		Kendall:  make([]float64, trials),
+	}
+	for t := 0; t < trials; t++ {
+		for i := range count {
+			count[i] = 1
+		}
+		rnd.Shuffle(len(perm), func(i, j int) { perm[i], perm[j] = perm[j], perm[i] })
+		for _, i := range perm[:k] {
+			count[i] = 0
+		}
+		p, err := resampled(g, keep, metric.Attr, edges, count)
+		if err != nil {
+			panic("graphprac: " + err.Error())
+		}
+		for added := 0; added < k; {
+			u, v := nodes[rnd.Intn(len(nodes))], nodes[rnd.Intn(len(nodes))]
+			if u.ID() == v.ID() || g.HasEdgeBetween(u.ID(), v.ID()) || p.HasEdgeBetween(u.ID(), v.ID()) {
+				continue
+			}
+			p.SetEdge(&Edge{F: p.Node(u.ID()).(*Node), T: p.Node(v.ID()).(*Node)})
+			added++
+		}
+
+		got, err := metric.values(p)
+		if err != nil {
+			panic("graphprac: " + err.Error())
+		}
+		var a, b []float64
+		for _, n := range nodes {
+			v, ok := want[n.ID()]
+			if !ok {
+				continue
+			}
+			w, ok := got[n.ID()]
+			if !ok {
+				continue
+			}
+			a = append(a, v)
+			b = append(b, w)
+		}
+		report.Spearman[t] = stat.Correlation(fractionalRanks(a), fractionalRanks(b), nil)
+		report.Kendall[t] = kendallTau(a, b)
+	}
+	return report
+}
+
+// kendallTau returns Kendall's tau-b rank correlation between a and b,
+// which accounts for ties. It returns NaN if either a or b has fewer than
+// two distinct values.
+func kendallTau(a, b []float64) float64 {
+	var concordant, discordant, tiedA, tiedB float64
+	for i := range a {
+		for j := i + 1; j < len(a); j++ {
+			da, db := a[i]-a[j], b[i]-b[j]
+			switch {
+			case da == 0 && db == 0:
+				tiedA++
+				tiedB++
+			case da == 0:
+				tiedA++
+			case db == 0:
+				tiedB++
+			case (da > 0) == (db > 0):
+				concordant++
+			default:
+				discordant++
+			}
+		}
+	}
+	pairs := float64(len(a)) * float64(len(a)-1) / 2
+	return (concordant - discordant) / math.Sqrt((pairs-tiedA)*(pairs-tiedB))
+}
@@ -0,0 +1,89 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+var kendallTests = []struct {
+	a, b []float64
+	want float64
+}{
+	{a: []float64{1, 2, 3}, b: []float64{1, 2, 3}, want: 1},
+	{a: []float64{1, 2, 3}, b: []float64{3, 2, 1}, want: -1},
+	{a: []float64{1, 2, 3, 4}, b: []float64{1, 3, 2, 4}, want: 2.0 / 3},
+	{a: []float64{1, 1, 2}, b: []float64{1, 2, 3}, want: 2 / math.Sqrt(6)},
+	{a: []float64{1, 1, 1}, b: []float64{1, 2, 3}, want: math.NaN()},
+}
+
+func TestKendallTau(t *testing.T) {
+	for i, test := range kendallTests {
+		got := kendallTau(test.a, test.b)
+		if math.IsNaN(test.want) {
+			if !math.IsNaN(got) {
+				t.Errorf("unexpected result for test %d: got:%v want:NaN", i, got)
+			}
+			continue
+		}
+		if math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("unexpected result for test %d: got:%v want:%v", i, got, test.want)
+		}
+	}
+}
+
+func TestPerturb(t *testing.T) {
+	g := graphFromDOT(t, ringOfCliques(4, 5))
+	want := DOT(g)
+	strength := Analysis{Name: "Strength", Attr: "strength"}
+
+	r := Perturb(g, 0, 3, 1, strength)
+	for i := range r.Spearman {
+		if r.Spearman[i] != 1 || r.Kendall[i] != 1 {
+			t.Errorf("unexpected correlation without noise for trial %d: got:%v,%v want:1,1", i, r.Spearman[i], r.Kendall[i])
+		}
+	}
+
+	r = Perturb(g, 0.2, 10, 1, strength)
+	if got := DOT(g); got != want {
+		t.Errorf("unexpected change to graph:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if len(r.Spearman) != 10 || len(r.Kendall) != 10 {
+		t.Fatalf("unexpected number of trials: got:%d,%d want:10", len(r.Spearman), len(r.Kendall))
+	}
+	for i := range r.Spearman {
+		if !(r.Spearman[i] < 1 && r.Spearman[i] >= -1) || !(r.Kendall[i] < 1 && r.Kendall[i] >= -1) {
+			t.Errorf("unexpected correlation with noise for trial %d: got:%v,%v", i, r.Spearman[i], r.Kendall[i])
+		}
+	}
+	if got := Perturb(g, 0.2, 10, 1, strength); !reflect.DeepEqual(got, r) {
+		t.Errorf("unexpected report for repeated seed: got:%+v want:%+v", got, r)
+	}
+}
+
+func TestPerturbPanics(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b -- c }`)
+	strength := Analysis{Name: "Strength", Attr: "strength"}
+	for _, test := range []struct {
+		name   string
+		noise  float64
+		trials int
+	}{
+		{name: "negative noise", noise: -0.1, trials: 1},
+		{name: "excess noise", noise: 1.1, trials: 1},
+		{name: "no trials", noise: 0.1, trials: 0},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for %s", test.name)
+				}
+			}()
+			Perturb(g, test.noise, test.trials, 1, strength)
+		}()
+	}
+}
@@ -0,0 +1,318 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// plotSize is the width and height of rendered plots.
+const plotSize = 6 * vg.Inch
+
+// edgeColor is the colour used to draw edges in plots.
+var edgeColor = color.NRGBA{R: 0x40, G: 0x40, B: 0x80, A: 0x60}
+
+// HiveSpec specifies the layout of a hive plot.
+type HiveSpec struct {
+	// Axis is the node attribute that determines the
+	// axis a node is placed on. Each distinct value
+	// of the attribute is given an axis. Nodes without
+	// the attribute are not drawn.
+	Axis string
+
+	// Position is the numerical node attribute that
+	// determines the distance of a node along its
+	// axis. If Position is empty, the degree of the
+	// node is used.
+	Position string
+}
+
+// PlotHive renders a hive plot of g as an SVG written to w. Nodes are
+// placed on radial axes according to axes.Axis and along each axis by
+// axes.Position, and edges are drawn as curves between them.
+func PlotHive(g *Graph, axes HiveSpec, w io.Writer) error {
+	var names []string
+	axis := make(map[string]int)
+	for _, n := range NodesOf(g) {
+		a := n.GetUnquoted(axes.Axis)
+		if a == "" {
+			continue
+		}
+		if _, ok := axis[a]; !ok {
+			axis[a] = 0
+			names = append(names, a)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no node has attribute %q", axes.Axis)
+	}
+	sortNatural(names)
+	for i, a := range names {
+		axis[a] = i
+	}
+
+	h := &hivePlot{g: g, names: names, axis: make(map[int64]int), pos: make(map[int64]float64)}
+	for _, n := range NodesOf(g) {
+		a, ok := axis[n.GetUnquoted(axes.Axis)]
+		if !ok {
+			continue
+		}
+		var v float64
+		if axes.Position == "" {
+			v = float64(g.From(n.ID()).Len())
+		} else {
+			var err error
+			v, err = strconv.ParseFloat(n.GetUnquoted(axes.Position), 64)
+			if err != nil {
+				return fmt.Errorf("invalid position for node %q: %v", n.Name, err)
+			}
+		}
+		h.axis[n.ID()] = a
+		h.pos[n.ID()] = v
+	}
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range h.pos {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	for id, v := range h.pos {
+		if max > min {
+			v = (v - min) / (max - min)
+		} else {
+			v = 0
+		}
+		// Leave a hole at the centre so that axes
+		// are distinguishable.
+		h.pos[id] = 0.1 + 0.9*v
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		return err
+	}
+	p.HideAxes()
+	p.Add(h)
+	return writePlot(p, w)
+}
+
+// hivePlot is a plot.Plotter that draws a hive plot.
+type hivePlot struct {
+	g     graph.Graph
+	names []string
+	axis  map[int64]int
+	pos   map[int64]float64
+}
+
+func (h *hivePlot) Plot(c draw.Canvas, _ *plot.Plot) {
+	centre := c.Center()
+	radius := 0.85 * math.Min(float64(c.Max.X-c.Min.X), float64(c.Max.Y-c.Min.Y)) / 2
+	angle := func(a int) float64 {
+		return math.Pi/2 - 2*math.Pi*float64(a)/float64(len(h.names))
+	}
+	// Centre the axes vertically.
+	minY, maxY := 0.0, 0.0
+	for a := range h.names {
+		minY = math.Min(minY, math.Sin(angle(a)))
+		maxY = math.Max(maxY, math.Sin(angle(a)))
+	}
+	centre.Y -= vg.Length((minY + maxY) / 2 * radius)
+	point := func(theta, r float64) vg.Point {
+		return vg.Point{
+			X: centre.X + vg.Length(r*radius*math.Cos(theta)),
+			Y: centre.Y + vg.Length(r*radius*math.Sin(theta)),
+		}
+	}
+
+	font, err := vg.MakeFont(plot.DefaultFont, vg.Points(10))
+	if err != nil {
+		panic(err)
+	}
+	axisStyle := draw.LineStyle{Color: color.Black, Width: vg.Points(1)}
+	for a, name := range h.names {
+		theta := angle(a)
+		c.StrokeLine2(axisStyle, centre.X, centre.Y, point(theta, 1).X, point(theta, 1).Y)
+		c.FillText(draw.TextStyle{Color: color.Black, Font: font, XAlign: draw.XCenter, YAlign: draw.YCenter},
+			point(theta, 1.08), name)
+	}
+
+	c.SetColor(edgeColor)
+	c.SetLineWidth(vg.Points(0.5))
+	for uid, ua := range h.axis {
+		for _, v := range graph.NodesOf(h.g.From(uid)) {
+			vid := v.ID()
+			va, ok := h.axis[vid]
+			if !ok || vid < uid {
+				continue
+			}
+			tu, tv := angle(ua), angle(va)
+			ru, rv := h.pos[uid], h.pos[vid]
+			var mid float64
+			if ua == va {
+				// Loop out from the axis for edges
+				// within an axis.
+				mid = tu + math.Pi/float64(4*len(h.names))
+			} else {
+				d := math.Remainder(tv-tu, 2*math.Pi)
+				mid = tu + d/2
+			}
+			var path vg.Path
+			path.Move(point(tu, ru))
+			path.QuadTo(point(mid, (ru+rv)/2), point(tv, rv))
+			c.Stroke(path)
+		}
+	}
+
+	glyph := draw.GlyphStyle{Color: color.Black, Radius: vg.Points(1.5), Shape: draw.CircleGlyph{}}
+	for id, a := range h.axis {
+		c.DrawGlyph(glyph, point(angle(a), h.pos[id]))
+	}
+}
+
+// PlotArc renders an arc diagram of g as an SVG written to w. Nodes are
+// placed on a line ordered by the orderBy attribute and edges are drawn as
+// arcs above the line. Attribute values that are all numeric are ordered
+// numerically, otherwise they are ordered lexically. Nodes without the
+// attribute are placed last. If orderBy is empty, nodes are ordered by
+// their DOT ID.
+func PlotArc(g *Graph, orderBy string, w io.Writer) error {
+	nodes := NodesOf(g)
+	if len(nodes) == 0 {
+		return fmt.Errorf("empty graph")
+	}
+	orderNodes(nodes, orderBy)
+
+	a := &arcPlot{g: g, nodes: nodes, index: make(map[int64]int, len(nodes))}
+	for i, n := range nodes {
+		a.index[n.ID()] = i
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		return err
+	}
+	p.HideAxes()
+	p.Add(a)
+	return writePlot(p, w)
+}
+
+// arcPlot is a plot.Plotter that draws an arc diagram.
+type arcPlot struct {
+	g     graph.Graph
+	nodes []*Node
+	index map[int64]int
+}
+
+func (a *arcPlot) Plot(c draw.Canvas, _ *plot.Plot) {
+	const labelSpace = vg.Inch
+	width := c.Max.X - c.Min.X
+	base := c.Min.Y + labelSpace
+	step := width / vg.Length(len(a.nodes)+1)
+	x := func(i int) vg.Length { return c.Min.X + step*vg.Length(i+1) }
+
+	c.SetColor(edgeColor)
+	c.SetLineWidth(vg.Points(0.5))
+	for i, u := range a.nodes {
+		for _, v := range graph.NodesOf(a.g.From(u.ID())) {
+			j := a.index[v.ID()]
+			if j <= i {
+				continue
+			}
+			r := (x(j) - x(i)) / 2
+			var path vg.Path
+			path.Move(vg.Point{X: x(j), Y: base})
+			path.Arc(vg.Point{X: x(i) + r, Y: base}, r, 0, math.Pi)
+			c.Stroke(path)
+		}
+	}
+
+	font, err := vg.MakeFont(plot.DefaultFont, vg.Points(math.Min(8, float64(step))))
+	if err != nil {
+		panic(err)
+	}
+	label := draw.TextStyle{Color: color.Black, Font: font, Rotation: math.Pi / 2, XAlign: draw.XRight, YAlign: draw.YCenter}
+	glyph := draw.GlyphStyle{Color: color.Black, Radius: vg.Points(1.5), Shape: draw.CircleGlyph{}}
+	for i, n := range a.nodes {
+		pt := vg.Point{X: x(i), Y: base}
+		c.DrawGlyph(glyph, pt)
+		c.FillText(label, vg.Point{X: pt.X, Y: pt.Y - vg.Points(4)}, dotIDOf(n))
+	}
+}
+
+// orderNodes sorts nodes by the value of the given attribute, numerically
+// if all values are numeric and lexically otherwise, with ties and nodes
+// lacking the attribute ordered by DOT ID. If attr is empty, nodes are
+// sorted by DOT ID.
+func orderNodes(nodes []*Node, attr string) {
+	key := make(map[int64]string, len(nodes))
+	num := make(map[int64]float64, len(nodes))
+	numeric := attr != ""
+	for _, n := range nodes {
+		if attr == "" {
+			continue
+		}
+		v := n.GetUnquoted(attr)
+		key[n.ID()] = v
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			numeric = false
+		}
+		num[n.ID()] = f
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		ki, kj := key[nodes[i].ID()], key[nodes[j].ID()]
+		if (ki == "") != (kj == "") {
+			return kj == ""
+		}
+		if ki != kj {
+			if numeric {
+				return num[nodes[i].ID()] < num[nodes[j].ID()]
+			}
+			return ki < kj
+		}
+		return dotIDOf(nodes[i]) < dotIDOf(nodes[j])
+	})
+}
+
+// sortNatural sorts s numerically if all elements are numbers and
+// lexically otherwise.
+func sortNatural(s []string) {
+	v := make([]float64, len(s))
+	for i, e := range s {
+		var err error
+		v[i], err = strconv.ParseFloat(e, 64)
+		if err != nil {
+			sort.Strings(s)
+			return
+		}
+	}
+	sort.Slice(s, func(i, j int) bool {
+		fi, _ := strconv.ParseFloat(s[i], 64)
+		fj, _ := strconv.ParseFloat(s[j], 64)
+		return fi < fj
+	})
+}
+
+// writePlot writes p to w as an SVG.
+func writePlot(p *plot.Plot, w io.Writer) error {
+	wt, err := p.WriterTo(plotSize, plotSize, "svg")
+	if err != nil {
+		return err
+	}
+	_, err = wt.WriteTo(w)
+	return err
+}
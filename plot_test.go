@@ -0,0 +1,121 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var sortNaturalTests = []struct {
+	in, want []string
+}{
+	{in: []string{"10", "2", "1"}, want: []string{"1", "2", "10"}},
+	{in: []string{"1.5", "-1", "1e1"}, want: []string{"-1", "1.5", "1e1"}},
+	{in: []string{"10", "b", "2"}, want: []string{"10", "2", "b"}},
+	{in: nil, want: nil},
+}
+
+func TestSortNatural(t *testing.T) {
+	for i, test := range sortNaturalTests {
+		got := append([]string(nil), test.in...)
+		sortNatural(got)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected order for test %d: got:%q want:%q", i, got, test.want)
+		}
+	}
+}
+
+var orderNodesTests = []struct {
+	attr string
+	want []string
+}{
+	{attr: "", want: []string{"a", "b", "c", "d", "e"}},
+	{attr: "rank", want: []string{"a", "c", "e", "b", "d"}},
+	{attr: "name", want: []string{"b", "a", "e", "c", "d"}},
+}
+
+func TestOrderNodes(t *testing.T) {
+	g := graphFromDOT(t, `graph {
+	e [rank=2, name=beta];
+	d;
+	c [rank=1, name=gamma];
+	b [rank=10, name=alpha];
+	a [rank=1, name=beta];
+}`)
+	for i, test := range orderNodesTests {
+		nodes := NodesOf(g)
+		orderNodes(nodes, test.attr)
+		var got []string
+		for _, n := range nodes {
+			got = append(got, n.Name)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected order for test %d: got:%v want:%v", i, got, test.want)
+		}
+	}
+}
+
+// checkSVG fails the test if s is not well-formed XML with an svg root.
+func checkSVG(t *testing.T, s string) {
+	t.Helper()
+	var root struct {
+		XMLName xml.Name
+	}
+	err := xml.Unmarshal([]byte(s), &root)
+	if err != nil {
+		t.Errorf("invalid SVG: %v", err)
+		return
+	}
+	if root.XMLName.Local != "svg" {
+		t.Errorf("unexpected root element: got:%s want:svg", root.XMLName.Local)
+	}
+}
+
+const plotDOT = `graph {
+	a [group=1, rank=0.5];
+	b [group=1, rank=2];
+	c [group=2, rank=1];
+	d [group=10];
+	e;
+	a -- b; b -- c; c -- d [weight=2]; d -- e;
+}`
+
+func TestPlotHive(t *testing.T) {
+	g := graphFromDOT(t, plotDOT)
+	var buf strings.Builder
+	err := PlotHive(g, HiveSpec{Axis: "group"}, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkSVG(t, buf.String())
+
+	for _, axes := range []HiveSpec{
+		{Axis: "missing"},
+		{Axis: "group", Position: "rank"},
+	} {
+		err := PlotHive(g, axes, &strings.Builder{})
+		if err == nil {
+			t.Errorf("expected error for %+v", axes)
+		}
+	}
+}
+
+func TestPlotArc(t *testing.T) {
+	g := graphFromDOT(t, plotDOT)
+	var buf strings.Builder
+	err := PlotArc(g, "rank", &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkSVG(t, buf.String())
+
+	err = PlotArc(graphFromDOT(t, `graph {}`), "", &strings.Builder{})
+	if err == nil {
+		t.Error("expected error for empty graph")
+	}
+}
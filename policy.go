@@ -0,0 +1,147 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"path"
+	"sync"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// RedactAction is the action taken by an ExportPolicy on the value of a
+// matching attribute.
+type RedactAction int
+
+const (
+	// DropAttribute removes the attribute.
+	DropAttribute RedactAction = iota
+
+	// HashAttribute replaces the value of the
+	// attribute with a hash salted with the
+	// policy's salt, as used by Anonymize.
+	HashAttribute
+
+	// TruncateAttribute shortens the value of
+	// the attribute to the rule's length in
+	// characters.
+	TruncateAttribute
+)
+
+// RedactRule is an attribute redaction rule of an ExportPolicy.
+type RedactRule struct {
+	// Pattern is a path.Match pattern matched
+	// against attribute keys, for example
+	// "email" or "desc*".
+	Pattern string
+
+	// Action is the action taken on matching
+	// attributes.
+	Action RedactAction
+
+	// Length is the number of characters kept
+	// by TruncateAttribute.
+	Length int
+}
+
+// ExportPolicy is a set of attribute redaction rules applied to the
+// graph, node and edge attributes written by the writers of this
+// package: DOT and drawing output, GraphML, GraphSON, Parquet, XLSX,
+// protocol buffers, Pajek networks and value files, DIMACS and METIS
+// weights, and ExportTiles. The first rule with a pattern matching an
+// attribute's key is applied, and attributes not matching any rule are
+// written unchanged. The graphs themselves are not altered. SaveBinary
+// and Store.Save persist the state of a session rather than exporting
+// it, and are exempt from the policy.
+type ExportPolicy struct {
+	Rules []RedactRule
+
+	// Salt is the salt used by HashAttribute.
+	Salt string
+}
+
+var (
+	policyMu sync.RWMutex
+	policy   ExportPolicy
+)
+
+// SetExportPolicy sets the policy applied by all writers. By default no
+// attributes are redacted. SetExportPolicy returns an error and leaves
+// the current policy in place if a rule has a malformed pattern, an
+// unknown action or a negative length.
+func SetExportPolicy(p ExportPolicy) error {
+	for _, r := range p.Rules {
+		if _, err := path.Match(r.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", r.Pattern, err)
+		}
+		switch r.Action {
+		case DropAttribute, HashAttribute:
+		case TruncateAttribute:
+			if r.Length < 0 {
+				return fmt.Errorf("invalid truncation length for %q: %d", r.Pattern, r.Length)
+			}
+		default:
+			return fmt.Errorf("invalid action for %q: %d", r.Pattern, r.Action)
+		}
+	}
+	p.Rules = append([]RedactRule(nil), p.Rules...)
+	policyMu.Lock()
+	policy = p
+	policyMu.Unlock()
+	return nil
+}
+
+// exportAttributes returns attrs with the export policy applied. If no
+// rule matches, attrs is returned without copying.
+func exportAttributes(attrs []encoding.Attribute) Attributes {
+	policyMu.RLock()
+	p := policy
+	policyMu.RUnlock()
+	if len(p.Rules) == 0 {
+		return attrs
+	}
+
+	var dst Attributes
+	for i, kv := range attrs {
+		r, ok := p.rule(kv.Key)
+		if !ok {
+			if dst != nil {
+				dst = append(dst, kv)
+			}
+			continue
+		}
+		if dst == nil {
+			dst = append(make(Attributes, 0, len(attrs)), attrs[:i]...)
+		}
+		v := Attributes{kv}.GetUnquoted(kv.Key)
+		switch r.Action {
+		case DropAttribute:
+			continue
+		case HashAttribute:
+			kv.Value = anonymousName(p.Salt, v)
+		case TruncateAttribute:
+			if s := []rune(v); len(s) > r.Length {
+				v = string(s[:r.Length])
+			}
+			kv.Value = quoteDOT(v)
+		}
+		dst = append(dst, kv)
+	}
+	if dst == nil {
+		return attrs
+	}
+	return dst
+}
+
+// rule returns the first rule of p matching key.
+func (p ExportPolicy) rule(key string) (RedactRule, bool) {
+	for _, r := range p.Rules {
+		if ok, _ := path.Match(r.Pattern, key); ok {
+			return r, true
+		}
+	}
+	return RedactRule{}, false
+}
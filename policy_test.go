@@ -0,0 +1,178 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var exportPolicyTests = []struct {
+	policy ExportPolicy
+	attrs  Attributes
+	want   Attributes
+}{
+	{
+		policy: ExportPolicy{},
+		attrs:  Attributes{{Key: "email", Value: "a@b"}},
+		want:   Attributes{{Key: "email", Value: "a@b"}},
+	},
+	{
+		policy: ExportPolicy{Rules: []RedactRule{{Pattern: "email"}}},
+		attrs:  Attributes{{Key: "name", Value: "a"}, {Key: "email", Value: "a@b"}, {Key: "rank", Value: "1"}},
+		want:   Attributes{{Key: "name", Value: "a"}, {Key: "rank", Value: "1"}},
+	},
+	{
+		policy: ExportPolicy{Rules: []RedactRule{{Pattern: "email"}}},
+		attrs:  Attributes{{Key: "name", Value: "a"}},
+		want:   Attributes{{Key: "name", Value: "a"}},
+	},
+	{
+		policy: ExportPolicy{Rules: []RedactRule{{Pattern: "email", Action: HashAttribute}}, Salt: "salt"},
+		attrs:  Attributes{{Key: "email", Value: `"alice"`}},
+		want:   Attributes{{Key: "email", Value: anonAlice}},
+	},
+	{
+		policy: ExportPolicy{Rules: []RedactRule{{Pattern: "desc*", Action: TruncateAttribute, Length: 3}}},
+		attrs:  Attributes{{Key: "description", Value: `"héllo world"`}, {Key: "descr", Value: "ab"}},
+		want:   Attributes{{Key: "description", Value: `"hél"`}, {Key: "descr", Value: `"ab"`}},
+	},
+	{
+		policy: ExportPolicy{Rules: []RedactRule{
+			{Pattern: "note", Action: TruncateAttribute, Length: 0},
+			{Pattern: "*"},
+		}},
+		attrs: Attributes{{Key: "note", Value: "secret"}, {Key: "rank", Value: "1"}},
+		want:  Attributes{{Key: "note", Value: `""`}},
+	},
+}
+
+func TestExportPolicy(t *testing.T) {
+	defer SetExportPolicy(ExportPolicy{})
+	for i, test := range exportPolicyTests {
+		err := SetExportPolicy(test.policy)
+		if err != nil {
+			t.Fatalf("unexpected error for test %d: %v", i, err)
+		}
+		attrs := append(Attributes(nil), test.attrs...)
+		got := exportAttributes(attrs)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected attributes for test %d: got:%v want:%v", i, got, test.want)
+		}
+		if !reflect.DeepEqual(attrs, test.attrs) {
+			t.Errorf("attributes modified for test %d: got:%v want:%v", i, attrs, test.attrs)
+		}
+	}
+}
+
+var setExportPolicyErrorTests = []ExportPolicy{
+	{Rules: []RedactRule{{Pattern: "[email"}}},
+	{Rules: []RedactRule{{Pattern: "email", Action: TruncateAttribute, Length: -1}}},
+	{Rules: []RedactRule{{Pattern: "email", Action: RedactAction(-1)}}},
+}
+
+func TestSetExportPolicyErrors(t *testing.T) {
+	defer SetExportPolicy(ExportPolicy{})
+	keep := ExportPolicy{Rules: []RedactRule{{Pattern: "email"}}}
+	err := SetExportPolicy(keep)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, p := range setExportPolicyErrorTests {
+		err := SetExportPolicy(p)
+		if err == nil {
+			t.Errorf("expected error for test %d", i)
+		}
+	}
+	got := exportAttributes(Attributes{{Key: "email", Value: "a@b"}})
+	if len(got) != 0 {
+		t.Errorf("policy replaced by invalid policy: got:%v", got)
+	}
+}
+
+func TestExportPolicyDOT(t *testing.T) {
+	defer SetExportPolicy(ExportPolicy{})
+	g := graphFromDOT(t, `graph { a [email="a@b", rank=1]; b; a -- b [email="c@d"]; }`)
+	err := SetExportPolicy(ExportPolicy{Rules: []RedactRule{{Pattern: "email"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := DOTWithOptions(g, DOTOptions{})
+	if strings.Contains(got, "email") {
+		t.Errorf("redacted attribute written:\n%s", got)
+	}
+	if !strings.Contains(got, "rank=1") {
+		t.Errorf("unredacted attribute missing:\n%s", got)
+	}
+	if g.NodeNamed("a").Attributes.Get("email") == "" {
+		t.Error("graph attributes altered by export policy")
+	}
+}
+
+var exportPolicyWeightTests = []struct {
+	name  string
+	write func(*Graph, io.Writer) error
+	rules []RedactRule
+
+	want    string
+	wantErr bool
+}{
+	{
+		name:  "DIMACS",
+		write: WriteDIMACS,
+		want:  "p edge 2 1\nn 1 3\ne 1 2\n",
+	},
+	{
+		name:  "DIMACS",
+		write: WriteDIMACS,
+		rules: []RedactRule{{Pattern: "weight"}},
+		want:  "p edge 2 1\ne 1 2\n",
+	},
+	{
+		name:    "DIMACS",
+		write:   WriteDIMACS,
+		rules:   []RedactRule{{Pattern: "w*", Action: HashAttribute}},
+		wantErr: true,
+	},
+	{
+		name:  "METIS",
+		write: WriteMETIS,
+		want:  "2 1 011\n3 2 4\n1 1 4\n",
+	},
+	{
+		name:  "METIS",
+		write: WriteMETIS,
+		rules: []RedactRule{{Pattern: "weight"}},
+		want:  "2 1\n2\n1\n",
+	},
+	{
+		name:    "METIS",
+		write:   WriteMETIS,
+		rules:   []RedactRule{{Pattern: "w*", Action: HashAttribute}},
+		wantErr: true,
+	},
+}
+
+func TestExportPolicyWeights(t *testing.T) {
+	defer SetExportPolicy(ExportPolicy{})
+	for i, test := range exportPolicyWeightTests {
+		g := graphFromDOT(t, `graph { a [weight=3]; b; a -- b [weight=4]; }`)
+		err := SetExportPolicy(ExportPolicy{Rules: test.rules, Salt: "salt"})
+		if err != nil {
+			t.Fatalf("unexpected error setting policy for test %d: %v", i, err)
+		}
+		var buf strings.Builder
+		err = test.write(g, &buf)
+		if (err != nil) != test.wantErr {
+			t.Errorf("unexpected error for test %d %s: got:%v want error:%t", i, test.name, err, test.wantErr)
+			continue
+		}
+		if err == nil && buf.String() != test.want {
+			t.Errorf("unexpected %s output for test %d:\ngot:\n%s\nwant:\n%s", test.name, i, &buf, test.want)
+		}
+	}
+}
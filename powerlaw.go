@@ -0,0 +1,176 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"sort"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/mathext"
+)
+
+// FitPowerLaw fits a discrete power law, p(k) ∝ k^-alpha for k ≥ xmin, to
+// the tail of the degree distribution of g using the procedure of Clauset,
+// Shalizi and Newman (doi:10.1137/070710111). For each distinct non-zero
+// degree as a candidate xmin, alpha is the maximum likelihood estimate for
+// the degrees at or above xmin, and the fit with the smallest
+// Kolmogorov-Smirnov distance, ks, between the empirical and fitted
+// distributions is returned. Nodes with no edges are ignored.
+//
+// A small ks alone does not show that the degrees follow a power law; use
+// PowerLawGoodness to test the plausibility of the fit. If g has fewer than
+// two distinct non-zero degrees, FitPowerLaw returns NaN values.
+func FitPowerLaw(g *Graph) (alpha, xmin float64, ks float64) {
+	f := fitPowerLaw(nonZeroDegrees(g))
+	return f.alpha, f.xmin, f.ks
+}
+
+// PowerLawGoodness returns the p-value of the goodness-of-fit test of
+// Clauset, Shalizi and Newman for the power law fitted to the degrees of g
+// by FitPowerLaw. Each of trials synthetic degree sequences of the same
+// length is drawn from the fitted power law above xmin and from the
+// empirical degrees below it, and is fitted in the same way as the
+// degrees of g. The p-value is the fraction of synthetic sequences with a
+// Kolmogorov-Smirnov distance at least as large as that of the observed
+// fit. A power law is commonly rejected if p ≤ 0.1; 2500 trials give p to
+// within about 0.01. The random sequences are determined by seed.
+//
+// PowerLawGoodness panics if trials is less than one.
+func PowerLawGoodness(g *Graph, trials int, seed int64) (p float64) {
+	if trials < 1 {
+		panic("graphprac: invalid number of trials")
+	}
+	x := nonZeroDegrees(g)
+	f := fitPowerLaw(x)
+	if math.IsNaN(f.ks) {
+		return math.NaN()
+	}
+
+	// body holds the degrees below xmin.
+	body := x[:len(x)-f.n]
+	pTail := float64(f.n) / float64(len(x))
+
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	syn := make([]float64, len(x))
+	var worse int
+	for t := 0; t < trials; t++ {
+		for i := range syn {
+			if rnd.Float64() < pTail {
+				// Sample from the continuous approximation to the
+				// discrete power law given in appendix D of Clauset
+				// et al.
+				syn[i] = math.Floor((f.xmin-0.5)*math.Pow(1-rnd.Float64(), -1/(f.alpha-1)) + 0.5)
+			} else {
+				syn[i] = body[rnd.Intn(len(body))]
+			}
+		}
+		sort.Float64s(syn)
+		if s := fitPowerLaw(syn); !(s.ks < f.ks) {
+			worse++
+		}
+	}
+	return float64(worse) / float64(trials)
+}
+
+// nonZeroDegrees returns the non-zero degrees of the nodes of g in
+// ascending order.
+func nonZeroDegrees(g *Graph) []float64 {
+	var x []float64
+	for _, d := range degrees(g, g.Index()) {
+		if d > 0 {
+			x = append(x, d)
+		}
+	}
+	sort.Float64s(x)
+	return x
+}
+
+// powerLawFit is a discrete power law fitted to the n values at or above
+// xmin with Kolmogorov-Smirnov distance ks.
+type powerLawFit struct {
+	alpha, xmin, ks float64
+	n               int
+}
+
+// fitPowerLaw returns the power law fit to x, a sorted slice of positive
+// integer values, that minimises the Kolmogorov-Smirnov distance over
+// all choices of xmin. If x has fewer than two distinct values, the
+// fields of the returned fit are NaN.
+func fitPowerLaw(x []float64) powerLawFit {
+	best := powerLawFit{alpha: math.NaN(), xmin: math.NaN(), ks: math.NaN()}
+	for start := 0; start < len(x); {
+		xmin := x[start]
+		tail := x[start:]
+		if tail[len(tail)-1] == xmin {
+			// A tail of a single value has no finite alpha.
+			break
+		}
+		alpha := powerLawAlpha(tail)
+		ks := powerLawKS(tail, alpha)
+		if !(ks >= best.ks) {
+			best = powerLawFit{alpha: alpha, xmin: xmin, ks: ks, n: len(tail)}
+		}
+		for start < len(x) && x[start] == xmin {
+			start++
+		}
+	}
+	return best
+}
+
+// powerLawAlpha returns the maximum likelihood estimate of the exponent of
+// a discrete power law for the sorted values in x, with xmin = x[0].
+func powerLawAlpha(x []float64) float64 {
+	xmin := x[0]
+	var sumLog float64
+	for _, v := range x {
+		sumLog += math.Log(v)
+	}
+	n := float64(len(x))
+	logL := func(a float64) float64 {
+		return -n*math.Log(mathext.Zeta(a, xmin)) - a*sumLog
+	}
+
+	// The log-likelihood is concave in alpha, so
+	// find its maximum by golden section search.
+	const (
+		tol   = 1e-6
+		ratio = 0.6180339887498949 // (√5-1)/2
+	)
+	lo, hi := 1+tol, 20.0
+	a, b := hi-ratio*(hi-lo), lo+ratio*(hi-lo)
+	fa, fb := logL(a), logL(b)
+	for hi-lo > tol {
+		if fa > fb {
+			hi, b, fb = b, a, fa
+			a = hi - ratio*(hi-lo)
+			fa = logL(a)
+		} else {
+			lo, a, fa = a, b, fb
+			b = lo + ratio*(hi-lo)
+			fb = logL(b)
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// powerLawKS returns the Kolmogorov-Smirnov distance between the empirical
+// distribution of the sorted values in x and the discrete power law with
+// exponent alpha and xmin = x[0].
+func powerLawKS(x []float64, alpha float64) float64 {
+	z := mathext.Zeta(alpha, x[0])
+	n := float64(len(x))
+	var d float64
+	for i := 0; i < len(x); {
+		v := x[i]
+		for i < len(x) && x[i] == v {
+			i++
+		}
+		emp := float64(i) / n
+		fit := 1 - mathext.Zeta(alpha, v+1)/z
+		d = math.Max(d, math.Abs(emp-fit))
+	}
+	return d
+}
@@ -0,0 +1,121 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+// powerLawSample returns n values drawn from the discrete power law with
+// the given exponent and xmin, sorted in ascending order.
+func powerLawSample(n int, alpha, xmin float64, seed uint64) []float64 {
+	rnd := rand.New(rand.NewSource(seed))
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = math.Floor((xmin-0.5)*math.Pow(1-rnd.Float64(), -1/(alpha-1)) + 0.5)
+	}
+	sort.Float64s(x)
+	return x
+}
+
+var fitPowerLawTests = []struct {
+	alpha, xmin float64
+}{
+	{alpha: 2.5, xmin: 1},
+	{alpha: 2.2, xmin: 3},
+	{alpha: 3, xmin: 5},
+}
+
+func TestFitPowerLaw(t *testing.T) {
+	for i, test := range fitPowerLawTests {
+		x := powerLawSample(5000, test.alpha, test.xmin, uint64(i))
+		f := fitPowerLaw(x)
+		if math.Abs(f.alpha-test.alpha) > 0.1 {
+			t.Errorf("unexpected alpha for test %d: got:%v want:%v", i, f.alpha, test.alpha)
+		}
+		if f.xmin < test.xmin || f.xmin > 4*test.xmin {
+			t.Errorf("unexpected xmin for test %d: got:%v want near:%v", i, f.xmin, test.xmin)
+		}
+	}
+
+	for _, dot := range []string{
+		`graph { a -- b -- c -- a }`,
+		`graph { a; b }`,
+	} {
+		alpha, xmin, ks := FitPowerLaw(graphFromDOT(t, dot))
+		if !math.IsNaN(alpha) || !math.IsNaN(xmin) || !math.IsNaN(ks) {
+			t.Errorf("unexpected fit for %s: got:%v,%v,%v want NaN values", dot, alpha, xmin, ks)
+		}
+	}
+}
+
+// starsGraph returns a DOT graph of disjoint stars with the given
+// numbers of leaves.
+func starsGraph(leaves []float64) string {
+	var buf strings.Builder
+	buf.WriteString("graph {\n")
+	for i, k := range leaves {
+		for j := 0; j < int(k); j++ {
+			fmt.Fprintf(&buf, "\ts%d -- s%d_%d;\n", i, i, j)
+		}
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// gridGraph returns a DOT n×n square lattice.
+func gridGraph(n int) string {
+	var buf strings.Builder
+	buf.WriteString("graph {\n")
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if i+1 < n {
+				fmt.Fprintf(&buf, "\tg%d_%d -- g%d_%d;\n", i, j, i+1, j)
+			}
+			if j+1 < n {
+				fmt.Fprintf(&buf, "\tg%d_%d -- g%d_%d;\n", i, j, i, j+1)
+			}
+		}
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+func TestPowerLawGoodness(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		dot       string
+		plausible bool
+	}{
+		{name: "power law stars", dot: starsGraph(powerLawSample(300, 2.5, 2, 1)), plausible: true},
+		{name: "grid", dot: gridGraph(10), plausible: false},
+	} {
+		g := graphFromDOT(t, test.dot)
+		p := PowerLawGoodness(g, 200, 1)
+		if (p > 0.1) != test.plausible {
+			t.Errorf("unexpected p-value for %s: got:%v plausible:%t", test.name, p, test.plausible)
+		}
+		if r := PowerLawGoodness(g, 200, 1); r != p {
+			t.Errorf("unexpected p-value for %s with repeated seed: got:%v want:%v", test.name, r, p)
+		}
+	}
+
+	if p := PowerLawGoodness(graphFromDOT(t, `graph { a -- b }`), 10, 1); !math.IsNaN(p) {
+		t.Errorf("unexpected p-value for single degree: got:%v want:NaN", p)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for no trials")
+		}
+	}()
+	PowerLawGoodness(graphFromDOT(t, `graph { a -- b }`), 0, 1)
+}
@@ -0,0 +1,294 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// Field numbers of the messages in graph.proto.
+const (
+	graphGraphAttrs protowire.Number = 1
+	graphNodeAttrs  protowire.Number = 2
+	graphEdgeAttrs  protowire.Number = 3
+	graphNodes      protowire.Number = 4
+	graphEdges      protowire.Number = 5
+
+	nodeID    protowire.Number = 1
+	nodeName  protowire.Number = 2
+	nodeAttrs protowire.Number = 3
+
+	edgeFrom  protowire.Number = 1
+	edgeTo    protowire.Number = 2
+	edgeAttrs protowire.Number = 3
+
+	attrKey    protowire.Number = 1
+	attrString protowire.Number = 2
+	attrFloat  protowire.Number = 3
+	attrInt    protowire.Number = 4
+	attrBool   protowire.Number = 5
+)
+
+// ToProto returns g encoded as a Graph protocol buffer message described
+// by the schema in graph.proto. Attribute values are typed as integers,
+// floating point numbers or booleans when their text can be recovered
+// exactly, and as strings otherwise.
+func ToProto(g *Graph) []byte {
+	return toProto(g, true)
+}
+
+// toProto returns g encoded as a Graph protocol buffer message. If attrs
+// is false, only the structure of g is encoded.
+func toProto(g *Graph, attrs bool) []byte {
+	var b []byte
+	if attrs {
+		b = appendAttributes(b, graphGraphAttrs, g.GraphAttrs)
+		b = appendAttributes(b, graphNodeAttrs, g.NodeAttrs)
+		b = appendAttributes(b, graphEdgeAttrs, g.EdgeAttrs)
+	}
+	for _, n := range NodesOf(g) {
+		var m []byte
+		m = protowire.AppendTag(m, nodeID, protowire.VarintType)
+		m = protowire.AppendVarint(m, uint64(n.ID()))
+		if attrs {
+			m = protowire.AppendTag(m, nodeName, protowire.BytesType)
+			m = protowire.AppendString(m, n.Name)
+			m = appendAttributes(m, nodeAttrs, n.Attributes)
+		}
+		b = protowire.AppendTag(b, graphNodes, protowire.BytesType)
+		b = protowire.AppendBytes(b, m)
+	}
+	for _, e := range graph.EdgesOf(g.Edges()) {
+		e := e.(*Edge)
+		var m []byte
+		m = protowire.AppendTag(m, edgeFrom, protowire.VarintType)
+		m = protowire.AppendVarint(m, uint64(e.F.ID()))
+		m = protowire.AppendTag(m, edgeTo, protowire.VarintType)
+		m = protowire.AppendVarint(m, uint64(e.T.ID()))
+		if attrs {
+			m = appendAttributes(m, edgeAttrs, e.Attributes)
+		}
+		b = protowire.AppendTag(b, graphEdges, protowire.BytesType)
+		b = protowire.AppendBytes(b, m)
+	}
+	return b
+}
+
+// appendAttributes appends attrs to b as repeated Attribute messages
+// with the field number num, with the export policy applied.
+func appendAttributes(b []byte, num protowire.Number, attrs Attributes) []byte {
+	for _, a := range exportAttributes(attrs) {
+		var m []byte
+		m = protowire.AppendTag(m, attrKey, protowire.BytesType)
+		m = protowire.AppendString(m, a.Key)
+		if i, err := strconv.ParseInt(a.Value, 10, 64); err == nil && strconv.FormatInt(i, 10) == a.Value {
+			m = protowire.AppendTag(m, attrInt, protowire.VarintType)
+			m = protowire.AppendVarint(m, uint64(i))
+		} else if f, err := strconv.ParseFloat(a.Value, 64); err == nil && fmt.Sprint(f) == a.Value {
+			m = protowire.AppendTag(m, attrFloat, protowire.Fixed64Type)
+			m = protowire.AppendFixed64(m, math.Float64bits(f))
+		} else if a.Value == "true" || a.Value == "false" {
+			m = protowire.AppendTag(m, attrBool, protowire.VarintType)
+			m = protowire.AppendVarint(m, protowire.EncodeBool(a.Value == "true"))
+		} else {
+			m = protowire.AppendTag(m, attrString, protowire.BytesType)
+			m = protowire.AppendString(m, a.Value)
+		}
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendBytes(b, m)
+	}
+	return b
+}
+
+// FromProto returns the graph encoded in the Graph protocol buffer message
+// in b. Typed attribute values are converted to text in the form written
+// by the analysis functions.
+func FromProto(b []byte) (*Graph, error) {
+	g := &Graph{UndirectedGraph: simple.NewUndirectedGraph()}
+	type edge struct {
+		from, to int64
+		attrs    Attributes
+	}
+	var edges []edge
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+		switch num {
+		case graphGraphAttrs, graphNodeAttrs, graphEdgeAttrs:
+			m, n := protowire.ConsumeBytes(v)
+			if n < 0 || typ != protowire.BytesType {
+				return n, fmt.Errorf("invalid global attribute")
+			}
+			a, err := consumeAttribute(m)
+			if err != nil {
+				return n, err
+			}
+			switch num {
+			case graphGraphAttrs:
+				g.GraphAttrs = append(g.GraphAttrs, a)
+			case graphNodeAttrs:
+				g.NodeAttrs = append(g.NodeAttrs, a)
+			case graphEdgeAttrs:
+				g.EdgeAttrs = append(g.EdgeAttrs, a)
+			}
+			return n, nil
+		case graphNodes:
+			m, n := protowire.ConsumeBytes(v)
+			if n < 0 || typ != protowire.BytesType {
+				return n, fmt.Errorf("invalid node")
+			}
+			node := &Node{}
+			err := consumeFields(m, func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+				switch {
+				case num == nodeID && typ == protowire.VarintType:
+					id, n := protowire.ConsumeVarint(v)
+					node.NodeID = int64(id)
+					return n, nil
+				case num == nodeName && typ == protowire.BytesType:
+					var n int
+					node.Name, n = protowire.ConsumeString(v)
+					return n, nil
+				case num == nodeAttrs && typ == protowire.BytesType:
+					m, n := protowire.ConsumeBytes(v)
+					if n < 0 {
+						return n, nil
+					}
+					a, err := consumeAttribute(m)
+					node.Attributes = append(node.Attributes, a)
+					return n, err
+				}
+				return 0, errUnknownField
+			})
+			if err != nil {
+				return n, err
+			}
+			if g.Node(node.NodeID) != nil {
+				return n, fmt.Errorf("duplicate node ID %d", node.NodeID)
+			}
+			g.AddNode(node)
+			return n, nil
+		case graphEdges:
+			m, n := protowire.ConsumeBytes(v)
+			if n < 0 || typ != protowire.BytesType {
+				return n, fmt.Errorf("invalid edge")
+			}
+			var e edge
+			err := consumeFields(m, func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+				switch {
+				case num == edgeFrom && typ == protowire.VarintType:
+					id, n := protowire.ConsumeVarint(v)
+					e.from = int64(id)
+					return n, nil
+				case num == edgeTo && typ == protowire.VarintType:
+					id, n := protowire.ConsumeVarint(v)
+					e.to = int64(id)
+					return n, nil
+				case num == edgeAttrs && typ == protowire.BytesType:
+					m, n := protowire.ConsumeBytes(v)
+					if n < 0 {
+						return n, nil
+					}
+					a, err := consumeAttribute(m)
+					e.attrs = append(e.attrs, a)
+					return n, err
+				}
+				return 0, errUnknownField
+			})
+			edges = append(edges, e)
+			return n, err
+		}
+		return 0, errUnknownField
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Edges are added after all nodes since
+	// fields may appear in any order.
+	for _, e := range edges {
+		f, ok := g.Node(e.from).(*Node)
+		t, ok2 := g.Node(e.to).(*Node)
+		if !ok || !ok2 || f == t {
+			return nil, fmt.Errorf("invalid edge %d--%d", e.from, e.to)
+		}
+		g.SetEdge(&Edge{F: f, T: t, Attributes: e.attrs})
+	}
+	return g, nil
+}
+
+// consumeAttribute returns the Attribute message encoded in b.
+func consumeAttribute(b []byte) (encoding.Attribute, error) {
+	var a encoding.Attribute
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+		switch {
+		case num == attrKey && typ == protowire.BytesType:
+			var n int
+			a.Key, n = protowire.ConsumeString(v)
+			return n, nil
+		case num == attrString && typ == protowire.BytesType:
+			var n int
+			a.Value, n = protowire.ConsumeString(v)
+			return n, nil
+		case num == attrFloat && typ == protowire.Fixed64Type:
+			f, n := protowire.ConsumeFixed64(v)
+			a.Value = fmt.Sprint(math.Float64frombits(f))
+			return n, nil
+		case num == attrInt && typ == protowire.VarintType:
+			i, n := protowire.ConsumeVarint(v)
+			a.Value = strconv.FormatInt(int64(i), 10)
+			return n, nil
+		case num == attrBool && typ == protowire.VarintType:
+			t, n := protowire.ConsumeVarint(v)
+			a.Value = strconv.FormatBool(protowire.DecodeBool(t))
+			return n, nil
+		}
+		return 0, errUnknownField
+	})
+	if err != nil {
+		return a, err
+	}
+	if a.Key == "" {
+		return a, fmt.Errorf("attribute with no key")
+	}
+	return a, nil
+}
+
+// errUnknownField is returned by consumeFields callbacks to indicate that
+// a field is not known and should be skipped.
+var errUnknownField = errors.New("unknown field")
+
+// consumeFields calls fn with the number, type and remaining data for each
+// field in the message encoded in b. The fn function returns the length of
+// the field value it consumed, or errUnknownField if the field should be
+// skipped.
+func consumeFields(b []byte, fn func(protowire.Number, protowire.Type, []byte) (int, error)) error {
+	for len(b) != 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("invalid protobuf tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		n, err := fn(num, typ, b)
+		switch err {
+		case nil:
+		case errUnknownField:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		default:
+			return err
+		}
+		if n < 0 {
+			return fmt.Errorf("invalid protobuf field %d: %v", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"testing"
+)
+
+var protoTests = []string{
+	`graph { a }`,
+	`graph {
+	a [count=3, neg=-7, rank=0.25, big="1e+21", flag=true, pad=1.50, label="A \"quoted\" node"];
+	b [id=007];
+	a -- b [weight=2.5, label=ab];
+	b -- c;
+	d;
+}`,
+}
+
+func TestProtoRoundTrip(t *testing.T) {
+	for i, src := range protoTests {
+		g := graphFromDOT(t, src)
+		got, err := FromProto(ToProto(g))
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		if got, want := DOT(got), DOT(g); got != want {
+			t.Errorf("unexpected round trip graph for test %d:\ngot:\n%s\nwant:\n%s", i, got, want)
+		}
+		for _, n := range NodesOf(g) {
+			if got.Node(n.ID()).(*Node).Name != n.Name {
+				t.Errorf("node ID %d not retained for %s in test %d", n.ID(), n.Name, i)
+			}
+		}
+	}
+}
+
+func TestFromProtoInvalid(t *testing.T) {
+	for i, b := range [][]byte{
+		{0x22, 0x05, 0x08},
+		{0xff},
+	} {
+		if _, err := FromProto(b); err == nil {
+			t.Errorf("expected error for test %d", i)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AttributeProvenance describes how an attribute was computed. It is
+// intended to be serialised with encoding/json.
+type AttributeProvenance struct {
+	Attribute string `json:"attribute"`
+
+	// Analysis is the name of the function
+	// that wrote the attribute.
+	Analysis string `json:"analysis"`
+	// Params holds the parameters of the
+	// analysis keyed by parameter name.
+	Params map[string]string `json:"params,omitempty"`
+
+	// Time is the time the analysis completed.
+	Time time.Time `json:"time"`
+}
+
+// String returns a description of the provenance, for example
+// "rank ← PageRank(damp=0.85, tol=1e-06) at 2017-06-01T10:00:00Z".
+// Parameters are listed in name order.
+func (p AttributeProvenance) String() string {
+	return fmt.Sprintf("%s ← %s at %s", p.Attribute, p.call(), p.Time.Format(time.RFC3339))
+}
+
+// call returns the analysis and its parameters in call form, for example
+// "PageRank(damp=0.85, tol=1e-06)".
+func (p AttributeProvenance) call() string {
+	params := make([]string, 0, len(p.Params))
+	for k, v := range p.Params {
+		params = append(params, k+"="+v)
+	}
+	sort.Strings(params)
+	return fmt.Sprintf("%s(%s)", p.Analysis, strings.Join(params, ", "))
+}
+
+// Provenance returns the provenance of the node or edge attribute attr of
+// g, and whether it is known. Provenance is recorded for the attributes
+// written by the analyses available to Run and by NormalizeAttribute,
+// NormalizeAttributeTo, BinAttribute and ResolutionSweep. The most recent
+// analysis to write an attribute is reported.
+func Provenance(g *Graph, attr string) (AttributeProvenance, bool) {
+	p, ok := g.provenance[attr]
+	return p, ok
+}
+
+// analysisState is the state of a graph used to trace its analyses.
+type analysisState struct {
+	// provenance holds the provenance of
+	// attributes written by analyses.
+	provenance map[string]AttributeProvenance
+
+	// refused holds the last refusal of an
+	// analysis to overwrite an attribute.
+	refused *OverwriteError
+}
+
+// tracing returns the receiver.
+func (s *analysisState) tracing() *analysisState {
+	return s
+}
+
+// setProvenance records that the attributes in attrs were written by the
+// named analysis of g with the provided parameter key-value pairs.
+func setProvenance(g analysed, attrs []string, name string, params ...interface{}) {
+	m := paramMap(params...)
+	now := time.Now()
+	s := g.tracing()
+	for _, attr := range attrs {
+		s.setProvenanceRecord(attr, AttributeProvenance{
+			Attribute: attr,
+			Analysis:  name,
+			Params:    m,
+			Time:      now,
+		})
+	}
+}
+
+// paramMap returns the parameter key-value pairs in params as a map, or
+// nil if there are no parameters.
+func paramMap(params ...interface{}) map[string]string {
+	if len(params) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(params)/2)
+	for i := 0; i+1 < len(params); i += 2 {
+		m[fmt.Sprint(params[i])] = fmt.Sprint(params[i+1])
+	}
+	return m
+}
+
+func (s *analysisState) setProvenanceRecord(attr string, p AttributeProvenance) {
+	if s.provenance == nil {
+		s.provenance = make(map[string]AttributeProvenance)
+	}
+	s.provenance[attr] = p
+}
@@ -0,0 +1,98 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProvenance(t *testing.T) {
+	g := graphFromDOT(t, `graph { a [x=1]; b [x=2]; a -- b }`)
+	if _, ok := Provenance(g, "rank"); ok {
+		t.Error("unexpected provenance before analysis")
+	}
+
+	PageRank(g, 0.85, 1e-6)
+	p, ok := Provenance(g, "rank")
+	if !ok {
+		t.Fatal("missing provenance for rank")
+	}
+	want := map[string]string{"damp": "0.85", "tol": "1e-06"}
+	if p.Attribute != "rank" || p.Analysis != "PageRank" || !reflect.DeepEqual(p.Params, want) {
+		t.Errorf("unexpected provenance: got:%+v", p)
+	}
+	if wantCall := "PageRank(damp=0.85, tol=1e-06)"; p.call() != wantCall {
+		t.Errorf("unexpected call: got:%s want:%s", p.call(), wantCall)
+	}
+	p.Time = time.Date(2017, 6, 1, 10, 0, 0, 0, time.UTC)
+	if got, want := p.String(), "rank ← PageRank(damp=0.85, tol=1e-06) at 2017-06-01T10:00:00Z"; got != want {
+		t.Errorf("unexpected string: got:%s want:%s", got, want)
+	}
+
+	err := BinAttribute(g, "x", 2, QuantileBins)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, ok = Provenance(g, "x_bin")
+	if !ok || p.Analysis != "BinAttribute" || p.Params["attr"] != "x" || p.Params["bins"] != "2" {
+		t.Errorf("unexpected provenance for x_bin: got:%+v ok:%t", p, ok)
+	}
+
+	// The most recent analysis is reported.
+	PageRank(g, 0.5, 1e-6)
+	p, _ = Provenance(g, "rank")
+	if p.Params["damp"] != "0.5" {
+		t.Errorf("unexpected damping parameter after second analysis: got:%s want:0.5", p.Params["damp"])
+	}
+}
+
+func TestProvenanceFailedAnalysis(t *testing.T) {
+	l := &testLogger{}
+	SetLogger(l)
+	defer SetLogger(nil)
+	SetRecordTimings(true)
+	defer SetRecordTimings(false)
+	ResetTimings()
+	defer ResetTimings()
+
+	g := graphFromDOT(t, `graph { a -- b [w=-1]; b -- c }`)
+	Communities(g, 1)
+	want, ok := Provenance(g, "community")
+	if !ok {
+		t.Fatal("missing provenance for community")
+	}
+	timings := len(Timings())
+
+	l.events = nil
+	err := CommunitiesWithOptions(g, CommunityOptions{Resolution: 5, Weight: "w"})
+	if err == nil {
+		t.Fatal("expected error for negative weight")
+	}
+	if got, _ := Provenance(g, "community"); !reflect.DeepEqual(got, want) {
+		t.Errorf("provenance changed by failed analysis:\ngot: %+v\nwant:%+v", got, want)
+	}
+	if got := len(Timings()); got != timings {
+		t.Errorf("timing recorded for failed analysis: got:%d want:%d", got, timings)
+	}
+	for _, e := range l.events {
+		if e.msg == "analysis done" {
+			t.Errorf("completion logged for failed analysis: %+v", e)
+		}
+	}
+	if last := l.events[len(l.events)-1]; last.msg != "analysis failed" || last.level != "warn" || last.arg("error") != err {
+		t.Errorf("unexpected final event for failed analysis: %+v", last)
+	}
+
+	h := graphFromDOT(t, `graph { a -- b [weight=heavy] }`)
+	err = Strength(h)
+	if err == nil {
+		t.Fatal("expected error for invalid weight")
+	}
+	if p, ok := Provenance(h, "strength"); ok {
+		t.Errorf("unexpected provenance for failed analysis: %+v", p)
+	}
+}
@@ -0,0 +1,410 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// AnalysisFunc is an analysis that can be registered by name and run with
+// Run. Parameters are passed as strings keyed by parameter name, and an
+// AnalysisFunc should use a sensible default for any parameter that is
+// not set.
+type AnalysisFunc func(g *Graph, params map[string]string) error
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]AnalysisFunc)
+	parsers    = make(map[string]parser)
+)
+
+// Register makes an analysis available to Run under the provided name.
+// Register panics if fn is nil or if an analysis with the same name has
+// already been registered. The package's own analyses are registered
+// under their function names, for example "PageRank".
+func Register(name string, fn AnalysisFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if fn == nil {
+		panic("graphprac: Register analysis is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("graphprac: Register called twice for analysis " + name)
+	}
+	registry[name] = fn
+}
+
+// Run performs the analysis registered under name on g with the provided
+// parameters. It returns an error if no analysis is registered under name,
+// if a parameter cannot be parsed, if the analysis fails or if it refuses
+// to overwrite an attribute, as described by SetAnalysisOptions.
+func Run(g *Graph, name string, params map[string]string) error {
+	registryMu.RLock()
+	fn, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown analysis %q", name)
+	}
+	g.refused = nil
+	err := fn(g, params)
+	if e, ok := err.(*OverwriteError); ok {
+		return e
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	if g.refused != nil {
+		// Analyses without an error result
+		// only record their refusal.
+		return g.refused
+	}
+	return nil
+}
+
+// ValidateParams returns an error if no analysis is registered under name
+// or if params are not valid for the package's own analysis registered
+// under name. Parameters of analyses registered by Register are not
+// checked. ValidateParams does not need a graph, so it can be used to
+// reject a request before waiting to run the analysis.
+func ValidateParams(name string, params map[string]string) error {
+	registryMu.RLock()
+	_, ok := registry[name]
+	parse := parsers[name]
+	registryMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown analysis %q", name)
+	}
+	if parse == nil {
+		return nil
+	}
+	_, err := parse(params)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
+
+// Analysis describes a run of a registered analysis that writes a
+// numerical node attribute.
+type Analysis struct {
+	// Name and Params are the name and
+	// parameters of the analysis, as
+	// passed to Run.
+	Name   string
+	Params map[string]string
+
+	// Attr is the node attribute written
+	// by the analysis, for example "rank"
+	// for "PageRank".
+	Attr string
+}
+
+// values runs a on g and returns the value of a.Attr for each node of g
+// keyed by node ID. Nodes without the attribute are omitted.
+func (a Analysis) values(g *Graph) (map[int64]float64, error) {
+	err := Run(g, a.Name, a.Params)
+	if err != nil {
+		return nil, err
+	}
+	vals := make(map[int64]float64)
+	for _, n := range NodesOf(g) {
+		s := n.GetUnquoted(a.Attr)
+		if s == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid %s value for node %q: %v", a.Name, a.Attr, n.Name, err)
+		}
+		vals[n.ID()] = v
+	}
+	return vals, nil
+}
+
+// Analyses returns the names of the registered analyses in lexical order.
+func Analyses() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	noParams := func(fn func(*Graph)) AnalysisFunc {
+		return func(g *Graph, _ map[string]string) error {
+			fn(g)
+			return nil
+		}
+	}
+	Register("Closeness", noParams(Closeness))
+	Register("Farness", noParams(Farness))
+	Register("Harmonic", noParams(Harmonic))
+	Register("Eccentricity", noParams(Eccentricity))
+	Register("Betweenness", noParams(Betweenness))
+	Register("EdgeBetweenness", noParams(EdgeBetweenness))
+	Register("Triangles", noParams(Triangles))
+	Register("Clustering", noParams(Clustering))
+	Register("CommunicabilityCentrality", noParams(CommunicabilityCentrality))
+	Register("Strength", func(g *Graph, _ map[string]string) error { return Strength(g) })
+	Register("WeightedClustering", func(g *Graph, _ map[string]string) error { return WeightedClustering(g) })
+
+	register("PageRank", func(params map[string]string) (func(*Graph) error, error) {
+		damp, err := floatParam(params, "damp", 0.85)
+		if err != nil {
+			return nil, err
+		}
+		tol, err := floatParam(params, "tol", 1e-6)
+		if err != nil {
+			return nil, err
+		}
+		if !(damp >= 0 && damp < 1) {
+			return nil, fmt.Errorf("invalid damping factor: %v not in [0,1)", damp)
+		}
+		if !(tol > 0) {
+			return nil, fmt.Errorf("invalid tolerance: %v not positive", tol)
+		}
+		return func(g *Graph) error {
+			PageRank(g, damp, tol)
+			return nil
+		}, nil
+	})
+	register("Communities", func(params map[string]string) (func(*Graph) error, error) {
+		res, err := floatParam(params, "resolution", 1)
+		if err != nil {
+			return nil, err
+		}
+		return func(g *Graph) error {
+			return CommunitiesWithOptions(g, CommunityOptions{Resolution: res, Weight: params["weight"]})
+		}, nil
+	})
+	register("Leiden", func(params map[string]string) (func(*Graph) error, error) {
+		res, err := floatParam(params, "resolution", 1)
+		if err != nil {
+			return nil, err
+		}
+		seed, err := intParam(params, "seed", 1)
+		if err != nil {
+			return nil, err
+		}
+		return func(g *Graph) error {
+			return Leiden(g, res, int64(seed))
+		}, nil
+	})
+	register("Infomap", func(params map[string]string) (func(*Graph) error, error) {
+		trials, err := intParam(params, "trials", 10)
+		if err != nil {
+			return nil, err
+		}
+		seed, err := intParam(params, "seed", 1)
+		if err != nil {
+			return nil, err
+		}
+		if trials < 1 {
+			return nil, fmt.Errorf("invalid number of trials: %d", trials)
+		}
+		return func(g *Graph) error {
+			_, err := Infomap(g, trials, int64(seed))
+			return err
+		}, nil
+	})
+	register("ConsensusCommunities", func(params map[string]string) (func(*Graph) error, error) {
+		runs, err := intParam(params, "runs", 10)
+		if err != nil {
+			return nil, err
+		}
+		res, err := floatParam(params, "resolution", 1)
+		if err != nil {
+			return nil, err
+		}
+		seed, err := intParam(params, "seed", 1)
+		if err != nil {
+			return nil, err
+		}
+		if runs < 1 {
+			return nil, fmt.Errorf("invalid number of runs: %d", runs)
+		}
+		return func(g *Graph) error {
+			ConsensusCommunities(g, runs, res, int64(seed))
+			return nil
+		}, nil
+	})
+	register("ClosenessApprox", func(params map[string]string) (func(*Graph) error, error) {
+		pivots, err := intParam(params, "pivots", 100)
+		if err != nil {
+			return nil, err
+		}
+		seed, err := intParam(params, "seed", 1)
+		if err != nil {
+			return nil, err
+		}
+		if pivots < 1 {
+			return nil, fmt.Errorf("invalid number of pivots: %d", pivots)
+		}
+		return func(g *Graph) error {
+			ClosenessApprox(g, pivots, int64(seed))
+			return nil
+		}, nil
+	})
+	register("AnnealCommunities", func(params map[string]string) (func(*Graph) error, error) {
+		var s AnnealSchedule
+		var err error
+		s.Start, err = floatParam(params, "start", 1)
+		if err != nil {
+			return nil, err
+		}
+		s.Stop, err = floatParam(params, "stop", 0.01)
+		if err != nil {
+			return nil, err
+		}
+		s.Cooling, err = floatParam(params, "cooling", 0.95)
+		if err != nil {
+			return nil, err
+		}
+		s.Moves, err = intParam(params, "moves", 50)
+		if err != nil {
+			return nil, err
+		}
+		seed, err := intParam(params, "seed", 1)
+		if err != nil {
+			return nil, err
+		}
+		if s.Stop <= 0 || s.Start < s.Stop || s.Cooling <= 0 || s.Cooling >= 1 || s.Moves < 1 {
+			return nil, fmt.Errorf("invalid anneal schedule: %+v", s)
+		}
+		return func(g *Graph) error {
+			_, err := AnnealCommunities(g, s, int64(seed))
+			return err
+		}, nil
+	})
+	register("SignedCommunities", func(params map[string]string) (func(*Graph) error, error) {
+		seed, err := intParam(params, "seed", 1)
+		if err != nil {
+			return nil, err
+		}
+		return func(g *Graph) error {
+			SignedCommunities(g, int64(seed))
+			return nil
+		}, nil
+	})
+	register("StructuralEquivalence", func(params map[string]string) (func(*Graph) error, error) {
+		k, err := intParam(params, "k", 5)
+		if err != nil {
+			return nil, err
+		}
+		if k < 1 {
+			return nil, fmt.Errorf("invalid number of roles: %d", k)
+		}
+		return func(g *Graph) error {
+			if n := g.Nodes().Len(); k > n {
+				return fmt.Errorf("invalid number of roles: %d", k)
+			}
+			StructuralEquivalence(g, k)
+			return nil
+		}, nil
+	})
+	register("ExtractRoles", func(params map[string]string) (func(*Graph) error, error) {
+		roles, err := intParam(params, "roles", 4)
+		if err != nil {
+			return nil, err
+		}
+		seed, err := intParam(params, "seed", 1)
+		if err != nil {
+			return nil, err
+		}
+		if roles < 1 {
+			return nil, fmt.Errorf("invalid number of roles: %d", roles)
+		}
+		var features []string
+		if f := params["features"]; f != "" {
+			features = strings.Split(f, ",")
+		}
+		return func(g *Graph) error {
+			_, err := ExtractRoles(g, features, roles, int64(seed))
+			return err
+		}, nil
+	})
+	register("Clique", func(params map[string]string) (func(*Graph) error, error) {
+		k, err := intParam(params, "k", 3)
+		if err != nil {
+			return nil, err
+		}
+		if k < 2 {
+			return nil, fmt.Errorf("invalid clique size: %d less than 2", k)
+		}
+		return func(g *Graph) error {
+			Clique(g, k)
+			return nil
+		}, nil
+	})
+	register("LinkCommunities", func(params map[string]string) (func(*Graph) error, error) {
+		threshold, err := floatParam(params, "threshold", 0.2)
+		if err != nil {
+			return nil, err
+		}
+		if !(threshold >= 0 && threshold <= 1) {
+			return nil, fmt.Errorf("invalid similarity threshold: %v not in [0,1]", threshold)
+		}
+		return func(g *Graph) error {
+			LinkCommunities(g, threshold)
+			return nil
+		}, nil
+	})
+}
+
+// parser is a function that checks the parameters of a registered
+// analysis and returns a function that performs the analysis with them.
+type parser func(params map[string]string) (func(*Graph) error, error)
+
+// register registers the analysis returned by parse under the provided
+// name, retaining parse so the analysis's parameters can be checked by
+// ValidateParams without a graph.
+func register(name string, parse parser) {
+	Register(name, func(g *Graph, params map[string]string) error {
+		fn, err := parse(params)
+		if err != nil {
+			return err
+		}
+		return fn(g)
+	})
+	registryMu.Lock()
+	parsers[name] = parse
+	registryMu.Unlock()
+}
+
+// floatParam returns the value of the numerical parameter key in params,
+// or def if it is not set.
+func floatParam(params map[string]string, key string, def float64) (float64, error) {
+	v, ok := params[key]
+	if !ok {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s parameter: %v", key, err)
+	}
+	return f, nil
+}
+
+// intParam returns the value of the integer parameter key in params,
+// or def if it is not set.
+func intParam(params map[string]string, key string, def int) (int, error) {
+	v, ok := params[key]
+	if !ok {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s parameter: %v", key, err)
+	}
+	return i, nil
+}
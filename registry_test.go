@@ -0,0 +1,153 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+func TestRun(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b -- c }`)
+	err := Run(g, "Eccentricity", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, want := range map[string]string{"a": "2", "b": "1", "c": "2"} {
+		if got := g.NodeNamed(name).Get("eccentricity"); got != want {
+			t.Errorf("unexpected eccentricity for %s: got:%s want:%s", name, got, want)
+		}
+	}
+
+	for _, test := range []struct {
+		name   string
+		params map[string]string
+	}{
+		{name: "NoSuchAnalysis"},
+		{name: "PageRank", params: map[string]string{"damp": "high"}},
+		{name: "Clique", params: map[string]string{"k": "3.5"}},
+		{name: "ConsensusCommunities", params: map[string]string{"runs": "0"}},
+		{name: "PageRank", params: map[string]string{"damp": "1"}},
+		{name: "PageRank", params: map[string]string{"damp": "2"}},
+		{name: "PageRank", params: map[string]string{"damp": "-0.1"}},
+		{name: "PageRank", params: map[string]string{"damp": "NaN"}},
+		{name: "PageRank", params: map[string]string{"tol": "0"}},
+		{name: "PageRank", params: map[string]string{"tol": "-1e-6"}},
+		{name: "PageRank", params: map[string]string{"tol": "NaN"}},
+		{name: "Clique", params: map[string]string{"k": "1"}},
+		{name: "Clique", params: map[string]string{"k": "-3"}},
+		{name: "LinkCommunities", params: map[string]string{"threshold": "-0.1"}},
+		{name: "LinkCommunities", params: map[string]string{"threshold": "1.5"}},
+		{name: "LinkCommunities", params: map[string]string{"threshold": "NaN"}},
+	} {
+		if err := Run(g, test.name, test.params); err == nil {
+			t.Errorf("expected error for %s with %v", test.name, test.params)
+		}
+	}
+
+	for _, test := range []struct {
+		name   string
+		params map[string]string
+	}{
+		{name: "PageRank", params: map[string]string{"damp": "0", "tol": "1e-3"}},
+		{name: "Clique", params: map[string]string{"k": "2"}},
+		{name: "LinkCommunities", params: map[string]string{"threshold": "0"}},
+		{name: "LinkCommunities", params: map[string]string{"threshold": "1"}},
+	} {
+		if err := Run(g, test.name, test.params); err != nil {
+			t.Errorf("unexpected error for %s with %v: %v", test.name, test.params, err)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	const name = "test.Mark"
+	errFailed := errors.New("failed")
+	Register(name, func(g *Graph, params map[string]string) error {
+		if params["fail"] != "" {
+			return errFailed
+		}
+		for _, n := range NodesOf(g) {
+			n.SetAttribute(encoding.Attribute{Key: "mark", Value: params["value"]})
+		}
+		return nil
+	})
+
+	names := Analyses()
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("analysis names not sorted: %v", names)
+	}
+	for _, want := range []string{name, "PageRank", "Leiden", "Infomap"} {
+		i := sort.SearchStrings(names, want)
+		if i == len(names) || names[i] != want {
+			t.Errorf("missing analysis %s", want)
+		}
+	}
+
+	g := graphFromDOT(t, `graph { a -- b }`)
+	err := Run(g, name, map[string]string{"value": "x"})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got := g.NodeNamed("a").Get("mark"); got != "x" {
+		t.Errorf("unexpected mark: got:%s want:x", got)
+	}
+	err = Run(g, name, map[string]string{"fail": "yes"})
+	if err == nil || err.Error() != name+": failed" {
+		t.Errorf("unexpected error: got:%v want:%s: failed", err, name)
+	}
+
+	panicked := func(fn AnalysisFunc) (ok bool) {
+		defer func() { ok = recover() != nil }()
+		Register(name, fn)
+		return false
+	}
+	if !panicked(func(*Graph, map[string]string) error { return nil }) {
+		t.Error("expected panic for duplicate registration")
+	}
+	if !panicked(nil) {
+		t.Error("expected panic for nil analysis")
+	}
+}
+
+var validateParamsTests = []struct {
+	name    string
+	params  map[string]string
+	wantErr bool
+}{
+	{name: "NoSuchAnalysis", wantErr: true},
+	{name: "Eccentricity"},
+	{name: "PageRank"},
+	{name: "PageRank", params: map[string]string{"damp": "0.5", "tol": "1e-3"}},
+	{name: "PageRank", params: map[string]string{"damp": "2"}, wantErr: true},
+	{name: "PageRank", params: map[string]string{"tol": "0"}, wantErr: true},
+	{name: "StructuralEquivalence", params: map[string]string{"k": "100"}},
+	{name: "StructuralEquivalence", params: map[string]string{"k": "0"}, wantErr: true},
+	{name: "Clique", params: map[string]string{"k": "1"}, wantErr: true},
+	{name: "LinkCommunities", params: map[string]string{"threshold": "1.5"}, wantErr: true},
+}
+
+func TestValidateParams(t *testing.T) {
+	for i, test := range validateParamsTests {
+		err := ValidateParams(test.name, test.params)
+		if (err != nil) != test.wantErr {
+			t.Errorf("unexpected error for test %d %s with %v: got:%v want error:%t",
+				i, test.name, test.params, err, test.wantErr)
+		}
+	}
+}
+
+func TestRunEmpty(t *testing.T) {
+	for _, name := range []string{"PageRank", "ExtractRoles"} {
+		g := graphFromDOT(t, `graph {}`)
+		err := Run(g, name, nil)
+		if err != nil {
+			t.Errorf("unexpected error for %s on empty graph: %v", name, err)
+		}
+	}
+}
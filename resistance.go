@@ -0,0 +1,165 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/mat"
+)
+
+// EffectiveResistance returns the effective resistance between the nodes
+// with the DOT IDs u and v when each edge of g is a unit resistor. The
+// resistance between nodes in different connected components is +Inf.
+//
+// The resistance is found by grounding v and solving the reduced
+// Laplacian system of the connected component holding u and v.
+func EffectiveResistance(g *Graph, u, v string) (float64, error) {
+	s := g.NodeNamed(u)
+	if s == nil {
+		return 0, fmt.Errorf("no node %q", u)
+	}
+	t := g.NodeNamed(v)
+	if t == nil {
+		return 0, fmt.Errorf("no node %q", v)
+	}
+	if s == t {
+		return 0, nil
+	}
+	comp := hops(g, s.ID())
+	if _, ok := comp[t.ID()]; !ok {
+		return math.Inf(1), nil
+	}
+
+	index := make(map[int64]int, len(comp)-1)
+	for id := range comp {
+		if id != t.ID() {
+			index[id] = len(index)
+		}
+	}
+	l := mat.NewSymDense(len(index), nil)
+	for id, i := range index {
+		to := graph.NodesOf(g.From(id))
+		l.SetSym(i, i, float64(len(to)))
+		for _, n := range to {
+			if j, ok := index[n.ID()]; ok {
+				l.SetSym(i, j, -1)
+			}
+		}
+	}
+	var chol mat.Cholesky
+	if !chol.Factorize(l) {
+		return 0, fmt.Errorf("failed to factorize reduced laplacian")
+	}
+	b := mat.NewVecDense(len(index), nil)
+	b.SetVec(index[s.ID()], 1)
+	var x mat.VecDense
+	err := chol.SolveVecTo(&x, b)
+	if err != nil {
+		return 0, err
+	}
+	return x.AtVec(index[s.ID()]), nil
+}
+
+// ResistanceMatrix returns the effective resistances between all pairs of
+// nodes of g when each edge is a unit resistor, with rows and columns
+// ordered by the dense node index returned by g.Index(). The resistance
+// between nodes in different connected components is +Inf. If g has no
+// nodes, the returned matrix is empty.
+//
+// The resistances are found from the Moore-Penrose pseudoinverse of the
+// graph Laplacian, L⁺, as R[i,j] = L⁺[i,i] + L⁺[j,j] - 2L⁺[i,j]. For each
+// connected component of n nodes, L⁺ differs from (L + J/n)⁻¹, where J is
+// the matrix of ones, by J/n, which cancels in the sum, so the inverse is
+// used in its place.
+func ResistanceMatrix(g *Graph) (*mat.SymDense, error) {
+	x := g.Index()
+	n := x.Len()
+	if n == 0 {
+		return &mat.SymDense{}, nil
+	}
+	adj := x.adjacency(g)
+	comp := components(adj)
+	members := make(map[int][]int)
+	for i, c := range comp {
+		members[c] = append(members[c], i)
+	}
+
+	r := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if comp[i] != comp[j] {
+				r.SetSym(i, j, math.Inf(1))
+			}
+		}
+	}
+	for _, nodes := range members {
+		m := len(nodes)
+		if m == 1 {
+			continue
+		}
+		local := make(map[int]int, m)
+		for k, i := range nodes {
+			local[i] = k
+		}
+		l := mat.NewSymDense(m, nil)
+		for k, i := range nodes {
+			for h := k; h < m; h++ {
+				l.SetSym(k, h, 1/float64(m))
+			}
+			l.SetSym(k, k, float64(len(adj[i]))+1/float64(m))
+			for _, j := range adj[i] {
+				l.SetSym(k, local[j], -1+1/float64(m))
+			}
+		}
+		var chol mat.Cholesky
+		if !chol.Factorize(l) {
+			return nil, fmt.Errorf("failed to factorize laplacian")
+		}
+		var p mat.SymDense
+		err := chol.InverseTo(&p)
+		if err != nil {
+			return nil, err
+		}
+		for k, i := range nodes {
+			for h := k + 1; h < m; h++ {
+				r.SetSym(i, nodes[h], p.At(k, k)+p.At(h, h)-2*p.At(k, h))
+			}
+		}
+	}
+	return r, nil
+}
+
+// components returns the connected component label of each node of the
+// graph with adjacency lists adj.
+func components(adj [][]int) []int {
+	comp := make([]int, len(adj))
+	for i := range comp {
+		comp[i] = -1
+	}
+	var queue []int
+	label := 0
+	for s := range adj {
+		if comp[s] >= 0 {
+			continue
+		}
+		comp[s] = label
+		queue = append(queue[:0], s)
+		for len(queue) != 0 {
+			u := queue[0]
+			queue = queue[1:]
+			for _, v := range adj[u] {
+				if comp[v] < 0 {
+					comp[v] = label
+					queue = append(queue, v)
+				}
+			}
+		}
+		label++
+	}
+	return comp
+}
@@ -0,0 +1,87 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// The triangle a, b, c with the pendant d on c, the 4-cycle
+// e, f, g, h and the isolated node i.
+const resistanceGraph = `graph {
+	a -- b -- c -- a; c -- d;
+	e -- f -- g -- h -- e;
+	i;
+}`
+
+var resistanceTests = []struct {
+	u, v string
+	want float64
+}{
+	{u: "a", v: "a", want: 0},
+	{u: "a", v: "b", want: 2.0 / 3},
+	{u: "c", v: "d", want: 1},
+	{u: "a", v: "d", want: 5.0 / 3},
+	{u: "e", v: "f", want: 3.0 / 4},
+	{u: "e", v: "g", want: 1},
+	{u: "a", v: "e", want: math.Inf(1)},
+	{u: "i", v: "a", want: math.Inf(1)},
+}
+
+func TestEffectiveResistance(t *testing.T) {
+	g := graphFromDOT(t, resistanceGraph)
+	for i, test := range resistanceTests {
+		got, err := EffectiveResistance(g, test.u, test.v)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		if !sameFloat(got, test.want) {
+			t.Errorf("unexpected resistance for test %d: got:%v want:%v", i, got, test.want)
+		}
+	}
+	if _, err := EffectiveResistance(g, "a", "z"); err == nil {
+		t.Error("expected error for missing node")
+	}
+}
+
+func TestResistanceMatrix(t *testing.T) {
+	g := graphFromDOT(t, resistanceGraph)
+	r, err := ResistanceMatrix(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	x := g.Index()
+	for i, test := range resistanceTests {
+		u, v := x.Index(g.NodeNamed(test.u).ID()), x.Index(g.NodeNamed(test.v).ID())
+		for _, got := range []float64{r.At(u, v), r.At(v, u)} {
+			if !sameFloat(got, test.want) {
+				t.Errorf("unexpected resistance for test %d: got:%v want:%v", i, got, test.want)
+			}
+		}
+	}
+}
+
+func TestResistanceMatrixEmpty(t *testing.T) {
+	r, err := ResistanceMatrix(graphFromDOT(t, `graph {}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !r.IsEmpty() {
+		t.Errorf("unexpected resistance matrix for empty graph: got:%v", mat.Formatted(r))
+	}
+}
+
+// sameFloat returns whether a and b are equal within a tolerance,
+// or are both the same infinity.
+func sameFloat(a, b float64) bool {
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return a == b
+	}
+	return math.Abs(a-b) < 1e-10
+}
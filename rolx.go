@@ -0,0 +1,227 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/mat"
+)
+
+// structuralFeatures are the node features calculated by ExtractRoles
+// when they are not set as node attributes.
+var structuralFeatures = []string{"degree", "triangles", "egonet_edges", "egonet_boundary"}
+
+// ExtractRoles discovers roles in g in the manner of RolX (Henderson et al.
+// doi:10.1145/2339530.2339723), by non-negative matrix factorisation of a
+// matrix of node features, V ≈ WH, where each row of W holds the soft
+// membership of a node in each role and each row of H holds the feature
+// profile of a role. Unlike StructuralEquivalence, nodes in different
+// parts of the graph can share a role if they have similar features.
+//
+// Each feature is the numerical value of the named node attribute, for
+// example "rank" written by PageRank or "betweenness" written by
+// Betweenness, except for the structural features "degree", "triangles",
+// "egonet_edges" and "egonet_boundary", which are calculated if they are
+// not set as attributes. The egonet of a node is the node and its
+// neighbours, and "egonet_edges" and "egonet_boundary" are the numbers of
+// edges within and leaving it. If features is empty, the structural
+// features are used. Each feature is scaled so that its largest value is
+// 1. The factorisation uses multiplicative updates from a random start
+// determined by seed.
+//
+// ExtractRoles returns the role profiles, H, with a row for each role and
+// a column for each feature. If g has no nodes, the profiles are all
+// zero. It returns an error if a feature is missing
+// from a node or is not a non-negative number.
+//
+// ExtractRoles panics if roles is less than one.
+//
+// The membership of each node in role r, scaled so that the memberships
+// of each node sum to 1, is written into the "role_r" attribute of each
+// node, for example "role_0".
+func ExtractRoles(g *Graph, features []string, roles int, seed int64) (profiles *mat.Dense, err error) {
+	if roles < 1 {
+		panic("graphprac: invalid number of roles")
+	}
+	if len(features) == 0 {
+		features = structuralFeatures
+	}
+	attrs := make([]string, roles)
+	for r := range attrs {
+		attrs[r] = fmt.Sprintf("role_%d", r)
+	}
+	done, err := trace(g, "ExtractRoles", attrs, "features", strings.Join(features, ","), "roles", roles, "seed", seed)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { done(err) }()
+
+	if g.Nodes().Len() == 0 {
+		return mat.NewDense(roles, len(features), nil), nil
+	}
+	v, err := featureMatrix(g, features)
+	if err != nil {
+		return nil, err
+	}
+	n, f := v.Dims()
+
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	w := mat.NewDense(n, roles, nil)
+	h := mat.NewDense(roles, f, nil)
+	for _, m := range []*mat.Dense{w, h} {
+		r, c := m.Dims()
+		for i := 0; i < r; i++ {
+			for j := 0; j < c; j++ {
+				m.Set(i, j, rnd.Float64()+0.01)
+			}
+		}
+	}
+	nmf(v, w, h)
+
+	x := g.Index()
+	for i := 0; i < n; i++ {
+		row := w.RawRowView(i)
+		total := sum(row)
+		for r, m := range row {
+			if total != 0 {
+				m /= total
+			}
+			x.Node(i).SetAttribute(encoding.Attribute{Key: attrs[r], Value: fmt.Sprint(m)})
+		}
+	}
+	return h, nil
+}
+
+// featureMatrix returns the matrix of the named node features of g with
+// a row for each node ordered by g.Index() and each column scaled so that
+// its maximum is 1.
+func featureMatrix(g *Graph, features []string) (*mat.Dense, error) {
+	x := g.Index()
+	n := x.Len()
+	v := mat.NewDense(n, len(features), nil)
+
+	var structural map[string][]float64
+	for j, name := range features {
+		col := make([]float64, n)
+		for i := range col {
+			s := x.Node(i).GetUnquoted(name)
+			if s == "" {
+				if structural == nil {
+					structural = structuralFeatureValues(g, x)
+				}
+				vals, ok := structural[name]
+				if !ok {
+					return nil, fmt.Errorf("no %s feature for node %q", name, x.Node(i).Name)
+				}
+				col[i] = vals[i]
+				continue
+			}
+			val, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s feature for node %q: %v", name, x.Node(i).Name, err)
+			}
+			if val < 0 || math.IsNaN(val) || math.IsInf(val, 0) {
+				return nil, fmt.Errorf("invalid %s feature for node %q: %v", name, x.Node(i).Name, val)
+			}
+			col[i] = val
+		}
+		var max float64
+		for _, val := range col {
+			max = math.Max(max, val)
+		}
+		for i, val := range col {
+			if max != 0 {
+				val /= max
+			}
+			v.Set(i, j, val)
+		}
+	}
+	return v, nil
+}
+
+// structuralFeatureValues returns the values of each of the structural
+// features for the nodes of g indexed by x.
+func structuralFeatureValues(g *Graph, x NodeIndex) map[string][]float64 {
+	adj := x.adjacency(g)
+	tri := triangles(g)
+	n := len(adj)
+	vals := make(map[string][]float64, len(structuralFeatures))
+	for _, name := range structuralFeatures {
+		vals[name] = make([]float64, n)
+	}
+	for i, to := range adj {
+		deg := float64(len(to))
+		t := float64(tri[x.ID(i)])
+		var neighbourDeg float64
+		for _, j := range to {
+			neighbourDeg += float64(len(adj[j]))
+		}
+		vals["degree"][i] = deg
+		vals["triangles"][i] = t
+		vals["egonet_edges"][i] = deg + t
+		vals["egonet_boundary"][i] = deg + neighbourDeg - 2*(deg+t)
+	}
+	return vals
+}
+
+// nmf updates the non-negative factors w and h of v to reduce the
+// Frobenius norm of v - wh using the multiplicative updates of Lee and
+// Seung, stopping when the relative reduction in the norm is small.
+func nmf(v, w, h *mat.Dense) {
+	const (
+		maxIter = 1000
+		tol     = 1e-6
+		eps     = 1e-12
+	)
+	n, f := v.Dims()
+	r, _ := h.Dims()
+	var (
+		numH  = mat.NewDense(r, f, nil)
+		denH  = mat.NewDense(r, f, nil)
+		numW  = mat.NewDense(n, r, nil)
+		denW  = mat.NewDense(n, r, nil)
+		hht   = mat.NewDense(r, r, nil)
+		wtw   = mat.NewDense(r, r, nil)
+		resid = mat.NewDense(n, f, nil)
+	)
+	var prev float64
+	for iter := 0; iter < maxIter; iter++ {
+		// H ← H ⊙ (WᵀV) ⊘ (WᵀWH)
+		numH.Mul(w.T(), v)
+		wtw.Mul(w.T(), w)
+		denH.Mul(wtw, h)
+		nmfUpdate(h, numH, denH, eps)
+
+		// W ← W ⊙ (VHᵀ) ⊘ (WHHᵀ)
+		numW.Mul(v, h.T())
+		hht.Mul(h, h.T())
+		denW.Mul(w, hht)
+		nmfUpdate(w, numW, denW, eps)
+
+		resid.Mul(w, h)
+		resid.Sub(v, resid)
+		norm := mat.Norm(resid, 2)
+		if iter != 0 && prev-norm <= tol*prev {
+			break
+		}
+		prev = norm
+	}
+}
+
+// nmfUpdate sets each element of m to m*num/(den+eps).
+func nmfUpdate(m, num, den *mat.Dense, eps float64) {
+	r, c := m.Dims()
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			m.Set(i, j, m.At(i, j)*num.At(i, j)/(den.At(i, j)+eps))
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestStructuralFeatureValues(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b -- c -- a; c -- d; e }`)
+	x := g.Index()
+	got := structuralFeatureValues(g, x)
+	want := map[string]map[string]float64{
+		"a": {"degree": 2, "triangles": 1, "egonet_edges": 3, "egonet_boundary": 1},
+		"b": {"degree": 2, "triangles": 1, "egonet_edges": 3, "egonet_boundary": 1},
+		"c": {"degree": 3, "triangles": 1, "egonet_edges": 4, "egonet_boundary": 0},
+		"d": {"degree": 1, "triangles": 0, "egonet_edges": 1, "egonet_boundary": 2},
+		"e": {"degree": 0, "triangles": 0, "egonet_edges": 0, "egonet_boundary": 0},
+	}
+	for name, features := range want {
+		i := x.Index(g.NodeNamed(name).ID())
+		for f, v := range features {
+			if got[f][i] != v {
+				t.Errorf("unexpected %s for %s: got:%v want:%v", f, name, got[f][i], v)
+			}
+		}
+	}
+}
+
+func TestFeatureMatrix(t *testing.T) {
+	g := graphFromDOT(t, `graph { a [x=2]; b [x=4]; c [x=0]; a -- b -- c }`)
+	v, err := featureMatrix(g, []string{"x", "degree"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := mat.NewDense(3, 2, []float64{
+		0.5, 0.5,
+		1, 1,
+		0, 0.5,
+	})
+	if !mat.Equal(v, want) {
+		t.Errorf("unexpected feature matrix:\ngot:\n%v\nwant:\n%v", mat.Formatted(v), mat.Formatted(want))
+	}
+
+	for _, test := range []struct {
+		dot     string
+		feature string
+	}{
+		{dot: `graph { a [x=1]; b }`, feature: "x"},
+		{dot: `graph { a [x=1]; b [x=-1] }`, feature: "x"},
+		{dot: `graph { a [x=1]; b [x=many] }`, feature: "x"},
+	} {
+		if _, err := featureMatrix(graphFromDOT(t, test.dot), []string{test.feature}); err == nil {
+			t.Errorf("expected error for %s", test.dot)
+		}
+	}
+}
+
+func TestExtractRoles(t *testing.T) {
+	// Two stars have centre and leaf roles.
+	const stars = `graph { a -- {b c d e}; f -- {g h i j} }`
+	centres := []string{"a", "f"}
+	leaves := []string{"b", "c", "d", "e", "g", "h", "i", "j"}
+
+	g := graphFromDOT(t, stars)
+	h, err := ExtractRoles(g, nil, 2, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r, c := h.Dims(); r != 2 || c != len(structuralFeatures) {
+		t.Errorf("unexpected role profile dimensions: got:%d×%d want:2×%d", r, c, len(structuralFeatures))
+	}
+
+	role := func(name string) string {
+		a, b := floatAttr(t, g, name, "role_0"), floatAttr(t, g, name, "role_1")
+		if d := a + b - 1; d > 1e-12 || d < -1e-12 {
+			t.Errorf("role memberships of %s do not sum to 1: %v+%v", name, a, b)
+		}
+		if a > b {
+			return "role_0"
+		}
+		return "role_1"
+	}
+	centre := role("a")
+	for _, name := range centres {
+		if got := role(name); got != centre {
+			t.Errorf("unexpected role for centre %s: got:%s want:%s", name, got, centre)
+		}
+	}
+	for _, name := range leaves {
+		if got := role(name); got == centre {
+			t.Errorf("unexpected centre role for leaf %s", name)
+		}
+		// Nodes with the same features have the
+		// same memberships.
+		if got, want := g.NodeNamed(name).Get("role_0"), g.NodeNamed("b").Get("role_0"); got != want {
+			t.Errorf("unexpected membership for leaf %s: got:%s want:%s", name, got, want)
+		}
+	}
+
+	r := graphFromDOT(t, stars)
+	hr, _ := ExtractRoles(r, nil, 2, 1)
+	if !reflect.DeepEqual(hr, h) {
+		t.Error("unexpected role profiles for repeated seed")
+	}
+
+	if _, err := ExtractRoles(graphFromDOT(t, stars), []string{"rank"}, 2, 1); err == nil {
+		t.Error("expected error for missing feature")
+	}
+
+	h, err = ExtractRoles(graphFromDOT(t, `graph {}`), nil, 2, 1)
+	if err != nil {
+		t.Errorf("unexpected error for empty graph: %v", err)
+	} else if want := mat.NewDense(2, len(structuralFeatures), nil); !mat.Equal(h, want) {
+		t.Errorf("unexpected role profiles for empty graph:\ngot:\n%v\nwant:\n%v", mat.Formatted(h), mat.Formatted(want))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for no roles")
+		}
+	}()
+	ExtractRoles(graphFromDOT(t, stars), nil, 0, 1)
+}
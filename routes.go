@@ -0,0 +1,281 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"sort"
+)
+
+// maxSimplePaths is the maximum number of paths returned by
+// AllSimplePaths.
+const maxSimplePaths = 100000
+
+// KShortestPaths returns up to k loopless paths between the nodes with the
+// DOT IDs from and to in order of increasing length, using Yen's
+// algorithm. Edges are treated as having unit weight, and paths of equal
+// length are returned in a deterministic order. Fewer than k paths are
+// returned if no more exist.
+func KShortestPaths(g *Graph, from, to string, k int) ([][]*Node, error) {
+	r, s, t, err := newRouter(g, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if k < 1 {
+		return nil, fmt.Errorf("invalid k: %d", k)
+	}
+
+	first := r.shortest(s, t)
+	if first == nil {
+		return nil, nil
+	}
+	paths := [][]int{first}
+	var candidates [][]int
+	for len(paths) < k {
+		last := paths[len(paths)-1]
+		for i := 0; i < len(last)-1; i++ {
+			spur, root := last[i], last[:i+1]
+
+			// Remove the edges leaving the root path that are
+			// used by the paths already found, and the nodes of
+			// the root path other than the spur node.
+			for _, p := range paths {
+				if len(p) > i+1 && equalPaths(p[:i+1], root) {
+					r.blockEdge(p[i], p[i+1])
+				}
+			}
+			for _, u := range root[:i] {
+				r.blockedNode[u] = true
+			}
+			tail := r.shortest(spur, t)
+			r.unblock()
+
+			if tail == nil {
+				continue
+			}
+			cand := append(append([]int(nil), root[:i]...), tail...)
+			if !containsPath(paths, cand) && !containsPath(candidates, cand) {
+				candidates = append(candidates, cand)
+			}
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		sort.Slice(candidates, func(i, j int) bool { return lessPath(candidates[i], candidates[j]) })
+		paths = append(paths, candidates[0])
+		candidates = candidates[1:]
+	}
+	return r.nodes(paths), nil
+}
+
+// AllSimplePaths returns all paths between the nodes with the DOT IDs from
+// and to that visit no node more than once and have at most maxLen edges.
+// The number of simple paths grows very rapidly with maxLen, so at most
+// 100000 paths are returned; if there are more, the paths found are
+// returned with an error.
+func AllSimplePaths(g *Graph, from, to string, maxLen int) ([][]*Node, error) {
+	r, s, t, err := newRouter(g, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if maxLen < 0 {
+		return nil, fmt.Errorf("invalid maximum length: %d", maxLen)
+	}
+
+	// Nodes further than the remaining length
+	// from t cannot be on a path.
+	remain := r.hops(t)
+
+	var (
+		paths [][]int
+		path  = []int{s}
+		full  bool
+	)
+	r.blockedNode[s] = true
+	var walk func(u int)
+	walk = func(u int) {
+		if u == t {
+			paths = append(paths, append([]int(nil), path...))
+			full = len(paths) > maxSimplePaths
+			return
+		}
+		for _, v := range r.adj[u] {
+			if full {
+				return
+			}
+			if r.blockedNode[v] || remain[v] < 0 || len(path)+remain[v] > maxLen {
+				continue
+			}
+			r.blockedNode[v] = true
+			path = append(path, v)
+			walk(v)
+			path = path[:len(path)-1]
+			r.blockedNode[v] = false
+		}
+	}
+	walk(s)
+
+	if full {
+		return r.nodes(paths[:maxSimplePaths]), fmt.Errorf("more than %d simple paths", maxSimplePaths)
+	}
+	return r.nodes(paths), nil
+}
+
+// router finds paths in a graph in terms of node indices.
+type router struct {
+	NodeIndex
+
+	// adj is the adjacency list of the graph
+	// with each list sorted by index.
+	adj [][]int
+
+	blockedNode []bool
+	blockedEdge map[[2]int]bool
+}
+
+// newRouter returns a router for g and the indices of the nodes with the
+// DOT IDs from and to.
+func newRouter(g *Graph, from, to string) (r *router, s, t int, err error) {
+	f := g.NodeNamed(from)
+	if f == nil {
+		return nil, -1, -1, fmt.Errorf("no node %q", from)
+	}
+	l := g.NodeNamed(to)
+	if l == nil {
+		return nil, -1, -1, fmt.Errorf("no node %q", to)
+	}
+	x := g.Index()
+	r = &router{
+		NodeIndex:   x,
+		adj:         x.adjacency(g),
+		blockedNode: make([]bool, x.Len()),
+		blockedEdge: make(map[[2]int]bool),
+	}
+	for _, a := range r.adj {
+		sort.Ints(a)
+	}
+	return r, x.Index(f.ID()), x.Index(l.ID()), nil
+}
+
+// blockEdge prevents shortest from using the edge between u and v.
+func (r *router) blockEdge(u, v int) {
+	r.blockedEdge[[2]int{u, v}] = true
+	r.blockedEdge[[2]int{v, u}] = true
+}
+
+// unblock removes all blocks on nodes and edges.
+func (r *router) unblock() {
+	for i := range r.blockedNode {
+		r.blockedNode[i] = false
+	}
+	for e := range r.blockedEdge {
+		delete(r.blockedEdge, e)
+	}
+}
+
+// shortest returns a shortest path from s to t that avoids blocked nodes
+// and edges, or nil if there is no such path.
+func (r *router) shortest(s, t int) []int {
+	prev := make([]int, r.Len())
+	for i := range prev {
+		prev[i] = -1
+	}
+	prev[s] = s
+	queue := []int{s}
+	for len(queue) != 0 && prev[t] < 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, v := range r.adj[u] {
+			if prev[v] >= 0 || r.blockedNode[v] || r.blockedEdge[[2]int{u, v}] {
+				continue
+			}
+			prev[v] = u
+			queue = append(queue, v)
+		}
+	}
+	if prev[t] < 0 {
+		return nil
+	}
+	var path []int
+	for v := t; v != s; v = prev[v] {
+		path = append(path, v)
+	}
+	path = append(path, s)
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// hops returns the number of hops from t to each node, or -1 for nodes
+// that cannot be reached.
+func (r *router) hops(t int) []int {
+	dist := make([]int, r.Len())
+	for i := range dist {
+		dist[i] = -1
+	}
+	dist[t] = 0
+	queue := []int{t}
+	for len(queue) != 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, v := range r.adj[u] {
+			if dist[v] < 0 {
+				dist[v] = dist[u] + 1
+				queue = append(queue, v)
+			}
+		}
+	}
+	return dist
+}
+
+// nodes returns the paths in terms of nodes.
+func (r *router) nodes(paths [][]int) [][]*Node {
+	n := make([][]*Node, len(paths))
+	for i, p := range paths {
+		n[i] = make([]*Node, len(p))
+		for j, u := range p {
+			n[i][j] = r.Node(u)
+		}
+	}
+	return n
+}
+
+// equalPaths returns whether a and b are the same path.
+func equalPaths(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// containsPath returns whether p is in paths.
+func containsPath(paths [][]int, p []int) bool {
+	for _, q := range paths {
+		if equalPaths(q, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// lessPath returns whether a is shorter than b, or lexically before b if
+// they have the same length.
+func lessPath(a, b []int) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
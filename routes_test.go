@@ -0,0 +1,98 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// routeGraph is a square a-b-d-c with a diagonal b--c and a tail d--e.
+const routeGraph = `graph { a -- b; a -- c; b -- c; b -- d; c -- d; d -- e; f }`
+
+// pathStrings returns the paths as strings of node names joined by "-".
+func pathStrings(paths [][]*Node) []string {
+	s := make([]string, len(paths))
+	for i, p := range paths {
+		names := make([]string, len(p))
+		for j, n := range p {
+			names[j] = n.Name
+		}
+		s[i] = strings.Join(names, "-")
+	}
+	return s
+}
+
+var kShortestTests = []struct {
+	from, to string
+	k        int
+	want     []string
+}{
+	{from: "a", to: "e", k: 1, want: []string{"a-b-d-e"}},
+	{from: "a", to: "e", k: 2, want: []string{"a-b-d-e", "a-c-d-e"}},
+	{from: "a", to: "e", k: 10, want: []string{"a-b-d-e", "a-c-d-e", "a-b-c-d-e", "a-c-b-d-e"}},
+	{from: "a", to: "a", k: 3, want: []string{"a"}},
+	{from: "a", to: "f", k: 3, want: nil},
+}
+
+func TestKShortestPaths(t *testing.T) {
+	g := graphFromDOT(t, routeGraph)
+	for i, test := range kShortestTests {
+		paths, err := KShortestPaths(g, test.from, test.to, test.k)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		var got []string
+		if len(paths) != 0 {
+			got = pathStrings(paths)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected paths for test %d: got:%q want:%q", i, got, test.want)
+		}
+	}
+
+	if _, err := KShortestPaths(g, "a", "z", 1); err == nil {
+		t.Error("expected error for missing node")
+	}
+	if _, err := KShortestPaths(g, "a", "e", 0); err == nil {
+		t.Error("expected error for invalid k")
+	}
+}
+
+var simplePathTests = []struct {
+	from, to string
+	maxLen   int
+	want     []string
+}{
+	{from: "a", to: "e", maxLen: 2, want: nil},
+	{from: "a", to: "e", maxLen: 3, want: []string{"a-b-d-e", "a-c-d-e"}},
+	{from: "a", to: "e", maxLen: 4, want: []string{"a-b-c-d-e", "a-b-d-e", "a-c-b-d-e", "a-c-d-e"}},
+	{from: "a", to: "d", maxLen: 10, want: []string{"a-b-c-d", "a-b-d", "a-c-b-d", "a-c-d"}},
+	{from: "a", to: "f", maxLen: 10, want: nil},
+}
+
+func TestAllSimplePaths(t *testing.T) {
+	g := graphFromDOT(t, routeGraph)
+	for i, test := range simplePathTests {
+		paths, err := AllSimplePaths(g, test.from, test.to, test.maxLen)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		var got []string
+		if len(paths) != 0 {
+			got = pathStrings(paths)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected paths for test %d: got:%q want:%q", i, got, test.want)
+		}
+	}
+
+	if _, err := AllSimplePaths(g, "a", "e", -1); err == nil {
+		t.Error("expected error for invalid maximum length")
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+// ScatterOptions controls the rendering of attribute scatter plots.
+type ScatterOptions struct {
+	// X and Y are the numerical node attributes
+	// plotted on the x and y axes.
+	X, Y string
+
+	// LogX and LogY specify that the x and y
+	// axes are logarithmic. Nodes with non-positive
+	// values on a logarithmic axis are not plotted.
+	LogX, LogY bool
+
+	// Outliers is the number of nodes with the
+	// largest residuals from a least squares linear
+	// fit, on the plotted scales, that are labelled
+	// with their DOT ID.
+	Outliers int
+}
+
+// PlotScatter renders a scatter plot of the xAttr and yAttr attributes
+// of the nodes of g as an SVG written to w. Nodes without both attributes
+// are not plotted.
+func PlotScatter(g *Graph, xAttr, yAttr string, logX, logY bool, w io.Writer) error {
+	return PlotScatterWithOptions(g, ScatterOptions{X: xAttr, Y: yAttr, LogX: logX, LogY: logY}, w)
+}
+
+// PlotScatterWithOptions renders a scatter plot of node attributes of g as
+// an SVG written to w using the provided options. Nodes without both
+// attributes are not plotted.
+func PlotScatterWithOptions(g *Graph, opts ScatterOptions, w io.Writer) error {
+	var (
+		nodes []*Node
+		xys   plotter.XYs
+	)
+	for _, n := range NodesOf(g) {
+		xs, ys := n.GetUnquoted(opts.X), n.GetUnquoted(opts.Y)
+		if xs == "" || ys == "" {
+			continue
+		}
+		x, err := strconv.ParseFloat(xs, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s for node %q: %v", opts.X, n.Name, err)
+		}
+		y, err := strconv.ParseFloat(ys, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s for node %q: %v", opts.Y, n.Name, err)
+		}
+		if (opts.LogX && x <= 0) || (opts.LogY && y <= 0) {
+			continue
+		}
+		nodes = append(nodes, n)
+		xys = append(xys, plotter.XY{X: x, Y: y})
+	}
+	if len(xys) == 0 {
+		return fmt.Errorf("no node has attributes %q and %q", opts.X, opts.Y)
+	}
+
+	p, err := plot.New()
+	if err != nil {
+		return err
+	}
+	p.X.Label.Text = opts.X
+	p.Y.Label.Text = opts.Y
+	if opts.LogX {
+		p.X.Scale = plot.LogScale{}
+		p.X.Tick.Marker = plot.LogTicks{}
+	}
+	if opts.LogY {
+		p.Y.Scale = plot.LogScale{}
+		p.Y.Tick.Marker = plot.LogTicks{}
+	}
+	s, err := plotter.NewScatter(xys)
+	if err != nil {
+		return err
+	}
+	s.GlyphStyle.Radius = vg.Points(2)
+	p.Add(s)
+
+	if opts.Outliers > 0 {
+		var labels plotter.XYLabels
+		for _, i := range outliers(xys, opts.LogX, opts.LogY, opts.Outliers) {
+			labels.XYs = append(labels.XYs, xys[i])
+			labels.Labels = append(labels.Labels, dotIDOf(nodes[i]))
+		}
+		l, err := plotter.NewLabels(labels)
+		if err != nil {
+			return err
+		}
+		l.XOffset = vg.Points(3)
+		p.Add(l)
+	}
+
+	return writePlot(p, w)
+}
+
+// outliers returns the indices of the k points of xys with the largest
+// absolute residuals from a least squares linear fit. If logX or logY is
+// true, the fit is performed on the logarithm of the corresponding
+// values.
+func outliers(xys plotter.XYs, logX, logY bool, k int) []int {
+	x := make([]float64, len(xys))
+	y := make([]float64, len(xys))
+	for i, p := range xys {
+		x[i], y[i] = p.X, p.Y
+		if logX {
+			x[i] = math.Log(x[i])
+		}
+		if logY {
+			y[i] = math.Log(y[i])
+		}
+	}
+	alpha, beta := stat.LinearRegression(x, y, nil, false)
+	resid := make([]float64, len(xys))
+	idx := make([]int, len(xys))
+	for i := range xys {
+		resid[i] = math.Abs(y[i] - (alpha + beta*x[i]))
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return resid[idx[i]] > resid[idx[j]] })
+	if k > len(idx) {
+		k = len(idx)
+	}
+	return idx[:k]
+}
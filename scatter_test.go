@@ -0,0 +1,91 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/plot/plotter"
+)
+
+var outliersTests = []struct {
+	xys        plotter.XYs
+	logX, logY bool
+	k          int
+	want       []int
+}{
+	{
+		// y = 2x + 1 with displaced points 2 and 4.
+		xys:  plotter.XYs{{X: 0, Y: 1}, {X: 1, Y: 3}, {X: 2, Y: 15}, {X: 3, Y: 7}, {X: 4, Y: 3}, {X: 5, Y: 11}},
+		k:    2,
+		want: []int{2, 4},
+	},
+	{
+		// y = 1.3x + 0.3 with residuals -0.3, -0.6, 2.1 and -1.2.
+		xys:  plotter.XYs{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 5}, {X: 3, Y: 3}},
+		k:    5,
+		want: []int{2, 3, 1, 0},
+	},
+	{
+		// y = x² on log-log axes with point 1 displaced.
+		xys:  plotter.XYs{{X: 1, Y: 1}, {X: 2, Y: 40}, {X: 4, Y: 16}, {X: 8, Y: 64}, {X: 16, Y: 256}},
+		logX: true, logY: true,
+		k:    1,
+		want: []int{1},
+	},
+	{
+		xys:  plotter.XYs{{X: 0, Y: 1}, {X: 1, Y: 2}},
+		k:    0,
+		want: []int{},
+	},
+}
+
+func TestOutliers(t *testing.T) {
+	for i, test := range outliersTests {
+		got := outliers(test.xys, test.logX, test.logY, test.k)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected outliers for test %d: got:%v want:%v", i, got, test.want)
+		}
+	}
+}
+
+func TestPlotScatter(t *testing.T) {
+	g := graphFromDOT(t, `graph {
+	a [x=1, y=2];
+	b [x=2, y=4];
+	c [x=3, y=9];
+	d [x=-1, y=1];
+	e [x=4];
+}`)
+	for _, opts := range []ScatterOptions{
+		{X: "x", Y: "y"},
+		{X: "x", Y: "y", LogX: true, LogY: true, Outliers: 1},
+	} {
+		var buf strings.Builder
+		err := PlotScatterWithOptions(g, opts, &buf)
+		if err != nil {
+			t.Errorf("unexpected error for %+v: %v", opts, err)
+			continue
+		}
+		checkSVG(t, buf.String())
+	}
+
+	for _, test := range []struct {
+		src  string
+		opts ScatterOptions
+	}{
+		{src: `graph { a [x=1]; }`, opts: ScatterOptions{X: "x", Y: "y"}},
+		{src: `graph { a [x=-1, y=1]; }`, opts: ScatterOptions{X: "x", Y: "y", LogX: true}},
+		{src: `graph { a [x=one, y=1]; }`, opts: ScatterOptions{X: "x", Y: "y"}},
+		{src: `graph { a [x=1, y=one]; }`, opts: ScatterOptions{X: "x", Y: "y"}},
+	} {
+		err := PlotScatterWithOptions(graphFromDOT(t, test.src), test.opts, &strings.Builder{})
+		if err == nil {
+			t.Errorf("expected error for %s with %+v", test.src, test.opts)
+		}
+	}
+}
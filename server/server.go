@@ -0,0 +1,291 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package server provides an HTTP API for remote graph analysis using the
+// analyses registered with graphprac.Register.
+//
+// The API provides the following endpoints:
+//
+//	GET    /analyses                       list the available analyses
+//	POST   /graphs                         upload a DOT graph, returning its ID
+//	GET    /graphs/{id}                    return the graph and its attributes as DOT
+//	DELETE /graphs/{id}                    discard the graph
+//	POST   /graphs/{id}/analyses/{name}    run an analysis on the graph
+//	GET    /graphs/{id}/top?attr=rank&n=10 return the nodes with the highest attribute values
+//
+// Analysis names are matched without regard to case, so the PageRank
+// analysis may be run with a POST to /graphs/1/analyses/pagerank. Analysis
+// parameters are given as URL query or form values, for example
+// /graphs/1/analyses/pagerank?damp=0.9. Results and errors are returned
+// as JSON, except for the DOT graph.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kortschak/graphprac"
+)
+
+// maxUpload is the maximum size of an uploaded graph in bytes.
+const maxUpload = 64 << 20
+
+// Server is an http.Handler serving the analysis API. Graphs are held in
+// memory for the lifetime of the Server.
+type Server struct {
+	mu     sync.Mutex
+	graphs map[string]*entry
+	next   int
+}
+
+// entry is a graph held by a Server. Analyses of the
+// graph are serialised by the entry's mutex.
+type entry struct {
+	mu sync.Mutex
+	g  *graphprac.Graph
+}
+
+// New returns a new Server holding no graphs.
+func New() *Server {
+	return &Server{graphs: make(map[string]*entry)}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(path) == 1 && path[0] == "analyses":
+		if !allow(w, r, http.MethodGet) {
+			return
+		}
+		writeJSON(w, http.StatusOK, graphprac.Analyses())
+
+	case len(path) == 1 && path[0] == "graphs":
+		if !allow(w, r, http.MethodPost) {
+			return
+		}
+		s.upload(w, r)
+
+	case len(path) >= 2 && path[0] == "graphs":
+		e, ok := s.graph(path[1])
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("no graph with id %q", path[1]))
+			return
+		}
+		switch {
+		case len(path) == 2:
+			if !allow(w, r, http.MethodGet, http.MethodDelete) {
+				return
+			}
+			if r.Method == http.MethodDelete {
+				s.mu.Lock()
+				delete(s.graphs, path[1])
+				s.mu.Unlock()
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			e.mu.Lock()
+			dot := graphprac.DOT(e.g)
+			e.mu.Unlock()
+			w.Header().Set("Content-Type", "text/vnd.graphviz")
+			fmt.Fprint(w, dot)
+
+		case len(path) == 4 && path[2] == "analyses":
+			if !allow(w, r, http.MethodPost) {
+				return
+			}
+			analyse(w, r, e, path[3])
+
+		case len(path) == 3 && path[2] == "top":
+			if !allow(w, r, http.MethodGet) {
+				return
+			}
+			top(w, r, e)
+
+		default:
+			http.NotFound(w, r)
+		}
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// upload adds the DOT graph in the body of r to the server.
+func (s *Server) upload(w http.ResponseWriter, r *http.Request) {
+	g, err := graphprac.ReadDOT(http.MaxBytesReader(w, r.Body, maxUpload))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.mu.Lock()
+	s.next++
+	id := strconv.Itoa(s.next)
+	s.graphs[id] = &entry{g: g}
+	s.mu.Unlock()
+
+	w.Header().Set("Location", "/graphs/"+id)
+	writeJSON(w, http.StatusCreated, struct {
+		ID    string `json:"id"`
+		Nodes int    `json:"nodes"`
+		Edges int    `json:"edges"`
+	}{ID: id, Nodes: g.Nodes().Len(), Edges: g.Edges().Len()})
+}
+
+// graph returns the graph with the given ID.
+func (s *Server) graph(id string) (*entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.graphs[id]
+	return e, ok
+}
+
+// analyse runs the named analysis on the graph held by e with parameters
+// from the form values of r.
+func analyse(w http.ResponseWriter, r *http.Request, e *entry, name string) {
+	var analysis string
+	for _, a := range graphprac.Analyses() {
+		if strings.EqualFold(a, name) {
+			analysis = a
+			break
+		}
+	}
+	if analysis == "" {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown analysis %q", name))
+		return
+	}
+	err := r.ParseForm()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	params := make(map[string]string)
+	for k, v := range r.Form {
+		params[k] = v[len(v)-1]
+	}
+	err = graphprac.ValidateParams(analysis, params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	status, err := run(e, analysis, params)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Analysis string            `json:"analysis"`
+		Params   map[string]string `json:"params,omitempty"`
+	}{Analysis: analysis, Params: params})
+}
+
+// run runs the named analysis on the graph held by e, returning the
+// HTTP status and error to report if the analysis fails. A panic during
+// the analysis is reported as an internal server error.
+func run(e *entry, analysis string, params map[string]string) (status int, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	defer func() {
+		r := recover()
+		if r != nil {
+			status = http.StatusInternalServerError
+			err = fmt.Errorf("%s: analysis failed: %v", analysis, r)
+		}
+	}()
+	err = graphprac.Run(e.g, analysis, params)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	return http.StatusOK, nil
+}
+
+// top writes the nodes of the graph held by e with the highest values of
+// the attribute given by the attr form value of r. The number of nodes is
+// given by the n form value, which defaults to 10.
+func top(w http.ResponseWriter, r *http.Request, e *entry) {
+	attr := r.FormValue("attr")
+	if attr == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing attr parameter"))
+		return
+	}
+	n := 10
+	if v := r.FormValue("n"); v != "" {
+		var err error
+		n, err = strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid n parameter: %q", v))
+			return
+		}
+	}
+
+	type value struct {
+		Node  string  `json:"node"`
+		Value float64 `json:"value"`
+	}
+	var vals []value
+	e.mu.Lock()
+	nodes, err := graphprac.NodesByAttribute(attr, e.g)
+	if err == nil {
+		for _, nd := range nodes {
+			v := nd.Get(attr)
+			if v == "" {
+				continue
+			}
+			f, _ := strconv.ParseFloat(v, 64)
+			vals = append(vals, value{Node: nd.Name, Value: f})
+		}
+	}
+	e.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid %s values: %v", attr, err))
+		return
+	}
+	// Break ties by node name so that
+	// responses are deterministic.
+	sort.SliceStable(vals, func(i, j int) bool {
+		if vals[i].Value != vals[j].Value {
+			return vals[i].Value > vals[j].Value
+		}
+		return vals[i].Node < vals[j].Node
+	})
+	if len(vals) == 0 && n != 0 {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no node has attribute %q", attr))
+		return
+	}
+	if len(vals) > n {
+		vals = vals[:n]
+	}
+	writeJSON(w, http.StatusOK, vals)
+}
+
+// allow returns whether the method of r is one of methods, writing an
+// error response if it is not.
+func allow(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+}
@@ -0,0 +1,143 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kortschak/graphprac"
+)
+
+// serverTests is a sequence of requests made to a single Server.
+var serverTests = []struct {
+	method, path, body string
+
+	wantStatus int
+	wantBody   string
+}{
+	{
+		method: http.MethodPost, path: "/graphs", body: `graph { a -- b -- c; c -- d }`,
+		wantStatus: http.StatusCreated,
+		wantBody:   `{"id":"1","nodes":4,"edges":3}` + "\n",
+	},
+	{
+		method: http.MethodPost, path: "/graphs", body: `graph { a -- `,
+		wantStatus: http.StatusBadRequest,
+	},
+	{
+		method: http.MethodGet, path: "/graphs/1/top?attr=eccentricity",
+		wantStatus: http.StatusNotFound,
+		wantBody:   `{"error":"no node has attribute \"eccentricity\""}` + "\n",
+	},
+	{
+		method: http.MethodPost, path: "/graphs/1/analyses/eccentricity",
+		wantStatus: http.StatusOK,
+		wantBody:   `{"analysis":"Eccentricity"}` + "\n",
+	},
+	{
+		method: http.MethodGet, path: "/graphs/1/top?attr=eccentricity&n=2",
+		wantStatus: http.StatusOK,
+		wantBody:   `[{"node":"a","value":3},{"node":"d","value":3}]` + "\n",
+	},
+	{
+		method: http.MethodPost, path: "/graphs/1/analyses/pagerank?damp=high",
+		wantStatus: http.StatusBadRequest,
+	},
+	{
+		method: http.MethodPost, path: "/graphs/1/analyses/pagerank?damp=2",
+		wantStatus: http.StatusBadRequest,
+		wantBody:   `{"error":"PageRank: invalid damping factor: 2 not in [0,1)"}` + "\n",
+	},
+	{
+		method: http.MethodPost, path: "/graphs/1/analyses/pagerank?tol=0",
+		wantStatus: http.StatusBadRequest,
+		wantBody:   `{"error":"PageRank: invalid tolerance: 0 not positive"}` + "\n",
+	},
+	{
+		method: http.MethodPost, path: "/graphs/1/analyses/clique?k=1",
+		wantStatus: http.StatusBadRequest,
+	},
+	{
+		method: http.MethodPost, path: "/graphs/1/analyses/nosuchanalysis",
+		wantStatus: http.StatusNotFound,
+	},
+	{
+		method: http.MethodGet, path: "/graphs/1/analyses/eccentricity",
+		wantStatus: http.StatusMethodNotAllowed,
+	},
+	{
+		method: http.MethodGet, path: "/graphs/1",
+		wantStatus: http.StatusOK,
+		wantBody: `graph {
+  // Node definitions.
+  a [eccentricity=3];
+  b [eccentricity=2];
+  c [eccentricity=2];
+  d [eccentricity=3];
+
+  // Edge definitions.
+  a -- b;
+  b -- c;
+  c -- d;
+}`,
+	},
+	{
+		method: http.MethodDelete, path: "/graphs/1",
+		wantStatus: http.StatusNoContent,
+	},
+	{
+		method: http.MethodGet, path: "/graphs/1",
+		wantStatus: http.StatusNotFound,
+	},
+	{
+		method: http.MethodGet, path: "/nowhere",
+		wantStatus: http.StatusNotFound,
+	},
+	{
+		method: http.MethodPost, path: "/graphs", body: `graph {}`,
+		wantStatus: http.StatusCreated,
+		wantBody:   `{"id":"2","nodes":0,"edges":0}` + "\n",
+	},
+	{
+		method: http.MethodPost, path: "/graphs/2/analyses/pagerank",
+		wantStatus: http.StatusOK,
+	},
+	{
+		method: http.MethodPost, path: "/graphs/2/analyses/test.panic",
+		wantStatus: http.StatusInternalServerError,
+		wantBody:   `{"error":"test.Panic: analysis failed: test panic"}` + "\n",
+	},
+	{
+		// The graph is not left locked by the panic.
+		method: http.MethodGet, path: "/graphs/2",
+		wantStatus: http.StatusOK,
+	},
+}
+
+func init() {
+	graphprac.Register("test.Panic", func(*graphprac.Graph, map[string]string) error {
+		panic("test panic")
+	})
+}
+
+func TestServer(t *testing.T) {
+	s := New()
+	for i, test := range serverTests {
+		req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != test.wantStatus {
+			t.Errorf("unexpected status for test %d %s %s: got:%d want:%d\n%s",
+				i, test.method, test.path, rec.Code, test.wantStatus, rec.Body)
+		}
+		if test.wantBody != "" && rec.Body.String() != test.wantBody {
+			t.Errorf("unexpected body for test %d %s %s:\ngot: %s\nwant:%s",
+				i, test.method, test.path, rec.Body, test.wantBody)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// edgeSign returns the sign of e. The sign is negative if the "sign"
+// attribute of e is "-", "negative" or a negative number, or if the sign
+// is not set and the "weight" attribute is a negative number. Otherwise
+// the sign is positive.
+func edgeSign(e *Edge) int {
+	switch s := e.GetUnquoted("sign"); s {
+	case "":
+	case "-", "negative":
+		return -1
+	case "+", "positive":
+		return 1
+	default:
+		if v, err := strconv.ParseFloat(s, 64); err == nil && v < 0 {
+			return -1
+		}
+		return 1
+	}
+	if v, err := strconv.ParseFloat(e.GetUnquoted("weight"), 64); err == nil && v < 0 {
+		return -1
+	}
+	return 1
+}
+
+// signedAdjacency returns the adjacency lists of g indexed by x with each
+// list sorted, and the sign of each edge in the lists.
+func signedAdjacency(g *Graph, x NodeIndex) (adj [][]int, sign [][]int) {
+	adj = x.adjacency(g)
+	sign = make([][]int, len(adj))
+	for i, to := range adj {
+		sort.Ints(to)
+		sign[i] = make([]int, len(to))
+		for k, j := range to {
+			sign[i][k] = edgeSign(g.EdgeBetween(x.ID(i), x.ID(j)).(*Edge))
+		}
+	}
+	return adj, sign
+}
+
+// StructuralBalance returns the number of balanced and unbalanced
+// triangles in the signed graph g. A triangle is balanced if the product
+// of the signs of its edges is positive; that is, if it has no negative
+// edges or two negative edges. Edge signs are given by the "sign"
+// attribute of each edge, or by the sign of the "weight" attribute if
+// "sign" is not set. Edges with neither attribute are positive.
+func StructuralBalance(g *Graph) (balancedTriads, unbalancedTriads int) {
+	x := g.Index()
+	adj, sign := signedAdjacency(g, x)
+
+	// marked holds the sign of the edge from the
+	// current node to each neighbour, or 0.
+	marked := make([]int, len(adj))
+	for i, to := range adj {
+		for k, j := range to {
+			marked[j] = sign[i][k]
+		}
+		for _, j := range to {
+			if j < i {
+				continue
+			}
+			for h, l := range adj[j] {
+				if l <= j || marked[l] == 0 {
+					continue
+				}
+				if marked[j]*sign[j][h]*marked[l] > 0 {
+					balancedTriads++
+				} else {
+					unbalancedTriads++
+				}
+			}
+		}
+		for _, j := range to {
+			marked[j] = 0
+		}
+	}
+	return balancedTriads, unbalancedTriads
+}
+
+// SignedCommunities partitions the signed graph g into communities that
+// minimise the frustration of the partition, the number of negative edges
+// within communities plus the number of positive edges between them.
+// Edge signs are given as for StructuralBalance. Starting from the
+// connected components of the positive edges, nodes are visited in a
+// random order determined by seed and moved to the community, possibly a
+// new one, that most reduces the frustration until no move reduces it.
+// The frustration of the final partition is returned.
+//
+// The community identity value is written into the "signed_community"
+// attribute of each node.
+func SignedCommunities(g *Graph, seed int64) (frustration int) {
+	done, err := trace(g, "SignedCommunities", []string{"signed_community"}, "seed", seed)
+	if err != nil {
+		return 0
+	}
+	defer done(nil)
+
+	x := g.Index()
+	adj, sign := signedAdjacency(g, x)
+	n := len(adj)
+
+	pos := make([][]int, n)
+	for i, to := range adj {
+		for k, j := range to {
+			if sign[i][k] > 0 {
+				pos[i] = append(pos[i], j)
+			}
+		}
+	}
+	comm := components(pos)
+	next := 0
+	for _, c := range comm {
+		if c >= next {
+			next = c + 1
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	// posTo and negTo hold the number of positive and
+	// negative edges from the current node to each
+	// community.
+	posTo := make(map[int]int)
+	negTo := make(map[int]int)
+	var candidates []int
+	for moved := true; moved; {
+		moved = false
+		rnd.Shuffle(n, func(i, j int) { order[i], order[j] = order[j], order[i] })
+		for _, i := range order {
+			for c := range posTo {
+				delete(posTo, c)
+			}
+			for c := range negTo {
+				delete(negTo, c)
+			}
+			var posTotal int
+			for k, j := range adj[i] {
+				if sign[i][k] > 0 {
+					posTo[comm[j]]++
+					posTotal++
+				} else {
+					negTo[comm[j]]++
+				}
+			}
+
+			// The cost of placing i in community c is the number
+			// of negative edges from i into c and the number of
+			// positive edges from i out of c.
+			cost := func(c int) int { return negTo[c] + posTotal - posTo[c] }
+			candidates = candidates[:0]
+			for _, to := range []map[int]int{posTo, negTo} {
+				for c := range to {
+					candidates = append(candidates, c)
+				}
+			}
+			sort.Ints(candidates)
+			best, bestCost := comm[i], cost(comm[i])
+			for _, c := range candidates {
+				if d := cost(c); d < bestCost {
+					best, bestCost = c, d
+				}
+			}
+			if posTotal < bestCost {
+				best, bestCost = next, posTotal
+				next++
+			}
+			if best != comm[i] {
+				comm[i] = best
+				moved = true
+			}
+		}
+	}
+
+	label := make(map[int]int)
+	for i, c := range comm {
+		l, ok := label[c]
+		if !ok {
+			l = len(label)
+			label[c] = l
+		}
+		x.Node(i).SetAttribute(encoding.Attribute{Key: "signed_community", Value: fmt.Sprint(l)})
+		for k, j := range adj[i] {
+			if j < i {
+				continue
+			}
+			if (sign[i][k] < 0) == (comm[i] == comm[j]) {
+				frustration++
+			}
+		}
+	}
+	return frustration
+}
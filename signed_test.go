@@ -0,0 +1,108 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"testing"
+)
+
+var edgeSignTests = []struct {
+	attrs string
+	want  int
+}{
+	{attrs: ``, want: 1},
+	{attrs: `sign="-"`, want: -1},
+	{attrs: `sign=negative`, want: -1},
+	{attrs: `sign="+"`, want: 1},
+	{attrs: `sign=positive`, want: 1},
+	{attrs: `sign=-0.5`, want: -1},
+	{attrs: `sign=2`, want: 1},
+	{attrs: `weight=-3`, want: -1},
+	{attrs: `weight=3`, want: 1},
+	{attrs: `sign=positive, weight=-3`, want: 1},
+}
+
+func TestEdgeSign(t *testing.T) {
+	for i, test := range edgeSignTests {
+		g := graphFromDOT(t, `graph { a -- b [`+test.attrs+`] }`)
+		e := g.EdgeBetween(g.NodeNamed("a").ID(), g.NodeNamed("b").ID()).(*Edge)
+		if got := edgeSign(e); got != test.want {
+			t.Errorf("unexpected sign for test %d: got:%d want:%d", i, got, test.want)
+		}
+	}
+}
+
+var balanceTests = []struct {
+	dot        string
+	balanced   int
+	unbalanced int
+}{
+	{dot: `graph { a -- b -- c }`, balanced: 0, unbalanced: 0},
+	{dot: `graph { a -- b -- c -- a }`, balanced: 1, unbalanced: 0},
+	{dot: `graph { a -- b [sign="-"]; b -- c; c -- a }`, balanced: 0, unbalanced: 1},
+	{dot: `graph { a -- b [sign="-"]; b -- c [weight=-1]; c -- a }`, balanced: 1, unbalanced: 0},
+	{
+		dot: `graph {
+	a -- b -- c -- a;
+	c -- d [sign="-"]; d -- e [sign="-"]; e -- c;
+	e -- f [sign="-"]; f -- g -- e;
+}`,
+		balanced:   2,
+		unbalanced: 1,
+	},
+}
+
+func TestStructuralBalance(t *testing.T) {
+	for i, test := range balanceTests {
+		g := graphFromDOT(t, test.dot)
+		balanced, unbalanced := StructuralBalance(g)
+		if balanced != test.balanced || unbalanced != test.unbalanced {
+			t.Errorf("unexpected triad counts for test %d: got:%d,%d want:%d,%d",
+				i, balanced, unbalanced, test.balanced, test.unbalanced)
+		}
+	}
+}
+
+var signedCommunityTests = []struct {
+	dot         string
+	frustration int
+	want        [][]string
+}{
+	{
+		// Two positive triangles joined by negative edges
+		// can be partitioned with no frustration.
+		dot: `graph {
+	a -- b -- c -- a; d -- e -- f -- d;
+	a -- d [sign="-"]; b -- e [sign="-"]; c -- f [sign="-"];
+}`,
+		frustration: 0,
+		want:        [][]string{{"a", "b", "c"}, {"d", "e", "f"}},
+	},
+	{
+		// An unbalanced triangle has a frustration of
+		// at least one.
+		dot:         `graph { a -- b [sign="-"]; b -- c; c -- a }`,
+		frustration: 1,
+	},
+	{
+		// Three mutually hostile nodes are best placed
+		// in separate communities.
+		dot:         `graph { a -- b [sign="-"]; b -- c [sign="-"]; c -- a [sign="-"] }`,
+		frustration: 0,
+		want:        [][]string{{"a"}, {"b"}, {"c"}},
+	},
+}
+
+func TestSignedCommunities(t *testing.T) {
+	for i, test := range signedCommunityTests {
+		for seed := int64(0); seed < 5; seed++ {
+			g := graphFromDOT(t, test.dot)
+			if got := SignedCommunities(g, seed); got != test.frustration {
+				t.Errorf("unexpected frustration for test %d seed %d: got:%d want:%d", i, seed, got, test.frustration)
+			}
+			checkPartition(t, g, "signed_community", test.want)
+		}
+	}
+}
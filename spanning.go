@@ -0,0 +1,112 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"sort"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/mat"
+)
+
+// RandomSpanningTree returns a copy of g holding only the edges of a
+// spanning tree chosen uniformly at random from all spanning trees of g,
+// using Wilson's algorithm of loop-erased random walks. If g is not
+// connected, a random spanning tree of each connected component is
+// returned. Node IDs and attributes are retained, and the returned graph
+// is a *Graph. The same seed gives the same tree for the same graph.
+func RandomSpanningTree(g *Graph, seed int64) graph.Graph {
+	x := g.Index()
+	adj := x.adjacency(g)
+	for _, a := range adj {
+		sort.Ints(a)
+	}
+	rnd := rand.New(rand.NewSource(uint64(seed)))
+
+	// The first node of each component in index
+	// order is the root of the component's tree.
+	comp := components(adj)
+	inTree := make([]bool, len(adj))
+	seen := make(map[int]bool)
+	for i, c := range comp {
+		if !seen[c] {
+			seen[c] = true
+			inTree[i] = true
+		}
+	}
+
+	// next holds the parent of each node in the
+	// tree, or -1 for roots.
+	next := make([]int, len(adj))
+	for i := range next {
+		next[i] = -1
+	}
+	for i := range adj {
+		// Walk until the tree is hit. Overwriting next
+		// erases loops from the recorded walk.
+		for u := i; !inTree[u]; u = next[u] {
+			next[u] = adj[u][rnd.Intn(len(adj[u]))]
+		}
+		for u := i; !inTree[u]; u = next[u] {
+			inTree[u] = true
+		}
+	}
+
+	keep := make(map[int64]int, len(adj))
+	for i := range adj {
+		keep[x.ID(i)] = i
+	}
+	t := subgraph(g, keep)
+	for _, e := range graph.EdgesOf(t.Edges()) {
+		u, v := x.Index(e.From().ID()), x.Index(e.To().ID())
+		if next[u] == v || next[v] == u {
+			continue
+		}
+		t.RemoveEdge(e.From().ID(), e.To().ID())
+	}
+	return t
+}
+
+// SpanningTreeCount returns the number of spanning trees of g, found using
+// Kirchhoff's matrix-tree theorem as the determinant of the Laplacian of g
+// with one row and column removed. The count is zero if g is not
+// connected. The count grows very rapidly with the size of g and is +Inf
+// if it is too large to be represented as a float64.
+func SpanningTreeCount(g *Graph) float64 {
+	x := g.Index()
+	n := x.Len()
+	if n <= 1 {
+		return 1
+	}
+	adj := x.adjacency(g)
+	for _, c := range components(adj) {
+		if c != 0 {
+			return 0
+		}
+	}
+
+	// Remove the row and column of the last node.
+	l := mat.NewSymDense(n-1, nil)
+	for i, to := range adj[:n-1] {
+		l.SetSym(i, i, float64(len(to)))
+		for _, j := range to {
+			if j < n-1 {
+				l.SetSym(i, j, -1)
+			}
+		}
+	}
+	var chol mat.Cholesky
+	if !chol.Factorize(l) {
+		return 0
+	}
+	count := math.Exp(chol.LogDet())
+	if count < 1<<53 {
+		// The count is an integer.
+		count = math.Round(count)
+	}
+	return count
+}
@@ -0,0 +1,94 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+var spanningCountTests = []struct {
+	dot  string
+	want float64
+}{
+	{dot: `graph { a }`, want: 1},
+	{dot: `graph { a -- b -- c -- d }`, want: 1},
+	{dot: `graph { a -- b -- c -- d -- e -- a }`, want: 5},
+	{dot: `graph { a -- b -- c -- a; c -- d }`, want: 3},
+	{dot: `graph { a -- b; a -- c; a -- d; b -- c; b -- d; c -- d }`, want: 16},
+	{dot: `graph { a -- b; a -- c; a -- d; a -- e; b -- c; b -- d; b -- e; c -- d; c -- e; d -- e }`, want: 125},
+	{dot: `graph { a -- b; c -- d }`, want: 0},
+}
+
+func TestSpanningTreeCount(t *testing.T) {
+	for i, test := range spanningCountTests {
+		g := graphFromDOT(t, test.dot)
+		if got := SpanningTreeCount(g); got != test.want {
+			t.Errorf("unexpected count for test %d: got:%v want:%v", i, got, test.want)
+		}
+	}
+}
+
+// treeKey returns a canonical description of the edges of g.
+func treeKey(g graph.Graph) string {
+	var edges []string
+	for _, e := range graph.EdgesOf(g.(*Graph).Edges()) {
+		u, v := e.From().(*Node).Name, e.To().(*Node).Name
+		if v < u {
+			u, v = v, u
+		}
+		edges = append(edges, u+"--"+v)
+	}
+	sort.Strings(edges)
+	return strings.Join(edges, " ")
+}
+
+func TestRandomSpanningTree(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b -- c -- d -- a; e -- f -- g -- e; h }`)
+	for seed := int64(0); seed < 20; seed++ {
+		tree := RandomSpanningTree(g, seed).(*Graph)
+		if n := tree.Nodes().Len(); n != 8 {
+			t.Errorf("unexpected number of nodes for seed %d: got:%d want:8", seed, n)
+		}
+		if n := tree.Edges().Len(); n != 5 {
+			t.Errorf("unexpected number of edges for seed %d: got:%d want:5", seed, n)
+		}
+		for _, e := range graph.EdgesOf(tree.Edges()) {
+			if !g.HasEdgeBetween(e.From().ID(), e.To().ID()) {
+				t.Errorf("unexpected edge for seed %d: %v", seed, e)
+			}
+		}
+		for _, n := range NodesOf(g) {
+			if got, want := len(hops(tree, n.ID())), len(hops(g, n.ID())); got != want {
+				t.Errorf("unexpected component size of %s for seed %d: got:%d want:%d", n.Name, seed, got, want)
+			}
+		}
+		if got, want := treeKey(RandomSpanningTree(g, seed)), treeKey(tree); got != want {
+			t.Errorf("unexpected tree for repeated seed %d: got:%s want:%s", seed, got, want)
+		}
+	}
+}
+
+func TestRandomSpanningTreeUniform(t *testing.T) {
+	// Each of the four spanning trees of a 4-cycle
+	// should be chosen with equal probability.
+	const n = 4000
+	g := graphFromDOT(t, `graph { a -- b -- c -- d -- a }`)
+	counts := make(map[string]int)
+	for seed := int64(0); seed < n; seed++ {
+		counts[treeKey(RandomSpanningTree(g, seed))]++
+	}
+	if len(counts) != 4 {
+		t.Fatalf("unexpected number of distinct trees: got:%d want:4", len(counts))
+	}
+	for tree, c := range counts {
+		if c < n/4-150 || n/4+150 < c {
+			t.Errorf("unexpected frequency of tree %s: got:%d want:%d±150", tree, c, n/4)
+		}
+	}
+}
@@ -0,0 +1,581 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/simple"
+)
+
+// storeSchema is the SQL schema of a Store. The dot_id and strict columns
+// of graphs hold the Name and Strict fields of a graph. Global attributes
+// are held in graph_attributes with a kind of "graph", "node" or "edge".
+// Clusters are numbered in depth-first order, with a NULL parent for
+// top-level clusters, and their nodes are held by DOT ID. Node, edge and
+// cluster attributes and cluster nodes are ordered by rowid.
+const storeSchema = `
+CREATE TABLE IF NOT EXISTS graphs (
+	id     INTEGER PRIMARY KEY,
+	name   TEXT NOT NULL UNIQUE,
+	dot_id TEXT NOT NULL DEFAULT '',
+	strict INTEGER NOT NULL DEFAULT 0,
+	saved  TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS graph_attributes (
+	graph INTEGER NOT NULL REFERENCES graphs(id),
+	kind  TEXT NOT NULL,
+	key   TEXT NOT NULL,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS nodes (
+	graph INTEGER NOT NULL REFERENCES graphs(id),
+	id    INTEGER NOT NULL,
+	name  TEXT NOT NULL,
+	PRIMARY KEY (graph, id)
+);
+CREATE TABLE IF NOT EXISTS node_attributes (
+	graph INTEGER NOT NULL,
+	node  INTEGER NOT NULL,
+	key   TEXT NOT NULL,
+	value TEXT NOT NULL,
+	FOREIGN KEY (graph, node) REFERENCES nodes(graph, id)
+);
+CREATE INDEX IF NOT EXISTS node_attributes_key ON node_attributes (graph, key);
+CREATE TABLE IF NOT EXISTS edges (
+	graph  INTEGER NOT NULL,
+	source INTEGER NOT NULL,
+	target INTEGER NOT NULL,
+	PRIMARY KEY (graph, source, target)
+);
+CREATE TABLE IF NOT EXISTS edge_attributes (
+	graph  INTEGER NOT NULL,
+	source INTEGER NOT NULL,
+	target INTEGER NOT NULL,
+	key    TEXT NOT NULL,
+	value  TEXT NOT NULL,
+	FOREIGN KEY (graph, source, target) REFERENCES edges(graph, source, target)
+);
+CREATE INDEX IF NOT EXISTS edge_attributes_key ON edge_attributes (graph, key);
+CREATE TABLE IF NOT EXISTS clusters (
+	graph  INTEGER NOT NULL REFERENCES graphs(id),
+	id     INTEGER NOT NULL,
+	parent INTEGER,
+	name   TEXT NOT NULL,
+	PRIMARY KEY (graph, id)
+);
+CREATE TABLE IF NOT EXISTS cluster_attributes (
+	graph   INTEGER NOT NULL,
+	cluster INTEGER NOT NULL,
+	key     TEXT NOT NULL,
+	value   TEXT NOT NULL,
+	FOREIGN KEY (graph, cluster) REFERENCES clusters(graph, id)
+);
+CREATE TABLE IF NOT EXISTS cluster_nodes (
+	graph   INTEGER NOT NULL,
+	cluster INTEGER NOT NULL,
+	node    TEXT NOT NULL,
+	FOREIGN KEY (graph, cluster) REFERENCES clusters(graph, id)
+);
+CREATE TABLE IF NOT EXISTS provenance (
+	graph     INTEGER NOT NULL REFERENCES graphs(id),
+	attribute TEXT NOT NULL,
+	analysis  TEXT NOT NULL,
+	params    TEXT NOT NULL,
+	time      TEXT NOT NULL
+);
+`
+
+// storeColumns are the columns added to the tables of storeSchema since
+// they were first defined. They are added to the tables of stores created
+// with an earlier schema when the store is opened.
+var storeColumns = []struct {
+	table, column, definition string
+}{
+	{table: "graphs", column: "dot_id", definition: "TEXT NOT NULL DEFAULT ''"},
+	{table: "graphs", column: "strict", definition: "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// Store is a collection of named graphs held in an SQLite database. Nodes,
+// edges, clusters, attributes and attribute provenance are held in tables
+// so that the results of analyses can be queried with SQL.
+type Store struct {
+	db *sql.DB
+}
+
+// NewStore returns a Store held in the SQLite database db, creating its
+// tables if necessary. The database may be opened with any SQLite driver.
+// Open in the graphprac/sqlite package opens SQLite database files using a
+// cgo driver, which is kept out of this package so that it can be built
+// without cgo.
+func NewStore(db *sql.DB) (*Store, error) {
+	_, err := db.Exec(storeSchema)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range storeColumns {
+		err = addColumn(db, c.table, c.column, c.definition)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &Store{db: db}, nil
+}
+
+// addColumn adds the column with the given definition to table if the
+// table does not already have it.
+func addColumn(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	err = rows.Err()
+	if err != nil {
+		return err
+	}
+	rows.Close()
+	_, err = db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column + ` ` + definition)
+	return err
+}
+
+// Close closes the store and its database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Names returns the names of the graphs in the store in lexical order.
+func (s *Store) Names() ([]string, error) {
+	rows, err := s.db.Query(`SELECT name FROM graphs ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		err = rows.Scan(&name)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// Save stores g under the given name, replacing any graph previously
+// stored with that name. Like SaveBinary, Save persists the state of a
+// session, so the policy set by SetExportPolicy is not applied.
+func (s *Store) Save(name string, g *Graph) (err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var id int64
+	err = tx.QueryRow(`SELECT id FROM graphs WHERE name = ?`, name).Scan(&id)
+	switch err {
+	case nil:
+		err = deleteGraph(tx, id)
+		if err != nil {
+			return err
+		}
+	case sql.ErrNoRows:
+	default:
+		return err
+	}
+	res, err := tx.Exec(`INSERT INTO graphs (name, dot_id, strict, saved) VALUES (?, ?, ?, ?)`,
+		name, g.Name, g.Strict, time.Now().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	id, err = res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	insert := func(query string, rows func(stmt *sql.Stmt) error) error {
+		stmt, err := tx.Prepare(query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		return rows(stmt)
+	}
+	err = insert(`INSERT INTO graph_attributes (graph, kind, key, value) VALUES (?, ?, ?, ?)`, func(stmt *sql.Stmt) error {
+		for _, global := range []struct {
+			kind  string
+			attrs Attributes
+		}{
+			{kind: "graph", attrs: g.GraphAttrs},
+			{kind: "node", attrs: g.NodeAttrs},
+			{kind: "edge", attrs: g.EdgeAttrs},
+		} {
+			for _, a := range global.attrs {
+				_, err := stmt.Exec(id, global.kind, a.Key, a.Value)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	nodes := NodesOf(g)
+	err = insert(`INSERT INTO nodes (graph, id, name) VALUES (?, ?, ?)`, func(stmt *sql.Stmt) error {
+		for _, n := range nodes {
+			_, err := stmt.Exec(id, n.ID(), n.Name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = insert(`INSERT INTO node_attributes (graph, node, key, value) VALUES (?, ?, ?, ?)`, func(stmt *sql.Stmt) error {
+		for _, n := range nodes {
+			for _, a := range n.Attributes {
+				_, err := stmt.Exec(id, n.ID(), a.Key, a.Value)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	edges := graph.EdgesOf(g.Edges())
+	err = insert(`INSERT INTO edges (graph, source, target) VALUES (?, ?, ?)`, func(stmt *sql.Stmt) error {
+		for _, e := range edges {
+			_, err := stmt.Exec(id, e.From().ID(), e.To().ID())
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = insert(`INSERT INTO edge_attributes (graph, source, target, key, value) VALUES (?, ?, ?, ?, ?)`, func(stmt *sql.Stmt) error {
+		for _, e := range edges {
+			for _, a := range e.(*Edge).Attributes {
+				_, err := stmt.Exec(id, e.From().ID(), e.To().ID(), a.Key, a.Value)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = insertClusters(tx, id, g.clusters)
+	if err != nil {
+		return err
+	}
+	return insert(`INSERT INTO provenance (graph, attribute, analysis, params, time) VALUES (?, ?, ?, ?, ?)`, func(stmt *sql.Stmt) error {
+		for attr, p := range g.provenance {
+			params, err := json.Marshal(p.Params)
+			if err != nil {
+				return err
+			}
+			_, err = stmt.Exec(id, attr, p.Analysis, string(params), p.Time.Format(time.RFC3339Nano))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// insertClusters inserts the clusters of the graph with the given ID into
+// the store, numbering them in depth-first order.
+func insertClusters(tx *sql.Tx, id int64, clusters []*clusterSpec) error {
+	var next int64
+	var insert func(parent interface{}, clusters []*clusterSpec) error
+	insert = func(parent interface{}, clusters []*clusterSpec) error {
+		for _, c := range clusters {
+			cid := next
+			next++
+			_, err := tx.Exec(`INSERT INTO clusters (graph, id, parent, name) VALUES (?, ?, ?, ?)`, id, cid, parent, c.name)
+			if err != nil {
+				return err
+			}
+			for _, a := range c.attrs {
+				_, err = tx.Exec(`INSERT INTO cluster_attributes (graph, cluster, key, value) VALUES (?, ?, ?, ?)`, id, cid, a.Key, a.Value)
+				if err != nil {
+					return err
+				}
+			}
+			for _, n := range c.nodes {
+				_, err = tx.Exec(`INSERT INTO cluster_nodes (graph, cluster, node) VALUES (?, ?, ?)`, id, cid, n)
+				if err != nil {
+					return err
+				}
+			}
+			err = insert(cid, c.clusters)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return insert(nil, clusters)
+}
+
+// deleteGraph removes the graph with the given ID from the store.
+func deleteGraph(tx *sql.Tx, id int64) error {
+	for _, table := range []string{"provenance", "cluster_nodes", "cluster_attributes", "clusters", "edge_attributes", "edges", "node_attributes", "nodes", "graph_attributes"} {
+		_, err := tx.Exec(`DELETE FROM `+table+` WHERE graph = ?`, id)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec(`DELETE FROM graphs WHERE id = ?`, id)
+	return err
+}
+
+// Load returns the graph stored under the given name.
+func (s *Store) Load(name string) (*Graph, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	g := &Graph{UndirectedGraph: simple.NewUndirectedGraph()}
+	err = tx.QueryRow(`SELECT id, dot_id, strict FROM graphs WHERE name = ?`, name).Scan(&id, &g.Name, &g.Strict)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("no graph named %q in store", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = scan(tx, `SELECT kind, key, value FROM graph_attributes WHERE graph = ? ORDER BY rowid`, id, func(rows *sql.Rows) error {
+		var kind string
+		var a encoding.Attribute
+		err := rows.Scan(&kind, &a.Key, &a.Value)
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case "graph":
+			g.GraphAttrs = append(g.GraphAttrs, a)
+		case "node":
+			g.NodeAttrs = append(g.NodeAttrs, a)
+		case "edge":
+			g.EdgeAttrs = append(g.EdgeAttrs, a)
+		default:
+			return fmt.Errorf("invalid global attribute kind %q", kind)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = scan(tx, `SELECT id, name FROM nodes WHERE graph = ?`, id, func(rows *sql.Rows) error {
+		n := &Node{}
+		err := rows.Scan(&n.NodeID, &n.Name)
+		if err != nil {
+			return err
+		}
+		g.AddNode(n)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	nodes := g.NodeMap()
+	err = scan(tx, `SELECT node, key, value FROM node_attributes WHERE graph = ? ORDER BY rowid`, id, func(rows *sql.Rows) error {
+		var nid int64
+		var a encoding.Attribute
+		err := rows.Scan(&nid, &a.Key, &a.Value)
+		if err != nil {
+			return err
+		}
+		n, ok := nodes[nid]
+		if !ok {
+			return fmt.Errorf("attribute for missing node %d", nid)
+		}
+		n.Attributes = append(n.Attributes, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = scan(tx, `SELECT source, target FROM edges WHERE graph = ?`, id, func(rows *sql.Rows) error {
+		var uid, vid int64
+		err := rows.Scan(&uid, &vid)
+		if err != nil {
+			return err
+		}
+		u, ok := nodes[uid]
+		v, ok2 := nodes[vid]
+		if !ok || !ok2 || u == v {
+			return fmt.Errorf("invalid edge %d--%d", uid, vid)
+		}
+		g.SetEdge(&Edge{F: u, T: v})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = scan(tx, `SELECT source, target, key, value FROM edge_attributes WHERE graph = ? ORDER BY rowid`, id, func(rows *sql.Rows) error {
+		var uid, vid int64
+		var a encoding.Attribute
+		err := rows.Scan(&uid, &vid, &a.Key, &a.Value)
+		if err != nil {
+			return err
+		}
+		e, ok := g.EdgeBetween(uid, vid).(*Edge)
+		if !ok {
+			return fmt.Errorf("attribute for missing edge %d--%d", uid, vid)
+		}
+		e.Attributes = append(e.Attributes, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.clusters, err = loadClusters(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	err = scan(tx, `SELECT attribute, analysis, params, time FROM provenance WHERE graph = ?`, id, func(rows *sql.Rows) error {
+		var p AttributeProvenance
+		var params, t string
+		err := rows.Scan(&p.Attribute, &p.Analysis, &params, &t)
+		if err != nil {
+			return err
+		}
+		err = json.Unmarshal([]byte(params), &p.Params)
+		if err != nil {
+			return err
+		}
+		p.Time, err = time.Parse(time.RFC3339Nano, t)
+		if err != nil {
+			return err
+		}
+		g.setProvenanceRecord(p.Attribute, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// loadClusters returns the clusters of the graph with the given ID.
+func loadClusters(tx *sql.Tx, id int64) ([]*clusterSpec, error) {
+	var top []*clusterSpec
+	clusters := make(map[int64]*clusterSpec)
+	err := scan(tx, `SELECT id, parent, name FROM clusters WHERE graph = ? ORDER BY id`, id, func(rows *sql.Rows) error {
+		var cid int64
+		var parent sql.NullInt64
+		c := &clusterSpec{}
+		err := rows.Scan(&cid, &parent, &c.name)
+		if err != nil {
+			return err
+		}
+		clusters[cid] = c
+		if !parent.Valid {
+			top = append(top, c)
+			return nil
+		}
+		p, ok := clusters[parent.Int64]
+		if !ok {
+			return fmt.Errorf("invalid parent %d for cluster %q", parent.Int64, c.name)
+		}
+		p.clusters = append(p.clusters, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = scan(tx, `SELECT cluster, key, value FROM cluster_attributes WHERE graph = ? ORDER BY rowid`, id, func(rows *sql.Rows) error {
+		var cid int64
+		var a encoding.Attribute
+		err := rows.Scan(&cid, &a.Key, &a.Value)
+		if err != nil {
+			return err
+		}
+		c, ok := clusters[cid]
+		if !ok {
+			return fmt.Errorf("attribute for missing cluster %d", cid)
+		}
+		c.attrs = append(c.attrs, a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = scan(tx, `SELECT cluster, node FROM cluster_nodes WHERE graph = ? ORDER BY rowid`, id, func(rows *sql.Rows) error {
+		var cid int64
+		var n string
+		err := rows.Scan(&cid, &n)
+		if err != nil {
+			return err
+		}
+		c, ok := clusters[cid]
+		if !ok {
+			return fmt.Errorf("node for missing cluster %d", cid)
+		}
+		c.nodes = append(c.nodes, n)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return top, nil
+}
+
+// scan calls fn for each row returned by the query for the graph with the
+// given ID.
+func scan(tx *sql.Tx, query string, id int64, fn func(*sql.Rows) error) error {
+	rows, err := tx.Query(query, id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		err = fn(rows)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
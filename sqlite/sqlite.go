@@ -0,0 +1,32 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sqlite opens graphprac graph stores held in SQLite database
+// files. It is separate from graphprac since the SQLite driver it uses
+// requires cgo.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3" // Register the sqlite3 driver.
+
+	"github.com/kortschak/graphprac"
+)
+
+// Open opens the SQLite database at path as a graphprac.Store, creating
+// the database and its tables if necessary.
+func Open(path string) (*graphprac.Store, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := graphprac.NewStore(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("could not initialise store %s: %v", path, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,179 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sqlite
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kortschak/graphprac"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graphprac-sqlite")
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "graphs.db")
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error opening store: %v", err)
+	}
+	graphs := map[string]string{
+		"yeast": `graph { a [label="A \"quoted\" node"]; a -- b [weight=2]; b -- c; d }`,
+		"pair":  `graph { x -- y }`,
+		"clustered": `strict graph G {
+	subgraph cluster_x { label="X"; a; subgraph cluster_y { b; c } }
+	subgraph cluster_z { d }
+	a -- b -- c; c -- d; e
+}`,
+	}
+	for name, src := range graphs {
+		g, err := graphprac.ReadDOT(strings.NewReader(src))
+		if err != nil {
+			t.Fatalf("failed to read graph: %v", err)
+		}
+		graphprac.Eccentricity(g)
+		err = s.Save(name, g)
+		if err != nil {
+			t.Fatalf("unexpected error saving %s: %v", name, err)
+		}
+		graphs[name] = graphprac.DOT(g)
+	}
+	err = s.Close()
+	if err != nil {
+		t.Fatalf("unexpected error closing store: %v", err)
+	}
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error reopening store: %v", err)
+	}
+	defer s.Close()
+	names, err := s.Names()
+	if err != nil {
+		t.Fatalf("unexpected error listing graphs: %v", err)
+	}
+	if want := []string{"clustered", "pair", "yeast"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("unexpected graph names: got:%v want:%v", names, want)
+	}
+	for name, want := range graphs {
+		g, err := s.Load(name)
+		if err != nil {
+			t.Errorf("unexpected error loading %s: %v", name, err)
+			continue
+		}
+		if got := graphprac.DOT(g); got != want {
+			t.Errorf("unexpected graph for %s:\ngot:\n%s\nwant:\n%s", name, got, want)
+		}
+		if _, ok := graphprac.Provenance(g, "eccentricity"); !ok {
+			t.Errorf("missing provenance for %s", name)
+		}
+	}
+	g, err := s.Load("clustered")
+	if err != nil {
+		t.Fatalf("unexpected error loading clustered graph: %v", err)
+	}
+	if g.Name != "G" || !g.Strict {
+		t.Errorf("unexpected graph header: got:name=%q strict=%t want:name=\"G\" strict=true", g.Name, g.Strict)
+	}
+	c := g.Clusters()
+	if len(c) != 2 || c[0].Name != "cluster_x" || c[0].Get("label") != "X" ||
+		len(c[0].Clusters) != 1 || c[0].Clusters[0].Name != "cluster_y" || len(c[0].Clusters[0].Nodes) != 2 ||
+		c[1].Name != "cluster_z" || len(c[1].Nodes) != 1 || c[1].Nodes[0].Name != "d" {
+		t.Errorf("unexpected clusters: %+v", c)
+	}
+
+	// Saving under an existing name replaces the graph.
+	g, err = graphprac.ReadDOT(strings.NewReader(`graph { p -- q -- r }`))
+	if err != nil {
+		t.Fatalf("failed to read graph: %v", err)
+	}
+	err = s.Save("pair", g)
+	if err != nil {
+		t.Fatalf("unexpected error replacing graph: %v", err)
+	}
+	got, err := s.Load("pair")
+	if err != nil {
+		t.Fatalf("unexpected error loading replaced graph: %v", err)
+	}
+	if got, want := graphprac.DOT(got), graphprac.DOT(g); got != want {
+		t.Errorf("unexpected replaced graph:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := s.Load("missing"); err == nil {
+		t.Error("expected error loading missing graph")
+	}
+}
+
+func TestStoreMigration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graphprac-sqlite")
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "graphs.db")
+
+	// A store created before the DOT ID and
+	// strictness of graphs were held.
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	_, err = db.Exec(`
+CREATE TABLE graphs (
+	id    INTEGER PRIMARY KEY,
+	name  TEXT NOT NULL UNIQUE,
+	saved TEXT NOT NULL
+);
+INSERT INTO graphs (name, saved) VALUES ('old', '2017-06-01T10:00:00Z');`)
+	if err != nil {
+		t.Fatalf("failed to create old schema: %v", err)
+	}
+	db.Close()
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error opening old store: %v", err)
+	}
+	defer s.Close()
+	old, err := s.Load("old")
+	if err != nil {
+		t.Fatalf("unexpected error loading old graph: %v", err)
+	}
+	if old.Name != "" || old.Strict || old.Nodes().Len() != 0 {
+		t.Errorf("unexpected old graph:\n%s", graphprac.DOT(old))
+	}
+
+	g, err := graphprac.ReadDOT(strings.NewReader(`strict graph G { a -- b }`))
+	if err != nil {
+		t.Fatalf("failed to read graph: %v", err)
+	}
+	err = s.Save("new", g)
+	if err != nil {
+		t.Fatalf("unexpected error saving graph: %v", err)
+	}
+	got, err := s.Load("new")
+	if err != nil {
+		t.Fatalf("unexpected error loading graph: %v", err)
+	}
+	if got, want := graphprac.DOT(got), graphprac.DOT(g); got != want {
+		t.Errorf("unexpected graph:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Reopening a migrated store leaves it unchanged.
+	s2, err := Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error reopening store: %v", err)
+	}
+	s2.Close()
+}
@@ -0,0 +1,217 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/graph/topo"
+	"gonum.org/v1/gonum/stat"
+)
+
+// StatsJSON holds summary statistics for a graph. It is intended to be
+// serialised with encoding/json.
+type StatsJSON struct {
+	Nodes   int     `json:"nodes"`
+	Edges   int     `json:"edges"`
+	Density float64 `json:"density"`
+
+	Degree DegreeStats `json:"degree"`
+
+	// AverageClustering is the mean of the local
+	// clustering coefficients of all nodes.
+	AverageClustering float64 `json:"average_clustering"`
+	// Transitivity is the global clustering coefficient.
+	Transitivity float64 `json:"transitivity"`
+	// Triangles is the number of triangles in the graph.
+	Triangles int `json:"triangles"`
+
+	Components       int `json:"components"`
+	LargestComponent int `json:"largest_component"`
+
+	// Diameter holds bounds on the diameter of the
+	// largest connected component.
+	Diameter DiameterBounds `json:"diameter"`
+}
+
+// DegreeStats holds summary statistics of a degree distribution.
+type DegreeStats struct {
+	Min      int     `json:"min"`
+	Max      int     `json:"max"`
+	Mean     float64 `json:"mean"`
+	Variance float64 `json:"variance"`
+	Skew     float64 `json:"skew"`
+}
+
+// DiameterBounds holds lower and upper bounds on a graph diameter.
+type DiameterBounds struct {
+	Lower int `json:"lower"`
+	Upper int `json:"upper"`
+}
+
+// Stats returns summary statistics for g.
+func Stats(g *Graph) StatsJSON {
+	nodes := graph.NodesOf(g.Nodes())
+	s := StatsJSON{
+		Nodes: len(nodes),
+		Edges: g.Edges().Len(),
+	}
+	if s.Nodes == 0 {
+		return s
+	}
+	if s.Nodes > 1 {
+		s.Density = 2 * float64(s.Edges) / float64(s.Nodes*(s.Nodes-1))
+	}
+
+	deg := make([]float64, len(nodes))
+	s.Degree.Min = math.MaxInt32
+	for i, n := range nodes {
+		d := g.From(n.ID()).Len()
+		deg[i] = float64(d)
+		if d < s.Degree.Min {
+			s.Degree.Min = d
+		}
+		if d > s.Degree.Max {
+			s.Degree.Max = d
+		}
+	}
+	s.Degree.Mean = stat.Mean(deg, nil)
+	if len(deg) > 1 {
+		s.Degree.Variance = stat.Variance(deg, nil)
+		if s.Degree.Variance != 0 {
+			s.Degree.Skew = stat.Skew(deg, nil)
+		}
+	}
+
+	tri := triangles(g)
+	var sumC float64
+	var closed, triples float64
+	for i, n := range nodes {
+		t := float64(tri[n.ID()])
+		s.Triangles += tri[n.ID()]
+		k := deg[i]
+		if k > 1 {
+			sumC += 2 * t / (k * (k - 1))
+		}
+		closed += t
+		triples += k * (k - 1) / 2
+	}
+	s.Triangles /= 3
+	s.AverageClustering = sumC / float64(len(nodes))
+	if triples != 0 {
+		s.Transitivity = closed / triples
+	}
+
+	cc := topo.ConnectedComponents(g)
+	s.Components = len(cc)
+	var largest []graph.Node
+	for _, c := range cc {
+		if len(c) > len(largest) {
+			largest = c
+		}
+	}
+	s.LargestComponent = len(largest)
+	s.Diameter = diameterBounds(g, largest)
+
+	return s
+}
+
+// Triangles performs a triangle count on g.
+//
+// The number of triangles each node participates in is written into the "triangles"
+// attribute of each node.
+func Triangles(g *Graph) {
+	done, err := trace(g, "Triangles", []string{"triangles"})
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
+	tri := triangles(g)
+
+	nodes := g.NodeMap()
+	for id, t := range tri {
+		nodes[id].SetAttribute(encoding.Attribute{Key: "triangles", Value: fmt.Sprint(t)})
+	}
+}
+
+// Clustering performs a local clustering coefficient analysis on g.
+//
+// The clustering coefficient value is written into the "clustering" attribute of each node.
+func Clustering(g *Graph) {
+	done, err := trace(g, "Clustering", []string{"clustering"})
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
+	tri := triangles(g)
+
+	nodes := g.NodeMap()
+	for id, t := range tri {
+		var c float64
+		k := float64(g.From(id).Len())
+		if k > 1 {
+			c = 2 * float64(t) / (k * (k - 1))
+		}
+		nodes[id].SetAttribute(encoding.Attribute{Key: "clustering", Value: fmt.Sprint(c)})
+	}
+}
+
+// diameterBounds returns bounds on the diameter of the connected
+// component of g holding the nodes in c using repeated double sweeps
+// of breadth first searches.
+func diameterBounds(g graph.Undirected, c []graph.Node) DiameterBounds {
+	if len(c) == 0 {
+		return DiameterBounds{}
+	}
+	const sweeps = 4
+	lower, upper := 0, math.MaxInt32
+	from := c[0].ID()
+	for i := 0; i < sweeps; i++ {
+		dist := hops(g, from)
+		var ecc int
+		far := from
+		for id, d := range dist {
+			if d > ecc || (d == ecc && id < far) {
+				ecc, far = d, id
+			}
+		}
+		if ecc > lower {
+			lower = ecc
+		}
+		if 2*ecc < upper {
+			upper = 2 * ecc
+		}
+		if far == from {
+			break
+		}
+		from = far
+	}
+	return DiameterBounds{Lower: lower, Upper: upper}
+}
+
+// hops returns the number of hops from the node with the given ID to
+// each node reachable from it in g.
+func hops(g graph.Graph, from int64) map[int64]int {
+	dist := map[int64]int{from: 0}
+	queue := []int64{from}
+	for len(queue) != 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, v := range graph.NodesOf(g.From(u)) {
+			vid := v.ID()
+			if _, ok := dist[vid]; ok {
+				continue
+			}
+			dist[vid] = dist[u] + 1
+			queue = append(queue, vid)
+		}
+	}
+	return dist
+}
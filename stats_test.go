@@ -0,0 +1,43 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b -- c -- a; c -- d; e }`)
+	got := Stats(g)
+	want := StatsJSON{
+		Nodes:             5,
+		Edges:             4,
+		Density:           0.4,
+		Degree:            DegreeStats{Min: 0, Max: 3, Mean: 1.6, Variance: 1.3, Skew: -0.404796008910937},
+		AverageClustering: 7.0 / 15,
+		Transitivity:      0.6,
+		Triangles:         1,
+		Components:        2,
+		LargestComponent:  4,
+	}
+	const diameter = 2
+	if got.Diameter.Lower > diameter || got.Diameter.Upper < diameter {
+		t.Errorf("diameter bounds do not hold diameter %d: %+v", diameter, got.Diameter)
+	}
+	got.Diameter = DiameterBounds{}
+	for _, v := range []struct{ got, want *float64 }{
+		{&got.AverageClustering, &want.AverageClustering},
+		{&got.Degree.Variance, &want.Degree.Variance},
+		{&got.Degree.Skew, &want.Degree.Skew},
+	} {
+		if math.Abs(*v.got-*v.want) < 1e-12 {
+			*v.got = *v.want
+		}
+	}
+	if got != want {
+		t.Errorf("unexpected stats:\ngot: %+v\nwant:%+v", got, want)
+	}
+}
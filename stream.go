@@ -0,0 +1,207 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// streamEvents is the order in which the events held in a single stream
+// object are applied.
+var streamEvents = []string{"an", "cn", "ae", "ce", "de", "dn"}
+
+// ApplyStream applies the events in the Gephi graph streaming JSON stream
+// read from r to g. Each JSON object in the stream holds one or more
+// events keyed by the event type:
+//
+//	{"an":{"A":{"label":"Node A","size":2}}}
+//	{"an":{"B":{}}}
+//	{"ae":{"AB":{"source":"A","target":"B","weight":1}}}
+//	{"cn":{"A":{"size":null}}}
+//	{"de":{"AB":{}}}
+//	{"dn":{"A":{}}}
+//
+// The an, cn, dn, ae, ce and de events add, change and delete nodes and
+// edges. Nodes are identified by their DOT ID. The Gephi ID of an edge
+// added by the stream is held in its "id" attribute; ce and de events find
+// edges by their source and target if given, and by this ID otherwise. The
+// directed flag of edges is ignored. String attribute values are quoted
+// as DOT strings, and null values remove the attribute.
+//
+// The events of each object are applied before the next object is read,
+// so r may be a live feed. ApplyStream returns when r is exhausted or an
+// invalid event is read.
+func ApplyStream(g *Graph, r io.Reader) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	for i := 1; ; i++ {
+		var obj map[string]map[string]map[string]interface{}
+		err := dec.Decode(&obj)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("stream object %d: %v", i, err)
+		}
+		for k := range obj {
+			switch k {
+			case "an", "cn", "dn", "ae", "ce", "de":
+			default:
+				return fmt.Errorf("stream object %d: unknown event %q", i, k)
+			}
+		}
+		for _, typ := range streamEvents {
+			ids := make([]string, 0, len(obj[typ]))
+			for id := range obj[typ] {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			for _, id := range ids {
+				err = applyEvent(g, typ, id, obj[typ][id])
+				if err != nil {
+					return fmt.Errorf("stream object %d: %s %q: %v", i, typ, id, err)
+				}
+			}
+		}
+	}
+}
+
+// applyEvent applies the stream event of type typ for the node or edge
+// with the given Gephi ID to g.
+func applyEvent(g *Graph, typ, id string, attrs map[string]interface{}) error {
+	switch typ {
+	case "an":
+		n := g.NodeNamed(id)
+		if n == nil {
+			n = g.NewNode().(*Node)
+			n.Name = id
+			g.AddNode(n)
+		}
+		return setStreamAttributes(n, attrs)
+	case "cn":
+		n := g.NodeNamed(id)
+		if n == nil {
+			return fmt.Errorf("no node")
+		}
+		return setStreamAttributes(n, attrs)
+	case "dn":
+		n := g.NodeNamed(id)
+		if n == nil {
+			return fmt.Errorf("no node")
+		}
+		g.RemoveNode(n.ID())
+		return nil
+	case "ae":
+		u, v, err := streamEnds(g, attrs)
+		if err != nil {
+			return err
+		}
+		if u == nil || v == nil {
+			return fmt.Errorf("missing source or target")
+		}
+		if u == v {
+			return fmt.Errorf("self edge")
+		}
+		e := g.NewEdge(u, v).(*Edge)
+		e.SetQuoted("id", id)
+		return setStreamAttributes(e, attrs)
+	case "ce", "de":
+		e, err := streamEdge(g, id, attrs)
+		if err != nil {
+			return err
+		}
+		if typ == "de" {
+			g.RemoveEdge(e.F.ID(), e.T.ID())
+			return nil
+		}
+		return setStreamAttributes(e, attrs)
+	}
+	panic("cannot reach")
+}
+
+// streamEnds returns the nodes named by the source and target fields of
+// an edge event. Absent fields give nil nodes.
+func streamEnds(g *Graph, attrs map[string]interface{}) (u, v *Node, err error) {
+	for _, end := range []struct {
+		key  string
+		node **Node
+	}{{"source", &u}, {"target", &v}} {
+		name, ok := attrs[end.key]
+		if !ok {
+			continue
+		}
+		s, ok := name.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid %s: %v", end.key, name)
+		}
+		*end.node = g.NodeNamed(s)
+		if *end.node == nil {
+			return nil, nil, fmt.Errorf("no %s node %q", end.key, s)
+		}
+	}
+	return u, v, nil
+}
+
+// streamEdge returns the edge in g with the given Gephi ID, or the edge
+// between the source and target given in attrs.
+func streamEdge(g *Graph, id string, attrs map[string]interface{}) (*Edge, error) {
+	u, v, err := streamEnds(g, attrs)
+	if err != nil {
+		return nil, err
+	}
+	if u != nil && v != nil {
+		e, ok := g.EdgeBetween(u.ID(), v.ID()).(*Edge)
+		if !ok {
+			return nil, fmt.Errorf("no edge")
+		}
+		return e, nil
+	}
+	for _, e := range graph.EdgesOf(g.Edges()) {
+		e := e.(*Edge)
+		if e.GetUnquoted("id") == id {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("no edge")
+}
+
+// setStreamAttributes sets the attributes of a to the values in attrs,
+// other than the source and target of edges and the directed flag.
+func setStreamAttributes(a encoding.AttributeSetter, attrs map[string]interface{}) error {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		switch k {
+		case "source", "target", "directed":
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var val string
+		switch v := attrs[k].(type) {
+		case nil:
+			// Remove the attribute.
+		case string:
+			val = quoteDOT(v)
+		case json.Number, bool:
+			val = fmt.Sprint(v)
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			val = quoteDOT(string(b))
+		}
+		a.SetAttribute(encoding.Attribute{Key: k, Value: val})
+	}
+	return nil
+}
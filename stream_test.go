@@ -0,0 +1,82 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"strings"
+	"testing"
+)
+
+var streamTests = []struct {
+	stream  string
+	want    string
+	wantErr bool
+}{
+	{
+		stream: `{"an":{"A":{"label":"Node A","size":2}}}
+{"an":{"B":{}, "C":{}}}
+{"ae":{"AB":{"source":"A","target":"B","weight":1}}}
+{"ae":{"BC":{"source":"B","target":"C","directed":true}}}
+{"cn":{"A":{"size":null, "flag":true}}}
+{"ce":{"AB":{"weight":2.5}}}
+{"de":{"BC":{}}}`,
+		want: `graph {
+  // Node definitions.
+  A [
+    label="Node A"
+    flag=true
+  ];
+  B;
+  C;
+
+  // Edge definitions.
+  A -- B [
+    id="AB"
+    weight=2.5
+  ];
+}`,
+	},
+	{
+		// Events in one object are applied in
+		// add, change, delete order.
+		stream: `{"dn":{"A":{}},"an":{"A":{},"B":{}},"ae":{"AB":{"source":"A","target":"B"}}}`,
+		want: `graph {
+  // Node definitions.
+  B;
+}`,
+	},
+	{
+		stream: `{"an":{"A":{}}}
+{"ae":{"AB":{"source":"A","target":"B"}}}`,
+		want: `graph {
+  // Node definitions.
+  A;
+}`,
+		wantErr: true,
+	},
+	{
+		stream:  `{"xx":{"A":{}}}`,
+		want:    "graph {\n}",
+		wantErr: true,
+	},
+	{
+		stream:  `{"cn":{"A":{}}}`,
+		want:    "graph {\n}",
+		wantErr: true,
+	},
+}
+
+func TestApplyStream(t *testing.T) {
+	for i, test := range streamTests {
+		g := graphFromDOT(t, `graph {}`)
+		err := ApplyStream(g, strings.NewReader(test.stream))
+		if (err != nil) != test.wantErr {
+			t.Errorf("unexpected error state for test %d: got:%v want error:%t", i, err, test.wantErr)
+		}
+		if got := DOT(g); got != test.want {
+			t.Errorf("unexpected graph for test %d:\ngot:\n%s\nwant:\n%s", i, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// TemporalGraph is a graph whose edges are active only at particular
+// times. Each timed contact between two nodes is represented by an *Edge
+// with a "time" attribute holding the time of the contact.
+type TemporalGraph struct {
+	// Graph is the static projection of the
+	// temporal graph, with an edge between
+	// each pair of nodes that have at least
+	// one contact.
+	*Graph
+
+	// contacts holds the contacts ordered
+	// by time and times holds their times.
+	contacts []*Edge
+	times    []float64
+}
+
+// NewTemporalGraph returns a temporal graph with the nodes and edges of g.
+// The "time" attribute of each edge of g holds a comma-separated list of
+// the times of the contacts between its nodes. The contacts returned by
+// the TemporalGraph are copies of the edge of g with the "time" attribute
+// set to the time of the contact. NewTemporalGraph returns an error if an
+// edge has no "time" attribute or a time is not a number.
+func NewTemporalGraph(g *Graph) (*TemporalGraph, error) {
+	t := &TemporalGraph{Graph: g}
+	for _, e := range graph.EdgesOf(g.Edges()) {
+		e := e.(*Edge)
+		s := e.GetUnquoted("time")
+		if s == "" {
+			return nil, fmt.Errorf("no time for edge %q--%q", e.F.Name, e.T.Name)
+		}
+		for _, f := range strings.Split(s, ",") {
+			v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid time for edge %q--%q: %v", e.F.Name, e.T.Name, err)
+			}
+			c := &Edge{F: e.F, T: e.T, Attributes: append(Attributes(nil), e.Attributes...)}
+			c.SetAttribute(encoding.Attribute{Key: "time", Value: fmt.Sprint(v)})
+			t.contacts = append(t.contacts, c)
+			t.times = append(t.times, v)
+		}
+	}
+	sort.Sort(contactsByTime{t})
+	return t, nil
+}
+
+// Contacts returns the contacts of g ordered by time. Contacts at the same
+// time are ordered by the DOT IDs of their nodes.
+func (g *TemporalGraph) Contacts() []*Edge {
+	return append([]*Edge(nil), g.contacts...)
+}
+
+type contactsByTime struct {
+	*TemporalGraph
+}
+
+func (c contactsByTime) Len() int { return len(c.contacts) }
+func (c contactsByTime) Less(i, j int) bool {
+	if c.times[i] != c.times[j] {
+		return c.times[i] < c.times[j]
+	}
+	a, b := c.contacts[i], c.contacts[j]
+	if a.F.Name != b.F.Name {
+		return a.F.Name < b.F.Name
+	}
+	return a.T.Name < b.T.Name
+}
+func (c contactsByTime) Swap(i, j int) {
+	c.contacts[i], c.contacts[j] = c.contacts[j], c.contacts[i]
+	c.times[i], c.times[j] = c.times[j], c.times[i]
+}
+
+// TimeRespectingPath returns the contacts of a time-respecting path in g
+// from the node with DOT ID from to the node with DOT ID to that departs
+// at or after start and arrives as early as possible. In a
+// time-respecting path each contact is no earlier than the previous one,
+// and contacts take no time to traverse. A path between two nodes may
+// exist in the static projection of g but not in g itself, and paths need
+// not be symmetric. It returns an error if either node does not exist or
+// if there is no time-respecting path.
+func TimeRespectingPath(g *TemporalGraph, from, to string, start float64) ([]*Edge, error) {
+	u := g.NodeNamed(from)
+	if u == nil {
+		return nil, fmt.Errorf("no node %q", from)
+	}
+	v := g.NodeNamed(to)
+	if v == nil {
+		return nil, fmt.Errorf("no node %q", to)
+	}
+	arrival, via := earliestArrival(g, u.ID(), start, math.Inf(1))
+	if _, ok := arrival[v.ID()]; !ok {
+		return nil, fmt.Errorf("no time-respecting path from %q to %q after %v", from, to, start)
+	}
+	var path []*Edge
+	for id := v.ID(); id != u.ID(); {
+		c := via[id]
+		path = append(path, c)
+		if c.F.ID() == id {
+			id = c.T.ID()
+		} else {
+			id = c.F.ID()
+		}
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
+
+// TemporalReachability returns the earliest arrival time at each node of g
+// that can be reached from the node with DOT ID from by a time-respecting
+// path departing at or after start and arriving no later than
+// start+horizon, keyed by node ID. The arrival time of from itself is
+// start. It returns an error if from does not exist or if horizon is
+// negative.
+func TemporalReachability(g *TemporalGraph, from string, start, horizon float64) (map[int64]float64, error) {
+	u := g.NodeNamed(from)
+	if u == nil {
+		return nil, fmt.Errorf("no node %q", from)
+	}
+	if horizon < 0 || math.IsNaN(horizon) {
+		return nil, fmt.Errorf("invalid horizon: %v", horizon)
+	}
+	arrival, _ := earliestArrival(g, u.ID(), start, start+horizon)
+	return arrival, nil
+}
+
+// earliestArrival returns the earliest arrival times at the nodes of g
+// reachable by time-respecting paths from the node with ID from departing
+// at or after start and arriving no later than end, and the last contact
+// on the path to each node other than from.
+func earliestArrival(g *TemporalGraph, from int64, start, end float64) (arrival map[int64]float64, via map[int64]*Edge) {
+	arrival = map[int64]float64{from: start}
+	via = make(map[int64]*Edge)
+	i := sort.SearchFloat64s(g.times, start)
+	for i < len(g.contacts) && g.times[i] <= end {
+		// Contacts at the same time may form a chain
+		// in any order, so repeat them until no
+		// arrival time improves.
+		j := i
+		for j < len(g.contacts) && g.times[j] == g.times[i] {
+			j++
+		}
+		t := g.times[i]
+		for changed := true; changed; {
+			changed = false
+			for _, c := range g.contacts[i:j] {
+				for _, e := range [][2]int64{{c.F.ID(), c.T.ID()}, {c.T.ID(), c.F.ID()}} {
+					a, ok := arrival[e[0]]
+					if !ok || a > t {
+						continue
+					}
+					if b, ok := arrival[e[1]]; ok && b <= t {
+						continue
+					}
+					arrival[e[1]] = t
+					via[e[1]] = c
+					changed = true
+				}
+			}
+		}
+		i = j
+	}
+	return arrival, via
+}
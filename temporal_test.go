@@ -0,0 +1,127 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// temporalGraph has contacts such that d can reach a, but a cannot
+// reach d, and the chain e--f--g at a single time.
+const temporalGraph = `graph {
+	a -- b [time="1,5"];
+	b -- c [time=3];
+	c -- d [time=2];
+	b -- e [time=6];
+	f -- g [time=7];
+	e -- f [time=7];
+}`
+
+// contactStrings returns the contacts as "u--v@t" strings.
+func contactStrings(contacts []*Edge) []string {
+	s := make([]string, len(contacts))
+	for i, c := range contacts {
+		s[i] = c.F.Name + "--" + c.T.Name + "@" + c.Get("time")
+	}
+	return s
+}
+
+func newTemporalGraph(t *testing.T) *TemporalGraph {
+	t.Helper()
+	g, err := NewTemporalGraph(graphFromDOT(t, temporalGraph))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g
+}
+
+func TestContacts(t *testing.T) {
+	g := newTemporalGraph(t)
+	got := contactStrings(g.Contacts())
+	want := []string{"a--b@1", "c--d@2", "b--c@3", "a--b@5", "b--e@6", "e--f@7", "f--g@7"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected contacts: got:%q want:%q", got, want)
+	}
+
+	for _, dot := range []string{
+		`graph { a -- b }`,
+		`graph { a -- b [time="1,x"] }`,
+	} {
+		if _, err := NewTemporalGraph(graphFromDOT(t, dot)); err == nil {
+			t.Errorf("expected error for %s", dot)
+		}
+	}
+}
+
+var timeRespectingTests = []struct {
+	from, to string
+	start    float64
+	want     []string
+}{
+	{from: "a", to: "c", start: 0, want: []string{"a--b@1", "b--c@3"}},
+	{from: "d", to: "a", start: 0, want: []string{"c--d@2", "b--c@3", "a--b@5"}},
+	{from: "a", to: "g", start: 0, want: []string{"a--b@1", "b--e@6", "e--f@7", "f--g@7"}},
+	{from: "a", to: "b", start: 2, want: []string{"a--b@5"}},
+	{from: "a", to: "d", start: 0, want: nil},
+	{from: "c", to: "a", start: 4, want: nil},
+}
+
+func TestTimeRespectingPath(t *testing.T) {
+	g := newTemporalGraph(t)
+	for i, test := range timeRespectingTests {
+		path, err := TimeRespectingPath(g, test.from, test.to, test.start)
+		if test.want == nil {
+			if err == nil {
+				t.Errorf("expected error for test %d", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		if got := contactStrings(path); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected path for test %d: got:%q want:%q", i, got, test.want)
+		}
+	}
+}
+
+var reachabilityTests = []struct {
+	from           string
+	start, horizon float64
+	want           map[string]float64
+}{
+	{from: "a", start: 0, horizon: math.Inf(1), want: map[string]float64{"a": 0, "b": 1, "c": 3, "e": 6, "f": 7, "g": 7}},
+	{from: "a", start: 0, horizon: 2, want: map[string]float64{"a": 0, "b": 1}},
+	{from: "a", start: 2, horizon: 10, want: map[string]float64{"a": 2, "b": 5, "e": 6, "f": 7, "g": 7}},
+	{from: "g", start: 0, horizon: 10, want: map[string]float64{"g": 0, "f": 7, "e": 7}},
+}
+
+func TestTemporalReachability(t *testing.T) {
+	g := newTemporalGraph(t)
+	for i, test := range reachabilityTests {
+		arrival, err := TemporalReachability(g, test.from, test.start, test.horizon)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		got := make(map[string]float64)
+		for id, a := range arrival {
+			got[g.Node(id).(*Node).Name] = a
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("unexpected arrivals for test %d: got:%v want:%v", i, got, test.want)
+		}
+	}
+
+	if _, err := TemporalReachability(g, "z", 0, 1); err == nil {
+		t.Error("expected error for missing node")
+	}
+	if _, err := TemporalReachability(g, "a", 0, -1); err == nil {
+		t.Error("expected error for negative horizon")
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TileOptions holds options for ExportTiles.
+type TileOptions struct {
+	// Format is the tile format, "svg" or "json".
+	// The default is "json".
+	Format string
+
+	// Levels is the maximum number of coarsening
+	// levels exported above the graph itself.
+	Levels int
+
+	// Community is the node attribute splitting each
+	// level into tiles, for example the "community"
+	// attribute written by Communities. If it is
+	// empty, each level is exported as a single tile.
+	Community string
+
+	// Draw holds the options used to render SVG
+	// tiles. If Draw.Engine is empty, "sfdp" is used.
+	// The Format field of Draw is ignored.
+	Draw DrawOptions
+}
+
+// TileIndex is the index of the tiles written by ExportTiles, held in
+// the index.json file of the tile directory.
+type TileIndex struct {
+	Format string      `json:"format"`
+	Levels []TileLevel `json:"levels"`
+}
+
+// TileLevel describes the tiles of one level of the tile hierarchy.
+type TileLevel struct {
+	Level int    `json:"level"`
+	Nodes int    `json:"nodes"`
+	Edges int    `json:"edges"`
+	Tiles []Tile `json:"tiles"`
+}
+
+// Tile describes a single tile.
+type Tile struct {
+	Community string `json:"community"`
+	Path      string `json:"path"`
+	Nodes     int    `json:"nodes"`
+	Edges     int    `json:"edges"`
+}
+
+// ExportTiles writes a hierarchy of tiles of g into dir for the HTML
+// viewer, so that graphs too large to draw at once can be explored from
+// an overview down to their individual nodes. Level 0 of the hierarchy is
+// g itself, and each higher level is a coarsening of the one below it
+// made by Coarsen, up to opts.Levels levels. When opts.Community is set,
+// each level is split into a tile for each community, holding the
+// subgraph induced by its members. The community of a coarse node is the
+// most common community of the nodes of g it summarises, and nodes
+// without a community are held in a tile with an empty community.
+//
+// Tiles are written to dir/level-<level>/tile-<n>.<format>, either as an
+// SVG rendered by GraphViz or as a JSON object holding the nodes and
+// edges of the tile with their attributes. An index of the tiles is
+// written to dir/index.json as a TileIndex.
+func ExportTiles(g *Graph, dir string, opts TileOptions) error {
+	switch opts.Format {
+	case "":
+		opts.Format = "json"
+	case "json", "svg":
+	default:
+		return fmt.Errorf("invalid tile format: %q", opts.Format)
+	}
+	if opts.Levels < 0 {
+		return fmt.Errorf("invalid number of levels: %d", opts.Levels)
+	}
+	if opts.Draw.Engine == "" {
+		opts.Draw.Engine = "sfdp"
+	}
+	opts.Draw.Format = "svg"
+
+	coarse, err := Coarsen(g, opts.Levels)
+	if err != nil {
+		return err
+	}
+	levels := append([]*Graph{g}, coarse...)
+
+	var community map[string]string
+	if opts.Community != "" {
+		community = make(map[string]string)
+		for _, n := range NodesOf(g) {
+			community[n.Name] = n.GetUnquoted(opts.Community)
+		}
+	}
+
+	index := TileIndex{Format: opts.Format}
+	for l, lg := range levels {
+		level := TileLevel{Level: l, Nodes: lg.Nodes().Len(), Edges: lg.Edges().Len()}
+		name := fmt.Sprintf("level-%d", l)
+		err = os.MkdirAll(filepath.Join(dir, name), 0755)
+		if err != nil {
+			return err
+		}
+		for i, t := range tileMembers(lg, l != 0, community) {
+			sub := subgraph(lg, t.keep)
+			path := filepath.Join(name, fmt.Sprintf("tile-%d.%s", i, opts.Format))
+			err = writeTile(filepath.Join(dir, path), sub, opts)
+			if err != nil {
+				return fmt.Errorf("level %d community %q: %v", l, t.community, err)
+			}
+			level.Tiles = append(level.Tiles, Tile{
+				Community: t.community,
+				Path:      filepath.ToSlash(path),
+				Nodes:     sub.Nodes().Len(),
+				Edges:     sub.Edges().Len(),
+			})
+		}
+		index.Levels = append(index.Levels, level)
+	}
+
+	b, err := json.MarshalIndent(index, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "index.json"), b, 0644)
+}
+
+// tileSet is the set of nodes of a tile.
+type tileSet struct {
+	community string
+	keep      map[int64]int
+}
+
+// tileMembers returns the node sets of the tiles of g, sorted by
+// community. If community is nil, a single tile holding all the nodes of g
+// is returned. Otherwise, community maps the DOT ID of each node of the
+// original graph to its community, and if coarse is true, the community of
+// each node of g is the most common community of its members.
+func tileMembers(g *Graph, coarse bool, community map[string]string) []tileSet {
+	sets := make(map[string]map[int64]int)
+	for _, n := range NodesOf(g) {
+		var c string
+		switch {
+		case community == nil:
+		case coarse:
+			c = majorityCommunity(strings.Split(n.GetUnquoted("members"), ","), community)
+		default:
+			c = community[n.Name]
+		}
+		if sets[c] == nil {
+			sets[c] = make(map[int64]int)
+		}
+		sets[c][n.ID()] = 0
+	}
+	tiles := make([]tileSet, 0, len(sets))
+	for c, keep := range sets {
+		tiles = append(tiles, tileSet{community: c, keep: keep})
+	}
+	sort.Slice(tiles, func(i, j int) bool { return tiles[i].community < tiles[j].community })
+	return tiles
+}
+
+// majorityCommunity returns the most common community of the named
+// members, choosing the lowest sorting community when there is a tie.
+func majorityCommunity(members []string, community map[string]string) string {
+	counts := make(map[string]int)
+	for _, m := range members {
+		counts[community[m]]++
+	}
+	var best string
+	max := -1
+	for c, n := range counts {
+		if n > max || (n == max && c < best) {
+			best, max = c, n
+		}
+	}
+	return best
+}
+
+// writeTile writes the tile g to path in the format given by opts.
+func writeTile(path string, g *Graph, opts TileOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if opts.Format == "svg" {
+		err = DrawTo(f, g, opts.Draw)
+	} else {
+		err = json.NewEncoder(f).Encode(tileJSON(g))
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// tileGraph is the JSON representation of a tile.
+type tileGraph struct {
+	Nodes []tileNode `json:"nodes"`
+	Edges []tileEdge `json:"edges"`
+}
+
+// tileNode is a node of a JSON tile.
+type tileNode struct {
+	ID         string            `json:"id"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// tileEdge is an edge of a JSON tile.
+type tileEdge struct {
+	Source     string            `json:"source"`
+	Target     string            `json:"target"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// tileJSON returns the JSON representation of g, with nodes in node ID
+// order and edges ordered by the node IDs of their ends.
+func tileJSON(g *Graph) tileGraph {
+	x := g.Index()
+	t := tileGraph{Nodes: []tileNode{}, Edges: []tileEdge{}}
+	for i, to := range x.adjacency(g) {
+		n := x.Node(i)
+		t.Nodes = append(t.Nodes, tileNode{ID: n.Name, Attributes: tileAttributes(n.Attributes)})
+		for _, j := range to {
+			if j < i {
+				continue
+			}
+			e := g.EdgeBetween(x.ID(i), x.ID(j)).(*Edge)
+			t.Edges = append(t.Edges, tileEdge{
+				Source:     n.Name,
+				Target:     x.Node(j).Name,
+				Attributes: tileAttributes(e.Attributes),
+			})
+		}
+	}
+	return t
+}
+
+// tileAttributes returns the attributes in a with the export policy
+// applied and any DOT quoting removed, or nil if there are none.
+func tileAttributes(a Attributes) map[string]string {
+	a = exportAttributes(a)
+	if len(a) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(a))
+	for _, attr := range a {
+		m[attr.Key] = a.GetUnquoted(attr.Key)
+	}
+	return m
+}
@@ -0,0 +1,152 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const tileDOT = `graph {
+	a [c=x]; b [c=x]; c [c=x];
+	d [c=y]; e [c=y]; f [c=y];
+	g;
+	a -- b; b -- c; c -- a;
+	c -- d [weight=2];
+	d -- e; e -- f; f -- d;
+}`
+
+func TestExportTiles(t *testing.T) {
+	g := graphFromDOT(t, tileDOT)
+
+	dir, err := ioutil.TempDir("", "graphprac-tiles")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = ExportTiles(g, dir, TileOptions{Levels: 1, Community: "c"})
+	if err != nil {
+		t.Fatalf("unexpected error exporting tiles: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "index.json"))
+	if err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+	var index TileIndex
+	err = json.Unmarshal(b, &index)
+	if err != nil {
+		t.Fatalf("failed to decode index: %v", err)
+	}
+	want := TileIndex{
+		Format: "json",
+		Levels: []TileLevel{
+			{Level: 0, Nodes: 7, Edges: 7, Tiles: []Tile{
+				{Community: "", Path: "level-0/tile-0.json", Nodes: 1, Edges: 0},
+				{Community: "x", Path: "level-0/tile-1.json", Nodes: 3, Edges: 3},
+				{Community: "y", Path: "level-0/tile-2.json", Nodes: 3, Edges: 3},
+			}},
+			{Level: 1, Nodes: 4, Edges: 2, Tiles: []Tile{
+				{Community: "", Path: "level-1/tile-0.json", Nodes: 1, Edges: 0},
+				{Community: "x", Path: "level-1/tile-1.json", Nodes: 2, Edges: 1},
+				{Community: "y", Path: "level-1/tile-2.json", Nodes: 1, Edges: 0},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(index, want) {
+		t.Errorf("unexpected index:\ngot: %+v\nwant:%+v", index, want)
+	}
+
+	for _, l := range index.Levels {
+		for _, tile := range l.Tiles {
+			b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(tile.Path)))
+			if err != nil {
+				t.Errorf("failed to read tile %s: %v", tile.Path, err)
+				continue
+			}
+			var tg tileGraph
+			err = json.Unmarshal(b, &tg)
+			if err != nil {
+				t.Errorf("failed to decode tile %s: %v", tile.Path, err)
+				continue
+			}
+			if len(tg.Nodes) != tile.Nodes || len(tg.Edges) != tile.Edges {
+				t.Errorf("unexpected tile size for %s: got:%d/%d want:%d/%d",
+					tile.Path, len(tg.Nodes), len(tg.Edges), tile.Nodes, tile.Edges)
+			}
+		}
+	}
+
+	b, err = ioutil.ReadFile(filepath.Join(dir, "level-0", "tile-1.json"))
+	if err != nil {
+		t.Fatalf("failed to read tile: %v", err)
+	}
+	var got tileGraph
+	err = json.Unmarshal(b, &got)
+	if err != nil {
+		t.Fatalf("failed to decode tile: %v", err)
+	}
+	wantTile := tileGraph{
+		Nodes: []tileNode{
+			{ID: "a", Attributes: map[string]string{"c": "x"}},
+			{ID: "b", Attributes: map[string]string{"c": "x"}},
+			{ID: "c", Attributes: map[string]string{"c": "x"}},
+		},
+		Edges: []tileEdge{
+			{Source: "a", Target: "b"},
+			{Source: "a", Target: "c"},
+			{Source: "b", Target: "c"},
+		},
+	}
+	if !reflect.DeepEqual(got, wantTile) {
+		t.Errorf("unexpected tile:\ngot: %+v\nwant:%+v", got, wantTile)
+	}
+}
+
+var majorityCommunityTests = []struct {
+	members []string
+	want    string
+}{
+	{members: []string{"a", "b", "c"}, want: "x"},
+	{members: []string{"a", "d", "e"}, want: "y"},
+	{members: []string{"a", "d"}, want: "x"},
+	{members: []string{"d", "a"}, want: "x"},
+	{members: []string{"g"}, want: ""},
+}
+
+func TestMajorityCommunity(t *testing.T) {
+	community := map[string]string{"a": "x", "b": "x", "c": "x", "d": "y", "e": "y"}
+	for i, test := range majorityCommunityTests {
+		got := majorityCommunity(test.members, community)
+		if got != test.want {
+			t.Errorf("unexpected community for test %d: got:%q want:%q", i, got, test.want)
+		}
+	}
+}
+
+func TestExportTilesErrors(t *testing.T) {
+	g := graphFromDOT(t, tileDOT)
+
+	dir, err := ioutil.TempDir("", "graphprac-tiles")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i, opts := range []TileOptions{
+		{Format: "png"},
+		{Levels: -1},
+	} {
+		err := ExportTiles(g, dir, opts)
+		if err == nil {
+			t.Errorf("expected error for test %d", i)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// Timing holds the resource use of calls to an analysis recorded during
+// a session. It is intended to be serialised with encoding/json.
+type Timing struct {
+	Analysis string `json:"analysis"`
+	Calls    int    `json:"calls"`
+
+	// Duration is the total wall clock time
+	// of the calls, in nanoseconds when
+	// serialised.
+	Duration time.Duration `json:"duration"`
+
+	// Bytes and Allocs are the total number of
+	// bytes and heap objects allocated during
+	// the calls. Allocations made concurrently
+	// by other goroutines are included.
+	Bytes  uint64 `json:"bytes"`
+	Allocs uint64 `json:"allocs"`
+}
+
+var (
+	timingsMu sync.Mutex
+	timings   = make(map[string]*Timing)
+	recording bool
+	annotate  bool
+)
+
+// SetRecordTimings sets whether the resource use of calls to the
+// registered analyses is recorded for Timings. Recording is disabled by
+// default since measuring allocations briefly stops the program.
+func SetRecordTimings(on bool) {
+	timingsMu.Lock()
+	recording = on
+	timingsMu.Unlock()
+}
+
+// recordingTimings returns whether timings are being recorded.
+func recordingTimings() bool {
+	timingsMu.Lock()
+	defer timingsMu.Unlock()
+	return recording
+}
+
+// Timings returns the resource use of each of the registered analyses
+// called while recording was enabled by SetRecordTimings, since the start
+// of the session or the last call to ResetTimings, ordered by analysis
+// name.
+func Timings() []Timing {
+	timingsMu.Lock()
+	defer timingsMu.Unlock()
+	t := make([]Timing, 0, len(timings))
+	for _, v := range timings {
+		t = append(t, *v)
+	}
+	sort.Slice(t, func(i, j int) bool { return t[i].Analysis < t[j].Analysis })
+	return t
+}
+
+// ResetTimings discards the recorded analysis timings.
+func ResetTimings() {
+	timingsMu.Lock()
+	timings = make(map[string]*Timing)
+	timingsMu.Unlock()
+}
+
+// SetAnnotateTimings sets whether the registered analyses write the wall
+// clock time of each call in milliseconds into the "analysis_ms_<name>"
+// graph attribute of the analysed graph, for example "analysis_ms_PageRank".
+// Annotation is disabled by default.
+func SetAnnotateTimings(on bool) {
+	timingsMu.Lock()
+	annotate = on
+	timingsMu.Unlock()
+}
+
+// record adds a call of the named analysis of g with the provided resource
+// use to the session timings if they are being recorded, annotating g if
+// requested.
+func record(g analysed, name string, d time.Duration, bytes, allocs uint64) {
+	timingsMu.Lock()
+	if recording {
+		t, ok := timings[name]
+		if !ok {
+			t = &Timing{Analysis: name}
+			timings[name] = t
+		}
+		t.Calls++
+		t.Duration += d
+		t.Bytes += bytes
+		t.Allocs += allocs
+	}
+	a := annotate
+	timingsMu.Unlock()
+
+	if a {
+		ms := float64(d) / float64(time.Millisecond)
+		g.graphAttributes().SetAttribute(encoding.Attribute{Key: "analysis_ms_" + name, Value: fmt.Sprint(ms)})
+	}
+}
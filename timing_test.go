@@ -0,0 +1,66 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"testing"
+)
+
+func TestTimings(t *testing.T) {
+	defer ResetTimings()
+	g := graphFromDOT(t, `graph { a -- b -- c }`)
+
+	ResetTimings()
+	Farness(g)
+	if got := Timings(); len(got) != 0 {
+		t.Errorf("unexpected timings when not recording: %+v", got)
+	}
+
+	SetRecordTimings(true)
+	Farness(g)
+	Harmonic(g)
+	Farness(g)
+	SetRecordTimings(false)
+	Harmonic(g)
+
+	got := Timings()
+	if len(got) != 2 {
+		t.Fatalf("unexpected number of timings: got:%d want:2", len(got))
+	}
+	for i, want := range []struct {
+		name  string
+		calls int
+	}{
+		{name: "Farness", calls: 2},
+		{name: "Harmonic", calls: 1},
+	} {
+		if got[i].Analysis != want.name || got[i].Calls != want.calls {
+			t.Errorf("unexpected timing %d: got:%s with %d calls want:%s with %d calls",
+				i, got[i].Analysis, got[i].Calls, want.name, want.calls)
+		}
+		if got[i].Allocs == 0 {
+			t.Errorf("no allocations recorded for %s", got[i].Analysis)
+		}
+	}
+
+	ResetTimings()
+	if got := Timings(); len(got) != 0 {
+		t.Errorf("unexpected timings after reset: %+v", got)
+	}
+}
+
+func TestAnnotateTimings(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b -- c }`)
+	SetAnnotateTimings(true)
+	Farness(g)
+	SetAnnotateTimings(false)
+	Harmonic(g)
+	if g.GraphAttrs.Get("analysis_ms_Farness") == "" {
+		t.Error("missing timing annotation")
+	}
+	if g.GraphAttrs.Get("analysis_ms_Harmonic") != "" {
+		t.Error("unexpected timing annotation when annotation disabled")
+	}
+}
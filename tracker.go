@@ -0,0 +1,231 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// Tracker records changes made to a graph after a full analysis and
+// incrementally recomputes node degree, connected component membership
+// and PageRank.
+//
+// The degree, component identity and PageRank values are written into the
+// "degree", "component" and "rank" attributes of each node. Component
+// identities are arbitrary and are not contiguous after updates.
+type Tracker struct {
+	g         *Graph
+	damp, tol float64
+
+	rank map[int64]float64
+	comp map[int64]int
+	next int
+
+	// touched holds nodes whose degree has changed,
+	// and dirty holds components that may have merged
+	// or split since the last update.
+	touched map[int64]bool
+	dirty   map[int]bool
+	changed bool
+}
+
+// NewTracker performs a full degree, connected components and PageRank
+// analysis of g using the provided damping and tolerance parameters, and
+// returns a Tracker for subsequent changes to g. Changes to g must be
+// made through the returned Tracker.
+func NewTracker(g *Graph, damp, tol float64) *Tracker {
+	t := &Tracker{
+		g:       g,
+		damp:    damp,
+		tol:     tol,
+		comp:    make(map[int64]int),
+		touched: make(map[int64]bool),
+		dirty:   make(map[int]bool),
+	}
+	for _, n := range graph.NodesOf(g.Nodes()) {
+		t.touched[n.ID()] = true
+		t.comp[n.ID()] = -1
+	}
+	t.dirty[-1] = true
+	t.changed = true
+	t.Update()
+	return t
+}
+
+// AddNode adds n to the graph.
+func (t *Tracker) AddNode(n *Node) {
+	t.g.AddNode(n)
+	t.touched[n.ID()] = true
+	t.comp[n.ID()] = -1
+	t.dirty[-1] = true
+	t.changed = true
+}
+
+// RemoveNode removes the node with the given ID and its edges from the
+// graph.
+func (t *Tracker) RemoveNode(id int64) {
+	if t.g.Node(id) == nil {
+		return
+	}
+	for _, v := range graph.NodesOf(t.g.From(id)) {
+		t.touched[v.ID()] = true
+	}
+	t.dirty[t.component(id)] = true
+	t.g.RemoveNode(id)
+	delete(t.comp, id)
+	delete(t.rank, id)
+	delete(t.touched, id)
+	t.changed = true
+}
+
+// SetEdge adds an edge between the nodes u and v to the graph, returning
+// the new edge, or the existing edge if already present.
+func (t *Tracker) SetEdge(u, v *Node) graph.Edge {
+	cu, cv := t.component(u.ID()), t.component(v.ID())
+	e := t.g.NewEdge(u, v)
+	t.touched[u.ID()] = true
+	t.touched[v.ID()] = true
+	if cu != cv {
+		t.dirty[cu] = true
+		t.dirty[cv] = true
+	}
+	t.changed = true
+	return e
+}
+
+// RemoveEdge removes the edge between the nodes with IDs uid and vid
+// from the graph.
+func (t *Tracker) RemoveEdge(uid, vid int64) {
+	if !t.g.HasEdgeBetween(uid, vid) {
+		return
+	}
+	t.g.RemoveEdge(uid, vid)
+	t.touched[uid] = true
+	t.touched[vid] = true
+	t.dirty[t.component(uid)] = true
+	t.changed = true
+}
+
+// component returns the component label of the node with the given ID.
+// Nodes not added through t are given a fresh label so that they are not
+// confused with an existing component.
+func (t *Tracker) component(id int64) int {
+	c, ok := t.comp[id]
+	if !ok {
+		c = t.next
+		t.next++
+		t.comp[id] = c
+		t.touched[id] = true
+		t.dirty[c] = true
+	}
+	return c
+}
+
+// Update recomputes the degree, component membership and PageRank of
+// nodes affected by changes since the last update. PageRank iteration is
+// restarted from the previous rank vector.
+func (t *Tracker) Update() {
+	if !t.changed {
+		return
+	}
+	nodes := t.g.NodeMap()
+
+	for id := range t.touched {
+		n := nodes[id]
+		n.SetAttribute(encoding.Attribute{Key: "degree", Value: fmt.Sprint(t.g.From(id).Len())})
+	}
+	t.touched = make(map[int64]bool)
+
+	// Relabel nodes in components that may have merged or
+	// split. Searches from these nodes can only reach nodes
+	// in other dirty components.
+	seen := make(map[int64]bool)
+	for id, c := range t.comp {
+		if !t.dirty[c] || seen[id] {
+			continue
+		}
+		label := t.next
+		t.next++
+		for rid := range hops(t.g, id) {
+			seen[rid] = true
+			t.comp[rid] = label
+			nodes[rid].SetAttribute(encoding.Attribute{Key: "component", Value: fmt.Sprint(label)})
+		}
+	}
+	t.dirty = make(map[int]bool)
+
+	t.rank = pageRankFrom(t.g, t.damp, t.tol, t.rank)
+	for id, w := range t.rank {
+		nodes[id].SetAttribute(encoding.Attribute{Key: "rank", Value: fmt.Sprint(w)})
+	}
+	t.changed = false
+}
+
+// pageRankFrom returns the PageRank weights for nodes of the undirected
+// graph g treated as a directed graph with edges in both directions. The
+// power iteration starts from the rank vector init, with nodes absent from
+// init given equal starting weight, and terminates when the 2-norm of the
+// vector difference between iterations is below tol.
+func pageRankFrom(g graph.Undirected, damp, tol float64, init map[int64]float64) map[int64]float64 {
+	x := newNodeIndex(g)
+	n := x.Len()
+	if n == 0 {
+		return map[int64]float64{}
+	}
+	adj := x.adjacency(g)
+
+	last := make([]float64, n)
+	var sum float64
+	for i, u := range x.nodes {
+		w, ok := init[u.ID()]
+		if !ok {
+			w = 1 / float64(n)
+		}
+		last[i] = w
+		sum += w
+	}
+	for i := range last {
+		last[i] /= sum
+	}
+
+	vec := make([]float64, n)
+	for {
+		var dangling float64
+		for i := range vec {
+			vec[i] = 0
+		}
+		for i, to := range adj {
+			if len(to) == 0 {
+				dangling += last[i]
+				continue
+			}
+			w := damp * last[i] / float64(len(to))
+			for _, j := range to {
+				vec[j] += w
+			}
+		}
+		base := (damp*dangling + (1 - damp)) / float64(n)
+		var diff float64
+		for i := range vec {
+			vec[i] += base
+			d := vec[i] - last[i]
+			diff += d * d
+		}
+		last, vec = vec, last
+		if math.Sqrt(diff) < tol {
+			break
+		}
+	}
+
+	rank := make(map[int64]float64, n)
+	for i, u := range x.nodes {
+		rank[u.ID()] = last[i]
+	}
+	return rank
+}
@@ -0,0 +1,71 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"gonum.org/v1/gonum/graph/network"
+)
+
+func TestTracker(t *testing.T) {
+	g := graphFromDOT(t, `graph { a -- b -- c; d -- e }`)
+	tr := NewTracker(g, 0.85, 1e-10)
+	checkTracker(t, "initial", g, [][]string{{"a", "b", "c"}, {"d", "e"}})
+
+	n := func(name string) *Node { return g.NodeNamed(name) }
+
+	tr.SetEdge(n("c"), n("d"))
+	tr.Update()
+	checkTracker(t, "after join", g, [][]string{{"a", "b", "c", "d", "e"}})
+
+	tr.RemoveEdge(n("a").ID(), n("b").ID())
+	tr.Update()
+	checkTracker(t, "after split", g, [][]string{{"a"}, {"b", "c", "d", "e"}})
+
+	// Nodes added to the graph directly are not
+	// known to the tracker until they are used.
+	f := g.NewNode().(*Node)
+	f.Name = "f"
+	g.AddNode(f)
+	tr.SetEdge(f, n("a"))
+	tr.Update()
+	checkTracker(t, "after untracked node", g, [][]string{{"a", "f"}, {"b", "c", "d", "e"}})
+
+	tr.RemoveNode(n("c").ID())
+	tr.Update()
+	checkTracker(t, "after node removal", g, [][]string{{"a", "f"}, {"b"}, {"d", "e"}})
+}
+
+// checkTracker checks that the attributes written by a Tracker match
+// those of a full analysis of g, and that the nodes in each of comps
+// share a component label distinct from the other components.
+func checkTracker(t *testing.T, step string, g *Graph, comps [][]string) {
+	t.Helper()
+	rank := network.PageRank(directed{g}, 0.85, 1e-10)
+	for id, n := range g.NodeMap() {
+		if got, want := n.Get("degree"), strconv.Itoa(g.From(id).Len()); got != want {
+			t.Errorf("unexpected degree for %s %s: got:%s want:%s", n.Name, step, got, want)
+		}
+		if got := floatAttr(t, g, n.Name, "rank"); math.Abs(got-rank[id]) > 1e-6 {
+			t.Errorf("unexpected rank for %s %s: got:%v want:%v", n.Name, step, got, rank[id])
+		}
+	}
+	seen := make(map[string]bool)
+	for _, c := range comps {
+		label := g.NodeNamed(c[0]).Get("component")
+		if seen[label] {
+			t.Errorf("component label %s reused %s", label, step)
+		}
+		seen[label] = true
+		for _, name := range c[1:] {
+			if got := g.NodeNamed(name).Get("component"); got != label {
+				t.Errorf("unexpected component for %s %s: got:%s want:%s", name, step, got, label)
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/mat"
+)
+
+// TrophicLevels performs a trophic level analysis on g, where edges point
+// in the direction of energy flow, from prey to predator. Basal nodes,
+// those without incoming edges, have a trophic level of 1, and the level
+// of every other node is one more than the weighted mean of the levels of
+// the nodes with edges to it (Levine doi:10.1016/0022-5193(80)90288-X).
+// Edge weights are given by the "weight" attribute of each edge, or 1 if
+// it is not set.
+//
+// The levels are found by solving the linear system s_j k_j - Σ_i w_ij s_i
+// = k_j, where k_j is the weighted in-degree of node j, over the
+// non-basal nodes. TrophicLevels returns an error if a node cannot be
+// reached from a basal node, since its level is then undefined.
+//
+// The trophic level is written into the "trophic" attribute of each node.
+func TrophicLevels(g *Digraph) (err error) {
+	done, err := trace(g, "TrophicLevels", []string{"trophic"})
+	if err != nil {
+		return err
+	}
+	defer func() { done(err) }()
+
+	x, s, _, err := trophicLevels(g)
+	if err != nil {
+		return err
+	}
+	for i, v := range s {
+		x.Node(i).SetAttribute(encoding.Attribute{Key: "trophic", Value: fmt.Sprint(v)})
+	}
+	return nil
+}
+
+// TrophicIncoherence returns the trophic incoherence parameter q of g
+// (Johnson et al. doi:10.1073/pnas.1409077111), the weighted standard
+// deviation of the trophic distances s_j - s_i over the edges i→j of g,
+// with trophic levels found as for TrophicLevels. A value of zero
+// indicates a perfectly coherent network, where every edge joins
+// adjacent levels. TrophicIncoherence returns an error if the trophic
+// levels of g are undefined or g has no edges.
+func TrophicIncoherence(g *Digraph) (float64, error) {
+	_, s, w, err := trophicLevels(g)
+	if err != nil {
+		return 0, err
+	}
+	var sumW, sumX float64
+	for _, e := range w {
+		d := s[e.j] - s[e.i]
+		sumW += e.w
+		sumX += e.w * d
+	}
+	if sumW == 0 {
+		return 0, fmt.Errorf("no weighted edges")
+	}
+	mean := sumX / sumW
+	var ss float64
+	for _, e := range w {
+		d := s[e.j] - s[e.i] - mean
+		ss += e.w * d * d
+	}
+	return math.Sqrt(ss / sumW), nil
+}
+
+// trophicEdge is a weighted edge between indexed nodes.
+type trophicEdge struct {
+	i, j int
+	w    float64
+}
+
+// trophicLevels returns the node index of g, the trophic levels of the
+// nodes of g in index order and the weighted edges of g.
+func trophicLevels(g *Digraph) (NodeIndex, []float64, []trophicEdge, error) {
+	x := g.Index()
+	out := x.adjacency(g)
+	n := x.Len()
+
+	var edges []trophicEdge
+	in := make([]float64, n)
+	for i, to := range out {
+		for _, j := range to {
+			e := g.Edge(x.ID(i), x.ID(j)).(*Edge)
+			v, err := edgeWeight(e, "weight")
+			if err != nil {
+				return x, nil, nil, err
+			}
+			if v < 0 {
+				return x, nil, nil, fmt.Errorf("negative weight for edge %q->%q: %v", e.F.Name, e.T.Name, v)
+			}
+			edges = append(edges, trophicEdge{i: i, j: j, w: v})
+			in[j] += v
+		}
+	}
+
+	// Check that every node is reachable from a
+	// basal node through edges with weight.
+	seen := make([]bool, n)
+	var queue []int
+	for i, k := range in {
+		if k == 0 {
+			seen[i] = true
+			queue = append(queue, i)
+		}
+	}
+	wout := make([][]trophicEdge, n)
+	for _, e := range edges {
+		if e.w != 0 {
+			wout[e.i] = append(wout[e.i], e)
+		}
+	}
+	for len(queue) != 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, e := range wout[u] {
+			if !seen[e.j] {
+				seen[e.j] = true
+				queue = append(queue, e.j)
+			}
+		}
+	}
+	for i, ok := range seen {
+		if !ok {
+			return x, nil, nil, fmt.Errorf("trophic level undefined for node %q: not reachable from a basal node", x.Node(i).Name)
+		}
+	}
+
+	s := make([]float64, n)
+	index := make([]int, n)
+	var m int
+	for i, k := range in {
+		s[i] = 1
+		index[i] = -1
+		if k != 0 {
+			index[i] = m
+			m++
+		}
+	}
+	if m == 0 {
+		return x, s, edges, nil
+	}
+	a := mat.NewDense(m, m, nil)
+	b := mat.NewVecDense(m, nil)
+	for i, k := range in {
+		if r := index[i]; r >= 0 {
+			a.Set(r, r, k)
+			b.SetVec(r, k)
+		}
+	}
+	for _, e := range edges {
+		r := index[e.j]
+		if r < 0 {
+			continue
+		}
+		if c := index[e.i]; c >= 0 {
+			a.Set(r, c, a.At(r, c)-e.w)
+		} else {
+			b.SetVec(r, b.AtVec(r)+e.w)
+		}
+	}
+	var sol mat.VecDense
+	err := sol.SolveVec(a, b)
+	if err != nil {
+		return x, nil, nil, fmt.Errorf("failed to solve for trophic levels: %v", err)
+	}
+	for i, r := range index {
+		if r >= 0 {
+			s[i] = sol.AtVec(r)
+		}
+	}
+	return x, s, edges, nil
+}
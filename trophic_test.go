@@ -0,0 +1,85 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+var trophicTests = []struct {
+	dot         string
+	want        map[string]float64
+	incoherence float64
+}{
+	{
+		dot:         `digraph { a -> b -> c }`,
+		want:        map[string]float64{"a": 1, "b": 2, "c": 3},
+		incoherence: 0,
+	},
+	{
+		dot:         `digraph { a -> b; a -> c; b -> c }`,
+		want:        map[string]float64{"a": 1, "b": 2, "c": 2.5},
+		incoherence: math.Sqrt(1.0 / 6),
+	},
+	{
+		dot:         `digraph { a -> c [weight=3]; b -> c; d }`,
+		want:        map[string]float64{"a": 1, "b": 1, "c": 2, "d": 1},
+		incoherence: 0,
+	},
+	{
+		// The levels of b and c satisfy s_b = 1 + (1+s_c)/2
+		// and s_c = 1 + s_b.
+		dot:         `digraph { a -> b -> c -> b }`,
+		want:        map[string]float64{"a": 1, "b": 4, "c": 5},
+		incoherence: math.Sqrt(8.0 / 3),
+	},
+}
+
+func TestTrophicLevels(t *testing.T) {
+	for i, test := range trophicTests {
+		g := digraphFromDOT(t, test.dot)
+		err := TrophicLevels(g)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		for _, n := range g.NodeMap() {
+			got, err := strconv.ParseFloat(n.Get("trophic"), 64)
+			if err != nil {
+				t.Errorf("failed to parse trophic level of %s for test %d: %v", n.Name, i, err)
+				continue
+			}
+			if want := test.want[n.Name]; math.Abs(got-want) > 1e-12 {
+				t.Errorf("unexpected trophic level of %s for test %d: got:%v want:%v", n.Name, i, got, want)
+			}
+		}
+		q, err := TrophicIncoherence(g)
+		if err != nil {
+			t.Errorf("unexpected incoherence error for test %d: %v", i, err)
+			continue
+		}
+		if math.Abs(q-test.incoherence) > 1e-12 {
+			t.Errorf("unexpected incoherence for test %d: got:%v want:%v", i, q, test.incoherence)
+		}
+	}
+}
+
+func TestTrophicErrors(t *testing.T) {
+	for i, dot := range []string{
+		`digraph { a -> b -> a }`,
+		`digraph { a -> b; c -> d -> c }`,
+		`digraph { a -> b [weight=-1] }`,
+		`digraph { a -> b [weight=heavy] }`,
+	} {
+		if err := TrophicLevels(digraphFromDOT(t, dot)); err == nil {
+			t.Errorf("expected error for test %d", i)
+		}
+	}
+	if _, err := TrophicIncoherence(digraphFromDOT(t, `digraph { a; b }`)); err == nil {
+		t.Error("expected incoherence error for graph without edges")
+	}
+}
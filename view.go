@@ -0,0 +1,77 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"sort"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// view is a named induced subgraph of a Graph.
+type view struct {
+	pred func(*Node) bool
+
+	// induced is the cached view, or
+	// nil if it must be recomputed.
+	induced graph.Graph
+}
+
+// DefineView defines a named view of g induced by the nodes for which pred
+// returns true. Views are computed when first requested with View and the
+// result is retained until g or the attributes of its nodes or edges are
+// changed. For example, a view of a community identified by Communities
+// can be defined with
+//
+//	g.DefineView("community 3", func(n *graphprac.Node) bool {
+//		return n.Get("community") == "3"
+//	})
+//
+// Defining a view with an existing name replaces the view, and a nil pred
+// removes it.
+func (g *Graph) DefineView(name string, pred func(*Node) bool) {
+	if pred == nil {
+		delete(g.views, name)
+		return
+	}
+	if g.views == nil {
+		g.views = make(map[string]*view)
+		g.Subscribe(func(Event) {
+			for _, v := range g.views {
+				v.induced = nil
+			}
+		})
+	}
+	g.views[name] = &view{pred: pred}
+}
+
+// View returns the named view of g defined by DefineView, or nil if there
+// is no view with the given name.
+func (g *Graph) View(name string) graph.Graph {
+	v, ok := g.views[name]
+	if !ok {
+		return nil
+	}
+	if v.induced == nil {
+		var nodes []*Node
+		for _, n := range NodesOf(g) {
+			if v.pred(n) {
+				nodes = append(nodes, n)
+			}
+		}
+		v.induced = Induce(g, nodes)
+	}
+	return v.induced
+}
+
+// Views returns the sorted names of the views defined on g.
+func (g *Graph) Views() []string {
+	names := make([]string, 0, len(g.views))
+	for name := range g.views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,66 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"gonum.org/v1/gonum/graph"
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// viewNames returns the sorted names of the nodes in v.
+func viewNames(v graph.Graph) []string {
+	var names []string
+	for _, n := range graph.NodesOf(v.Nodes()) {
+		names = append(names, n.(*Node).Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestView(t *testing.T) {
+	g := graphFromDOT(t, `graph { a [c=1]; b [c=1]; c [c=2]; a -- b -- c }`)
+	var calls int
+	g.DefineView("one", func(n *Node) bool { calls++; return n.Get("c") == "1" })
+	g.DefineView("two", func(n *Node) bool { return n.Get("c") == "2" })
+
+	if got, want := g.Views(), []string{"one", "two"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected views: got:%q want:%q", got, want)
+	}
+	if g.View("three") != nil {
+		t.Error("unexpected view for undefined name")
+	}
+
+	v := g.View("one")
+	if got, want := viewNames(v), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected nodes in view: got:%q want:%q", got, want)
+	}
+	calls = 0
+	g.View("one")
+	if calls != 0 {
+		t.Errorf("unexpected recomputation of unchanged view: %d predicate calls", calls)
+	}
+
+	g.NodeNamed("c").SetAttribute(encoding.Attribute{Key: "c", Value: "1"})
+	if got, want := viewNames(g.View("one")), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected nodes in view after attribute change: got:%q want:%q", got, want)
+	}
+	if got := viewNames(g.View("two")); len(got) != 0 {
+		t.Errorf("unexpected nodes in emptied view: %q", got)
+	}
+
+	g.RemoveNode(g.NodeNamed("a").ID())
+	if got, want := viewNames(g.View("one")), []string{"b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected nodes in view after node removal: got:%q want:%q", got, want)
+	}
+
+	g.DefineView("two", nil)
+	if got, want := g.Views(), []string{"one"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected views after removal: got:%q want:%q", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/graph/encoding"
+	"gonum.org/v1/gonum/mat"
+)
+
+// CountWalks returns the number of walks of the given length between each
+// pair of nodes of g, the matrix power A^length of the adjacency matrix A,
+// with rows and columns ordered by the dense node index returned by
+// g.Index(). Walks may revisit nodes and edges. The diagonal holds the
+// number of closed walks from each node, so for length 3 each diagonal
+// element is twice the number of triangles the node is part of.
+// CountWalks panics if length is negative.
+func CountWalks(g *Graph, length int) *mat.Dense {
+	if length < 0 {
+		panic("graphprac: negative walk length")
+	}
+	var w mat.Dense
+	if g.Nodes().Len() == 0 {
+		return &w
+	}
+	w.Pow(AdjacencyMatrix(g), length)
+	return &w
+}
+
+// CommunicabilityCentrality performs a subgraph centrality analysis on g.
+// The communicability centrality of a node is the diagonal element of the
+// matrix exponential of the adjacency matrix, e^A, for the node. This is a
+// weighted count of the closed walks from the node, with walks of length k
+// weighted by 1/k!. The matrix exponential takes O(n³) time for a graph
+// with n nodes, so the analysis is slow for large graphs.
+//
+// The communicability centrality value is written into the "communicability"
+// attribute of each node.
+func CommunicabilityCentrality(g *Graph) {
+	done, err := trace(g, "CommunicabilityCentrality", []string{"communicability"})
+	if err != nil {
+		return
+	}
+	defer done(nil)
+
+	x := g.Index()
+	if x.Len() == 0 {
+		return
+	}
+	var e mat.Dense
+	e.Exp(AdjacencyMatrix(g))
+	for i := 0; i < x.Len(); i++ {
+		x.Node(i).SetAttribute(encoding.Attribute{Key: "communicability", Value: fmt.Sprint(e.At(i, i))})
+	}
+}
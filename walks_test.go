@@ -0,0 +1,87 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+var walkTests = []struct {
+	dot    string
+	length int
+	want   *mat.Dense
+}{
+	{
+		dot:    `graph { a -- b -- c }`,
+		length: 0,
+		want:   mat.NewDense(3, 3, []float64{1, 0, 0, 0, 1, 0, 0, 0, 1}),
+	},
+	{
+		dot:    `graph { a -- b -- c }`,
+		length: 2,
+		want:   mat.NewDense(3, 3, []float64{1, 0, 1, 0, 2, 0, 1, 0, 1}),
+	},
+	{
+		dot:    `graph { a -- b -- c }`,
+		length: 3,
+		want:   mat.NewDense(3, 3, []float64{0, 2, 0, 2, 0, 2, 0, 2, 0}),
+	},
+	{
+		dot:    `graph { a -- b -- c -- a }`,
+		length: 3,
+		want:   mat.NewDense(3, 3, []float64{2, 3, 3, 3, 2, 3, 3, 3, 2}),
+	},
+	{
+		dot:    `graph {}`,
+		length: 2,
+		want:   &mat.Dense{},
+	},
+}
+
+func TestCountWalks(t *testing.T) {
+	for i, test := range walkTests {
+		g := graphFromDOT(t, test.dot)
+		got := CountWalks(g, test.length)
+		if !mat.Equal(got, test.want) {
+			t.Errorf("unexpected walk counts for test %d:\ngot:\n%v\nwant:\n%v",
+				i, mat.Formatted(got), mat.Formatted(test.want))
+		}
+	}
+}
+
+var communicabilityTests = []struct {
+	dot  string
+	want map[string]float64
+}{
+	{
+		dot:  `graph { a -- b; c }`,
+		want: map[string]float64{"a": math.Cosh(1), "b": math.Cosh(1), "c": 1},
+	},
+	{
+		// The adjacency matrix of a triangle has
+		// eigenvalues 2, -1 and -1.
+		dot: `graph { a -- b -- c -- a }`,
+		want: map[string]float64{
+			"a": (math.Exp(2) + 2*math.Exp(-1)) / 3,
+			"b": (math.Exp(2) + 2*math.Exp(-1)) / 3,
+			"c": (math.Exp(2) + 2*math.Exp(-1)) / 3,
+		},
+	},
+}
+
+func TestCommunicabilityCentrality(t *testing.T) {
+	for i, test := range communicabilityTests {
+		g := graphFromDOT(t, test.dot)
+		CommunicabilityCentrality(g)
+		for name, want := range test.want {
+			if got := floatAttr(t, g, name, "communicability"); math.Abs(got-want) > 1e-10 {
+				t.Errorf("unexpected communicability for test %d node %s: got:%v want:%v", i, name, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build js && wasm
+// +build js,wasm
+
+// The wasm command exports graphprac functions to JavaScript so that the
+// practical can be run in a browser. It is built with
+//
+//	GOOS=js GOARCH=wasm go build -o graphprac.wasm ./wasm
+//
+// and loaded with the wasm_exec.js support file distributed with Go.
+// Once running, it defines a global graphprac object with the functions:
+//
+//	load(dot)                   parse a DOT graph, returning a graph handle
+//	analyses()                  return the names of the available analyses
+//	analyse(h, name, params)    run the named analysis with an object of parameters
+//	dot(h)                      return the graph and its attributes as DOT
+//	draw(h, plot, attr)         return an SVG arc, chord or hive plot of the graph
+//	free(h)                     discard the graph
+//
+// DOT returned by dot may be rendered with a JavaScript port of GraphViz.
+// Functions return an Error value on failure.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"syscall/js"
+
+	"github.com/kortschak/graphprac"
+)
+
+var (
+	graphs = make(map[int]*graphprac.Graph)
+	next   int
+)
+
+func main() {
+	api := map[string]interface{}{
+		"load":     js.FuncOf(load),
+		"analyses": js.FuncOf(analyses),
+		"analyse":  js.FuncOf(analyse),
+		"dot":      js.FuncOf(dot),
+		"draw":     js.FuncOf(draw),
+		"free":     js.FuncOf(free),
+	}
+	js.Global().Set("graphprac", js.ValueOf(api))
+	select {}
+}
+
+func load(_ js.Value, args []js.Value) interface{} {
+	if len(args) != 1 {
+		return jsError(fmt.Errorf("load: expected 1 argument, got %d", len(args)))
+	}
+	g, err := graphprac.ReadDOT(strings.NewReader(args[0].String()))
+	if err != nil {
+		return jsError(err)
+	}
+	next++
+	graphs[next] = g
+	return next
+}
+
+func analyses(_ js.Value, _ []js.Value) interface{} {
+	names := graphprac.Analyses()
+	vals := make([]interface{}, len(names))
+	for i, n := range names {
+		vals[i] = n
+	}
+	return vals
+}
+
+func analyse(_ js.Value, args []js.Value) (result interface{}) {
+	// A panic in a callback would terminate
+	// the program, losing all loaded graphs.
+	defer func() {
+		r := recover()
+		if r != nil {
+			result = jsError(fmt.Errorf("analyse: analysis failed: %v", r))
+		}
+	}()
+	if len(args) < 2 {
+		return jsError(fmt.Errorf("analyse: expected at least 2 arguments, got %d", len(args)))
+	}
+	g, err := graphOf(args[0])
+	if err != nil {
+		return jsError(err)
+	}
+	params := make(map[string]string)
+	if len(args) > 2 && args[2].Type() == js.TypeObject {
+		keys := js.Global().Get("Object").Call("keys", args[2])
+		for i := 0; i < keys.Length(); i++ {
+			k := keys.Index(i).String()
+			params[k] = js.Global().Call("String", args[2].Get(k)).String()
+		}
+	}
+	name := args[1].String()
+	err = graphprac.ValidateParams(name, params)
+	if err != nil {
+		return jsError(err)
+	}
+	err = graphprac.Run(g, name, params)
+	if err != nil {
+		return jsError(err)
+	}
+	return nil
+}
+
+func dot(_ js.Value, args []js.Value) interface{} {
+	if len(args) != 1 {
+		return jsError(fmt.Errorf("dot: expected 1 argument, got %d", len(args)))
+	}
+	g, err := graphOf(args[0])
+	if err != nil {
+		return jsError(err)
+	}
+	return graphprac.DOT(g)
+}
+
+func draw(_ js.Value, args []js.Value) interface{} {
+	if len(args) != 3 {
+		return jsError(fmt.Errorf("draw: expected 3 arguments, got %d", len(args)))
+	}
+	g, err := graphOf(args[0])
+	if err != nil {
+		return jsError(err)
+	}
+	var buf bytes.Buffer
+	attr := args[2].String()
+	switch plot := args[1].String(); plot {
+	case "arc":
+		err = graphprac.PlotArc(g, attr, &buf)
+	case "chord":
+		err = graphprac.PlotChord(g, attr, &buf)
+	case "hive":
+		err = graphprac.PlotHive(g, graphprac.HiveSpec{Axis: attr}, &buf)
+	default:
+		err = fmt.Errorf("draw: unknown plot %q", plot)
+	}
+	if err != nil {
+		return jsError(err)
+	}
+	return buf.String()
+}
+
+func free(_ js.Value, args []js.Value) interface{} {
+	if len(args) != 1 {
+		return jsError(fmt.Errorf("free: expected 1 argument, got %d", len(args)))
+	}
+	delete(graphs, args[0].Int())
+	return nil
+}
+
+// graphOf returns the graph referred to by the handle h.
+func graphOf(h js.Value) (*graphprac.Graph, error) {
+	if h.Type() != js.TypeNumber {
+		return nil, fmt.Errorf("invalid graph handle: %v", h)
+	}
+	g, ok := graphs[h.Int()]
+	if !ok {
+		return nil, fmt.Errorf("no graph with handle %d", h.Int())
+	}
+	return g, nil
+}
+
+// jsError returns err as a JavaScript Error.
+func jsError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
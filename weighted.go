@@ -0,0 +1,122 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/graph/encoding"
+)
+
+// Strength performs a weighted degree analysis on g. The strength of a node
+// is the sum of the weights of its edges. Edge weights are given by the
+// "weight" attribute of each edge, or 1 if it is not set.
+//
+// The strength value is written into the "strength" attribute of each node.
+func Strength(g *Graph) (err error) {
+	done, err := trace(g, "Strength", []string{"strength"})
+	if err != nil {
+		return err
+	}
+	defer func() { done(err) }()
+
+	x := g.Index()
+	adj := x.adjacency(g)
+	w, err := edgeWeights(g, x, adj)
+	if err != nil {
+		return err
+	}
+	for i := range adj {
+		x.Node(i).SetAttribute(encoding.Attribute{Key: "strength", Value: fmt.Sprint(sum(w[i]))})
+	}
+	return nil
+}
+
+// WeightedClustering performs a weighted local clustering coefficient
+// analysis on g using the definition of Barrat et al. (doi:10.1073/pnas.0400087101).
+// Each triangle at a node contributes the mean weight of the two triangle
+// edges meeting at the node, normalised by the node's strength, so the
+// coefficient is equal to the unweighted clustering coefficient when all
+// weights are equal. Edge weights are given by the "weight" attribute of
+// each edge, or 1 if it is not set.
+//
+// The clustering coefficient value is written into the "weighted_clustering"
+// attribute of each node.
+func WeightedClustering(g *Graph) (err error) {
+	done, err := trace(g, "WeightedClustering", []string{"weighted_clustering"})
+	if err != nil {
+		return err
+	}
+	defer func() { done(err) }()
+
+	x := g.Index()
+	adj := x.adjacency(g)
+	w, err := edgeWeights(g, x, adj)
+	if err != nil {
+		return err
+	}
+
+	// marked holds the weight of the edge to each
+	// neighbour of the current node, or -1.
+	marked := make([]float64, len(adj))
+	for i := range marked {
+		marked[i] = -1
+	}
+	for i, to := range adj {
+		for k, j := range to {
+			marked[j] = w[i][k]
+		}
+		var tri float64
+		for _, j := range to {
+			for _, h := range adj[j] {
+				if h > j && marked[h] >= 0 {
+					tri += marked[j] + marked[h]
+				}
+			}
+		}
+		for _, j := range to {
+			marked[j] = -1
+		}
+
+		var c float64
+		k := float64(len(to))
+		if k > 1 {
+			c = tri / (sum(w[i]) * (k - 1))
+		}
+		x.Node(i).SetAttribute(encoding.Attribute{Key: "weighted_clustering", Value: fmt.Sprint(c)})
+	}
+	return nil
+}
+
+// edgeWeights returns the weights of the edges in the adjacency lists adj
+// of g indexed by x. Weights are given by the "weight" attribute of each
+// edge, or 1 if it is not set. Negative weights are an error.
+func edgeWeights(g *Graph, x NodeIndex, adj [][]int) ([][]float64, error) {
+	w := make([][]float64, len(adj))
+	for i, to := range adj {
+		w[i] = make([]float64, len(to))
+		for k, j := range to {
+			e := g.EdgeBetween(x.ID(i), x.ID(j)).(*Edge)
+			v, err := edgeWeight(e, "weight")
+			if err != nil {
+				return nil, err
+			}
+			if v < 0 {
+				return nil, fmt.Errorf("negative weight for edge %q--%q: %v", e.F.Name, e.T.Name, v)
+			}
+			w[i][k] = v
+		}
+	}
+	return w, nil
+}
+
+// sum returns the sum of the values in s.
+func sum(s []float64) float64 {
+	var t float64
+	for _, v := range s {
+		t += v
+	}
+	return t
+}
@@ -0,0 +1,66 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"math"
+	"testing"
+)
+
+var weightedTests = []struct {
+	dot        string
+	strength   map[string]float64
+	clustering map[string]float64
+}{
+	{
+		dot:        `graph { a -- b -- c -- a; c -- d }`,
+		strength:   map[string]float64{"a": 2, "b": 2, "c": 3, "d": 1},
+		clustering: map[string]float64{"a": 1, "b": 1, "c": 1.0 / 3, "d": 0},
+	},
+	{
+		dot:        `graph { a -- b [weight=1]; a -- c [weight=3]; b -- c [weight=2]; c -- d [weight=4] }`,
+		strength:   map[string]float64{"a": 4, "b": 3, "c": 9, "d": 4},
+		clustering: map[string]float64{"a": 1, "b": 1, "c": 5.0 / 18, "d": 0},
+	},
+}
+
+func TestWeighted(t *testing.T) {
+	for i, test := range weightedTests {
+		g := graphFromDOT(t, test.dot)
+		if err := Strength(g); err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		if err := WeightedClustering(g); err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		for name, want := range test.strength {
+			if got := floatAttr(t, g, name, "strength"); got != want {
+				t.Errorf("unexpected strength for test %d node %s: got:%v want:%v", i, name, got, want)
+			}
+		}
+		for name, want := range test.clustering {
+			if got := floatAttr(t, g, name, "weighted_clustering"); math.Abs(got-want) > 1e-12 {
+				t.Errorf("unexpected clustering for test %d node %s: got:%v want:%v", i, name, got, want)
+			}
+		}
+	}
+}
+
+func TestWeightedInvalid(t *testing.T) {
+	for _, dot := range []string{
+		`graph { a -- b [weight=-1] }`,
+		`graph { a -- b [weight=heavy] }`,
+	} {
+		g := graphFromDOT(t, dot)
+		if err := Strength(g); err == nil {
+			t.Errorf("expected error from Strength for %s", dot)
+		}
+		if err := WeightedClustering(g); err == nil {
+			t.Errorf("expected error from WeightedClustering for %s", dot)
+		}
+	}
+}
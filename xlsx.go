@@ -0,0 +1,283 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/graph"
+)
+
+// WriteXLSX writes g to an Excel workbook at path. The workbook has three
+// sheets: Nodes, with a row for each node holding its DOT ID and its
+// attributes, Edges, with a row for each edge holding the DOT IDs of its
+// ends and its attributes, and Summary, holding the statistics returned by
+// Stats and the provenance of the written attributes. Only the attributes
+// in attrs are written, and each is written only to the sheets where it is
+// set on at least one node or edge. If attrs is nil, all attributes are
+// written. Numerical attribute values are written as numbers and other
+// values as text with any DOT quoting removed.
+func WriteXLSX(g *Graph, path string, attrs []string) error {
+	x := g.Index()
+	nodes := make([]*Node, x.Len())
+	for i := range nodes {
+		nodes[i] = x.Node(i)
+	}
+	edges := make([]*Edge, 0, g.Edges().Len())
+	for _, e := range graph.EdgesOf(g.Edges()) {
+		edges = append(edges, e.(*Edge))
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		return a.F.ID() < b.F.ID() || (a.F.ID() == b.F.ID() && a.T.ID() < b.T.ID())
+	})
+
+	nodeAttrs := make([]Attributes, len(nodes))
+	for i, n := range nodes {
+		nodeAttrs[i] = exportAttributes(n.Attributes)
+	}
+	edgeAttrs := make([]Attributes, len(edges))
+	for i, e := range edges {
+		edgeAttrs[i] = exportAttributes(e.Attributes)
+	}
+	nodeKeys := xlsxColumns(nodeAttrs, attrs)
+	edgeKeys := xlsxColumns(edgeAttrs, attrs)
+
+	nodeSheet := [][]interface{}{xlsxHeader([]string{"name"}, nodeKeys)}
+	for i, n := range nodes {
+		nodeSheet = append(nodeSheet, xlsxRow([]interface{}{n.Name}, nodeAttrs[i], nodeKeys))
+	}
+	edgeSheet := [][]interface{}{xlsxHeader([]string{"source", "target"}, edgeKeys)}
+	for i, e := range edges {
+		edgeSheet = append(edgeSheet, xlsxRow([]interface{}{e.F.Name, e.T.Name}, edgeAttrs[i], edgeKeys))
+	}
+
+	s := Stats(g)
+	summary := [][]interface{}{
+		{"statistic", "value"},
+		{"nodes", float64(s.Nodes)},
+		{"edges", float64(s.Edges)},
+		{"density", s.Density},
+		{"minimum degree", float64(s.Degree.Min)},
+		{"maximum degree", float64(s.Degree.Max)},
+		{"mean degree", s.Degree.Mean},
+		{"degree variance", s.Degree.Variance},
+		{"degree skew", s.Degree.Skew},
+		{"average clustering", s.AverageClustering},
+		{"transitivity", s.Transitivity},
+		{"triangles", float64(s.Triangles)},
+		{"components", float64(s.Components)},
+		{"largest component", float64(s.LargestComponent)},
+		{"diameter lower bound", float64(s.Diameter.Lower)},
+		{"diameter upper bound", float64(s.Diameter.Upper)},
+	}
+	written := make(map[string]bool)
+	for _, k := range append(nodeKeys, edgeKeys...) {
+		written[k] = true
+	}
+	keys := make([]string, 0, len(written))
+	for k := range written {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var prov [][]interface{}
+	for _, k := range keys {
+		p, ok := Provenance(g, k)
+		if ok {
+			prov = append(prov, []interface{}{k, p.call(), p.Time.UTC().Format("2006-01-02 15:04:05")})
+		}
+	}
+	if len(prov) != 0 {
+		summary = append(summary, nil, []interface{}{"attribute", "analysis", "time"})
+		summary = append(summary, prov...)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = writeXLSX(f, []xlsxSheet{
+		{name: "Nodes", rows: nodeSheet},
+		{name: "Edges", rows: edgeSheet},
+		{name: "Summary", rows: summary},
+	})
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// xlsxColumns returns the keys of the attributes in attrs that are set in
+// at least one of the elements of a, in the order of attrs, or all the
+// keys set in a in lexical order if attrs is nil.
+func xlsxColumns(a []Attributes, attrs []string) []string {
+	set := make(map[string]bool)
+	for _, kv := range a {
+		for _, attr := range kv {
+			set[attr.Key] = true
+		}
+	}
+	if attrs == nil {
+		keys := make([]string, 0, len(set))
+		for k := range set {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return keys
+	}
+	var keys []string
+	for _, k := range attrs {
+		if set[k] {
+			keys = append(keys, k)
+			delete(set, k)
+		}
+	}
+	return keys
+}
+
+// xlsxHeader returns a header row with the given leading column names
+// followed by the attribute keys.
+func xlsxHeader(lead, keys []string) []interface{} {
+	row := make([]interface{}, 0, len(lead)+len(keys))
+	for _, s := range lead {
+		row = append(row, s)
+	}
+	for _, k := range keys {
+		row = append(row, k)
+	}
+	return row
+}
+
+// xlsxRow returns a row holding lead followed by the values of the keys
+// in a. Numerical values are returned as float64, unset attributes as nil
+// and all other values as unquoted strings.
+func xlsxRow(lead []interface{}, a Attributes, keys []string) []interface{} {
+	row := lead
+	for _, k := range keys {
+		if a.Get(k) == "" {
+			row = append(row, nil)
+			continue
+		}
+		s := a.GetUnquoted(k)
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			row = append(row, s)
+		} else {
+			row = append(row, v)
+		}
+	}
+	return row
+}
+
+// xlsxSheet is a worksheet of a workbook. Each cell is a float64, a string
+// or nil for an empty cell, and a nil row is an empty row.
+type xlsxSheet struct {
+	name string
+	rows [][]interface{}
+}
+
+// writeXLSX writes a minimal Office Open XML workbook holding the given
+// sheets to w.
+func writeXLSX(w io.Writer, sheets []xlsxSheet) error {
+	z := zip.NewWriter(w)
+
+	var types, rels, book strings.Builder
+	types.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	rels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	book.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, s := range sheets {
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%[1]d.xml"/>`, i+1)
+		fmt.Fprintf(&book, `<sheet name="%s" sheetId="%d" r:id="rId%[2]d"/>`, s.name, i+1)
+	}
+	types.WriteString(`</Types>`)
+	rels.WriteString(`</Relationships>`)
+	book.WriteString(`</sheets></workbook>`)
+
+	parts := []struct {
+		name, content string
+	}{
+		{name: "[Content_Types].xml", content: types.String()},
+		{name: "_rels/.rels", content: xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{name: "xl/workbook.xml", content: book.String()},
+		{name: "xl/_rels/workbook.xml.rels", content: rels.String()},
+	}
+	for _, p := range parts {
+		pw, err := z.Create(p.name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(pw, p.content)
+		if err != nil {
+			return err
+		}
+	}
+	for i, s := range sheets {
+		pw, err := z.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return err
+		}
+		err = writeXLSXSheet(pw, s.rows)
+		if err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// writeXLSXSheet writes the worksheet part holding rows to w.
+func writeXLSXSheet(w io.Writer, rows [][]interface{}) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		if row == nil {
+			continue
+		}
+		fmt.Fprintf(bw, `<row r="%d">`, i+1)
+		for j, c := range row {
+			ref := xlsxColumn(j) + strconv.Itoa(i+1)
+			switch c := c.(type) {
+			case nil:
+			case float64:
+				fmt.Fprintf(bw, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(c, 'g', -1, 64))
+			case string:
+				fmt.Fprintf(bw, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+				xml.EscapeText(bw, []byte(c))
+				fmt.Fprint(bw, `</t></is></c>`)
+			default:
+				panic(fmt.Sprintf("graphprac: invalid cell type %T", c))
+			}
+		}
+		fmt.Fprint(bw, `</row>`)
+	}
+	fmt.Fprint(bw, `</sheetData></worksheet>`)
+	return bw.Flush()
+}
+
+// xlsxColumn returns the spreadsheet column name of the zero-based column
+// index i, for example "A" for 0 and "AA" for 26.
+func xlsxColumn(i int) string {
+	var b []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		b = append([]byte{byte('A' + (i-1)%26)}, b...)
+	}
+	return string(b)
+}
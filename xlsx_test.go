@@ -0,0 +1,186 @@
+// Copyright ©2017 Dan Kortschak. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphprac
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+var xlsxColumnTests = []struct {
+	index int
+	want  string
+}{
+	{index: 0, want: "A"},
+	{index: 1, want: "B"},
+	{index: 25, want: "Z"},
+	{index: 26, want: "AA"},
+	{index: 51, want: "AZ"},
+	{index: 52, want: "BA"},
+	{index: 701, want: "ZZ"},
+	{index: 702, want: "AAA"},
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for _, test := range xlsxColumnTests {
+		if got := xlsxColumn(test.index); got != test.want {
+			t.Errorf("unexpected column name for %d: got:%s want:%s", test.index, got, test.want)
+		}
+	}
+}
+
+// xlsxCells returns the cells of each sheet of the workbook at path keyed
+// by sheet name and then cell reference. Text cells are prefixed with a
+// single quote to distinguish them from numbers.
+func xlsxCells(t *testing.T, path string) map[string]map[string]string {
+	t.Helper()
+	z, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("failed to open workbook: %v", err)
+	}
+	defer z.Close()
+	parts := make(map[string]*zip.File)
+	for _, f := range z.File {
+		parts[f.Name] = f
+	}
+	decode := func(name string, v interface{}) {
+		f, ok := parts[name]
+		if !ok {
+			t.Fatalf("missing workbook part %s", name)
+		}
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", name, err)
+		}
+		defer r.Close()
+		err = xml.NewDecoder(r).Decode(v)
+		if err != nil {
+			t.Fatalf("failed to decode %s: %v", name, err)
+		}
+	}
+	if _, ok := parts["[Content_Types].xml"]; !ok {
+		t.Fatal("missing content types")
+	}
+
+	var book struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	decode("xl/workbook.xml", &book)
+	sheets := make(map[string]map[string]string)
+	for i, s := range book.Sheets {
+		var sheet struct {
+			Rows []struct {
+				Cells []struct {
+					Ref    string `xml:"r,attr"`
+					Type   string `xml:"t,attr"`
+					Value  string `xml:"v"`
+					Inline string `xml:"is>t"`
+				} `xml:"c"`
+			} `xml:"sheetData>row"`
+		}
+		decode("xl/worksheets/sheet"+strconv.Itoa(i+1)+".xml", &sheet)
+		cells := make(map[string]string)
+		for _, r := range sheet.Rows {
+			for _, c := range r.Cells {
+				if c.Type == "inlineStr" {
+					cells[c.Ref] = "'" + c.Inline
+				} else {
+					cells[c.Ref] = c.Value
+				}
+			}
+		}
+		sheets[s.Name] = cells
+	}
+	return sheets
+}
+
+var xlsxTests = []struct {
+	attrs []string
+	nodes map[string]string
+	edges map[string]string
+}{
+	{
+		attrs: nil,
+		nodes: map[string]string{
+			"A1": "'name", "B1": "'label", "C1": "'rank",
+			"A2": "'a", "B2": "'A <node>", "C2": "0.5",
+			"A3": "'b", "C3": "0.25",
+			"A4": "'c", "B4": "2",
+		},
+		edges: map[string]string{
+			"A1": "'source", "B1": "'target", "C1": "'weight",
+			"A2": "'a", "B2": "'b", "C2": "2",
+			"A3": "'b", "B3": "'c",
+		},
+	},
+	{
+		attrs: []string{"rank", "missing", "label"},
+		nodes: map[string]string{
+			"A1": "'name", "B1": "'rank", "C1": "'label",
+			"A2": "'a", "B2": "0.5", "C2": "'A <node>",
+			"A3": "'b", "B3": "0.25",
+			"A4": "'c", "C4": "2",
+		},
+		edges: map[string]string{
+			"A1": "'source", "B1": "'target",
+			"A2": "'a", "B2": "'b",
+			"A3": "'b", "B3": "'c",
+		},
+	},
+}
+
+func TestWriteXLSX(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graphprac-xlsx")
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "g.xlsx")
+
+	for i, test := range xlsxTests {
+		g := graphFromDOT(t, `graph {
+	a [label="A <node>", rank=0.5];
+	b [rank=0.25];
+	c [label="2"];
+	a -- b [weight=2];
+	b -- c;
+}`)
+		err := WriteXLSX(g, path, test.attrs)
+		if err != nil {
+			t.Errorf("unexpected error for test %d: %v", i, err)
+			continue
+		}
+		sheets := xlsxCells(t, path)
+		if len(sheets) != 3 {
+			t.Errorf("unexpected number of sheets for test %d: got:%d want:3", i, len(sheets))
+		}
+		if got := sheets["Nodes"]; !reflect.DeepEqual(got, test.nodes) {
+			t.Errorf("unexpected Nodes sheet for test %d:\ngot: %v\nwant:%v", i, got, test.nodes)
+		}
+		if got := sheets["Edges"]; !reflect.DeepEqual(got, test.edges) {
+			t.Errorf("unexpected Edges sheet for test %d:\ngot: %v\nwant:%v", i, got, test.edges)
+		}
+		summary := sheets["Summary"]
+		for ref, want := range map[string]string{
+			"A1": "'statistic", "B1": "'value",
+			"A2": "'nodes", "B2": "3",
+			"A3": "'edges", "B3": "2",
+			"A12": "'triangles", "B12": "0",
+			"A13": "'components", "B13": "1",
+		} {
+			if got := summary[ref]; got != want {
+				t.Errorf("unexpected Summary cell %s for test %d: got:%s want:%s", ref, i, got, want)
+			}
+		}
+	}
+}